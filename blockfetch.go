@@ -120,9 +120,10 @@ func (n *Node) blockfetchClientBlock(
 		event.NewEvent(
 			state.BlockfetchEventType,
 			state.BlockfetchEvent{
-				Point: ocommon.NewPoint(block.SlotNumber(), blkHash),
-				Type:  blockType,
-				Block: block,
+				ConnectionId: ctx.ConnectionId,
+				Point:        ocommon.NewPoint(block.SlotNumber(), blkHash),
+				Type:         blockType,
+				Block:        block,
 			},
 		),
 	)
@@ -185,9 +185,10 @@ func (n *Node) chainsyncClientRollBackward(
 		event.NewEvent(
 			state.ChainsyncEventType,
 			state.ChainsyncEvent{
-				Rollback: true,
-				Point:    point,
-				Tip:      tip,
+				ConnectionId: ctx.ConnectionId,
+				Rollback:     true,
+				Point:        point,
+				Tip:          tip,
 			},
 		),
 	)
@@ -202,6 +203,10 @@ func (n *Node) chainsyncClientRollForward(
 ) error {
 	switch v := blockData.(type) {
 	case ledger.BlockHeader:
+		// Wait for our chain to catch up if we've gotten too far ahead with this peer
+		if err := n.ledgerState.ChainsyncWait(ctx.ConnectionId); err != nil {
+			return err
+		}
 		blockSlot := v.SlotNumber()
 		blockHash, _ := hex.DecodeString(v.Hash())
 		n.eventBus.Publish(
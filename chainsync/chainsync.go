@@ -32,10 +32,9 @@ type ChainsyncClientState struct {
 
 type State struct {
 	sync.Mutex
-	eventBus     *event.EventBus
-	ledgerState  *state.LedgerState
-	clients      map[ouroboros.ConnectionId]*ChainsyncClientState
-	clientConnId *ouroboros.ConnectionId // TODO: replace with handling of multiple chainsync clients (#385)
+	eventBus    *event.EventBus
+	ledgerState *state.LedgerState
+	clients     map[ouroboros.ConnectionId]*ChainsyncClientState
 }
 
 func NewState(
@@ -77,20 +76,3 @@ func (s *State) RemoveClient(connId connection.ConnectionId) {
 	// Remove client state entry
 	delete(s.clients, connId)
 }
-
-// TODO: replace with handling of multiple chainsync clients (#385)
-func (s *State) GetClientConnId() *ouroboros.ConnectionId {
-	return s.clientConnId
-}
-
-// TODO: replace with handling of multiple chainsync clients (#385)
-func (s *State) SetClientConnId(connId ouroboros.ConnectionId) {
-	s.clientConnId = &connId
-}
-
-// TODO: replace with handling of multiple chainsync clients (#385)
-func (s *State) RemoveClientConnId(connId ouroboros.ConnectionId) {
-	if s.clientConnId != nil && *s.clientConnId == connId {
-		s.clientConnId = nil
-	}
-}
@@ -0,0 +1,75 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/blinklabs-io/dingo/internal/node"
+	"github.com/spf13/cobra"
+)
+
+func leaderlogCommand() *cobra.Command {
+	var vrfSkeyFile, poolId, epoch, nodeUrl string
+	leaderlogCmd := &cobra.Command{
+		Use:   "leaderlog",
+		Short: "Show the slots in which a pool is elected to produce blocks",
+		Long: "Show the slots in the current or next epoch in which a pool is elected to produce blocks. " +
+			"The epoch nonce and stake distribution are queried from a running node, and the slots are " +
+			"calculated locally with the pool's VRF signing key",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Configure logger
+			logger := configureLogger()
+			// Load config
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				slog.Error(err.Error())
+				os.Exit(1)
+			}
+			var next bool
+			switch epoch {
+			case "current":
+			case "next":
+				next = true
+			default:
+				slog.Error(
+					fmt.Sprintf(
+						"invalid epoch %q, must be one of: current, next",
+						epoch,
+					),
+				)
+				os.Exit(1)
+			}
+			if err := node.Leaderlog(cfg, logger, nodeUrl, vrfSkeyFile, poolId, next); err != nil {
+				slog.Error(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+	leaderlogCmd.Flags().
+		StringVarP(&vrfSkeyFile, "vrf-skey", "", "", "path to the pool VRF signing key file")
+	leaderlogCmd.Flags().
+		StringVarP(&poolId, "pool-id", "", "", "pool ID in bech32 or hex format")
+	leaderlogCmd.Flags().
+		StringVarP(&epoch, "epoch", "", "current", "epoch to calculate the schedule for (current, next)")
+	leaderlogCmd.Flags().
+		StringVarP(&nodeUrl, "node-url", "", "", "URL of the node's UTxO RPC listener (default uses the configured UTxO RPC port on localhost)")
+	_ = leaderlogCmd.MarkFlagRequired("vrf-skey")
+	_ = leaderlogCmd.MarkFlagRequired("pool-id")
+	return leaderlogCmd
+}
@@ -0,0 +1,48 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/blinklabs-io/dingo/internal/node"
+	"github.com/spf13/cobra"
+)
+
+func loadCommand() *cobra.Command {
+	loadCmd := &cobra.Command{
+		Use:   "load <immutable-dir>",
+		Short: "Load blocks from a cardano-node ImmutableDB directory",
+		Long: "Load blocks from a cardano-node ImmutableDB directory (such as from a Mithril snapshot) " +
+			"into the database. Loading resumes from the current tip if the database already contains blocks",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Configure logger
+			logger := configureLogger()
+			// Load config
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				slog.Error(err.Error())
+				os.Exit(1)
+			}
+			if err := node.Load(cfg, logger, args[0]); err != nil {
+				slog.Error(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+	return loadCmd
+}
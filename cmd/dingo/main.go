@@ -15,13 +15,18 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 
+	"github.com/blinklabs-io/dingo/database/plugin"
+	"github.com/blinklabs-io/dingo/internal/config"
 	"github.com/blinklabs-io/dingo/internal/node"
 	"github.com/blinklabs-io/dingo/internal/version"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"go.uber.org/automaxprocs/maxprocs"
 )
 
@@ -35,35 +40,100 @@ func slogPrintf(format string, v ...any) {
 	)
 }
 
-func main() {
-	globalFlags := struct {
-		version bool
-		debug   bool
-	}{}
+var globalFlags = struct {
+	version    bool
+	debug      bool
+	configFile string
+}{}
+
+// pluginsUsage returns a description of the available database plugins for the command help
+func pluginsUsage() string {
+	var sb strings.Builder
+	sb.WriteString("Available database plugins:\n")
+	for _, pluginType := range []plugin.PluginType{plugin.PluginTypeBlob, plugin.PluginTypeMetadata} {
+		sb.WriteString(
+			fmt.Sprintf("\n  %s:\n", plugin.PluginTypeName(pluginType)),
+		)
+		for _, p := range plugin.GetPlugins(pluginType) {
+			sb.WriteString(
+				fmt.Sprintf("    %-12s %s\n", p.Name, p.Description),
+			)
+		}
+	}
+	return sb.String()
+}
+
+// loadConfig loads the config from the config file and environment. Any flags explicitly provided on the
+// command line take precedence over both
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	// Capture explicitly set flags, since loading the config will overwrite their values
+	setFlags := map[string]string{}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		setFlags[f.Name] = f.Value.String()
+	})
+	cfg, err := config.LoadConfig(globalFlags.configFile)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range setFlags {
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// addDatabaseFlags adds flags for selecting the database plugins and setting their options
+func addDatabaseFlags(cmd *cobra.Command) error {
+	cfg := config.GetConfig()
+	cmd.PersistentFlags().
+		StringVarP(&cfg.BlobPlugin, "blob-plugin", "", cfg.BlobPlugin, "blob database plugin to use")
+	cmd.PersistentFlags().
+		StringVarP(&cfg.MetadataPlugin, "metadata-plugin", "", cfg.MetadataPlugin, "metadata database plugin to use")
+	pluginFlags := flag.NewFlagSet(programName, flag.ExitOnError)
+	if err := plugin.PopulateCmdlineOptions(pluginFlags); err != nil {
+		return err
+	}
+	cmd.PersistentFlags().AddGoFlagSet(pluginFlags)
+	return nil
+}
+
+func configureLogger() *slog.Logger {
+	logLevel := slog.LevelInfo
+	addSource := false
+	if globalFlags.debug {
+		logLevel = slog.LevelDebug
+		// addSource = true
+	}
+	logger := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			AddSource: addSource,
+			Level:     logLevel,
+		}),
+	)
+	slog.SetDefault(logger)
+	return logger
+}
 
+func main() {
 	rootCmd := &cobra.Command{
-		Use: programName,
+		Use:  programName,
+		Long: "Dingo is a Cardano blockchain data node\n\n" + pluginsUsage(),
 		Run: func(cmd *cobra.Command, args []string) {
 			if globalFlags.version {
 				fmt.Printf("%s %s\n", programName, version.GetVersionString())
 				os.Exit(0)
 			}
 			// Configure logger
-			logLevel := slog.LevelInfo
-			addSource := false
-			if globalFlags.debug {
-				logLevel = slog.LevelDebug
-				// addSource = true
+			logger := configureLogger()
+			// Load config
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				slog.Error(err.Error())
+				os.Exit(1)
 			}
-			logger := slog.New(
-				slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-					AddSource: addSource,
-					Level:     logLevel,
-				}),
-			)
-			slog.SetDefault(logger)
 			// Configure max processes with our logger wrapper, toss undo func
-			_, err := maxprocs.Set(maxprocs.Logger(slogPrintf))
+			_, err = maxprocs.Set(maxprocs.Logger(slogPrintf))
 			if err != nil {
 				// If we hit this, something really wrong happened
 				slog.Error(err.Error())
@@ -74,7 +144,7 @@ func main() {
 				"version: "+version.GetVersionString(),
 				"component", programName,
 			)
-			if err := node.Run(logger); err != nil {
+			if err := node.Run(cfg, logger); err != nil {
 				slog.Error(err.Error())
 				os.Exit(1)
 			}
@@ -86,6 +156,18 @@ func main() {
 		BoolVarP(&globalFlags.debug, "debug", "D", false, "enable debug logging")
 	rootCmd.PersistentFlags().
 		BoolVarP(&globalFlags.version, "version", "", false, "show version and exit")
+	rootCmd.PersistentFlags().
+		StringVarP(&globalFlags.configFile, "config", "", "", "path to YAML config file")
+
+	// Database plugin flags
+	if err := addDatabaseFlags(rootCmd); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	// Subcommands
+	rootCmd.AddCommand(loadCommand())
+	rootCmd.AddCommand(leaderlogCommand())
 
 	// Execute cobra command
 	if err := rootCmd.Execute(); err != nil {
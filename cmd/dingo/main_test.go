@@ -0,0 +1,95 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "dingo.yaml")
+	configData := `
+network: preview
+blobPlugin: file-blob
+metadataPlugin: file-metadata
+plugins:
+  blob:
+    badger:
+      block-cache-size: 100
+      index-cache-size: 100
+  metadata:
+    sqlite:
+      cache-size: 100
+`
+	if err := os.WriteFile(configFile, []byte(configData), 0o600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	t.Setenv("CARDANO_METADATA_PLUGIN", "env-metadata")
+	t.Setenv("CARDANO_BLOB_BADGER_BLOCK_CACHE_SIZE", "200")
+	t.Setenv("CARDANO_METADATA_SQLITE_CACHE_SIZE", "200")
+	cmd := &cobra.Command{
+		Run: func(cmd *cobra.Command, args []string) {},
+	}
+	if err := addDatabaseFlags(cmd); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err := cmd.ParseFlags(
+		[]string{
+			"--blob-plugin=flag-blob",
+			"--metadata-sqlite-cache-size=300",
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	globalFlags.configFile = configFile
+	t.Cleanup(func() {
+		globalFlags.configFile = ""
+	})
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Flags take precedence over env vars, which take precedence over the config file
+	if cfg.BlobPlugin != "flag-blob" {
+		t.Fatalf("did not get expected blob plugin: got %s, expected %s", cfg.BlobPlugin, "flag-blob")
+	}
+	if cfg.MetadataPlugin != "env-metadata" {
+		t.Fatalf("did not get expected metadata plugin: got %s, expected %s", cfg.MetadataPlugin, "env-metadata")
+	}
+	testDefs := []struct {
+		flagName string
+		expected string
+	}{
+		{flagName: "metadata-sqlite-cache-size", expected: "300"},
+		{flagName: "blob-badger-block-cache-size", expected: "200"},
+		{flagName: "blob-badger-index-cache-size", expected: "100"},
+	}
+	for _, testDef := range testDefs {
+		value := cmd.Flags().Lookup(testDef.flagName).Value.String()
+		if value != testDef.expected {
+			t.Fatalf(
+				"did not get expected value for plugin option %s: got %s, expected %s",
+				testDef.flagName,
+				value,
+				testDef.expected,
+			)
+		}
+	}
+}
@@ -19,9 +19,12 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"time"
 
 	"github.com/blinklabs-io/dingo/config/cardano"
 	"github.com/blinklabs-io/dingo/connmanager"
+	"github.com/blinklabs-io/dingo/mempool"
+	"github.com/blinklabs-io/dingo/state"
 	"github.com/blinklabs-io/dingo/topology"
 	ouroboros "github.com/blinklabs-io/gouroboros"
 	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
@@ -31,12 +34,19 @@ import (
 type ListenerConfig = connmanager.ListenerConfig
 
 type Config struct {
+	blobPlugin         string
 	cardanoNodeConfig  *cardano.CardanoNodeConfig
 	dataDir            string
 	intersectPoints    []ocommon.Point
 	intersectTip       bool
 	logger             *slog.Logger
 	listeners          []ListenerConfig
+	mempoolMaxBytes    uint64
+	mempoolMaxTxs      uint
+	mempoolOrdering    mempool.OrderingStrategy
+	mempoolPersist     bool
+	mempoolTxTtl       time.Duration
+	metadataPlugin     string
 	network            string
 	networkMagic       uint32
 	outboundSourcePort uint
@@ -44,10 +54,14 @@ type Config struct {
 	tlsCertFilePath    string
 	tlsKeyFilePath     string
 	peerSharing        bool
+	shelleyVrfKey      string
+	shelleyKesKey      string
+	shelleyOpCert      string
 	promRegistry       prometheus.Registerer
 	topologyConfig     *topology.TopologyConfig
 	tracing            bool
 	tracingStdout      bool
+	validationLevel    state.ValidationLevel
 }
 
 // configPopulateNetworkMagic uses the named network (if specified) to determine the network magic value (if not specified)
@@ -85,6 +99,18 @@ func (n *Node) configValidate() error {
 			"listener must provide net.Listener or listen network/address values",
 		)
 	}
+	if n.config.shelleyVrfKey != "" || n.config.shelleyKesKey != "" ||
+		n.config.shelleyOpCert != "" {
+		if n.config.shelleyVrfKey == "" || n.config.shelleyKesKey == "" ||
+			n.config.shelleyOpCert == "" {
+			return errors.New(
+				"block production requires a VRF key, KES key, and operational certificate",
+			)
+		}
+		if n.config.cardanoNodeConfig == nil {
+			return errors.New("block production requires a Cardano node config")
+		}
+	}
 	if n.config.cardanoNodeConfig != nil {
 		shelleyGenesis := n.config.cardanoNodeConfig.ShelleyGenesis()
 		if shelleyGenesis == nil {
@@ -118,6 +144,13 @@ func NewConfig(opts ...ConfigOptionFunc) Config {
 	return c
 }
 
+// WithBlobPlugin specifies the blob database plugin to use. The default is to use badger
+func WithBlobPlugin(blobPlugin string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.blobPlugin = blobPlugin
+	}
+}
+
 // WithCardanoNodeConfig specifies the CardanoNodeConfig object to use. This is mostly used for loading genesis config files
 // referenced by the dingo config
 func WithCardanoNodeConfig(
@@ -163,6 +196,52 @@ func WithListeners(listeners ...ListenerConfig) ConfigOptionFunc {
 	}
 }
 
+// WithMempoolMaxBytes specifies the maximum combined size of mempool transactions in bytes. The default is twice
+// the max block body size from the current protocol parameters
+func WithMempoolMaxBytes(maxBytes uint64) ConfigOptionFunc {
+	return func(c *Config) {
+		c.mempoolMaxBytes = maxBytes
+	}
+}
+
+// WithMempoolMaxTxs specifies the maximum number of mempool transactions. The default is no limit
+func WithMempoolMaxTxs(maxTxs uint) ConfigOptionFunc {
+	return func(c *Config) {
+		c.mempoolMaxTxs = maxTxs
+	}
+}
+
+// WithMempoolOrdering specifies the order in which mempool transactions are offered to peers and selected for new
+// blocks. The default is arrival order
+func WithMempoolOrdering(ordering mempool.OrderingStrategy) ConfigOptionFunc {
+	return func(c *Config) {
+		c.mempoolOrdering = ordering
+	}
+}
+
+// WithMempoolPersist specifies whether to save mempool transactions in the data directory so that they survive a
+// restart. This is disabled by default
+func WithMempoolPersist(persist bool) ConfigOptionFunc {
+	return func(c *Config) {
+		c.mempoolPersist = persist
+	}
+}
+
+// WithMempoolTxTtl specifies how long a transaction can stay in the mempool without being seen again before it's
+// removed. The default is no expiry
+func WithMempoolTxTtl(ttl time.Duration) ConfigOptionFunc {
+	return func(c *Config) {
+		c.mempoolTxTtl = ttl
+	}
+}
+
+// WithMetadataPlugin specifies the metadata database plugin to use. The default is to use sqlite
+func WithMetadataPlugin(metadataPlugin string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.metadataPlugin = metadataPlugin
+	}
+}
+
 // WithNetwork specifies the named network to operate on. This will automatically set the appropriate network magic value
 func WithNetwork(network string) ConfigOptionFunc {
 	return func(c *Config) {
@@ -212,6 +291,28 @@ func WithPeerSharing(peerSharing bool) ConfigOptionFunc {
 	}
 }
 
+// WithShelleyVrfKey specifies the path to the VRF signing key file used for block production
+func WithShelleyVrfKey(path string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.shelleyVrfKey = path
+	}
+}
+
+// WithShelleyKesKey specifies the path to the KES signing key file used for block production
+func WithShelleyKesKey(path string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.shelleyKesKey = path
+	}
+}
+
+// WithShelleyOperationalCertificate specifies the path to the operational certificate file used for block
+// production. Block production is enabled when this is specified along with the VRF and KES keys
+func WithShelleyOperationalCertificate(path string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.shelleyOpCert = path
+	}
+}
+
 // WithPrometheusRegistry specifies a prometheus.Registerer instance to add metrics to. In most cases, prometheus.DefaultRegistry would be
 // a good choice to get metrics working
 func WithPrometheusRegistry(registry prometheus.Registerer) ConfigOptionFunc {
@@ -243,3 +344,11 @@ func WithTracingStdout(stdout bool) ConfigOptionFunc {
 		c.tracingStdout = stdout
 	}
 }
+
+// WithValidationLevel specifies how much validation to perform on blocks received from upstream peers. The default
+// is to trust upstream peers and only log transaction validation failures
+func WithValidationLevel(level state.ValidationLevel) ConfigOptionFunc {
+	return func(c *Config) {
+		c.validationLevel = level
+	}
+}
@@ -28,9 +28,10 @@ import (
 )
 
 const (
-	blockBlobKeyPrefix         = "bp"
-	blockBlobHeightKeyPrefix   = "bh"
-	blockBlobMetadataKeySuffix = "_metadata"
+	blockBlobKeyPrefix          = "bp"
+	blockBlobHeightKeyPrefix    = "bh"
+	blockBlobEbbHeightKeyPrefix = "be"
+	blockBlobMetadataKeySuffix  = "_metadata"
 )
 
 var ErrBlockNotFound = errors.New("block not found")
@@ -56,7 +57,7 @@ func BlockCreateTxn(txn *Txn, block Block) error {
 		return err
 	}
 	// Block height to point key
-	heightKey := BlockBlobHeightKey(block.Number)
+	heightKey := blockHeightKey(block)
 	if err := txn.Blob().Set(heightKey, key); err != nil {
 		return err
 	}
@@ -84,7 +85,7 @@ func BlockDeleteTxn(txn *Txn, block Block) error {
 	if err := txn.Blob().Delete(key); err != nil {
 		return err
 	}
-	heightKey := BlockBlobHeightKey(block.Number)
+	heightKey := blockHeightKey(block)
 	if err := txn.Blob().Delete(heightKey); err != nil {
 		return err
 	}
@@ -123,6 +124,13 @@ func blockByKey(txn *Txn, blockKey []byte) (Block, error) {
 	if err != nil {
 		return ret, err
 	}
+	// An empty value means that the block content has been moved to immutable storage
+	if len(ret.Cbor) == 0 {
+		ret.Cbor, err = txn.DB().immutableBlockCbor(point)
+		if err != nil {
+			return ret, err
+		}
+	}
 	metadataKey := BlockBlobMetadataKey(blockKey)
 	item, err = txn.Blob().Get(metadataKey)
 	if err != nil {
@@ -175,6 +183,35 @@ func BlockByNumberTxn(txn *Txn, blockNumber uint64) (Block, error) {
 	return blockByKey(txn, blockKey)
 }
 
+// BlockEbbByNumber returns the Byron epoch boundary block (EBB) that follows the block with the specified
+// block number
+func BlockEbbByNumber(db *Database, blockNumber uint64) (Block, error) {
+	var ret Block
+	txn := db.Transaction(false)
+	err := txn.Do(func(txn *Txn) error {
+		var err error
+		ret, err = BlockEbbByNumberTxn(txn, blockNumber)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return ErrBlockNotFound
+		}
+		return err
+	})
+	return ret, err
+}
+
+func BlockEbbByNumberTxn(txn *Txn, blockNumber uint64) (Block, error) {
+	heightKey := BlockBlobEbbHeightKey(blockNumber)
+	item, err := txn.Blob().Get(heightKey)
+	if err != nil {
+		return Block{}, err
+	}
+	blockKey, err := item.ValueCopy(nil)
+	if err != nil {
+		return Block{}, err
+	}
+	return blockByKey(txn, blockKey)
+}
+
 func BlocksRecent(db *Database, count int) ([]Block, error) {
 	var ret []Block
 	txn := db.Transaction(false)
@@ -321,6 +358,25 @@ func BlockBlobHeightKey(blockNumber uint64) []byte {
 	return key
 }
 
+// BlockBlobEbbHeightKey returns the key for looking up a Byron epoch boundary block (EBB) by block number. An EBB
+// has the same block number as the block before it, so these are indexed separately
+func BlockBlobEbbHeightKey(blockNumber uint64) []byte {
+	key := []byte(blockBlobEbbHeightKeyPrefix)
+	// Convert block number to bytes
+	blockNumberBytes := blockBlobKeyUint64ToBytes(blockNumber)
+	key = append(key, blockNumberBytes...)
+	return key
+}
+
+// blockHeightKey returns the block number index key for a block. The EBB at the start of the chain has no
+// preceding block, so it's indexed like any other block
+func blockHeightKey(block Block) []byte {
+	if block.Type == ledger.BlockTypeByronEbb && block.Number > 0 {
+		return BlockBlobEbbHeightKey(block.Number)
+	}
+	return BlockBlobHeightKey(block.Number)
+}
+
 func BlockBlobMetadataKey(baseKey []byte) []byte {
 	return slices.Concat(baseKey, []byte(blockBlobMetadataKeySuffix))
 }
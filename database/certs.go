@@ -34,6 +34,42 @@ func (d *Database) GetStakeRegistrations(
 	return d.metadata.GetStakeRegistrations(stakingKey, txn.Metadata())
 }
 
+// SetAuthCommitteeHot saves a committee hot key authorization certificate
+func (d *Database) SetAuthCommitteeHot(
+	cert *lcommon.AuthCommitteeHotCertificate,
+	slot uint64,
+	txn *Txn,
+) error {
+	return d.metadata.SetAuthCommitteeHot(cert, slot, txn.Metadata())
+}
+
+// SetDeregistration saves a deregistration certificate
+func (d *Database) SetDeregistration(
+	cert *lcommon.DeregistrationCertificate,
+	slot uint64,
+	txn *Txn,
+) error {
+	return d.metadata.SetDeregistration(cert, slot, txn.Metadata())
+}
+
+// SetDeregistrationDrep saves a DRep deregistration certificate
+func (d *Database) SetDeregistrationDrep(
+	cert *lcommon.DeregistrationDrepCertificate,
+	slot, deposit uint64,
+	txn *Txn,
+) error {
+	return d.metadata.SetDeregistrationDrep(cert, slot, deposit, txn.Metadata())
+}
+
+// SetGenesisKeyDelegation saves a genesis key delegation certificate, which becomes active at the specified slot
+func (d *Database) SetGenesisKeyDelegation(
+	cert *lcommon.GenesisKeyDelegationCertificate,
+	slot, activeSlot uint64,
+	txn *Txn,
+) error {
+	return d.metadata.SetGenesisKeyDelegation(cert, slot, activeSlot, txn.Metadata())
+}
+
 // SetPoolRegistration saves a pool registration certificate
 func (d *Database) SetPoolRegistration(
 	cert *lcommon.PoolRegistrationCertificate,
@@ -52,6 +88,33 @@ func (d *Database) SetPoolRetirement(
 	return d.metadata.SetPoolRetirement(cert, slot, txn.Metadata())
 }
 
+// SetRegistration saves a registration certificate
+func (d *Database) SetRegistration(
+	cert *lcommon.RegistrationCertificate,
+	slot, deposit uint64,
+	txn *Txn,
+) error {
+	return d.metadata.SetRegistration(cert, slot, deposit, txn.Metadata())
+}
+
+// SetRegistrationDrep saves a DRep registration certificate
+func (d *Database) SetRegistrationDrep(
+	cert *lcommon.RegistrationDrepCertificate,
+	slot, deposit uint64,
+	txn *Txn,
+) error {
+	return d.metadata.SetRegistrationDrep(cert, slot, deposit, txn.Metadata())
+}
+
+// SetResignCommitteeCold saves a committee cold key resignation certificate
+func (d *Database) SetResignCommitteeCold(
+	cert *lcommon.ResignCommitteeColdCertificate,
+	slot uint64,
+	txn *Txn,
+) error {
+	return d.metadata.SetResignCommitteeCold(cert, slot, txn.Metadata())
+}
+
 // SetStakeDelegation saves a stake delegation certificate
 func (d *Database) SetStakeDelegation(
 	cert *lcommon.StakeDelegationCertificate,
@@ -78,3 +141,57 @@ func (d *Database) SetStakeRegistration(
 ) error {
 	return d.metadata.SetStakeRegistration(cert, slot, deposit, txn.Metadata())
 }
+
+// SetStakeRegistrationDelegation saves a combined stake registration and delegation certificate
+func (d *Database) SetStakeRegistrationDelegation(
+	cert *lcommon.StakeRegistrationDelegationCertificate,
+	slot, deposit uint64,
+	txn *Txn,
+) error {
+	return d.metadata.SetStakeRegistrationDelegation(cert, slot, deposit, txn.Metadata())
+}
+
+// SetStakeVoteDelegation saves a combined stake and vote delegation certificate
+func (d *Database) SetStakeVoteDelegation(
+	cert *lcommon.StakeVoteDelegationCertificate,
+	slot uint64,
+	txn *Txn,
+) error {
+	return d.metadata.SetStakeVoteDelegation(cert, slot, txn.Metadata())
+}
+
+// SetStakeVoteRegistrationDelegation saves a combined stake registration, stake delegation and vote delegation certificate
+func (d *Database) SetStakeVoteRegistrationDelegation(
+	cert *lcommon.StakeVoteRegistrationDelegationCertificate,
+	slot, deposit uint64,
+	txn *Txn,
+) error {
+	return d.metadata.SetStakeVoteRegistrationDelegation(cert, slot, deposit, txn.Metadata())
+}
+
+// SetUpdateDrep saves a DRep update certificate
+func (d *Database) SetUpdateDrep(
+	cert *lcommon.UpdateDrepCertificate,
+	slot uint64,
+	txn *Txn,
+) error {
+	return d.metadata.SetUpdateDrep(cert, slot, txn.Metadata())
+}
+
+// SetVoteDelegation saves a vote delegation certificate
+func (d *Database) SetVoteDelegation(
+	cert *lcommon.VoteDelegationCertificate,
+	slot uint64,
+	txn *Txn,
+) error {
+	return d.metadata.SetVoteDelegation(cert, slot, txn.Metadata())
+}
+
+// SetVoteRegistrationDelegation saves a combined stake registration and vote delegation certificate
+func (d *Database) SetVoteRegistrationDelegation(
+	cert *lcommon.VoteRegistrationDelegationCertificate,
+	slot, deposit uint64,
+	txn *Txn,
+) error {
+	return d.metadata.SetVoteRegistrationDelegation(cert, slot, deposit, txn.Metadata())
+}
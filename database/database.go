@@ -18,16 +18,38 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
 
+	"github.com/blinklabs-io/dingo/database/immutable"
 	"github.com/blinklabs-io/dingo/database/plugin/blob"
 	"github.com/blinklabs-io/dingo/database/plugin/metadata"
 )
 
+const (
+	DefaultBlobPlugin     = "badger"
+	DefaultMetadataPlugin = "sqlite"
+)
+
+// Config provides the configuration for a Database
+type Config struct {
+	BlobPlugin     string
+	DataDir        string
+	Logger         *slog.Logger
+	MetadataPlugin string
+}
+
 type Database struct {
 	logger   *slog.Logger
 	blob     blob.BlobStore
 	metadata metadata.MetadataStore
 	dataDir  string
+	// Storage for blocks that can no longer be rolled back
+	immutable       *immutable.ImmutableDb
+	immutableDir    string
+	immutableWriter *immutable.Writer
+	immutableMutex  sync.Mutex
 }
 
 // Blob returns the underling blob store instance
@@ -68,6 +90,14 @@ func (d *Database) MetadataTxn(readWrite bool) *Txn {
 // Close cleans up the database connections
 func (d *Database) Close() error {
 	var err error
+	// Close immutable storage
+	d.immutableMutex.Lock()
+	if d.immutableWriter != nil {
+		immutableErr := d.immutableWriter.Close()
+		err = errors.Join(err, immutableErr)
+		d.immutableWriter = nil
+	}
+	d.immutableMutex.Unlock()
 	// Close metadata
 	metadataErr := d.Metadata().Close()
 	err = errors.Join(err, metadataErr)
@@ -87,27 +117,56 @@ func (d *Database) init() error {
 	if err := d.checkCommitTimestamp(); err != nil {
 		return err
 	}
+	if err := d.migrateUtxoAmounts(); err != nil {
+		return err
+	}
 	return nil
 }
 
-// New creates a new database instance with optional persistence using the provided data directory
+// New creates a new database instance using the configured plugins, with optional persistence using the
+// provided data directory
 func New(
-	logger *slog.Logger,
-	dataDir string,
+	config *Config,
 ) (*Database, error) {
-	metadataDb, err := metadata.New("sqlite", dataDir, logger)
+	blobPlugin := config.BlobPlugin
+	if blobPlugin == "" {
+		blobPlugin = DefaultBlobPlugin
+	}
+	metadataPlugin := config.MetadataPlugin
+	if metadataPlugin == "" {
+		metadataPlugin = DefaultMetadataPlugin
+	}
+	metadataDb, err := metadata.New(
+		metadataPlugin,
+		config.DataDir,
+		config.Logger,
+	)
 	if err != nil {
 		return nil, err
 	}
-	blobDb, err := blob.New("badger", dataDir, logger)
+	blobDb, err := blob.New(
+		blobPlugin,
+		config.DataDir,
+		config.Logger,
+	)
 	if err != nil {
 		return nil, err
 	}
 	db := &Database{
-		logger:   logger,
+		logger:   config.Logger,
 		blob:     blobDb,
 		metadata: metadataDb,
-		dataDir:  dataDir,
+		dataDir:  config.DataDir,
+	}
+	if config.DataDir != "" {
+		db.immutableDir = filepath.Join(config.DataDir, immutableDirName)
+		if err := os.MkdirAll(db.immutableDir, 0o755); err != nil {
+			return nil, err
+		}
+		db.immutable, err = immutable.New(db.immutableDir)
+		if err != nil {
+			return nil, err
+		}
 	}
 	if err := db.init(); err != nil {
 		// Database is available for recovery, so return it with error
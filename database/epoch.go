@@ -38,6 +38,10 @@ func (d *Database) GetEpochLatest(txn *Txn) (Epoch, error) {
 	tmpEpoch := Epoch{}
 	if txn == nil {
 		txn = d.Transaction(false)
+		// Release our read transaction so that it doesn't block later writes
+		defer func() {
+			_ = txn.Rollback()
+		}()
 	}
 	epoch, err := txn.DB().Metadata().GetEpochLatest(txn.Metadata())
 	if err != nil {
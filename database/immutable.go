@@ -0,0 +1,185 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/blinklabs-io/dingo/database/immutable"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+	"github.com/dgraph-io/badger/v4"
+)
+
+const (
+	immutableDirName = "immutable"
+)
+
+// immutableBlockCbor returns the content of a block that has been moved to the ImmutableDB chunk store
+func (d *Database) immutableBlockCbor(point ocommon.Point) ([]byte, error) {
+	if d.immutable == nil {
+		return nil, ErrBlockNotFound
+	}
+	block, err := d.immutable.GetBlock(point)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf(
+			"block %x at slot %d is missing from immutable storage",
+			point.Hash,
+			point.Slot,
+		)
+	}
+	return block.Cbor, nil
+}
+
+// BlocksArchiveBatch moves the content of up to batchSize blocks with a slot at or before maxSlot from the blob
+// store to the ImmutableDB chunk store, in chain order. The block keys and metadata stay in the blob store, with
+// an empty value marking that the content is in the chunk store. The chunk size is the Byron epoch length. It
+// returns the number of blocks moved
+func BlocksArchiveBatch(
+	db *Database,
+	chunkSize uint64,
+	maxSlot uint64,
+	batchSize int,
+) (int, error) {
+	if db.immutable == nil {
+		return 0, nil
+	}
+	db.immutableMutex.Lock()
+	defer db.immutableMutex.Unlock()
+	if db.immutableWriter == nil {
+		writer, err := immutable.NewWriter(db.immutableDir, chunkSize)
+		if err != nil {
+			return 0, fmt.Errorf("open immutable storage: %w", err)
+		}
+		db.immutableWriter = writer
+	}
+	writer := db.immutableWriter
+	// Find blocks after the last archived block
+	tip := writer.Tip()
+	var startSlot uint64
+	if tip != nil {
+		startSlot = tip.Slot
+	}
+	var blocks []Block
+	txn := db.BlobTxn(false)
+	err := txn.Do(func(txn *Txn) error {
+		it := txn.Blob().NewIterator(badger.IteratorOptions{})
+		defer it.Close()
+		keyPrefix := slices.Concat(
+			[]byte(blockBlobKeyPrefix),
+			blockBlobKeyUint64ToBytes(startSlot),
+		)
+		for it.Seek(keyPrefix); it.ValidForPrefix([]byte(blockBlobKeyPrefix)); it.Next() {
+			item := it.Item()
+			k := item.Key()
+			// Skip the metadata key
+			if strings.HasSuffix(string(k), blockBlobMetadataKeySuffix) {
+				continue
+			}
+			point := blockBlobKeyToPoint(k)
+			if point.Slot > maxSlot {
+				break
+			}
+			// Don't split blocks with the same slot across batches, so that we can put them in order
+			if len(blocks) >= batchSize &&
+				point.Slot != blocks[len(blocks)-1].Slot {
+				break
+			}
+			// Skip blocks whose content has already been moved
+			if item.ValueSize() == 0 {
+				continue
+			}
+			block, err := blockByKey(txn, k)
+			if err != nil {
+				return err
+			}
+			isEbb := block.Type == ledger.BlockTypeByronEbb
+			// An EBB shares its slot with the following block and comes before it
+			if tip != nil && point.Slot == tip.Slot &&
+				(bytes.Equal(point.Hash, tip.Hash) || isEbb || !writer.TipIsEbb()) {
+				continue
+			}
+			blocks = append(blocks, block)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(blocks) == 0 {
+		return 0, nil
+	}
+	slices.SortStableFunc(
+		blocks,
+		func(a, b Block) int {
+			if ret := cmp.Compare(a.Slot, b.Slot); ret != 0 {
+				return ret
+			}
+			// Put an EBB before the block in the same slot
+			aIsEbb := a.Type == ledger.BlockTypeByronEbb
+			bIsEbb := b.Type == ledger.BlockTypeByronEbb
+			switch {
+			case aIsEbb && !bIsEbb:
+				return -1
+			case bIsEbb && !aIsEbb:
+				return 1
+			}
+			return 0
+		},
+	)
+	// Write the blocks to the chunk store before removing their content from the blob store
+	for _, block := range blocks {
+		err := writer.AppendBlock(
+			immutable.Block{
+				Type:  block.Type,
+				Slot:  block.Slot,
+				Hash:  block.Hash,
+				IsEbb: block.Type == ledger.BlockTypeByronEbb,
+				Cbor:  block.Cbor,
+			},
+		)
+		if err != nil {
+			return 0, fmt.Errorf(
+				"write block %x to immutable storage: %w",
+				block.Hash,
+				err,
+			)
+		}
+	}
+	if err := writer.Sync(); err != nil {
+		return 0, err
+	}
+	txn = db.BlobTxn(true)
+	err = txn.Do(func(txn *Txn) error {
+		for _, block := range blocks {
+			key := BlockBlobKey(block.Slot, block.Hash)
+			if err := txn.Blob().Set(key, []byte{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(blocks), nil
+}
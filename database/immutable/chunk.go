@@ -40,6 +40,25 @@ func newChunk() *chunk {
 }
 
 func (c *chunk) Open(path string, secondary *secondaryIndex) error {
+	currentEntry, err := secondary.Next()
+	if err != nil {
+		return err
+	}
+	nextEntry, err := secondary.Next()
+	if err != nil {
+		return err
+	}
+	return c.OpenAt(path, secondary, currentEntry, nextEntry)
+}
+
+// OpenAt opens the chunk with the specified secondary index entries as the current and next entries. This
+// allows reading a block from the middle of a chunk without reading the blocks before it
+func (c *chunk) OpenAt(
+	path string,
+	secondary *secondaryIndex,
+	currentEntry *secondaryIndexEntry,
+	nextEntry *secondaryIndexEntry,
+) error {
 	f, err := os.Open(path)
 	if err != nil {
 		return err
@@ -51,15 +70,7 @@ func (c *chunk) Open(path string, secondary *secondaryIndex) error {
 	} else {
 		c.fileSize = stat.Size()
 	}
-	currentEntry, err := secondary.Next()
-	if err != nil {
-		return err
-	}
 	c.currentEntry = currentEntry
-	nextEntry, err := secondary.Next()
-	if err != nil {
-		return err
-	}
 	c.nextEntry = nextEntry
 	return nil
 }
@@ -154,10 +165,11 @@ func (c *chunk) Next() (*Block, error) {
 			return nil, err
 		}
 		ret := &Block{
-			Type: blkType,
-			Slot: c.currentEntry.BlockOrEbb,
-			Hash: c.currentEntry.HeaderHash[:],
-			Cbor: blkBytes[:],
+			Type:  blkType,
+			Slot:  c.currentEntry.BlockOrEbb,
+			Hash:  c.currentEntry.HeaderHash[:],
+			IsEbb: c.currentEntry.IsEbb,
+			Cbor:  blkBytes[:],
 		}
 		c.currentEntry = c.nextEntry
 		nextEntry, err := c.secondary.Next()
@@ -78,50 +78,79 @@ func (i *ImmutableDb) getChunkNamesFromPoint(
 		return chunkNames, nil
 	}
 	lowerBound := 0
-	upperBound := len(chunkNames)
+	upperBound := len(chunkNames) - 1
 	for lowerBound <= upperBound {
 		// Get chunk in the middle of the current bounds
 		middlePoint := (lowerBound + upperBound) / 2
-		middleChunkName := chunkNames[middlePoint]
-		middleSecondary, err := i.getChunkSecondaryIndex(middleChunkName)
-		if err != nil {
-			return nil, err
-		}
-		next, err := middleSecondary.Next()
-		if err != nil {
-			return nil, err
-		}
-		if next == nil {
-			break
-		}
-		startSlot := next.BlockOrEbb
-		var endSlot uint64
-		for {
-			next, err := middleSecondary.Next()
+		// Chunks without any blocks don't tell us anything, so we use the nearest earlier chunk with blocks
+		probePoint := middlePoint
+		var startSlot, endSlot uint64
+		for ; probePoint >= lowerBound; probePoint-- {
+			var empty bool
+			var err error
+			startSlot, endSlot, empty, err = i.getChunkSlotRange(
+				chunkNames[probePoint],
+			)
 			if err != nil {
 				return nil, err
 			}
-			if next == nil {
+			if !empty {
 				break
 			}
-			endSlot = next.BlockOrEbb
+		}
+		if probePoint < lowerBound {
+			// All chunks from the lower bound through the middle are empty
+			lowerBound = middlePoint + 1
+			continue
 		}
 		if point.Slot < startSlot {
 			// The slot we're looking for is less than the first slot in the chunk, so
 			// we can eliminate all later chunks
-			upperBound = middlePoint - 1
+			upperBound = probePoint - 1
 		} else if point.Slot > endSlot {
 			// The slot we're looking for is greater than the last slot in the chunk, so
 			// we can eliminate all earlier chunks
 			lowerBound = middlePoint + 1
 		} else {
 			// We found the chunk that (probably) has the requested point
+			lowerBound = probePoint
 			break
 		}
 	}
 	return chunkNames[lowerBound:], nil
 }
 
+// getChunkSlotRange returns the slots of the first and last blocks in a chunk, or whether it's empty
+func (i *ImmutableDb) getChunkSlotRange(
+	chunkName string,
+) (uint64, uint64, bool, error) {
+	secondary, err := i.getChunkSecondaryIndex(chunkName)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	defer secondary.Close()
+	next, err := secondary.Next()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if next == nil {
+		return 0, 0, true, nil
+	}
+	startSlot := next.BlockOrEbb
+	endSlot := startSlot
+	for {
+		next, err := secondary.Next()
+		if err != nil {
+			return 0, 0, false, err
+		}
+		if next == nil {
+			break
+		}
+		endSlot = next.BlockOrEbb
+	}
+	return startSlot, endSlot, false, nil
+}
+
 func (i *ImmutableDb) getChunkPrimaryIndex(
 	chunkName string,
 ) (*primaryIndex, error) {
@@ -219,27 +248,60 @@ func (i *ImmutableDb) GetBlock(point ocommon.Point) (*Block, error) {
 	if err != nil {
 		return nil, err
 	}
-	chunk, err := i.getChunk(chunkNames[0])
+	if len(chunkNames) == 0 {
+		return nil, nil
+	}
+	// Find the block in the secondary index, so that we only need to read its own data from the chunk
+	secondary, err := i.getChunkSecondaryIndex(chunkNames[0])
 	if err != nil {
 		return nil, err
 	}
+	var entry, nextEntry *secondaryIndexEntry
 	for {
-		tmpBlock, err := chunk.Next()
+		tmpEntry, err := secondary.Next()
 		if err != nil {
+			_ = secondary.Close()
 			return nil, err
 		}
-		if tmpBlock == nil {
+		if entry != nil || tmpEntry == nil {
+			nextEntry = tmpEntry
 			break
 		}
-		if tmpBlock.Slot != point.Slot {
+		if string(tmpEntry.HeaderHash[:]) != string(point.Hash) {
 			continue
 		}
-		if string(tmpBlock.Hash) != string(point.Hash) {
+		// The secondary index contains the epoch number rather than the slot for an EBB
+		if !tmpEntry.IsEbb && tmpEntry.BlockOrEbb != point.Slot {
 			continue
 		}
-		return tmpBlock, nil
+		entry = tmpEntry
+	}
+	if entry == nil {
+		_ = secondary.Close()
+		return nil, nil
 	}
-	return nil, nil
+	chunkFilePath := filepath.Join(
+		i.dataDir,
+		chunkNames[0]+chunkFileExtension,
+	)
+	chunk := newChunk()
+	if err := chunk.OpenAt(chunkFilePath, secondary, entry, nextEntry); err != nil {
+		_ = secondary.Close()
+		return nil, fmt.Errorf(
+			"failed to read chunk: %s: %w",
+			chunkFilePath,
+			err,
+		)
+	}
+	defer chunk.Close()
+	ret, err := chunk.Next()
+	if err != nil {
+		return nil, err
+	}
+	if ret != nil {
+		ret.Slot = point.Slot
+	}
+	return ret, nil
 }
 
 func (i *ImmutableDb) TruncateChunksFromPoint(point ocommon.Point) error {
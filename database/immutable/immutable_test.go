@@ -15,14 +15,21 @@
 package immutable_test
 
 import (
+	"bytes"
 	"encoding/hex"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/blinklabs-io/dingo/database/immutable"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
 )
 
 const (
 	testDataDir = "./testdata"
+
+	// Chunk size for the preview network, which matches the Byron epoch length
+	testChunkSize = 4320
 )
 
 func TestGetTip(t *testing.T) {
@@ -56,4 +63,106 @@ func TestGetTip(t *testing.T) {
 	}
 }
 
-// TODO: add tests for getting a specific block and getting a range of blocks that traverses multiple chunks (#386)
+// TODO: add tests for getting a range of blocks that traverses multiple chunks (#386)
+
+func TestGetBlock(t *testing.T) {
+	// This is the first block in the last chunk of our test data
+	expectedSlot := uint64(38422124)
+	expectedHash, _ := hex.DecodeString(
+		"4bf1f23ffa0175138cabca52482c56f6ef8268c3bd652f788fdbbe696c3c5681",
+	)
+	imm, err := immutable.New(testDataDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	blk, err := imm.GetBlock(ocommon.NewPoint(expectedSlot, expectedHash))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if blk == nil {
+		t.Fatalf("did not find expected block")
+	}
+	if blk.Slot != expectedSlot || !bytes.Equal(blk.Hash, expectedHash) {
+		t.Fatalf(
+			"did not get expected block: got %x at slot %d",
+			blk.Hash,
+			blk.Slot,
+		)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	imm, err := immutable.New(testDataDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	iter, err := imm.BlocksFromPoint(ocommon.NewPoint(0, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer iter.Close()
+	tmpDir := t.TempDir()
+	writer, err := immutable.NewWriter(tmpDir, testChunkSize)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for {
+		blk, err := iter.Next()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if blk == nil {
+			break
+		}
+		if err := writer.AppendBlock(*blk); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// The written files should match those from cardano-node. The primary index for the last chunk
+	// hasn't been filled to the end of the chunk yet
+	for _, fileName := range []string{
+		"08893.chunk",
+		"08893.primary",
+		"08893.secondary",
+		"08894.chunk",
+		"08894.primary",
+		"08894.secondary",
+	} {
+		expected, err := os.ReadFile(filepath.Join(testDataDir, fileName))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		written, err := os.ReadFile(filepath.Join(tmpDir, fileName))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if fileName == "08894.primary" {
+			expected = expected[:len(written)]
+		}
+		if !bytes.Equal(written, expected) {
+			t.Fatalf("written file %s does not match expected content", fileName)
+		}
+	}
+	// Reopening the writer should resume from the last block
+	writer, err = immutable.NewWriter(tmpDir, testChunkSize)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer writer.Close()
+	expectedTip, err := imm.GetTip()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tip := writer.Tip()
+	if tip == nil || tip.Slot != expectedTip.Slot ||
+		!bytes.Equal(tip.Hash, expectedTip.Hash) {
+		t.Fatalf(
+			"did not get expected tip after reopening: got %v, expected %v",
+			tip,
+			expectedTip,
+		)
+	}
+}
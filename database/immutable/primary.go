@@ -29,6 +29,7 @@ type primaryIndex struct {
 	file       *os.File
 	slot       int
 	lastOffset uint32
+	started    bool
 	version    uint8
 }
 
@@ -63,7 +64,20 @@ func (p *primaryIndex) Close() error {
 	return p.file.Close()
 }
 
+// Next returns the entry for the next relative slot. The primary index contains the offset into the secondary
+// index for each slot, followed by a final offset for the end of the secondary index. A slot contains a block
+// when the offset for the following slot is greater than its own
 func (p *primaryIndex) Next() (*primaryIndexEntry, error) {
+	if !p.started {
+		// Read the offset for the first slot
+		if err := binary.Read(p.file, binary.BigEndian, &p.lastOffset); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		p.started = true
+	}
 	var tmpOffset uint32
 	if err := binary.Read(p.file, binary.BigEndian, &tmpOffset); err != nil {
 		if errors.Is(err, io.EOF) {
@@ -72,15 +86,13 @@ func (p *primaryIndex) Next() (*primaryIndexEntry, error) {
 		}
 		return nil, err
 	}
-	empty := true
-	if tmpOffset > p.lastOffset {
-		empty = false
-		p.lastOffset = tmpOffset
-	}
 	tmpEntry := primaryIndexEntry{
 		RelativeSlot:    p.slot,
-		SecondaryOffset: tmpOffset,
-		empty:           empty,
+		SecondaryOffset: p.lastOffset,
+		empty:           tmpOffset <= p.lastOffset,
+	}
+	if tmpOffset > p.lastOffset {
+		p.lastOffset = tmpOffset
 	}
 	p.slot++
 	return &tmpEntry, nil
@@ -95,8 +95,8 @@ func (s *secondaryIndex) Next() (*secondaryIndexEntry, error) {
 		secondaryIndexEntryInner: tmpEntryInner,
 	}
 	// Check for EBB
-	// A block with its secondary index in the first slot with a small BlockOrEbb value is *probably* an EBB
-	if nextOccupied.RelativeSlot == 0 && ret.BlockOrEbb < 200 {
+	// The first relative slot in each chunk is reserved for the Byron epoch boundary block
+	if nextOccupied.RelativeSlot == 0 {
 		ret.IsEbb = true
 	}
 	return ret, nil
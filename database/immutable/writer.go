@@ -0,0 +1,439 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package immutable
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+const (
+	primaryIndexVersion     = 1
+	secondaryIndexEntrySize = 56
+)
+
+// Writer appends blocks to an ImmutableDB directory, using the same chunk, primary index, and secondary index
+// layout as cardano-node. Each chunk covers a fixed number of slots, with an extra relative slot at the start
+// reserved for a Byron epoch boundary block (EBB)
+type Writer struct {
+	dataDir         string
+	chunkSize       uint64
+	chunkNum        uint64
+	chunkOpen       bool
+	chunkFile       *os.File
+	primaryFile     *os.File
+	secondaryFile   *os.File
+	chunkOffset     uint64
+	secondaryOffset uint32
+	primaryEntries  uint64
+	tip             *ocommon.Point
+	tipIsEbb        bool
+}
+
+// NewWriter returns a new Writer for the specified data directory. The chunk size is the number of slots in
+// each chunk, which matches the Byron epoch length. Writing resumes after the last complete block in the
+// directory, discarding any partially written data
+func NewWriter(dataDir string, chunkSize uint64) (*Writer, error) {
+	if chunkSize == 0 {
+		return nil, errors.New("invalid chunk size")
+	}
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return nil, err
+	}
+	w := &Writer{
+		dataDir:   dataDir,
+		chunkSize: chunkSize,
+	}
+	imm, err := New(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	chunkNames, err := imm.getChunkNames()
+	if err != nil {
+		return nil, err
+	}
+	if len(chunkNames) > 0 {
+		if err := w.resume(chunkNames[len(chunkNames)-1]); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Tip returns the point of the last block written, or nil if there are no blocks
+func (w *Writer) Tip() *ocommon.Point {
+	return w.tip
+}
+
+// TipIsEbb returns whether the last block written is a Byron epoch boundary block. An EBB shares its slot with
+// the first block of the epoch
+func (w *Writer) TipIsEbb() bool {
+	return w.tipIsEbb
+}
+
+// AppendBlock writes a block after the current tip. Blocks must be written in chain order
+func (w *Writer) AppendBlock(block Block) error {
+	chunkNum := block.Slot / w.chunkSize
+	relativeSlot := block.Slot%w.chunkSize + 1
+	blockOrEbb := block.Slot
+	if block.IsEbb {
+		relativeSlot = 0
+		// The secondary index contains the epoch number for an EBB
+		blockOrEbb = chunkNum
+	}
+	if w.tip != nil &&
+		(block.Slot < w.tip.Slot ||
+			(block.Slot == w.tip.Slot && (block.IsEbb || !w.tipIsEbb))) {
+		return fmt.Errorf(
+			"block at slot %d is not after the current tip at slot %d",
+			block.Slot,
+			w.tip.Slot,
+		)
+	}
+	if len(block.Hash) != 32 {
+		return fmt.Errorf("invalid block hash length: %d", len(block.Hash))
+	}
+	// Move to the chunk containing the block
+	if !w.chunkOpen || chunkNum > w.chunkNum {
+		if err := w.openChunk(chunkNum); err != nil {
+			return err
+		}
+	}
+	// Wrap the block with its type, as cardano-node does
+	tmpData := struct {
+		cbor.StructAsArray
+		BlockType uint
+		BlockCbor cbor.RawMessage
+	}{
+		BlockType: block.Type,
+		BlockCbor: block.Cbor,
+	}
+	blockData, err := cbor.Encode(&tmpData)
+	if err != nil {
+		return err
+	}
+	headerOffset, headerSize, err := blockHeaderLocation(
+		block.Cbor,
+		len(blockData)-len(block.Cbor),
+	)
+	if err != nil {
+		return err
+	}
+	// Write block data
+	if _, err := w.chunkFile.Write(blockData); err != nil {
+		return err
+	}
+	// Write secondary index entry
+	entry := secondaryIndexEntryInner{
+		BlockOffset:  w.chunkOffset,
+		HeaderOffset: headerOffset,
+		HeaderSize:   headerSize,
+		Checksum:     crc32.ChecksumIEEE(blockData),
+		BlockOrEbb:   blockOrEbb,
+	}
+	copy(entry.HeaderHash[:], block.Hash)
+	if err := binary.Write(w.secondaryFile, binary.BigEndian, &entry); err != nil {
+		return err
+	}
+	// Write primary index entries. Empty slots since the previous block repeat the current offset
+	if err := w.padPrimaryIndex(relativeSlot + 1); err != nil {
+		return err
+	}
+	w.chunkOffset += uint64(len(blockData))
+	w.secondaryOffset += secondaryIndexEntrySize
+	if err := binary.Write(w.primaryFile, binary.BigEndian, w.secondaryOffset); err != nil {
+		return err
+	}
+	w.primaryEntries++
+	tmpPoint := ocommon.NewPoint(block.Slot, block.Hash)
+	w.tip = &tmpPoint
+	w.tipIsEbb = block.IsEbb
+	return nil
+}
+
+// Sync flushes the files for the current chunk to disk
+func (w *Writer) Sync() error {
+	if !w.chunkOpen {
+		return nil
+	}
+	// Sync in the order written, so that the primary index never references missing data
+	for _, f := range []*os.File{w.chunkFile, w.secondaryFile, w.primaryFile} {
+		if err := f.Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close syncs and closes the files for the current chunk
+func (w *Writer) Close() error {
+	if !w.chunkOpen {
+		return nil
+	}
+	err := w.Sync()
+	err = errors.Join(
+		err,
+		w.chunkFile.Close(),
+		w.secondaryFile.Close(),
+		w.primaryFile.Close(),
+	)
+	w.chunkOpen = false
+	return err
+}
+
+// openChunk finalizes the current chunk, along with empty chunks for any skipped chunk numbers, and opens a new
+// chunk
+func (w *Writer) openChunk(chunkNum uint64) error {
+	if w.chunkOpen {
+		for {
+			if err := w.finalizeChunk(); err != nil {
+				return err
+			}
+			if w.chunkNum+1 >= chunkNum {
+				break
+			}
+			if err := w.createChunk(w.chunkNum + 1); err != nil {
+				return err
+			}
+		}
+	}
+	return w.createChunk(chunkNum)
+}
+
+// createChunk creates empty files for the specified chunk number
+func (w *Writer) createChunk(chunkNum uint64) error {
+	pathPrefix := w.chunkPathPrefix(chunkNum)
+	files := make([]*os.File, 0, 3)
+	for _, ext := range []string{chunkFileExtension, secondaryFileExtension, primaryFileExtension} {
+		f, err := os.OpenFile(
+			pathPrefix+ext,
+			os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
+			0o644,
+		)
+		if err != nil {
+			for _, tmpFile := range files {
+				_ = tmpFile.Close()
+			}
+			return err
+		}
+		files = append(files, f)
+	}
+	w.chunkFile = files[0]
+	w.secondaryFile = files[1]
+	w.primaryFile = files[2]
+	w.chunkNum = chunkNum
+	w.chunkOpen = true
+	w.chunkOffset = 0
+	w.secondaryOffset = 0
+	// The primary index starts with the version and the offset for the first slot
+	if err := binary.Write(w.primaryFile, binary.BigEndian, uint8(primaryIndexVersion)); err != nil {
+		return err
+	}
+	if err := binary.Write(w.primaryFile, binary.BigEndian, uint32(0)); err != nil {
+		return err
+	}
+	w.primaryEntries = 1
+	return nil
+}
+
+// finalizeChunk fills the primary index for the remaining slots in the current chunk and closes it
+func (w *Writer) finalizeChunk() error {
+	// There's an offset for each slot, the EBB slot, and the end of the secondary index
+	if err := w.padPrimaryIndex(w.chunkSize + 2); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// padPrimaryIndex writes the current secondary index offset to the primary index until it contains the
+// specified number of entries
+func (w *Writer) padPrimaryIndex(entries uint64) error {
+	for w.primaryEntries < entries {
+		if err := binary.Write(w.primaryFile, binary.BigEndian, w.secondaryOffset); err != nil {
+			return err
+		}
+		w.primaryEntries++
+	}
+	return nil
+}
+
+func (w *Writer) chunkPathPrefix(chunkNum uint64) string {
+	return filepath.Join(w.dataDir, fmt.Sprintf("%05d", chunkNum))
+}
+
+// resume opens the last chunk in the directory for appending. Data after the last block referenced by the
+// primary index is discarded
+func (w *Writer) resume(chunkName string) error {
+	var chunkNum uint64
+	if _, err := fmt.Sscanf(chunkName, "%d", &chunkNum); err != nil {
+		return fmt.Errorf("invalid chunk name: %s", chunkName)
+	}
+	pathPrefix := filepath.Join(w.dataDir, chunkName)
+	chunkData, err := os.ReadFile(pathPrefix + chunkFileExtension)
+	if err != nil {
+		return err
+	}
+	secondaryData, err := os.ReadFile(pathPrefix + secondaryFileExtension)
+	if err != nil {
+		return err
+	}
+	primaryData, err := os.ReadFile(pathPrefix + primaryFileExtension)
+	if err != nil {
+		return err
+	}
+	// Read the primary index offsets, ignoring any that reference missing secondary index entries
+	var offsets []uint32
+	if len(primaryData) > 0 {
+		for pos := 1; pos+4 <= len(primaryData); pos += 4 {
+			offset := binary.BigEndian.Uint32(primaryData[pos : pos+4])
+			if int(offset) > len(secondaryData) ||
+				offset%secondaryIndexEntrySize != 0 {
+				break
+			}
+			offsets = append(offsets, offset)
+		}
+	}
+	if len(offsets) == 0 {
+		offsets = []uint32{0}
+	}
+	// Discard blocks with incomplete data in the chunk
+	var chunkOffset uint64
+	for {
+		lastOffset := offsets[len(offsets)-1]
+		if lastOffset == 0 {
+			break
+		}
+		var entry secondaryIndexEntryInner
+		if _, err := binary.Decode(
+			secondaryData[lastOffset-secondaryIndexEntrySize:lastOffset],
+			binary.BigEndian,
+			&entry,
+		); err != nil {
+			return err
+		}
+		if entry.BlockOffset <= uint64(len(chunkData)) {
+			blockData := chunkData[entry.BlockOffset:]
+			var tmpData cbor.RawMessage
+			if n, err := cbor.Decode(blockData, &tmpData); err == nil {
+				chunkOffset = entry.BlockOffset + uint64(n) // #nosec G115
+				break
+			}
+		}
+		// Remove the offsets for the incomplete block, back to the start of its slot
+		for len(offsets) > 1 && offsets[len(offsets)-1] == lastOffset {
+			offsets = offsets[:len(offsets)-1]
+		}
+	}
+	// Find the last block and the slot it occupies
+	w.chunkNum = chunkNum
+	w.chunkOffset = chunkOffset
+	w.secondaryOffset = offsets[len(offsets)-1]
+	w.primaryEntries = uint64(len(offsets))
+	for idx := len(offsets) - 1; idx > 0; idx-- {
+		if offsets[idx] <= offsets[idx-1] {
+			continue
+		}
+		var entry secondaryIndexEntryInner
+		if _, err := binary.Decode(
+			secondaryData[offsets[idx-1]:offsets[idx]],
+			binary.BigEndian,
+			&entry,
+		); err != nil {
+			return err
+		}
+		slot := entry.BlockOrEbb
+		if idx-1 == 0 {
+			slot = chunkNum * w.chunkSize
+			w.tipIsEbb = true
+		}
+		tmpPoint := ocommon.NewPoint(slot, entry.HeaderHash[:])
+		w.tip = &tmpPoint
+		break
+	}
+	// Open the files for appending and truncate any partially written data
+	files := make([]*os.File, 0, 3)
+	sizes := []int64{
+		int64(chunkOffset), // #nosec G115
+		int64(w.secondaryOffset),
+		1 + 4*int64(len(offsets)), // #nosec G115
+	}
+	for idx, ext := range []string{chunkFileExtension, secondaryFileExtension, primaryFileExtension} {
+		f, err := os.OpenFile(pathPrefix+ext, os.O_WRONLY, 0o644)
+		if err == nil {
+			err = f.Truncate(sizes[idx])
+			if err == nil {
+				_, err = f.Seek(0, io.SeekEnd)
+			}
+		}
+		if err != nil {
+			if f != nil {
+				_ = f.Close()
+			}
+			for _, tmpFile := range files {
+				_ = tmpFile.Close()
+			}
+			return err
+		}
+		files = append(files, f)
+	}
+	w.chunkFile = files[0]
+	w.secondaryFile = files[1]
+	w.primaryFile = files[2]
+	w.chunkOpen = true
+	// Rewrite the primary index header if it was missing
+	if len(primaryData) < 5 {
+		if _, err := w.primaryFile.WriteAt([]byte{primaryIndexVersion, 0, 0, 0, 0}, 0); err != nil {
+			return err
+		}
+	}
+	// A full primary index means that the chunk has been finalized
+	if w.primaryEntries >= w.chunkSize+2 {
+		return w.Close()
+	}
+	return nil
+}
+
+// blockHeaderLocation returns the offset and size of the block header within the wrapped block data. The
+// header is the first item in the block
+func blockHeaderLocation(
+	blockCbor []byte,
+	wrapperSize int,
+) (uint16, uint16, error) {
+	var blockParts []cbor.RawMessage
+	if _, err := cbor.Decode(blockCbor, &blockParts); err != nil {
+		return 0, 0, fmt.Errorf("decode block: %w", err)
+	}
+	if len(blockParts) == 0 {
+		return 0, 0, errors.New("block has no header")
+	}
+	partsSize := 0
+	for _, part := range blockParts {
+		partsSize += len(part)
+	}
+	headerOffset := wrapperSize + len(blockCbor) - partsSize
+	headerSize := len(blockParts[0])
+	if headerOffset > 0xffff || headerSize > 0xffff {
+		return 0, 0, errors.New("block header too large")
+	}
+	return uint16(headerOffset), uint16(headerSize), nil // #nosec G115
+}
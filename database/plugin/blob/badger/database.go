@@ -28,12 +28,52 @@ import (
 	badger "github.com/dgraph-io/badger/v4"
 )
 
+const (
+	defaultBlockCacheSize = 256 // MiB
+	defaultIndexCacheSize = 0   // MiB
+	defaultGc             = true
+)
+
+var cmdlineOptions = struct {
+	blockCacheSize uint
+	indexCacheSize uint
+	gc             bool
+}{
+	blockCacheSize: defaultBlockCacheSize,
+	indexCacheSize: defaultIndexCacheSize,
+	gc:             defaultGc,
+}
+
 // Register plugin
 func init() {
 	plugin.Register(
 		plugin.PluginEntry{
-			Type: plugin.PluginTypeMetadata,
-			Name: "sqlite",
+			Type:        plugin.PluginTypeBlob,
+			Name:        "badger",
+			Description: "BadgerDB key/value store",
+			Options: []plugin.PluginOption{
+				{
+					Name:         "block-cache-size",
+					Type:         plugin.PluginOptionTypeUint,
+					Description:  "BadgerDB block cache size in MiB",
+					DefaultValue: uint(defaultBlockCacheSize),
+					Dest:         &(cmdlineOptions.blockCacheSize),
+				},
+				{
+					Name:         "index-cache-size",
+					Type:         plugin.PluginOptionTypeUint,
+					Description:  "BadgerDB index cache size in MiB (0 keeps indexes in memory)",
+					DefaultValue: uint(defaultIndexCacheSize),
+					Dest:         &(cmdlineOptions.indexCacheSize),
+				},
+				{
+					Name:         "gc",
+					Type:         plugin.PluginOptionTypeBool,
+					Description:  "run BadgerDB value log GC periodically",
+					DefaultValue: defaultGc,
+					Dest:         &(cmdlineOptions.gc),
+				},
+			},
 		},
 	)
 }
@@ -63,6 +103,8 @@ func New(
 			WithLogger(NewBadgerLogger(logger)).
 			// The default INFO logging is a bit verbose
 			WithLoggingLevel(badger.WARNING).
+			WithBlockCacheSize(int64(cmdlineOptions.blockCacheSize) << 20).
+			WithIndexCacheSize(int64(cmdlineOptions.indexCacheSize) << 20).
 			WithInMemory(true)
 		blobDb, err = badger.Open(badgerOpts)
 		if err != nil {
@@ -84,11 +126,13 @@ func New(
 			"blob",
 		)
 		// Run GC periodically
-		db.gcEnabled = true
+		db.gcEnabled = cmdlineOptions.gc
 		badgerOpts := badger.DefaultOptions(blobDir).
 			WithLogger(NewBadgerLogger(logger)).
 			// The default INFO logging is a bit verbose
-			WithLoggingLevel(badger.WARNING)
+			WithLoggingLevel(badger.WARNING).
+			WithBlockCacheSize(int64(cmdlineOptions.blockCacheSize) << 20).
+			WithIndexCacheSize(int64(cmdlineOptions.indexCacheSize) << 20)
 		blobDb, err = badger.Open(badgerOpts)
 		if err != nil {
 			return nil, err
@@ -15,6 +15,9 @@
 package badger
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 )
@@ -94,5 +97,15 @@ func (d *BlobStoreBadger) registerBlobMetrics() {
 			),
 		},
 	)
-	prometheus.MustRegister(collector)
+	// The badger metrics are process-wide, so we only need to register them for the first
+	// database that we open
+	if err := prometheus.Register(collector); err != nil {
+		var alreadyRegisteredErr prometheus.AlreadyRegisteredError
+		if !errors.As(err, &alreadyRegisteredErr) {
+			d.logger.Warn(
+				fmt.Sprintf("blob DB: failed to register metrics: %s", err),
+				"component", "database",
+			)
+		}
+	}
 }
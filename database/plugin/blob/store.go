@@ -15,8 +15,10 @@
 package blob
 
 import (
+	"fmt"
 	"log/slog"
 
+	"github.com/blinklabs-io/dingo/database/plugin"
 	badgerPlugin "github.com/blinklabs-io/dingo/database/plugin/blob/badger"
 	badger "github.com/dgraph-io/badger/v4"
 )
@@ -31,10 +33,21 @@ type BlobStore interface {
 	SetCommitTimestamp(*badger.Txn, int64) error
 }
 
-// For now, this always returns a badger plugin
+// New returns a new BlobStore using the specified plugin
 func New(
 	pluginName, dataDir string,
 	logger *slog.Logger,
 ) (BlobStore, error) {
-	return badgerPlugin.New(dataDir, logger)
+	if plugin.GetPluginEntry(plugin.PluginTypeBlob, pluginName) == nil {
+		return nil, fmt.Errorf("unknown blob plugin: %s", pluginName)
+	}
+	switch pluginName {
+	case "badger":
+		return badgerPlugin.New(dataDir, logger)
+	default:
+		return nil, fmt.Errorf(
+			"blob plugin does not provide a BlobStore: %s",
+			pluginName,
+		)
+	}
 }
@@ -0,0 +1,617 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormstore
+
+import (
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"gorm.io/gorm"
+)
+
+// GetPoolRegistrations returns pool registration certificates
+func (d *MetadataStoreGorm) GetPoolRegistrations(
+	pkh lcommon.PoolKeyHash,
+	txn *gorm.DB,
+) ([]lcommon.PoolRegistrationCertificate, error) {
+	ret := []lcommon.PoolRegistrationCertificate{}
+	certs := []models.PoolRegistration{}
+	if txn != nil {
+		result := txn.Where("pool_key_hash = ?", lcommon.Blake2b224(pkh).Bytes()).
+			Order("id DESC").
+			Find(&certs)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	} else {
+		result := d.DB().Where("pool_key_hash = ?", lcommon.Blake2b224(pkh).Bytes()).
+			Order("id DESC").
+			Find(&certs)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	}
+	for _, cert := range certs {
+		tmpMargin := cbor.Rat{Rat: cert.Margin.Rat}
+		tmpCert := lcommon.PoolRegistrationCertificate{
+			CertType: lcommon.CertificateTypePoolRegistration,
+			Operator: lcommon.PoolKeyHash(
+				lcommon.NewBlake2b224(cert.PoolKeyHash),
+			),
+			VrfKeyHash: lcommon.VrfKeyHash(
+				lcommon.NewBlake2b256(cert.VrfKeyHash),
+			),
+			Pledge: uint64(cert.Pledge),
+			Cost:   uint64(cert.Cost),
+			Margin: tmpMargin,
+			RewardAccount: lcommon.AddrKeyHash(
+				lcommon.NewBlake2b224(cert.RewardAccount),
+			),
+		}
+		for _, owner := range cert.Owners {
+			addrKeyHash := lcommon.AddrKeyHash(
+				lcommon.NewBlake2b224(owner.KeyHash),
+			)
+			tmpCert.PoolOwners = append(tmpCert.PoolOwners, addrKeyHash)
+		}
+		for _, relay := range cert.Relays {
+			tmpRelay := lcommon.PoolRelay{}
+			// Determine type
+			if relay.Port != 0 {
+				port := uint32(relay.Port) // #nosec G115
+				tmpRelay.Port = &port
+				if relay.Hostname != "" {
+					hostname := relay.Hostname
+					tmpRelay.Type = lcommon.PoolRelayTypeSingleHostName
+					tmpRelay.Hostname = &hostname
+				} else {
+					tmpRelay.Type = lcommon.PoolRelayTypeSingleHostAddress
+					tmpRelay.Ipv4 = relay.Ipv4
+					tmpRelay.Ipv6 = relay.Ipv6
+				}
+			} else {
+				hostname := relay.Hostname
+				tmpRelay.Type = lcommon.PoolRelayTypeMultiHostName
+				tmpRelay.Hostname = &hostname
+			}
+			tmpCert.Relays = append(tmpCert.Relays, tmpRelay)
+		}
+		if cert.MetadataUrl != "" {
+			poolMetadata := &lcommon.PoolMetadata{
+				Url: cert.MetadataUrl,
+				Hash: lcommon.PoolMetadataHash(
+					lcommon.NewBlake2b256(cert.MetadataHash),
+				),
+			}
+			tmpCert.PoolMetadata = poolMetadata
+		}
+		ret = append(ret, tmpCert)
+	}
+	return ret, nil
+}
+
+// GetStakeRegistrations returns stake registration certificates
+func (d *MetadataStoreGorm) GetStakeRegistrations(
+	stakingKey []byte,
+	txn *gorm.DB,
+) ([]lcommon.StakeRegistrationCertificate, error) {
+	ret := []lcommon.StakeRegistrationCertificate{}
+	certs := []models.StakeRegistration{}
+	if txn != nil {
+		result := txn.Where("staking_key = ?", stakingKey).
+			Order("id DESC").
+			Find(&certs)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	} else {
+		result := d.DB().Where("staking_key = ?", stakingKey).
+			Order("id DESC").
+			Find(&certs)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	}
+	for _, cert := range certs {
+		tmpCert := lcommon.StakeRegistrationCertificate{
+			CertType: lcommon.CertificateTypeStakeRegistration,
+			StakeRegistration: lcommon.StakeCredential{
+				CredType:   cert.CredentialType,
+				Credential: cert.StakingKey,
+			},
+		}
+		ret = append(ret, tmpCert)
+	}
+	return ret, nil
+}
+
+// SetAuthCommitteeHot saves a committee hot key authorization certificate
+func (d *MetadataStoreGorm) SetAuthCommitteeHot(
+	cert *lcommon.AuthCommitteeHotCertificate,
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.AuthCommitteeHot{
+		ColdCredential: cert.ColdCredential.Credential,
+		HotCredential:  cert.HostCredential.Credential,
+		AddedSlot:      slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetDeregistration saves a deregistration certificate
+func (d *MetadataStoreGorm) SetDeregistration(
+	cert *lcommon.DeregistrationCertificate,
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.StakeDeregistration{
+		StakingKey: cert.StakeCredential.Credential,
+		AddedSlot:  slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetDeregistrationDrep saves a DRep deregistration certificate
+func (d *MetadataStoreGorm) SetDeregistrationDrep(
+	cert *lcommon.DeregistrationDrepCertificate,
+	slot, deposit uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.DrepDeregistration{
+		DrepCredential: cert.DrepCredential.Credential,
+		AddedSlot:      slot,
+		DepositAmount:  deposit,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetPoolRegistration saves a pool registration certificate
+func (d *MetadataStoreGorm) SetPoolRegistration(
+	cert *lcommon.PoolRegistrationCertificate,
+	slot, deposit uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.PoolRegistration{
+		PoolKeyHash:   cert.Operator[:],
+		VrfKeyHash:    cert.VrfKeyHash[:],
+		Pledge:        models.Uint64(cert.Pledge),
+		Cost:          models.Uint64(cert.Cost),
+		Margin:        &models.Rat{Rat: cert.Margin.Rat},
+		RewardAccount: cert.RewardAccount[:],
+		AddedSlot:     slot,
+		DepositAmount: deposit,
+	}
+	if cert.PoolMetadata != nil {
+		tmpItem.MetadataUrl = cert.PoolMetadata.Url
+		tmpItem.MetadataHash = cert.PoolMetadata.Hash[:]
+	}
+	for _, owner := range cert.PoolOwners {
+		tmpItem.Owners = append(
+			tmpItem.Owners,
+			models.PoolRegistrationOwner{KeyHash: owner[:]},
+		)
+	}
+	for _, relay := range cert.Relays {
+		tmpRelay := models.PoolRegistrationRelay{
+			Type: relay.Type,
+			Ipv4: relay.Ipv4,
+			Ipv6: relay.Ipv6,
+		}
+		if relay.Port != nil {
+			tmpRelay.Port = uint(*relay.Port)
+		}
+		if relay.Hostname != nil {
+			tmpRelay.Hostname = *relay.Hostname
+		}
+		tmpItem.Relays = append(tmpItem.Relays, tmpRelay)
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetPoolRetirement saves a pool retirement certificate
+func (d *MetadataStoreGorm) SetPoolRetirement(
+	cert *lcommon.PoolRetirementCertificate,
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.PoolRetirement{
+		PoolKeyHash: cert.PoolKeyHash[:],
+		Epoch:       cert.Epoch,
+		AddedSlot:   slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetRegistration saves a registration certificate
+func (d *MetadataStoreGorm) SetRegistration(
+	cert *lcommon.RegistrationCertificate,
+	slot, deposit uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.StakeRegistration{
+		StakingKey:     cert.StakeCredential.Credential,
+		CredentialType: cert.StakeCredential.CredType,
+		AddedSlot:      slot,
+		DepositAmount:  deposit,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetRegistrationDrep saves a DRep registration certificate
+func (d *MetadataStoreGorm) SetRegistrationDrep(
+	cert *lcommon.RegistrationDrepCertificate,
+	slot, deposit uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.DrepRegistration{
+		DrepCredential: cert.DrepCredential.Credential,
+		AddedSlot:      slot,
+		DepositAmount:  deposit,
+	}
+	if cert.Anchor != nil {
+		tmpItem.AnchorUrl = cert.Anchor.Url
+		tmpItem.AnchorHash = cert.Anchor.DataHash[:]
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetResignCommitteeCold saves a committee cold key resignation certificate
+func (d *MetadataStoreGorm) SetResignCommitteeCold(
+	cert *lcommon.ResignCommitteeColdCertificate,
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.ResignCommitteeCold{
+		ColdCredential: cert.ColdCredential.Credential,
+		AddedSlot:      slot,
+	}
+	if cert.Anchor != nil {
+		tmpItem.AnchorUrl = cert.Anchor.Url
+		tmpItem.AnchorHash = cert.Anchor.DataHash[:]
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetStakeDelegation saves a stake delegation certificate
+func (d *MetadataStoreGorm) SetStakeDelegation(
+	cert *lcommon.StakeDelegationCertificate,
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.StakeDelegation{
+		StakingKey:  cert.StakeCredential.Credential,
+		PoolKeyHash: cert.PoolKeyHash[:],
+		AddedSlot:   slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetStakeDeregistration saves a stake deregistration certificate
+func (d *MetadataStoreGorm) SetStakeDeregistration(
+	cert *lcommon.StakeDeregistrationCertificate,
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.StakeDeregistration{
+		StakingKey: cert.StakeDeregistration.Credential,
+		AddedSlot:  slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetStakeRegistration saves a stake registration certificate
+func (d *MetadataStoreGorm) SetStakeRegistration(
+	cert *lcommon.StakeRegistrationCertificate,
+	slot, deposit uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.StakeRegistration{
+		StakingKey:     cert.StakeRegistration.Credential,
+		CredentialType: cert.StakeRegistration.CredType,
+		AddedSlot:      slot,
+		DepositAmount:  deposit,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetStakeRegistrationDelegation saves a combined stake registration and delegation certificate
+func (d *MetadataStoreGorm) SetStakeRegistrationDelegation(
+	cert *lcommon.StakeRegistrationDelegationCertificate,
+	slot, deposit uint64,
+	txn *gorm.DB,
+) error {
+	tmpItems := []any{
+		&models.StakeRegistration{
+			StakingKey:     cert.StakeCredential.Credential,
+			CredentialType: cert.StakeCredential.CredType,
+			AddedSlot:      slot,
+			DepositAmount:  deposit,
+		},
+		&models.StakeDelegation{
+			StakingKey:  cert.StakeCredential.Credential,
+			PoolKeyHash: cert.PoolKeyHash,
+			AddedSlot:   slot,
+		},
+	}
+	for _, tmpItem := range tmpItems {
+		if txn != nil {
+			if result := txn.Create(tmpItem); result.Error != nil {
+				return result.Error
+			}
+		} else {
+			if result := d.DB().Create(tmpItem); result.Error != nil {
+				return result.Error
+			}
+		}
+	}
+	return nil
+}
+
+// SetStakeVoteDelegation saves a combined stake and vote delegation certificate
+func (d *MetadataStoreGorm) SetStakeVoteDelegation(
+	cert *lcommon.StakeVoteDelegationCertificate,
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItems := []any{
+		&models.StakeDelegation{
+			StakingKey:  cert.StakeCredential.Credential,
+			PoolKeyHash: cert.PoolKeyHash,
+			AddedSlot:   slot,
+		},
+		&models.VoteDelegation{
+			StakingKey:     cert.StakeCredential.Credential,
+			DrepType:       cert.Drep.Type,
+			DrepCredential: cert.Drep.Credential,
+			AddedSlot:      slot,
+		},
+	}
+	for _, tmpItem := range tmpItems {
+		if txn != nil {
+			if result := txn.Create(tmpItem); result.Error != nil {
+				return result.Error
+			}
+		} else {
+			if result := d.DB().Create(tmpItem); result.Error != nil {
+				return result.Error
+			}
+		}
+	}
+	return nil
+}
+
+// SetStakeVoteRegistrationDelegation saves a combined stake registration, stake delegation and vote delegation
+// certificate
+func (d *MetadataStoreGorm) SetStakeVoteRegistrationDelegation(
+	cert *lcommon.StakeVoteRegistrationDelegationCertificate,
+	slot, deposit uint64,
+	txn *gorm.DB,
+) error {
+	tmpItems := []any{
+		&models.StakeRegistration{
+			StakingKey:     cert.StakeCredential.Credential,
+			CredentialType: cert.StakeCredential.CredType,
+			AddedSlot:      slot,
+			DepositAmount:  deposit,
+		},
+		&models.StakeDelegation{
+			StakingKey:  cert.StakeCredential.Credential,
+			PoolKeyHash: cert.PoolKeyHash,
+			AddedSlot:   slot,
+		},
+		&models.VoteDelegation{
+			StakingKey:     cert.StakeCredential.Credential,
+			DrepType:       cert.Drep.Type,
+			DrepCredential: cert.Drep.Credential,
+			AddedSlot:      slot,
+		},
+	}
+	for _, tmpItem := range tmpItems {
+		if txn != nil {
+			if result := txn.Create(tmpItem); result.Error != nil {
+				return result.Error
+			}
+		} else {
+			if result := d.DB().Create(tmpItem); result.Error != nil {
+				return result.Error
+			}
+		}
+	}
+	return nil
+}
+
+// SetUpdateDrep saves a DRep update certificate
+func (d *MetadataStoreGorm) SetUpdateDrep(
+	cert *lcommon.UpdateDrepCertificate,
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.DrepUpdate{
+		DrepCredential: cert.DrepCredential.Credential,
+		AddedSlot:      slot,
+	}
+	if cert.Anchor != nil {
+		tmpItem.AnchorUrl = cert.Anchor.Url
+		tmpItem.AnchorHash = cert.Anchor.DataHash[:]
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetVoteDelegation saves a vote delegation certificate
+func (d *MetadataStoreGorm) SetVoteDelegation(
+	cert *lcommon.VoteDelegationCertificate,
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.VoteDelegation{
+		StakingKey:     cert.StakeCredential.Credential,
+		DrepType:       cert.Drep.Type,
+		DrepCredential: cert.Drep.Credential,
+		AddedSlot:      slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetVoteRegistrationDelegation saves a combined stake registration and vote delegation certificate
+func (d *MetadataStoreGorm) SetVoteRegistrationDelegation(
+	cert *lcommon.VoteRegistrationDelegationCertificate,
+	slot, deposit uint64,
+	txn *gorm.DB,
+) error {
+	tmpItems := []any{
+		&models.StakeRegistration{
+			StakingKey:     cert.StakeCredential.Credential,
+			CredentialType: cert.StakeCredential.CredType,
+			AddedSlot:      slot,
+			DepositAmount:  deposit,
+		},
+		&models.VoteDelegation{
+			StakingKey:     cert.StakeCredential.Credential,
+			DrepType:       cert.Drep.Type,
+			DrepCredential: cert.Drep.Credential,
+			AddedSlot:      slot,
+		},
+	}
+	for _, tmpItem := range tmpItems {
+		if txn != nil {
+			if result := txn.Create(tmpItem); result.Error != nil {
+				return result.Error
+			}
+		} else {
+			if result := d.DB().Create(tmpItem); result.Error != nil {
+				return result.Error
+			}
+		}
+	}
+	return nil
+}
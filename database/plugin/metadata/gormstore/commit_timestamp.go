@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package sqlite
+package gormstore
 
 import (
 	"errors"
@@ -25,7 +25,7 @@ const (
 	commitTimestampRowId = 1
 )
 
-// CommitTimestamp represents the sqlite table used to track the current commit timestamp
+// CommitTimestamp represents the table used to track the current commit timestamp
 type CommitTimestamp struct {
 	ID        uint `gorm:"primarykey"`
 	Timestamp int64
@@ -35,8 +35,8 @@ func (CommitTimestamp) TableName() string {
 	return "commit_timestamp"
 }
 
-func (d *MetadataStoreSqlite) GetCommitTimestamp() (int64, error) {
-	// Get value from sqlite
+func (d *MetadataStoreGorm) GetCommitTimestamp() (int64, error) {
+	// Get value from database
 	var tmpCommitTimestamp CommitTimestamp
 	result := d.DB().First(&tmpCommitTimestamp)
 	if result.Error != nil {
@@ -49,7 +49,7 @@ func (d *MetadataStoreSqlite) GetCommitTimestamp() (int64, error) {
 	return tmpCommitTimestamp.Timestamp, nil
 }
 
-func (d *MetadataStoreSqlite) SetCommitTimestamp(
+func (d *MetadataStoreGorm) SetCommitTimestamp(
 	txn *gorm.DB,
 	timestamp int64,
 ) error {
@@ -0,0 +1,133 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormstore
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"gorm.io/gorm"
+	"gorm.io/plugin/opentelemetry/tracing"
+)
+
+// MetadataStoreGorm implements the metadata store queries on top of a gorm
+// database handle. The individual metadata plugins are responsible for opening
+// the connection and embed this type for everything else
+type MetadataStoreGorm struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// New creates a metadata store using the provided gorm database handle and
+// creates any missing table schemas
+func New(
+	metadataDb *gorm.DB,
+	logger *slog.Logger,
+) (*MetadataStoreGorm, error) {
+	db := &MetadataStoreGorm{
+		db:     metadataDb,
+		logger: logger,
+	}
+	if err := db.init(); err != nil {
+		// MetadataStoreGorm is available for recovery, so return it with error
+		return db, err
+	}
+	// UTxOs stored before the amount was tracked need it filled in from their CBOR. We record this before adding
+	// the column, so that the migration isn't lost if we're interrupted
+	if db.db.Migrator().HasTable(&models.Utxo{}) &&
+		!db.db.Migrator().HasColumn(&models.Utxo{}, "Amount") {
+		if err := db.db.AutoMigrate(&models.UtxoAmountMigration{}); err != nil {
+			return db, err
+		}
+		if err := db.SetUtxoAmountMigration(0, db.db); err != nil {
+			return db, err
+		}
+	}
+	// Create table schemas
+	db.logger.Debug(fmt.Sprintf("creating table: %#v", &CommitTimestamp{}))
+	if err := db.db.AutoMigrate(&CommitTimestamp{}); err != nil {
+		return db, err
+	}
+	for _, model := range models.MigrateModels {
+		db.logger.Debug(fmt.Sprintf("creating table: %#v", model))
+		if err := db.db.AutoMigrate(model); err != nil {
+			return db, err
+		}
+	}
+	return db, nil
+}
+
+func (d *MetadataStoreGorm) init() error {
+	if d.logger == nil {
+		// Create logger to throw away logs
+		// We do this so we don't have to add guards around every log operation
+		d.logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
+	}
+	// Configure tracing for GORM
+	if err := d.db.Use(tracing.NewPlugin(tracing.WithoutMetrics())); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AutoMigrate wraps the gorm AutoMigrate
+func (d *MetadataStoreGorm) AutoMigrate(dst ...interface{}) error {
+	return d.DB().AutoMigrate(dst...)
+}
+
+// Close gets the database handle from our MetadataStore and closes it
+func (d *MetadataStoreGorm) Close() error {
+	// get DB handle from gorm.DB
+	db, err := d.DB().DB()
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// Create creates a record
+func (d *MetadataStoreGorm) Create(value interface{}) *gorm.DB {
+	return d.DB().Create(value)
+}
+
+// DB returns the database handle
+func (d *MetadataStoreGorm) DB() *gorm.DB {
+	return d.db
+}
+
+// First returns the first DB entry
+func (d *MetadataStoreGorm) First(args interface{}) *gorm.DB {
+	return d.DB().First(args)
+}
+
+// Order orders a DB query
+func (d *MetadataStoreGorm) Order(args interface{}) *gorm.DB {
+	return d.DB().Order(args)
+}
+
+// Transaction creates a gorm transaction
+func (d *MetadataStoreGorm) Transaction() *gorm.DB {
+	return d.DB().Begin()
+}
+
+// Where constrains a DB query
+func (d *MetadataStoreGorm) Where(
+	query interface{},
+	args ...interface{},
+) *gorm.DB {
+	return d.DB().Where(query, args...)
+}
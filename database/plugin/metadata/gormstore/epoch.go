@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package sqlite
+package gormstore
 
 import (
 	"errors"
@@ -22,7 +22,7 @@ import (
 )
 
 // GetEpochLatest returns the latest epoch
-func (d *MetadataStoreSqlite) GetEpochLatest(
+func (d *MetadataStoreGorm) GetEpochLatest(
 	txn *gorm.DB,
 ) (models.Epoch, error) {
 	ret := models.Epoch{}
@@ -45,7 +45,7 @@ func (d *MetadataStoreSqlite) GetEpochLatest(
 }
 
 // GetEpochsByEra returns the list of epochs by era
-func (d *MetadataStoreSqlite) GetEpochsByEra(
+func (d *MetadataStoreGorm) GetEpochsByEra(
 	eraId uint,
 	txn *gorm.DB,
 ) ([]models.Epoch, error) {
@@ -65,7 +65,7 @@ func (d *MetadataStoreSqlite) GetEpochsByEra(
 }
 
 // SetEpoch saves an epoch
-func (d *MetadataStoreSqlite) SetEpoch(
+func (d *MetadataStoreGorm) SetEpoch(
 	slot, epoch uint64,
 	nonce []byte,
 	era, slotLength, lengthInSlots uint,
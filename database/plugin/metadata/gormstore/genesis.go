@@ -0,0 +1,64 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormstore
+
+import (
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"gorm.io/gorm"
+)
+
+// GetGenesisKeyDelegations returns the latest genesis key delegation for each genesis key that is active as of the
+// specified slot
+func (d *MetadataStoreGorm) GetGenesisKeyDelegations(
+	slot uint64,
+	txn *gorm.DB,
+) ([]models.GenesisKeyDelegation, error) {
+	ret := []models.GenesisKeyDelegation{}
+	if txn == nil {
+		txn = d.DB()
+	}
+	latestIds := txn.Model(&models.GenesisKeyDelegation{}).
+		Select("MAX(id)").
+		Where("active_slot <= ?", slot).
+		Group("genesis_hash")
+	result := txn.Where("id IN (?)", latestIds).Find(&ret)
+	if result.Error != nil {
+		return ret, result.Error
+	}
+	return ret, nil
+}
+
+// SetGenesisKeyDelegation saves a genesis key delegation certificate, which becomes active at the specified slot
+func (d *MetadataStoreGorm) SetGenesisKeyDelegation(
+	cert *lcommon.GenesisKeyDelegationCertificate,
+	slot, activeSlot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.GenesisKeyDelegation{
+		GenesisHash:         cert.GenesisHash,
+		GenesisDelegateHash: cert.GenesisDelegateHash,
+		VrfKeyHash:          cert.VrfKeyHash[:],
+		ActiveSlot:          activeSlot,
+		AddedSlot:           slot,
+	}
+	if txn == nil {
+		txn = d.DB()
+	}
+	if result := txn.Create(&tmpItem); result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
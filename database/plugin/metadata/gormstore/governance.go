@@ -0,0 +1,304 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormstore
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"gorm.io/gorm"
+)
+
+// DeleteCommitteeMember removes a member from the constitutional committee
+func (d *MetadataStoreGorm) DeleteCommitteeMember(
+	coldCredential []byte,
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	if txn != nil {
+		result := txn.Model(&models.CommitteeMember{}).
+			Where("cold_credential = ? AND deleted_slot = 0", coldCredential).
+			Update("deleted_slot", slot)
+		if result.Error != nil {
+			return result.Error
+		}
+	} else {
+		result := d.DB().Model(&models.CommitteeMember{}).
+			Where("cold_credential = ? AND deleted_slot = 0", coldCredential).
+			Update("deleted_slot", slot)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// GetCommittee returns the current state of the constitutional committee. An empty record is returned if
+// there is no committee
+func (d *MetadataStoreGorm) GetCommittee(
+	txn *gorm.DB,
+) (models.Committee, error) {
+	ret := models.Committee{}
+	if txn != nil {
+		result := txn.Order("id DESC").First(&ret)
+		if result.Error != nil {
+			if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return ret, result.Error
+			}
+		}
+	} else {
+		result := d.DB().Order("id DESC").First(&ret)
+		if result.Error != nil {
+			if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return ret, result.Error
+			}
+		}
+	}
+	return ret, nil
+}
+
+// GetCommitteeMembers returns the current members of the constitutional committee
+func (d *MetadataStoreGorm) GetCommitteeMembers(
+	txn *gorm.DB,
+) ([]models.CommitteeMember, error) {
+	ret := []models.CommitteeMember{}
+	if txn != nil {
+		result := txn.Where("deleted_slot = 0").Order("id ASC").Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	} else {
+		result := d.DB().Where("deleted_slot = 0").Order("id ASC").Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	}
+	return ret, nil
+}
+
+// GetConstitution returns the current constitution. An empty record is returned if there is no constitution
+func (d *MetadataStoreGorm) GetConstitution(
+	txn *gorm.DB,
+) (models.Constitution, error) {
+	ret := models.Constitution{}
+	if txn != nil {
+		result := txn.Order("id DESC").First(&ret)
+		if result.Error != nil {
+			if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return ret, result.Error
+			}
+		}
+	} else {
+		result := d.DB().Order("id DESC").First(&ret)
+		if result.Error != nil {
+			if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return ret, result.Error
+			}
+		}
+	}
+	return ret, nil
+}
+
+// GetGovernanceProposals returns the governance proposals that have not yet been enacted or expired
+func (d *MetadataStoreGorm) GetGovernanceProposals(
+	txn *gorm.DB,
+) ([]models.GovernanceProposal, error) {
+	ret := []models.GovernanceProposal{}
+	if txn != nil {
+		result := txn.Where("enacted_slot = 0 AND expired_slot = 0").
+			Order("id ASC").
+			Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	} else {
+		result := d.DB().Where("enacted_slot = 0 AND expired_slot = 0").
+			Order("id ASC").
+			Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	}
+	return ret, nil
+}
+
+// GetGovernanceVotes returns the votes cast on a governance action
+func (d *MetadataStoreGorm) GetGovernanceVotes(
+	txId []byte,
+	actionIdx uint32,
+	txn *gorm.DB,
+) ([]models.GovernanceVote, error) {
+	ret := []models.GovernanceVote{}
+	if txn != nil {
+		result := txn.Where("proposal_tx_id = ? AND proposal_action_idx = ?", txId, actionIdx).
+			Order("id ASC").
+			Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	} else {
+		result := d.DB().Where("proposal_tx_id = ? AND proposal_action_idx = ?", txId, actionIdx).
+			Order("id ASC").
+			Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	}
+	return ret, nil
+}
+
+// SetCommittee saves the state of the constitutional committee
+func (d *MetadataStoreGorm) SetCommittee(
+	quorum *big.Rat,
+	noConfidence bool,
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.Committee{
+		Quorum:       &models.Rat{Rat: quorum},
+		NoConfidence: noConfidence,
+		AddedSlot:    slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetCommitteeMember adds a member to the constitutional committee
+func (d *MetadataStoreGorm) SetCommitteeMember(
+	coldCredential []byte,
+	expiresEpoch, slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.CommitteeMember{
+		ColdCredential: coldCredential,
+		ExpiresEpoch:   expiresEpoch,
+		AddedSlot:      slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetConstitution saves an enacted constitution
+func (d *MetadataStoreGorm) SetConstitution(
+	anchor *lcommon.GovAnchor,
+	scriptHash []byte,
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.Constitution{
+		AnchorUrl:  anchor.Url,
+		AnchorHash: anchor.DataHash[:],
+		ScriptHash: scriptHash,
+		AddedSlot:  slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetGovernanceProposal saves a governance action proposal
+func (d *MetadataStoreGorm) SetGovernanceProposal(
+	proposal *lcommon.ProposalProcedure,
+	txId []byte,
+	actionIdx uint32,
+	epoch, expiresEpoch, slot uint64,
+	txn *gorm.DB,
+) error {
+	actionCbor, err := cbor.Encode(&proposal.GovAction)
+	if err != nil {
+		return err
+	}
+	tmpItem := models.GovernanceProposal{
+		TxId:          txId,
+		ActionIdx:     actionIdx,
+		ActionType:    proposal.GovAction.Type,
+		ActionCbor:    actionCbor,
+		Deposit:       proposal.Deposit,
+		RewardAccount: proposal.RewardAccount.StakeKeyHash().Bytes(),
+		AnchorUrl:     proposal.Anchor.Url,
+		AnchorHash:    proposal.Anchor.DataHash[:],
+		ProposedEpoch: epoch,
+		ExpiresEpoch:  expiresEpoch,
+		AddedSlot:     slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetGovernanceVote saves a vote on a governance action
+func (d *MetadataStoreGorm) SetGovernanceVote(
+	voter *lcommon.Voter,
+	actionId *lcommon.GovActionId,
+	vote *lcommon.VotingProcedure,
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.GovernanceVote{
+		ProposalTxId:      actionId.TransactionId[:],
+		ProposalActionIdx: actionId.GovActionIdx,
+		VoterType:         voter.Type,
+		VoterHash:         voter.Hash[:],
+		Vote:              vote.Vote,
+		AddedSlot:         slot,
+	}
+	if vote.Anchor != nil {
+		tmpItem.AnchorUrl = vote.Anchor.Url
+		tmpItem.AnchorHash = vote.Anchor.DataHash[:]
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormstore
+
+import (
+	"errors"
+
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"gorm.io/gorm"
+)
+
+// GetOpCertCounter returns the latest operational certificate counter for a pool. An empty record is returned
+// if we haven't seen a block from the pool
+func (d *MetadataStoreGorm) GetOpCertCounter(
+	pkh lcommon.PoolKeyHash,
+	txn *gorm.DB,
+) (models.OpCertCounter, error) {
+	ret := models.OpCertCounter{}
+	if txn != nil {
+		result := txn.Where("pool_key_hash = ?", pkh[:]).
+			Order("id DESC").
+			First(&ret)
+		if result.Error != nil {
+			if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return ret, result.Error
+			}
+		}
+	} else {
+		result := d.DB().Where("pool_key_hash = ?", pkh[:]).
+			Order("id DESC").
+			First(&ret)
+		if result.Error != nil {
+			if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return ret, result.Error
+			}
+		}
+	}
+	return ret, nil
+}
+
+// GetOpCertCounters returns the latest operational certificate counter for each pool
+func (d *MetadataStoreGorm) GetOpCertCounters(
+	txn *gorm.DB,
+) ([]models.OpCertCounter, error) {
+	ret := []models.OpCertCounter{}
+	if txn != nil {
+		latestIds := txn.Model(&models.OpCertCounter{}).
+			Select("MAX(id)").
+			Group("pool_key_hash")
+		result := txn.Where("id IN (?)", latestIds).Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	} else {
+		latestIds := d.DB().Model(&models.OpCertCounter{}).
+			Select("MAX(id)").
+			Group("pool_key_hash")
+		result := d.DB().Where("id IN (?)", latestIds).Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	}
+	return ret, nil
+}
+
+// SetOpCertCounter saves the operational certificate counter from a block produced by a pool
+func (d *MetadataStoreGorm) SetOpCertCounter(
+	pkh lcommon.PoolKeyHash,
+	counter, slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.OpCertCounter{
+		PoolKeyHash: pkh[:],
+		Counter:     counter,
+		AddedSlot:   slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
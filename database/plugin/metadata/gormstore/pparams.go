@@ -12,26 +12,30 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package sqlite
+package gormstore
 
 import (
 	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
 	"gorm.io/gorm"
 )
 
-// GetPParams returns a list of protocol parameters for a given epoch
-func (d *MetadataStoreSqlite) GetPParams(
+// GetPParams returns a list of protocol parameters in effect for a given epoch, with the most recent first
+func (d *MetadataStoreGorm) GetPParams(
 	epoch uint64,
 	txn *gorm.DB,
 ) ([]models.PParams, error) {
 	ret := []models.PParams{}
 	if txn != nil {
-		result := txn.Where("epoch = ?", epoch).Order("id DESC").Find(&ret)
+		result := txn.Where("epoch <= ?", epoch).
+			Order("epoch DESC, id DESC").
+			Find(&ret)
 		if result.Error != nil {
 			return ret, result.Error
 		}
 	} else {
-		result := d.DB().Where("epoch = ?", epoch).Order("id DESC").Find(&ret)
+		result := d.DB().Where("epoch <= ?", epoch).
+			Order("epoch DESC, id DESC").
+			Find(&ret)
 		if result.Error != nil {
 			return ret, result.Error
 		}
@@ -40,7 +44,7 @@ func (d *MetadataStoreSqlite) GetPParams(
 }
 
 // GetPParamUpdates returns a list of protocol parameter updates for a given epoch
-func (d *MetadataStoreSqlite) GetPParamUpdates(
+func (d *MetadataStoreGorm) GetPParamUpdates(
 	epoch uint64,
 	txn *gorm.DB,
 ) ([]models.PParamUpdate, error) {
@@ -60,7 +64,7 @@ func (d *MetadataStoreSqlite) GetPParamUpdates(
 }
 
 // SetPParams saves protocol parameters
-func (d *MetadataStoreSqlite) SetPParams(
+func (d *MetadataStoreGorm) SetPParams(
 	params []byte,
 	slot, epoch uint64,
 	eraId uint,
@@ -85,7 +89,7 @@ func (d *MetadataStoreSqlite) SetPParams(
 }
 
 // SetPParamUpdate saves a protocol parameter update
-func (d *MetadataStoreSqlite) SetPParamUpdate(
+func (d *MetadataStoreGorm) SetPParamUpdate(
 	genesis, update []byte,
 	slot, epoch uint64,
 	txn *gorm.DB,
@@ -0,0 +1,177 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormstore
+
+import (
+	"errors"
+
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"gorm.io/gorm"
+)
+
+// DeletePoolBlocksBefore removes the recorded pool blocks for epochs before the specified epoch
+func (d *MetadataStoreGorm) DeletePoolBlocksBefore(
+	epoch uint64,
+	txn *gorm.DB,
+) error {
+	if txn != nil {
+		result := txn.Where("epoch < ?", epoch).Delete(&models.PoolBlock{})
+		if result.Error != nil {
+			return result.Error
+		}
+	} else {
+		result := d.DB().Where("epoch < ?", epoch).Delete(&models.PoolBlock{})
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// GetAdaPots returns the reserves and treasury at the start of an epoch. An empty record is returned if
+// none exists for the epoch
+func (d *MetadataStoreGorm) GetAdaPots(
+	epoch uint64,
+	txn *gorm.DB,
+) (models.AdaPots, error) {
+	ret := models.AdaPots{}
+	if txn != nil {
+		result := txn.Where("epoch = ?", epoch).Order("id DESC").First(&ret)
+		if result.Error != nil {
+			if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return ret, result.Error
+			}
+		}
+	} else {
+		result := d.DB().Where("epoch = ?", epoch).Order("id DESC").First(&ret)
+		if result.Error != nil {
+			if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return ret, result.Error
+			}
+		}
+	}
+	return ret, nil
+}
+
+// GetPoolBlocks returns the blocks produced by pools in an epoch
+func (d *MetadataStoreGorm) GetPoolBlocks(
+	epoch uint64,
+	txn *gorm.DB,
+) ([]models.PoolBlock, error) {
+	ret := []models.PoolBlock{}
+	if txn != nil {
+		result := txn.Where("epoch = ?", epoch).Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	} else {
+		result := d.DB().Where("epoch = ?", epoch).Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	}
+	return ret, nil
+}
+
+// SetAdaPots saves the reserves and treasury at the start of an epoch
+func (d *MetadataStoreGorm) SetAdaPots(
+	epoch, reserves, treasury, slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.AdaPots{
+		Epoch:     epoch,
+		Reserves:  models.Uint64(reserves),
+		Treasury:  models.Uint64(treasury),
+		AddedSlot: slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetPoolBlock saves a block produced by a pool
+func (d *MetadataStoreGorm) SetPoolBlock(
+	pkh lcommon.PoolKeyHash,
+	fees, epoch, slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.PoolBlock{
+		PoolKeyHash: pkh[:],
+		Fees:        fees,
+		Epoch:       epoch,
+		AddedSlot:   slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetRewards saves rewards paid to reward accounts
+func (d *MetadataStoreGorm) SetRewards(
+	rewards []models.Reward,
+	txn *gorm.DB,
+) error {
+	if len(rewards) == 0 {
+		return nil
+	}
+	if txn != nil {
+		if result := txn.CreateInBatches(rewards, 1000); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().CreateInBatches(rewards, 1000); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetWithdrawal saves a withdrawal from a reward account
+func (d *MetadataStoreGorm) SetWithdrawal(
+	stakingKey []byte,
+	amount, slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.Withdrawal{
+		StakingKey: stakingKey,
+		Amount:     amount,
+		AddedSlot:  slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
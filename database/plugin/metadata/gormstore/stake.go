@@ -0,0 +1,164 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormstore
+
+import (
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"gorm.io/gorm"
+)
+
+// DeleteDelegatedStakesBefore removes the per-staking key stake snapshots for epochs before the specified epoch
+func (d *MetadataStoreGorm) DeleteDelegatedStakesBefore(
+	epoch uint64,
+	txn *gorm.DB,
+) error {
+	if txn != nil {
+		result := txn.Where("epoch < ?", epoch).Delete(&models.DelegatedStake{})
+		if result.Error != nil {
+			return result.Error
+		}
+	} else {
+		result := d.DB().Where("epoch < ?", epoch).Delete(&models.DelegatedStake{})
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// GetDelegatedStakes returns the per-staking key stake snapshot for an epoch
+func (d *MetadataStoreGorm) GetDelegatedStakes(
+	epoch uint64,
+	txn *gorm.DB,
+) ([]models.DelegatedStake, error) {
+	ret := []models.DelegatedStake{}
+	if txn != nil {
+		result := txn.Where("epoch = ?", epoch).Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	} else {
+		result := d.DB().Where("epoch = ?", epoch).Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	}
+	return ret, nil
+}
+
+// GetDrepStakes returns the DRep stake distribution for an epoch
+func (d *MetadataStoreGorm) GetDrepStakes(
+	epoch uint64,
+	txn *gorm.DB,
+) ([]models.DrepStake, error) {
+	ret := []models.DrepStake{}
+	if txn != nil {
+		result := txn.Where("epoch = ?", epoch).Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	} else {
+		result := d.DB().Where("epoch = ?", epoch).Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	}
+	return ret, nil
+}
+
+// GetPoolStakes returns the pool stake distribution for an epoch
+func (d *MetadataStoreGorm) GetPoolStakes(
+	epoch uint64,
+	txn *gorm.DB,
+) ([]models.PoolStake, error) {
+	ret := []models.PoolStake{}
+	if txn != nil {
+		result := txn.Where("epoch = ?", epoch).Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	} else {
+		result := d.DB().Where("epoch = ?", epoch).Find(&ret)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	}
+	return ret, nil
+}
+
+// SetDelegatedStakes saves the per-staking key stake snapshot for an epoch
+func (d *MetadataStoreGorm) SetDelegatedStakes(
+	stakes []models.DelegatedStake,
+	txn *gorm.DB,
+) error {
+	if len(stakes) == 0 {
+		return nil
+	}
+	if txn != nil {
+		if result := txn.CreateInBatches(stakes, 1000); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().CreateInBatches(stakes, 1000); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetDrepStakes saves the DRep stake distribution for an epoch
+func (d *MetadataStoreGorm) SetDrepStakes(
+	stakes []models.DrepStake,
+	txn *gorm.DB,
+) error {
+	if len(stakes) == 0 {
+		return nil
+	}
+	if txn != nil {
+		if result := txn.CreateInBatches(stakes, 1000); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().CreateInBatches(stakes, 1000); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// SetPoolStake saves the active stake for a pool in the stake distribution for an epoch
+func (d *MetadataStoreGorm) SetPoolStake(
+	pkh lcommon.PoolKeyHash,
+	stake, epoch, slot uint64,
+	txn *gorm.DB,
+) error {
+	tmpItem := models.PoolStake{
+		PoolKeyHash: pkh[:],
+		Stake:       stake,
+		Epoch:       epoch,
+		AddedSlot:   slot,
+	}
+	if txn != nil {
+		if result := txn.Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	} else {
+		if result := d.DB().Create(&tmpItem); result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package sqlite
+package gormstore
 
 import (
 	"errors"
@@ -29,7 +29,7 @@ const (
 )
 
 // GetTip returns the current metadata Tip as ocommon.Tip
-func (d *MetadataStoreSqlite) GetTip(
+func (d *MetadataStoreGorm) GetTip(
 	txn *gorm.DB,
 ) (ocommon.Tip, error) {
 	ret := ocommon.Tip{}
@@ -60,7 +60,7 @@ func (d *MetadataStoreSqlite) GetTip(
 }
 
 // SetTip saves a tip
-func (d *MetadataStoreSqlite) SetTip(
+func (d *MetadataStoreGorm) SetTip(
 	tip ochainsync.Tip,
 	txn *gorm.DB,
 ) error {
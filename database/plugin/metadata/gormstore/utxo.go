@@ -0,0 +1,251 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormstore
+
+import (
+	"errors"
+
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	utxoAmountMigrationRowId = 1
+)
+
+// GetUtxo returns a Utxo by reference
+func (d *MetadataStoreGorm) GetUtxo(
+	txId []byte,
+	idx uint32,
+	txn *gorm.DB,
+) (models.Utxo, error) {
+	ret := models.Utxo{}
+	tmpUtxo := models.Utxo{}
+	if txn != nil {
+		result := txn.Where("deleted_slot = 0").
+			First(&tmpUtxo, "tx_id = ? AND output_idx = ?", txId, idx)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	} else {
+		result := d.DB().Where("deleted_slot = 0").First(&tmpUtxo, "tx_id = ? AND output_idx = ?", txId, idx)
+		if result.Error != nil {
+			return ret, result.Error
+		}
+	}
+	ret = tmpUtxo
+	return ret, nil
+}
+
+// GetUtxosByAddress returns a list of Utxos
+func (d *MetadataStoreGorm) GetUtxosByAddress(
+	addr ledger.Address,
+	txn *gorm.DB,
+) ([]models.Utxo, error) {
+	var ret []models.Utxo
+	// Build sub-query for address
+	var addrQuery *gorm.DB
+	if addr.PaymentKeyHash() != ledger.NewBlake2b224(nil) {
+		addrQuery = txn.Where("payment_key = ?", addr.PaymentKeyHash().Bytes())
+	}
+	if addr.StakeKeyHash() != ledger.NewBlake2b224(nil) {
+		if addrQuery != nil {
+			addrQuery = addrQuery.Or(
+				"staking_key = ?",
+				addr.StakeKeyHash().Bytes(),
+			)
+		} else {
+			addrQuery = txn.Where("staking_key = ?", addr.StakeKeyHash().Bytes())
+		}
+	}
+	result := txn.
+		Where("deleted_slot = 0").
+		Where(addrQuery).
+		Find(&ret)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ret, nil
+}
+
+// GetUtxosBatch returns up to limit unspent Utxos with an ID greater than afterId, ordered by ID. This
+// allows iterating over the full UTxO set in batches
+func (d *MetadataStoreGorm) GetUtxosBatch(
+	afterId uint,
+	limit int,
+	txn *gorm.DB,
+) ([]models.Utxo, error) {
+	var ret []models.Utxo
+	if txn != nil {
+		result := txn.Where("deleted_slot = 0 AND id > ?", afterId).
+			Order("id ASC").
+			Limit(limit).
+			Find(&ret)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+	} else {
+		result := d.DB().Where("deleted_slot = 0 AND id > ?", afterId).
+			Order("id ASC").
+			Limit(limit).
+			Find(&ret)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+	}
+	return ret, nil
+}
+
+func (d *MetadataStoreGorm) DeleteUtxo(
+	utxo any,
+	txn *gorm.DB,
+) error {
+	tmpUtxo, ok := utxo.(models.Utxo)
+	if !ok {
+		return errors.New("failed to convert utxo")
+	}
+	if txn != nil {
+		result := txn.Delete(&tmpUtxo)
+		if result.Error != nil {
+			return result.Error
+		}
+	} else {
+		result := d.DB().Delete(&tmpUtxo)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+func (d *MetadataStoreGorm) DeleteUtxos(
+	utxos []any,
+	txn *gorm.DB,
+) error {
+	tmpUtxos := []models.Utxo{}
+	for _, utxo := range utxos {
+		tmpUtxo, ok := utxo.(models.Utxo)
+		if !ok {
+			return errors.New("failed to convert utxo")
+		}
+		tmpUtxos = append(tmpUtxos, tmpUtxo)
+	}
+	if txn != nil {
+		result := txn.Delete(&tmpUtxos)
+		if result.Error != nil {
+			return result.Error
+		}
+	} else {
+		result := d.DB().Delete(&tmpUtxos)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// GetUtxosAfterId returns up to limit Utxos, including spent Utxos, with an ID greater than afterId, ordered by ID
+func (d *MetadataStoreGorm) GetUtxosAfterId(
+	afterId uint,
+	limit int,
+	txn *gorm.DB,
+) ([]models.Utxo, error) {
+	var ret []models.Utxo
+	if txn == nil {
+		txn = d.DB()
+	}
+	result := txn.Where("id > ?", afterId).
+		Order("id ASC").
+		Limit(limit).
+		Find(&ret)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ret, nil
+}
+
+// SetUtxoAmount sets the amount for the Utxo with the specified ID
+func (d *MetadataStoreGorm) SetUtxoAmount(
+	id uint,
+	amount uint64,
+	txn *gorm.DB,
+) error {
+	if txn == nil {
+		txn = d.DB()
+	}
+	result := txn.Model(&models.Utxo{}).
+		Where("id = ?", id).
+		Update("amount", amount)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+// GetUtxoAmountMigration returns the progress of the pending Utxo amount migration. The returned record has an
+// ID of 0 if no migration is pending
+func (d *MetadataStoreGorm) GetUtxoAmountMigration(
+	txn *gorm.DB,
+) (models.UtxoAmountMigration, error) {
+	ret := models.UtxoAmountMigration{}
+	if txn == nil {
+		txn = d.DB()
+	}
+	result := txn.First(&ret)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return models.UtxoAmountMigration{}, nil
+		}
+		return ret, result.Error
+	}
+	return ret, nil
+}
+
+// SetUtxoAmountMigration records the ID of the last Utxo processed by the pending Utxo amount migration
+func (d *MetadataStoreGorm) SetUtxoAmountMigration(
+	lastUtxoId uint,
+	txn *gorm.DB,
+) error {
+	if txn == nil {
+		txn = d.DB()
+	}
+	tmpMigration := models.UtxoAmountMigration{
+		ID:         utxoAmountMigrationRowId,
+		LastUtxoId: lastUtxoId,
+	}
+	result := txn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_utxo_id"}),
+	}).Create(&tmpMigration)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+// DeleteUtxoAmountMigration removes the record for the Utxo amount migration once it's complete
+func (d *MetadataStoreGorm) DeleteUtxoAmountMigration(txn *gorm.DB) error {
+	if txn == nil {
+		txn = d.DB()
+	}
+	result := txn.Where("id = ?", utxoAmountMigrationRowId).
+		Delete(&models.UtxoAmountMigration{})
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
@@ -15,5 +15,6 @@
 package metadata
 
 import (
+	_ "github.com/blinklabs-io/dingo/database/plugin/metadata/postgres"
 	_ "github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite"
 )
@@ -0,0 +1,146 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/blinklabs-io/dingo/database/plugin"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/gormstore"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const (
+	defaultHost     = "localhost"
+	defaultPort     = 5432
+	defaultUser     = "postgres"
+	defaultDatabase = "dingo"
+	defaultSslMode  = "disable"
+)
+
+var cmdlineOptions = struct {
+	host     string
+	port     uint
+	user     string
+	password string
+	database string
+	sslMode  string
+}{
+	host:     defaultHost,
+	port:     defaultPort,
+	user:     defaultUser,
+	database: defaultDatabase,
+	sslMode:  defaultSslMode,
+}
+
+// Register plugin
+func init() {
+	plugin.Register(
+		plugin.PluginEntry{
+			Type:        plugin.PluginTypeMetadata,
+			Name:        "postgres",
+			Description: "PostgreSQL relational database",
+			Options: []plugin.PluginOption{
+				{
+					Name:         "host",
+					Type:         plugin.PluginOptionTypeString,
+					Description:  "PostgreSQL server hostname",
+					DefaultValue: defaultHost,
+					Dest:         &(cmdlineOptions.host),
+				},
+				{
+					Name:         "port",
+					Type:         plugin.PluginOptionTypeUint,
+					Description:  "PostgreSQL server port",
+					DefaultValue: uint(defaultPort),
+					Dest:         &(cmdlineOptions.port),
+				},
+				{
+					Name:         "user",
+					Type:         plugin.PluginOptionTypeString,
+					Description:  "PostgreSQL username",
+					DefaultValue: defaultUser,
+					Dest:         &(cmdlineOptions.user),
+				},
+				{
+					Name:         "password",
+					Type:         plugin.PluginOptionTypeString,
+					Description:  "PostgreSQL password",
+					DefaultValue: "",
+					Dest:         &(cmdlineOptions.password),
+				},
+				{
+					Name:         "database",
+					Type:         plugin.PluginOptionTypeString,
+					Description:  "PostgreSQL database name",
+					DefaultValue: defaultDatabase,
+					Dest:         &(cmdlineOptions.database),
+				},
+				{
+					Name:         "ssl-mode",
+					Type:         plugin.PluginOptionTypeString,
+					Description:  "PostgreSQL SSL mode (disable, require, verify-ca, verify-full)",
+					DefaultValue: defaultSslMode,
+					Dest:         &(cmdlineOptions.sslMode),
+				},
+			},
+		},
+	)
+}
+
+// MetadataStorePostgres stores all data in a PostgreSQL database
+type MetadataStorePostgres struct {
+	*gormstore.MetadataStoreGorm
+	dsn string
+}
+
+// NewFromCmdlineOptions creates a new database using the connection parameters from the plugin options
+func NewFromCmdlineOptions(logger *slog.Logger) (*MetadataStorePostgres, error) {
+	dsn := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cmdlineOptions.host,
+		cmdlineOptions.port,
+		cmdlineOptions.user,
+		cmdlineOptions.password,
+		cmdlineOptions.database,
+		cmdlineOptions.sslMode,
+	)
+	return New(dsn, logger)
+}
+
+// New creates a new database using the provided PostgreSQL connection string
+func New(
+	dsn string,
+	logger *slog.Logger,
+) (*MetadataStorePostgres, error) {
+	metadataDb, err := gorm.Open(
+		postgres.Open(dsn),
+		&gorm.Config{
+			Logger: gormlogger.Discard,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	store, err := gormstore.New(metadataDb, logger)
+	// MetadataStorePostgres is available for recovery, so return it with any error
+	return &MetadataStorePostgres{
+		MetadataStoreGorm: store,
+		dsn:               dsn,
+	}, err
+}
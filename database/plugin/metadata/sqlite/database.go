@@ -17,35 +17,62 @@ package sqlite
 import (
 	"errors"
 	"fmt"
-	"io"
 	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 
 	"github.com/blinklabs-io/dingo/database/plugin"
-	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/gormstore"
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
-	"gorm.io/plugin/opentelemetry/tracing"
 )
 
+const (
+	defaultCacheSize = 50000 // KiB
+	defaultSync      = false
+)
+
+var cmdlineOptions = struct {
+	cacheSize uint
+	sync      bool
+}{
+	cacheSize: defaultCacheSize,
+	sync:      defaultSync,
+}
+
 // Register plugin
 func init() {
 	plugin.Register(
 		plugin.PluginEntry{
-			Type: plugin.PluginTypeMetadata,
-			Name: "sqlite",
+			Type:        plugin.PluginTypeMetadata,
+			Name:        "sqlite",
+			Description: "SQLite relational database",
+			Options: []plugin.PluginOption{
+				{
+					Name:         "cache-size",
+					Type:         plugin.PluginOptionTypeUint,
+					Description:  "SQLite page cache size in KiB",
+					DefaultValue: uint(defaultCacheSize),
+					Dest:         &(cmdlineOptions.cacheSize),
+				},
+				{
+					Name:         "sync",
+					Type:         plugin.PluginOptionTypeBool,
+					Description:  "sync SQLite database to disk on every write",
+					DefaultValue: defaultSync,
+					Dest:         &(cmdlineOptions.sync),
+				},
+			},
 		},
 	)
 }
 
 // MetadataStoreSqlite stores all data in sqlite. Data may not be persisted
 type MetadataStoreSqlite struct {
+	*gormstore.MetadataStoreGorm
 	dataDir string
-	db      *gorm.DB
-	logger  *slog.Logger
 }
 
 // New creates a new database
@@ -82,8 +109,16 @@ func New(
 			dataDir,
 			"metadata.sqlite",
 		)
-		// WAL journal mode, disable sync on write, increase cache size to 50MB (from 2MB)
-		metadataConnOpts := "_pragma=journal_mode(WAL)&_pragma=sync(OFF)&_pragma=cache_size(-50000)"
+		// WAL journal mode, disable sync on write (by default), increase cache size to 50MB (from 2MB)
+		syncMode := "OFF"
+		if cmdlineOptions.sync {
+			syncMode = "FULL"
+		}
+		metadataConnOpts := fmt.Sprintf(
+			"_pragma=journal_mode(WAL)&_pragma=sync(%s)&_pragma=cache_size(-%d)",
+			syncMode,
+			cmdlineOptions.cacheSize,
+		)
 		metadataDb, err = gorm.Open(
 			sqlite.Open(
 				fmt.Sprintf("file:%s?%s", metadataDbPath, metadataConnOpts),
@@ -96,86 +131,10 @@ func New(
 			return nil, err
 		}
 	}
-	db := &MetadataStoreSqlite{
-		db:      metadataDb,
-		dataDir: dataDir,
-		logger:  logger,
-	}
-	if err := db.init(); err != nil {
-		// MetadataStoreSqlite is available for recovery, so return it with error
-		return db, err
-	}
-	// Create table schemas
-	db.logger.Debug(fmt.Sprintf("creating table: %#v", &CommitTimestamp{}))
-	if err := db.db.AutoMigrate(&CommitTimestamp{}); err != nil {
-		return db, err
-	}
-	for _, model := range models.MigrateModels {
-		db.logger.Debug(fmt.Sprintf("creating table: %#v", model))
-		if err := db.db.AutoMigrate(model); err != nil {
-			return db, err
-		}
-	}
-	return db, nil
-}
-
-func (d *MetadataStoreSqlite) init() error {
-	if d.logger == nil {
-		// Create logger to throw away logs
-		// We do this so we don't have to add guards around every log operation
-		d.logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
-	}
-	// Configure tracing for GORM
-	if err := d.db.Use(tracing.NewPlugin(tracing.WithoutMetrics())); err != nil {
-		return err
-	}
-	return nil
-}
-
-// AutoMigrate wraps the gorm AutoMigrate
-func (d *MetadataStoreSqlite) AutoMigrate(dst ...interface{}) error {
-	return d.DB().AutoMigrate(dst...)
-}
-
-// Close gets the database handle from our MetadataStore and closes it
-func (d *MetadataStoreSqlite) Close() error {
-	// get DB handle from gorm.DB
-	db, err := d.DB().DB()
-	if err != nil {
-		return err
-	}
-	return db.Close()
-}
-
-// Create creates a record
-func (d *MetadataStoreSqlite) Create(value interface{}) *gorm.DB {
-	return d.DB().Create(value)
-}
-
-// DB returns the database handle
-func (d *MetadataStoreSqlite) DB() *gorm.DB {
-	return d.db
-}
-
-// First returns the first DB entry
-func (d *MetadataStoreSqlite) First(args interface{}) *gorm.DB {
-	return d.DB().First(args)
-}
-
-// Order orders a DB query
-func (d *MetadataStoreSqlite) Order(args interface{}) *gorm.DB {
-	return d.DB().Order(args)
-}
-
-// Transaction creates a gorm transaction
-func (d *MetadataStoreSqlite) Transaction() *gorm.DB {
-	return d.DB().Begin()
-}
-
-// Where constrains a DB query
-func (d *MetadataStoreSqlite) Where(
-	query interface{},
-	args ...interface{},
-) *gorm.DB {
-	return d.DB().Where(query, args...)
+	store, err := gormstore.New(metadataDb, logger)
+	// MetadataStoreSqlite is available for recovery, so return it with any error
+	return &MetadataStoreSqlite{
+		MetadataStoreGorm: store,
+		dataDir:           dataDir,
+	}, err
 }
@@ -0,0 +1,82 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+func newTestStore(t *testing.T) *sqlite.MetadataStoreSqlite {
+	// Use a temp dir rather than the shared in-memory DB to isolate tests
+	store, err := sqlite.New(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+	return store
+}
+
+func TestSqlitePoolRegistration(t *testing.T) {
+	store := newTestStore(t)
+	testCert := lcommon.PoolRegistrationCertificate{
+		CertType: lcommon.CertificateTypePoolRegistration,
+		Operator: lcommon.PoolKeyHash(
+			lcommon.NewBlake2b224([]byte("0123456789abcdef0123456789ab")),
+		),
+		VrfKeyHash: lcommon.VrfKeyHash(
+			lcommon.NewBlake2b256([]byte("0123456789abcdef0123456789abcdef")),
+		),
+		// Larger than int64 to make sure that we don't lose precision
+		Pledge: 18446744073709551615,
+		Cost:   340000000,
+		Margin: cbor.Rat{Rat: big.NewRat(3, 100)},
+	}
+	txn := store.Transaction()
+	if err := store.SetPoolRegistration(&testCert, 1234, 500000000, txn); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	certs, err := store.GetPoolRegistrations(testCert.Operator, txn)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Discard changes
+	txn.Rollback()
+	if len(certs) != 1 {
+		t.Fatalf("did not get expected number of certs: got %d, expected 1", len(certs))
+	}
+	if certs[0].Pledge != testCert.Pledge || certs[0].Cost != testCert.Cost {
+		t.Fatalf(
+			"did not get expected pledge/cost: got %d/%d, expected %d/%d",
+			certs[0].Pledge,
+			certs[0].Cost,
+			testCert.Pledge,
+			testCert.Cost,
+		)
+	}
+	if certs[0].Margin.Cmp(testCert.Margin.Rat) != 0 {
+		t.Fatalf(
+			"did not get expected margin: got %s, expected %s",
+			certs[0].Margin.String(),
+			testCert.Margin.String(),
+		)
+	}
+}
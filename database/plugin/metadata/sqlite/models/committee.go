@@ -0,0 +1,38 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+type AuthCommitteeHot struct {
+	ID             uint   `gorm:"primarykey"`
+	ColdCredential []byte `gorm:"index"`
+	HotCredential  []byte
+	AddedSlot      uint64
+}
+
+func (AuthCommitteeHot) TableName() string {
+	return "auth_committee_hot"
+}
+
+type ResignCommitteeCold struct {
+	ID             uint   `gorm:"primarykey"`
+	ColdCredential []byte `gorm:"index"`
+	AnchorUrl      string
+	AnchorHash     []byte
+	AddedSlot      uint64
+}
+
+func (ResignCommitteeCold) TableName() string {
+	return "resign_committee_cold"
+}
@@ -0,0 +1,30 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+// DelegatedStake represents the active stake for a staking key and the pool it's delegated to in the stake
+// snapshot taken at the start of an epoch
+type DelegatedStake struct {
+	ID          uint   `gorm:"primarykey"`
+	StakingKey  []byte `gorm:"index"`
+	PoolKeyHash []byte `gorm:"index"`
+	Epoch       uint64 `gorm:"index"`
+	Stake       uint64
+	AddedSlot   uint64
+}
+
+func (DelegatedStake) TableName() string {
+	return "delegated_stake"
+}
@@ -0,0 +1,31 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+// DrepStake represents the voting stake delegated to a DRep in the DRep stake distribution taken at the start of
+// an epoch. Stake delegated to the predefined "always abstain" and "always no confidence" options is recorded
+// without a credential
+type DrepStake struct {
+	ID             uint   `gorm:"primarykey"`
+	DrepCredential []byte `gorm:"index"`
+	DrepType       int
+	Epoch          uint64 `gorm:"index"`
+	Stake          uint64
+	AddedSlot      uint64
+}
+
+func (DrepStake) TableName() string {
+	return "drep_stake"
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+// GovernanceProposal represents a governance action proposed in a transaction. The ratified, enacted and expired
+// slots are set when the proposal reaches that state, and are reset on rollback
+type GovernanceProposal struct {
+	ID            uint   `gorm:"primarykey"`
+	TxId          []byte `gorm:"index"`
+	ActionIdx     uint32
+	ActionType    uint
+	ActionCbor    []byte
+	Deposit       uint64
+	RewardAccount []byte
+	AnchorUrl     string
+	AnchorHash    []byte
+	ProposedEpoch uint64
+	ExpiresEpoch  uint64
+	RatifiedEpoch uint64
+	RatifiedSlot  uint64 `gorm:"index"`
+	EnactedEpoch  uint64
+	EnactedSlot   uint64 `gorm:"index"`
+	ExpiredEpoch  uint64
+	ExpiredSlot   uint64 `gorm:"index"`
+	AddedSlot     uint64
+}
+
+func (GovernanceProposal) TableName() string {
+	return "governance_proposal"
+}
+
+// GovernanceVote represents a vote on a governance action by a DRep, SPO or constitutional committee member
+type GovernanceVote struct {
+	ID                uint   `gorm:"primarykey"`
+	ProposalTxId      []byte `gorm:"index"`
+	ProposalActionIdx uint32
+	VoterType         uint8
+	VoterHash         []byte
+	Vote              uint8
+	AnchorUrl         string
+	AnchorHash        []byte
+	AddedSlot         uint64
+}
+
+func (GovernanceVote) TableName() string {
+	return "governance_vote"
+}
+
+// Constitution represents an enacted constitution. The most recent record is the current constitution
+type Constitution struct {
+	ID         uint `gorm:"primarykey"`
+	AnchorUrl  string
+	AnchorHash []byte
+	ScriptHash []byte
+	AddedSlot  uint64
+}
+
+func (Constitution) TableName() string {
+	return "constitution"
+}
+
+// Committee represents the state of the constitutional committee. The most recent record is the current state
+type Committee struct {
+	ID           uint `gorm:"primarykey"`
+	Quorum       *Rat
+	NoConfidence bool
+	AddedSlot    uint64
+}
+
+func (Committee) TableName() string {
+	return "committee"
+}
+
+// CommitteeMember represents a member of the constitutional committee
+type CommitteeMember struct {
+	ID             uint   `gorm:"primarykey"`
+	ColdCredential []byte `gorm:"index"`
+	ExpiresEpoch   uint64
+	AddedSlot      uint64
+	DeletedSlot    uint64 `gorm:"index"`
+}
+
+func (CommitteeMember) TableName() string {
+	return "committee_member"
+}
@@ -16,16 +16,37 @@ package models
 
 // MigrateModels contains a list of model objects that should have DB migrations applied
 var MigrateModels = []any{
+	&AdaPots{},
+	&AuthCommitteeHot{},
+	&Committee{},
+	&CommitteeMember{},
+	&Constitution{},
+	&DelegatedStake{},
+	&DrepDeregistration{},
+	&DrepRegistration{},
+	&DrepStake{},
+	&DrepUpdate{},
 	&Epoch{},
+	&GenesisKeyDelegation{},
+	&GovernanceProposal{},
+	&GovernanceVote{},
+	&OpCertCounter{},
+	&PoolBlock{},
 	&PoolRegistration{},
 	&PoolRegistrationOwner{},
 	&PoolRegistrationRelay{},
 	&PoolRetirement{},
+	&PoolStake{},
 	&PParams{},
 	&PParamUpdate{},
+	&ResignCommitteeCold{},
+	&Reward{},
 	&StakeDelegation{},
 	&StakeDeregistration{},
 	&StakeRegistration{},
 	&Tip{},
 	&Utxo{},
+	&UtxoAmountMigration{},
+	&VoteDelegation{},
+	&Withdrawal{},
 }
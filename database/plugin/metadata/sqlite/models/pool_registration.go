@@ -29,6 +29,7 @@ type PoolRegistration struct {
 	Pledge        Uint64
 	Cost          Uint64
 	Margin        *Rat
+	RewardAccount []byte
 	Owners        []PoolRegistrationOwner
 	Relays        []PoolRegistrationRelay
 	MetadataUrl   string
@@ -54,6 +55,7 @@ func (PoolRegistrationOwner) TableName() string {
 type PoolRegistrationRelay struct {
 	ID                 uint `gorm:"primarykey"`
 	PoolRegistrationID uint
+	Type               int
 	Port               uint
 	Ipv4               *net.IP
 	Ipv6               *net.IP
@@ -93,25 +95,49 @@ func (r *Rat) Scan(val any) error {
 	return nil
 }
 
+// Uint64 stores a uint64 value as its decimal string representation, since
+// neither SQLite nor PostgreSQL have a native unsigned 64-bit integer type
+//
 //nolint:recvcheck
 type Uint64 uint64
 
+// GormDataType sets an explicit column type, so that the full uint64 range
+// round-trips on every database backend
+func (Uint64) GormDataType() string {
+	return "text"
+}
+
 func (u Uint64) Value() (driver.Value, error) {
 	return strconv.FormatUint(uint64(u), 10), nil
 }
 
 func (u *Uint64) Scan(val any) error {
-	v, ok := val.(string)
-	if !ok {
+	var tmpUint uint64
+	switch v := val.(type) {
+	case string:
+		tmp, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		tmpUint = tmp
+	case []byte:
+		tmp, err := strconv.ParseUint(string(v), 10, 64)
+		if err != nil {
+			return err
+		}
+		tmpUint = tmp
+	case int64:
+		// Columns created with a numeric type are returned as integers
+		if v < 0 {
+			return fmt.Errorf("value out of range for uint64: %d", v)
+		}
+		tmpUint = uint64(v)
+	default:
 		return fmt.Errorf(
-			"value was not expected type, wanted string, got %T",
+			"value was not expected type, wanted string, []byte or int64, got %T",
 			val,
 		)
 	}
-	tmpUint, err := strconv.ParseUint(v, 10, 64)
-	if err != nil {
-		return err
-	}
 	*u = Uint64(tmpUint)
 	return nil
 }
@@ -0,0 +1,94 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+)
+
+func TestUint64Scan(t *testing.T) {
+	testDefs := []struct {
+		value       any
+		expected    models.Uint64
+		expectError bool
+	}{
+		{
+			value:    "123",
+			expected: 123,
+		},
+		{
+			value:    "18446744073709551615",
+			expected: math.MaxUint64,
+		},
+		{
+			value:    []byte("18446744073709551615"),
+			expected: math.MaxUint64,
+		},
+		{
+			value:    int64(456),
+			expected: 456,
+		},
+		{
+			value:       int64(-1),
+			expectError: true,
+		},
+		{
+			value:       float64(1.5),
+			expectError: true,
+		},
+	}
+	for _, testDef := range testDefs {
+		var tmpUint models.Uint64
+		err := tmpUint.Scan(testDef.value)
+		if err != nil {
+			if testDef.expectError {
+				continue
+			}
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if testDef.expectError {
+			t.Fatalf("did not get expected error for value %#v", testDef.value)
+		}
+		if tmpUint != testDef.expected {
+			t.Fatalf(
+				"did not get expected value: got %d, expected %d",
+				tmpUint,
+				testDef.expected,
+			)
+		}
+	}
+}
+
+func TestUint64ValueRoundTrip(t *testing.T) {
+	orig := models.Uint64(math.MaxUint64)
+	val, err := orig.Value()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var tmpUint models.Uint64
+	if err := tmpUint.Scan(val); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if tmpUint != orig {
+		t.Fatalf(
+			"did not get expected value: got %d, expected %d",
+			tmpUint,
+			orig,
+		)
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+// PoolStake represents the active stake delegated to a pool in the stake distribution taken at the start of an epoch
+type PoolStake struct {
+	ID          uint   `gorm:"primarykey"`
+	PoolKeyHash []byte `gorm:"index"`
+	Epoch       uint64 `gorm:"index"`
+	Stake       uint64
+	AddedSlot   uint64
+}
+
+func (PoolStake) TableName() string {
+	return "pool_stake"
+}
@@ -0,0 +1,39 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+const (
+	RewardTypeLeader             = 0
+	RewardTypeMember             = 1
+	RewardTypeTreasuryWithdrawal = 2
+	RewardTypeProposalRefund     = 3
+	RewardTypePoolDepositRefund  = 4
+)
+
+// Reward represents a reward paid to a reward account at an epoch boundary for blocks produced in an earlier epoch.
+// Treasury withdrawals and governance action and pool deposit refunds are also paid to reward accounts as rewards
+type Reward struct {
+	ID          uint   `gorm:"primarykey"`
+	StakingKey  []byte `gorm:"index"`
+	PoolKeyHash []byte
+	Epoch       uint64 `gorm:"index"`
+	Type        uint8
+	Amount      uint64
+	AddedSlot   uint64
+}
+
+func (Reward) TableName() string {
+	return "reward"
+}
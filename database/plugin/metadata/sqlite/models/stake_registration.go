@@ -15,10 +15,12 @@
 package models
 
 type StakeRegistration struct {
-	ID            uint   `gorm:"primarykey"`
-	StakingKey    []byte `gorm:"index"`
-	AddedSlot     uint64
-	DepositAmount uint64
+	ID         uint   `gorm:"primarykey"`
+	StakingKey []byte `gorm:"index"`
+	// Type of the stake credential, which determines the reward account address
+	CredentialType uint
+	AddedSlot      uint64
+	DepositAmount  uint64
 }
 
 func (StakeRegistration) TableName() string {
@@ -22,9 +22,21 @@ type Utxo struct {
 	DeletedSlot uint64 `gorm:"index"`
 	PaymentKey  []byte `gorm:"index"`
 	StakingKey  []byte `gorm:"index"`
+	Amount      uint64
 	Cbor        []byte `gorm:"-"` // This is here for convenience but not represented in the metadata DB
 }
 
 func (u *Utxo) TableName() string {
 	return "utxo"
 }
+
+// UtxoAmountMigration tracks the progress of filling in the amount for UTxOs that were stored before the amount
+// was tracked. The record only exists while the migration is pending
+type UtxoAmountMigration struct {
+	ID         uint `gorm:"primarykey"`
+	LastUtxoId uint
+}
+
+func (UtxoAmountMigration) TableName() string {
+	return "utxo_amount_migration"
+}
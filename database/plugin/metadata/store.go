@@ -15,8 +15,12 @@
 package metadata
 
 import (
+	"fmt"
 	"log/slog"
+	"math/big"
 
+	"github.com/blinklabs-io/dingo/database/plugin"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/postgres"
 	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite"
 	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
 	"github.com/blinklabs-io/gouroboros/ledger"
@@ -34,10 +38,61 @@ type MetadataStore interface {
 	Transaction() *gorm.DB
 
 	// Ledger state
+	DeleteCommitteeMember(
+		[]byte, // coldCredential
+		uint64, // slot
+		*gorm.DB,
+	) error
+	DeleteDelegatedStakesBefore(
+		uint64, // epoch
+		*gorm.DB,
+	) error
+	DeletePoolBlocksBefore(
+		uint64, // epoch
+		*gorm.DB,
+	) error
+	GetAdaPots(
+		uint64, // epoch
+		*gorm.DB,
+	) (models.AdaPots, error)
+	GetCommittee(*gorm.DB) (models.Committee, error)
+	GetCommitteeMembers(*gorm.DB) ([]models.CommitteeMember, error)
+	GetConstitution(*gorm.DB) (models.Constitution, error)
+	GetDelegatedStakes(
+		uint64, // epoch
+		*gorm.DB,
+	) ([]models.DelegatedStake, error)
+	GetDrepStakes(
+		uint64, // epoch
+		*gorm.DB,
+	) ([]models.DrepStake, error)
+	GetGenesisKeyDelegations(
+		uint64, // slot
+		*gorm.DB,
+	) ([]models.GenesisKeyDelegation, error)
+	GetGovernanceProposals(*gorm.DB) ([]models.GovernanceProposal, error)
+	GetGovernanceVotes(
+		[]byte, // txId
+		uint32, // actionIdx
+		*gorm.DB,
+	) ([]models.GovernanceVote, error)
+	GetOpCertCounter(
+		lcommon.PoolKeyHash,
+		*gorm.DB,
+	) (models.OpCertCounter, error)
+	GetOpCertCounters(*gorm.DB) ([]models.OpCertCounter, error)
+	GetPoolBlocks(
+		uint64, // epoch
+		*gorm.DB,
+	) ([]models.PoolBlock, error)
 	GetPoolRegistrations(
 		lcommon.PoolKeyHash,
 		*gorm.DB,
 	) ([]lcommon.PoolRegistrationCertificate, error)
+	GetPoolStakes(
+		uint64, // epoch
+		*gorm.DB,
+	) ([]models.PoolStake, error)
 	GetStakeRegistrations(
 		[]byte, // stakeKey
 		*gorm.DB,
@@ -58,6 +113,13 @@ type MetadataStore interface {
 		*gorm.DB,
 	) (models.Utxo, error)
 
+	SetAdaPots(
+		uint64, // epoch
+		uint64, // reserves
+		uint64, // treasury
+		uint64, // slot
+		*gorm.DB,
+	) error
 	SetEpoch(
 		uint64, // epoch
 		uint64, // slot
@@ -67,6 +129,83 @@ type MetadataStore interface {
 		uint, // lengthInSlots
 		*gorm.DB,
 	) error
+	SetAuthCommitteeHot(
+		*lcommon.AuthCommitteeHotCertificate,
+		uint64, // slot
+		*gorm.DB,
+	) error
+	SetCommittee(
+		*big.Rat, // quorum
+		bool, // noConfidence
+		uint64, // slot
+		*gorm.DB,
+	) error
+	SetCommitteeMember(
+		[]byte, // coldCredential
+		uint64, // expiresEpoch
+		uint64, // slot
+		*gorm.DB,
+	) error
+	SetConstitution(
+		*lcommon.GovAnchor,
+		[]byte, // scriptHash
+		uint64, // slot
+		*gorm.DB,
+	) error
+	SetDelegatedStakes(
+		[]models.DelegatedStake,
+		*gorm.DB,
+	) error
+	SetDeregistration(
+		*lcommon.DeregistrationCertificate,
+		uint64, // slot
+		*gorm.DB,
+	) error
+	SetDeregistrationDrep(
+		*lcommon.DeregistrationDrepCertificate,
+		uint64, // slot
+		uint64, // deposit
+		*gorm.DB,
+	) error
+	SetDrepStakes(
+		[]models.DrepStake,
+		*gorm.DB,
+	) error
+	SetGenesisKeyDelegation(
+		*lcommon.GenesisKeyDelegationCertificate,
+		uint64, // slot
+		uint64, // activeSlot
+		*gorm.DB,
+	) error
+	SetGovernanceProposal(
+		*lcommon.ProposalProcedure,
+		[]byte, // txId
+		uint32, // actionIdx
+		uint64, // epoch
+		uint64, // expiresEpoch
+		uint64, // slot
+		*gorm.DB,
+	) error
+	SetGovernanceVote(
+		*lcommon.Voter,
+		*lcommon.GovActionId,
+		*lcommon.VotingProcedure,
+		uint64, // slot
+		*gorm.DB,
+	) error
+	SetOpCertCounter(
+		lcommon.PoolKeyHash,
+		uint64, // counter
+		uint64, // slot
+		*gorm.DB,
+	) error
+	SetPoolBlock(
+		lcommon.PoolKeyHash,
+		uint64, // fees
+		uint64, // epoch
+		uint64, // slot
+		*gorm.DB,
+	) error
 	SetPoolRegistration(
 		*lcommon.PoolRegistrationCertificate,
 		uint64, // slot
@@ -78,6 +217,13 @@ type MetadataStore interface {
 		uint64, // slot
 		*gorm.DB,
 	) error
+	SetPoolStake(
+		lcommon.PoolKeyHash,
+		uint64, // stake
+		uint64, // epoch
+		uint64, // slot
+		*gorm.DB,
+	) error
 	SetPParams(
 		[]byte, // pparams
 		uint64, // slot
@@ -92,6 +238,27 @@ type MetadataStore interface {
 		uint64, // epoch
 		*gorm.DB,
 	) error
+	SetRegistration(
+		*lcommon.RegistrationCertificate,
+		uint64, // slot
+		uint64, // deposit
+		*gorm.DB,
+	) error
+	SetRegistrationDrep(
+		*lcommon.RegistrationDrepCertificate,
+		uint64, // slot
+		uint64, // deposit
+		*gorm.DB,
+	) error
+	SetResignCommitteeCold(
+		*lcommon.ResignCommitteeColdCertificate,
+		uint64, // slot
+		*gorm.DB,
+	) error
+	SetRewards(
+		[]models.Reward,
+		*gorm.DB,
+	) error
 	SetStakeDelegation(
 		*lcommon.StakeDelegationCertificate,
 		uint64, // slot
@@ -108,23 +275,88 @@ type MetadataStore interface {
 		uint64, // deposit
 		*gorm.DB,
 	) error
+	SetStakeRegistrationDelegation(
+		*lcommon.StakeRegistrationDelegationCertificate,
+		uint64, // slot
+		uint64, // deposit
+		*gorm.DB,
+	) error
+	SetStakeVoteDelegation(
+		*lcommon.StakeVoteDelegationCertificate,
+		uint64, // slot
+		*gorm.DB,
+	) error
+	SetStakeVoteRegistrationDelegation(
+		*lcommon.StakeVoteRegistrationDelegationCertificate,
+		uint64, // slot
+		uint64, // deposit
+		*gorm.DB,
+	) error
 	SetTip(
 		ochainsync.Tip,
 		*gorm.DB,
 	) error
+	SetUpdateDrep(
+		*lcommon.UpdateDrepCertificate,
+		uint64, // slot
+		*gorm.DB,
+	) error
+	SetVoteDelegation(
+		*lcommon.VoteDelegationCertificate,
+		uint64, // slot
+		*gorm.DB,
+	) error
+	SetVoteRegistrationDelegation(
+		*lcommon.VoteRegistrationDelegationCertificate,
+		uint64, // slot
+		uint64, // deposit
+		*gorm.DB,
+	) error
+	SetWithdrawal(
+		[]byte, // stakingKey
+		uint64, // amount
+		uint64, // slot
+		*gorm.DB,
+	) error
 
 	// Helpers
 	GetEpochLatest(*gorm.DB) (models.Epoch, error)
 	GetEpochsByEra(uint, *gorm.DB) ([]models.Epoch, error)
 	GetUtxosByAddress(ledger.Address, *gorm.DB) ([]models.Utxo, error)
+	GetUtxosBatch(uint, int, *gorm.DB) ([]models.Utxo, error)
+	GetUtxosAfterId(uint, int, *gorm.DB) ([]models.Utxo, error)
+	SetUtxoAmount(
+		uint, // id
+		uint64, // amount
+		*gorm.DB,
+	) error
+	GetUtxoAmountMigration(*gorm.DB) (models.UtxoAmountMigration, error)
+	SetUtxoAmountMigration(
+		uint, // lastUtxoId
+		*gorm.DB,
+	) error
+	DeleteUtxoAmountMigration(*gorm.DB) error
 	DeleteUtxo(any, *gorm.DB) error
 	DeleteUtxos([]any, *gorm.DB) error
 }
 
-// For now, this always returns a sqlite plugin
+// New returns a new MetadataStore using the specified plugin
 func New(
 	pluginName, dataDir string,
 	logger *slog.Logger,
 ) (MetadataStore, error) {
-	return sqlite.New(dataDir, logger)
+	if plugin.GetPluginEntry(plugin.PluginTypeMetadata, pluginName) == nil {
+		return nil, fmt.Errorf("unknown metadata plugin: %s", pluginName)
+	}
+	switch pluginName {
+	case "sqlite":
+		return sqlite.New(dataDir, logger)
+	case "postgres":
+		return postgres.NewFromCmdlineOptions(logger)
+	default:
+		return nil, fmt.Errorf(
+			"metadata plugin does not provide a MetadataStore: %s",
+			pluginName,
+		)
+	}
 }
@@ -62,7 +62,10 @@ func PopulateCmdlineOptions(fs *flag.FlagSet) error {
 	return nil
 }
 
-func ProcessEnvVars() error {
+// ProcessEnvVars populates plugin options from env vars. The env var for each option is generated from
+// the provided global prefix, the plugin type and name, and the option name, such as
+// CARDANO_METADATA_POSTGRES_HOST
+func ProcessEnvVars(globalPrefix string) error {
 	for _, plugin := range pluginEntries {
 		// Generate env var prefix based on plugin type and name
 		envVarPrefix := fmt.Sprintf(
@@ -70,6 +73,9 @@ func ProcessEnvVars() error {
 			PluginTypeName(plugin.Type),
 			plugin.Name,
 		)
+		if globalPrefix != "" {
+			envVarPrefix = globalPrefix + "-" + envVarPrefix
+		}
 		for _, option := range plugin.Options {
 			if err := option.ProcessEnvVars(envVarPrefix); err != nil {
 				return err
@@ -106,6 +112,17 @@ func GetPlugins(pluginType PluginType) []PluginEntry {
 	return ret
 }
 
+// GetPluginEntry returns the registered plugin entry with the specified type and name, or nil
+// if no such plugin is registered
+func GetPluginEntry(pluginType PluginType, name string) *PluginEntry {
+	for _, plugin := range pluginEntries {
+		if plugin.Type == pluginType && plugin.Name == name {
+			return &plugin
+		}
+	}
+	return nil
+}
+
 func GetPlugin(pluginType PluginType, name string) Plugin {
 	for _, plugin := range pluginEntries {
 		if plugin.Type == pluginType {
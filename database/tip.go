@@ -24,6 +24,10 @@ func (d *Database) GetTip(txn *Txn) (ochainsync.Tip, error) {
 	tmpTip := ochainsync.Tip{}
 	if txn == nil {
 		txn = d.Transaction(false)
+		// Release our read transaction so that it doesn't block later writes
+		defer func() {
+			_ = txn.Rollback()
+		}()
 	}
 	tip, err := txn.DB().Metadata().GetTip(txn.Metadata())
 	if err != nil {
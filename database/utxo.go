@@ -16,12 +16,17 @@ package database
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
 
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
 	"github.com/blinklabs-io/gouroboros/ledger"
 	"github.com/dgraph-io/badger/v4"
 )
 
+// Number of UTxOs to update in each transaction when filling in missing UTxO amounts
+const utxoAmountMigrationBatchSize = 1000
+
 type Utxo struct {
 	ID          uint   `gorm:"primarykey"`
 	TxId        []byte `gorm:"index:tx_id_output_idx"`
@@ -30,6 +35,7 @@ type Utxo struct {
 	DeletedSlot uint64 `gorm:"index"`
 	PaymentKey  []byte `gorm:"index"`
 	StakingKey  []byte `gorm:"index"`
+	Amount      uint64
 	Cbor        []byte `gorm:"-"` // This is not represented in the metadata DB
 }
 
@@ -85,6 +91,7 @@ func (d *Database) UtxoByRef(
 	tmpUtxo.DeletedSlot = utxo.DeletedSlot
 	tmpUtxo.PaymentKey = utxo.PaymentKey
 	tmpUtxo.StakingKey = utxo.StakingKey
+	tmpUtxo.Amount = utxo.Amount
 	if err := tmpUtxo.loadCbor(txn); err != nil {
 		return tmpUtxo, err
 	}
@@ -119,6 +126,41 @@ func (d *Database) UtxosByAddress(
 			DeletedSlot: utxo.DeletedSlot,
 			PaymentKey:  utxo.PaymentKey,
 			StakingKey:  utxo.StakingKey,
+			Amount:      utxo.Amount,
+		}
+		if err := tmpUtxo.loadCbor(txn); err != nil {
+			return ret, err
+		}
+		ret = append(ret, tmpUtxo)
+	}
+	return ret, nil
+}
+
+// UtxosBatch returns up to limit unspent UTxOs with an ID greater than afterId, ordered by ID. The full UTxO
+// set can be iterated by passing the ID of the last UTxO from the previous batch
+func (d *Database) UtxosBatch(
+	afterId uint,
+	limit int,
+	txn *Txn,
+) ([]Utxo, error) {
+	ret := []Utxo{}
+	if txn == nil {
+		txn = d.Transaction(false)
+	}
+	utxos, err := txn.DB().Metadata().GetUtxosBatch(afterId, limit, txn.Metadata())
+	if err != nil {
+		return ret, err
+	}
+	for _, utxo := range utxos {
+		tmpUtxo := Utxo{
+			ID:          utxo.ID,
+			TxId:        utxo.TxId,
+			OutputIdx:   utxo.OutputIdx,
+			AddedSlot:   utxo.AddedSlot,
+			DeletedSlot: utxo.DeletedSlot,
+			PaymentKey:  utxo.PaymentKey,
+			StakingKey:  utxo.StakingKey,
+			Amount:      utxo.Amount,
 		}
 		if err := tmpUtxo.loadCbor(txn); err != nil {
 			return ret, err
@@ -143,7 +185,7 @@ func (d *Database) UtxoDelete(
 		txn = d.Transaction(false)
 	}
 	// Remove from metadata DB
-	err := txn.DB().Metadata().DeleteUtxo(utxo, txn.Metadata())
+	err := txn.DB().Metadata().DeleteUtxo(models.Utxo(utxo), txn.Metadata())
 	if err != nil {
 		return err
 	}
@@ -170,8 +212,12 @@ func (d *Database) UtxosDelete(
 	if txn == nil {
 		txn = d.Transaction(false)
 	}
-	// Remove from metadata DB
-	err := txn.DB().Metadata().DeleteUtxos([]any{utxos}, txn.Metadata())
+	// Remove from metadata DB. The metadata store expects its own model type for each UTxO
+	tmpUtxos := make([]any, 0, len(utxos))
+	for _, utxo := range utxos {
+		tmpUtxos = append(tmpUtxos, models.Utxo(utxo))
+	}
+	err := txn.DB().Metadata().DeleteUtxos(tmpUtxos, txn.Metadata())
 	if err != nil {
 		return err
 	}
@@ -195,3 +241,90 @@ func UtxoBlobKey(txId []byte, outputIdx uint32) []byte {
 	key = append(key, idxBytes...)
 	return key
 }
+
+// migrateUtxoAmounts fills in the amount for UTxOs that were stored before the amount was tracked, using the
+// stored UTxO CBOR. The metadata store records that this is needed when it adds the column. Progress is saved
+// after each batch, so an interrupted migration picks up where it left off
+func (d *Database) migrateUtxoAmounts() error {
+	migration, err := d.Metadata().GetUtxoAmountMigration(nil)
+	if err != nil {
+		return err
+	}
+	if migration.ID == 0 {
+		return nil
+	}
+	d.logger.Info(
+		"filling in amounts for existing UTxOs, this may take a while",
+		"component", "database",
+	)
+	lastId := migration.LastUtxoId
+	var utxoCount int
+	for {
+		batchCount := 0
+		txn := d.Transaction(true)
+		err := txn.Do(func(txn *Txn) error {
+			utxos, err := txn.DB().Metadata().GetUtxosAfterId(
+				lastId,
+				utxoAmountMigrationBatchSize,
+				txn.Metadata(),
+			)
+			if err != nil {
+				return err
+			}
+			batchCount = len(utxos)
+			if batchCount == 0 {
+				return txn.DB().Metadata().DeleteUtxoAmountMigration(txn.Metadata())
+			}
+			for _, utxo := range utxos {
+				tmpUtxo := Utxo(utxo)
+				if err := tmpUtxo.loadCbor(txn); err != nil {
+					// Spent UTxOs may have already been removed from the blob DB, and they don't count toward stake
+					if errors.Is(err, badger.ErrKeyNotFound) &&
+						tmpUtxo.DeletedSlot > 0 {
+						continue
+					}
+					return fmt.Errorf(
+						"load CBOR for UTxO %x#%d: %w, a resync is required",
+						tmpUtxo.TxId,
+						tmpUtxo.OutputIdx,
+						err,
+					)
+				}
+				txOut, err := tmpUtxo.Decode()
+				if err != nil {
+					return fmt.Errorf(
+						"decode UTxO %x#%d: %w",
+						tmpUtxo.TxId,
+						tmpUtxo.OutputIdx,
+						err,
+					)
+				}
+				err = txn.DB().Metadata().SetUtxoAmount(
+					tmpUtxo.ID,
+					txOut.Amount(),
+					txn.Metadata(),
+				)
+				if err != nil {
+					return err
+				}
+			}
+			lastId = utxos[len(utxos)-1].ID
+			return txn.DB().Metadata().SetUtxoAmountMigration(
+				lastId,
+				txn.Metadata(),
+			)
+		})
+		if err != nil {
+			return fmt.Errorf("fill in UTxO amounts: %w", err)
+		}
+		if batchCount == 0 {
+			break
+		}
+		utxoCount += batchCount
+	}
+	d.logger.Info(
+		fmt.Sprintf("filled in amounts for %d existing UTxOs", utxoCount),
+		"component", "database",
+	)
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database_test
+
+import (
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"github.com/blinklabs-io/gouroboros/cbor"
+)
+
+func TestMigrateUtxoAmounts(t *testing.T) {
+	testDefs := []struct {
+		txId        byte
+		amount      uint64
+		deletedSlot uint64
+		noCbor      bool
+	}{
+		{txId: 0x01, amount: 1_000_000},
+		{txId: 0x02, amount: 45_000_000_000_000},
+		// Spent UTxOs are filled in if we still have their CBOR and skipped otherwise
+		{txId: 0x03, amount: 2_000_000, deletedSlot: 1234},
+		{txId: 0x04, amount: 3_000_000, deletedSlot: 1234, noCbor: true},
+	}
+	dataDir := t.TempDir()
+	db, err := database.New(&database.Config{DataDir: dataDir})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Store UTxOs without an amount and remove the column, like a database from before the amount was tracked
+	addr := append([]byte{0x60}, make([]byte, 28)...)
+	txn := db.Transaction(true)
+	err = txn.Do(func(txn *database.Txn) error {
+		for _, testDef := range testDefs {
+			txId := make([]byte, 32)
+			txId[0] = testDef.txId
+			if !testDef.noCbor {
+				outputCbor, err := cbor.Encode([]any{addr, testDef.amount})
+				if err != nil {
+					return err
+				}
+				if err := txn.Blob().Set(database.UtxoBlobKey(txId, 0), outputCbor); err != nil {
+					return err
+				}
+			}
+			tmpUtxo := models.Utxo{
+				TxId:        txId,
+				DeletedSlot: testDef.deletedSlot,
+			}
+			if result := txn.Metadata().Create(&tmpUtxo); result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := db.Metadata().DB().Migrator().DropColumn(&models.Utxo{}, "Amount"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Reopening the database adds the column and fills in the amounts
+	db, err = database.New(&database.Config{DataDir: dataDir})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+	var utxos []models.Utxo
+	if result := db.Metadata().DB().Order("id ASC").Find(&utxos); result.Error != nil {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if len(utxos) != len(testDefs) {
+		t.Fatalf("did not get expected number of UTxOs: got %d, expected %d", len(utxos), len(testDefs))
+	}
+	for idx, testDef := range testDefs {
+		expectedAmount := testDef.amount
+		if testDef.noCbor {
+			expectedAmount = 0
+		}
+		if utxos[idx].Amount != expectedAmount {
+			t.Fatalf(
+				"did not get expected amount for UTxO %d: got %d, expected %d",
+				idx,
+				utxos[idx].Amount,
+				expectedAmount,
+			)
+		}
+	}
+	migration, err := db.Metadata().GetUtxoAmountMigration(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if migration.ID != 0 {
+		t.Fatalf("UTxO amount migration is still pending")
+	}
+}
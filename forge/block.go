@@ -0,0 +1,270 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"fmt"
+
+	"github.com/blinklabs-io/dingo/mempool"
+	"github.com/blinklabs-io/dingo/state"
+	"github.com/blinklabs-io/dingo/state/eras"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+const (
+	// Allowance for the CBOR framing of the block body components, which isn't included in the sizes of the
+	// individual transactions
+	blockBodyOverhead = 64
+	// Allowance for the map key for auxiliary data and the entry in the invalid TX list for a transaction
+	txIndexOverhead = 5
+)
+
+// forgeTx contains the block body components for a transaction to be included in a forged block
+type forgeTx struct {
+	hash          string
+	body          cbor.RawMessage
+	witnesses     cbor.RawMessage
+	auxiliaryData cbor.RawMessage
+	isValid       bool
+}
+
+func (t *forgeTx) size() uint64 {
+	ret := uint64(len(t.body) + len(t.witnesses))
+	if t.auxiliaryData != nil {
+		ret += uint64(len(t.auxiliaryData)) + txIndexOverhead
+	}
+	if !t.isValid {
+		ret += txIndexOverhead
+	}
+	return ret
+}
+
+// eraBlockTypes returns the block and transaction types for the specified era. We only forge blocks in the
+// Praos eras
+func eraBlockTypes(eraId uint) (uint, uint, error) {
+	switch eraId {
+	case eras.BabbageEraDesc.Id:
+		return ledger.BlockTypeBabbage, ledger.TxTypeBabbage, nil
+	case eras.ConwayEraDesc.Id:
+		return ledger.BlockTypeConway, ledger.TxTypeConway, nil
+	default:
+		return 0, 0, fmt.Errorf(
+			"block forging is not supported in the %s era",
+			eras.Eras[eraId].Name,
+		)
+	}
+}
+
+// selectTransactions chooses transactions from the mempool for a new block, in mempool order, while staying within
+// the max block body size and execution units from the protocol parameters
+func (f *Forger) selectTransactions(
+	ctx *state.ForgingContext,
+	txType uint,
+) ([]*forgeTx, error) {
+	ret := []*forgeTx{}
+	if ctx.PParams == nil {
+		return ret, nil
+	}
+	pparams := ctx.PParams.Utxorpc()
+	if pparams == nil {
+		return ret, nil
+	}
+	maxBodySize := pparams.GetMaxBlockBodySize()
+	maxExUnits := pparams.GetMaxExecutionUnitsPerBlock()
+	bodySize := uint64(blockBodyOverhead)
+	var exUnitsMemory, exUnitsSteps uint64
+	// Track the UTxOs consumed and produced by transactions already in the block, so that transactions that spend
+	// the outputs of earlier transactions can be included
+	overlay := mempool.NewUtxoOverlay()
+	for _, mempoolTx := range f.config.Mempool.Transactions() {
+		if mempoolTx.Type != txType {
+			continue
+		}
+		tmpTx, err := ledger.NewTransactionFromCbor(txType, mempoolTx.Cbor)
+		if err != nil {
+			continue
+		}
+		tx, err := newForgeTx(mempoolTx.Hash, mempoolTx.Cbor)
+		if err != nil {
+			continue
+		}
+		if bodySize+tx.size() > maxBodySize {
+			continue
+		}
+		txMemory, txSteps := txExUnits(tmpTx)
+		if exUnitsMemory+txMemory > maxExUnits.GetMemory() ||
+			exUnitsSteps+txSteps > maxExUnits.GetSteps() {
+			continue
+		}
+		// Skip transactions that spend the same inputs as one already in the block
+		if _, ok := overlay.Conflict(tmpTx); ok {
+			continue
+		}
+		// The ledger may have changed since the transaction was added to the mempool
+		if err := f.config.LedgerState.ValidateTxWithOverlay(tmpTx, overlay); err != nil {
+			f.config.Logger.Debug(
+				"skipping transaction that failed validation",
+				"tx_hash", mempoolTx.Hash,
+				"error", err,
+			)
+			continue
+		}
+		overlay.Add(tmpTx)
+		bodySize += tx.size()
+		exUnitsMemory += txMemory
+		exUnitsSteps += txSteps
+		ret = append(ret, tx)
+	}
+	return ret, nil
+}
+
+// newForgeTx splits a transaction into its block body components, keeping the original CBOR for each
+func newForgeTx(hash string, txCbor []byte) (*forgeTx, error) {
+	var txParts []cbor.RawMessage
+	if _, err := cbor.Decode(txCbor, &txParts); err != nil {
+		return nil, err
+	}
+	if len(txParts) != 4 {
+		return nil, fmt.Errorf(
+			"transaction has unexpected number of items: %d",
+			len(txParts),
+		)
+	}
+	ret := &forgeTx{
+		hash:      hash,
+		body:      txParts[0],
+		witnesses: txParts[1],
+	}
+	if _, err := cbor.Decode(txParts[2], &ret.isValid); err != nil {
+		return nil, err
+	}
+	// Auxiliary data is null when not present
+	if len(txParts[3]) != 1 || txParts[3][0] != 0xf6 {
+		ret.auxiliaryData = txParts[3]
+	}
+	return ret, nil
+}
+
+// txExUnits returns the total execution units of the redeemers in a transaction
+func txExUnits(tx lcommon.Transaction) (uint64, uint64) {
+	var memory, steps uint64
+	witnesses := tx.Witnesses()
+	if witnesses == nil {
+		return 0, 0
+	}
+	redeemers := witnesses.Redeemers()
+	if redeemers == nil {
+		return 0, 0
+	}
+	for tag := lcommon.RedeemerTagSpend; tag <= lcommon.RedeemerTagProposing; tag++ {
+		for _, idx := range redeemers.Indexes(tag) {
+			_, exUnits := redeemers.Value(idx, tag)
+			memory += exUnits.Memory
+			steps += exUnits.Steps
+		}
+	}
+	return memory, steps
+}
+
+// buildBlock assembles and signs a block containing the specified transactions
+func (f *Forger) buildBlock(
+	ctx *state.ForgingContext,
+	slot uint64,
+	vrfProof []byte,
+	vrfOutput []byte,
+	kesPeriod uint64,
+	txs []*forgeTx,
+) ([]byte, error) {
+	// Build block body
+	txBodies := make([]cbor.RawMessage, 0, len(txs))
+	txWitnesses := make([]cbor.RawMessage, 0, len(txs))
+	auxiliaryData := make(map[uint]cbor.RawMessage)
+	invalidTxs := make([]uint, 0)
+	for idx, tx := range txs {
+		txBodies = append(txBodies, tx.body)
+		txWitnesses = append(txWitnesses, tx.witnesses)
+		if tx.auxiliaryData != nil {
+			auxiliaryData[uint(idx)] = tx.auxiliaryData // #nosec G115
+		}
+		if !tx.isValid {
+			invalidTxs = append(invalidTxs, uint(idx)) // #nosec G115
+		}
+	}
+	bodyParts := make([]cbor.RawMessage, 0, 4)
+	for _, part := range []any{txBodies, txWitnesses, auxiliaryData, invalidTxs} {
+		partCbor, err := cbor.Encode(part)
+		if err != nil {
+			return nil, err
+		}
+		bodyParts = append(bodyParts, partCbor)
+	}
+	// The body hash is the hash of the concatenated hashes of each body component
+	var bodySize uint64
+	partHashes := make([]byte, 0, len(bodyParts)*lcommon.Blake2b256Size)
+	for _, part := range bodyParts {
+		bodySize += uint64(len(part))
+		partHash := lcommon.Blake2b256Hash(part)
+		partHashes = append(partHashes, partHash.Bytes()...)
+	}
+	bodyHash := lcommon.Blake2b256Hash(partHashes)
+	// Build header body
+	var protoMajor, protoMinor uint32
+	if ctx.PParams != nil {
+		if pparams := ctx.PParams.Utxorpc(); pparams != nil {
+			protoMajor = pparams.GetProtocolVersion().GetMajor()
+			protoMinor = pparams.GetProtocolVersion().GetMinor()
+		}
+	}
+	// The first block on a new chain has no previous block hash
+	var blockNumber uint64
+	var prevHash []byte
+	if len(ctx.Tip.Point.Hash) > 0 {
+		blockNumber = ctx.Tip.BlockNumber + 1
+		prevHash = ctx.Tip.Point.Hash
+	}
+	headerBody := []any{
+		blockNumber,
+		slot,
+		prevHash,
+		f.opCert.ColdVkey,
+		f.vrfSkey[vrfSeedSize:],
+		[]any{vrfOutput, vrfProof},
+		bodySize,
+		bodyHash.Bytes(),
+		[]any{
+			f.opCert.Body.HotVkey,
+			f.opCert.Body.SequenceNumber,
+			f.opCert.Body.KesPeriod,
+			f.opCert.Body.Signature,
+		},
+		[]any{protoMajor, protoMinor},
+	}
+	headerBodyCbor, err := cbor.Encode(headerBody)
+	if err != nil {
+		return nil, err
+	}
+	// Sign the header body with the KES key
+	header := []any{
+		cbor.RawMessage(headerBodyCbor),
+		f.kesSkey.sign(kesPeriod, headerBodyCbor),
+	}
+	block := []any{header}
+	for _, part := range bodyParts {
+		block = append(block, part)
+	}
+	return cbor.Encode(block)
+}
@@ -0,0 +1,276 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/blinklabs-io/dingo/config/cardano"
+	"github.com/blinklabs-io/dingo/mempool"
+	"github.com/blinklabs-io/dingo/state"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+const (
+	// Delay before retrying after failing to determine the current slot
+	slotRetryInterval = 1 * time.Second
+)
+
+// Forger produces blocks for a stake pool in the slots that it's elected to lead
+type Forger struct {
+	mu          sync.Mutex
+	config      ForgerConfig
+	vrfSkey     []byte
+	kesSkey     *kesSigningKey
+	kesPeriod   uint64
+	opCert      *OpCert
+	poolKeyHash lcommon.PoolKeyHash
+	nextSlot    uint64
+	doneChan    chan struct{}
+}
+
+type ForgerConfig struct {
+	Logger            *slog.Logger
+	LedgerState       *state.LedgerState
+	Mempool           *mempool.Mempool
+	CardanoNodeConfig *cardano.CardanoNodeConfig
+	VrfSigningKeyFile string
+	KesSigningKeyFile string
+	OpCertFile        string
+}
+
+func NewForger(cfg ForgerConfig) *Forger {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
+	}
+	cfg.Logger = cfg.Logger.With("component", "forge")
+	return &Forger{
+		config: cfg,
+	}
+}
+
+// Start loads the pool keys and operational certificate and starts checking each slot for leadership
+func (f *Forger) Start() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.doneChan != nil {
+		return errors.New("forger is already running")
+	}
+	vrfSkey, err := LoadVrfSigningKey(f.config.VrfSigningKeyFile)
+	if err != nil {
+		return fmt.Errorf("load VRF signing key: %w", err)
+	}
+	kesSkey, err := loadKesSigningKey(f.config.KesSigningKeyFile)
+	if err != nil {
+		return fmt.Errorf("load KES signing key: %w", err)
+	}
+	opCert, err := LoadOpCert(f.config.OpCertFile)
+	if err != nil {
+		return fmt.Errorf("load operational certificate: %w", err)
+	}
+	// The KES key file contains the key for the first period of the operational certificate
+	if !bytes.Equal(kesSkey.verificationKey(), opCert.Body.HotVkey) {
+		return errors.New(
+			"KES signing key does not match operational certificate",
+		)
+	}
+	f.vrfSkey = vrfSkey
+	f.kesSkey = kesSkey
+	f.kesPeriod = 0
+	f.opCert = opCert
+	f.poolKeyHash = lcommon.PoolKeyHash(
+		lcommon.Blake2b224Hash(opCert.ColdVkey),
+	)
+	f.doneChan = make(chan struct{})
+	f.config.Logger.Info(
+		fmt.Sprintf(
+			"forging blocks for pool %s",
+			lcommon.Blake2b224(f.poolKeyHash).String(),
+		),
+	)
+	go f.run(f.doneChan)
+	return nil
+}
+
+// Stop stops checking for slot leadership
+func (f *Forger) Stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.doneChan != nil {
+		close(f.doneChan)
+		f.doneChan = nil
+	}
+}
+
+func (f *Forger) run(doneChan chan struct{}) {
+	for {
+		waitTime := slotRetryInterval
+		slot, err := f.config.LedgerState.TimeToSlot(time.Now())
+		if err != nil {
+			f.config.Logger.Error(
+				"failed to determine current slot",
+				"error", err,
+			)
+		} else {
+			if slot >= f.nextSlot {
+				if err := f.forgeSlot(slot); err != nil {
+					f.config.Logger.Error(
+						fmt.Sprintf("failed to forge block for slot %d", slot),
+						"error", err,
+					)
+				}
+				f.nextSlot = slot + 1
+			}
+			// Wait for the start of the next slot
+			nextSlotTime, err := f.config.LedgerState.SlotToTime(slot + 1)
+			if err == nil {
+				waitTime = time.Until(nextSlotTime)
+			}
+		}
+		select {
+		case <-doneChan:
+			return
+		case <-time.After(waitTime):
+		}
+	}
+}
+
+// forgeSlot checks whether the pool is the leader for the specified slot, and forges and adopts a block if so
+func (f *Forger) forgeSlot(slot uint64) error {
+	ctx, err := f.config.LedgerState.ForgingContext(slot, f.poolKeyHash)
+	if err != nil {
+		return err
+	}
+	// Nothing to do if we already have a block in this slot
+	if len(ctx.Tip.Point.Hash) > 0 && slot <= ctx.Tip.Point.Slot {
+		return nil
+	}
+	blockType, txType, err := eraBlockTypes(ctx.EraId)
+	if err != nil {
+		return err
+	}
+	// Check slot leadership
+	vrfInput := ledger.MkInputVrf(int64(slot), ctx.Nonce) // #nosec G115
+	vrfProof, vrfOutput, err := vrfProve(f.vrfSkey, vrfInput)
+	if err != nil {
+		return err
+	}
+	if !ctx.IsSlotLeader(state.PraosLeaderValue(vrfOutput)) {
+		return nil
+	}
+	f.config.Logger.Debug(
+		fmt.Sprintf("pool is the slot leader for slot %d", slot),
+	)
+	// Evolve the KES key to the current period
+	kesPeriod, err := f.evolveKesKey(slot)
+	if err != nil {
+		return err
+	}
+	txs, err := f.selectTransactions(ctx, txType)
+	if err != nil {
+		return err
+	}
+	blockCbor, err := f.buildBlock(
+		ctx,
+		slot,
+		vrfProof,
+		vrfOutput,
+		kesPeriod,
+		txs,
+	)
+	if err != nil {
+		return err
+	}
+	blk, err := ledger.NewBlockFromCbor(blockType, blockCbor)
+	if err != nil {
+		return fmt.Errorf("decode forged block: %w", err)
+	}
+	blockHash, err := hex.DecodeString(blk.Hash())
+	if err != nil {
+		return err
+	}
+	// Adopt the block. Downstream chainsync clients will pick it up from the ledger
+	err = f.config.LedgerState.AddForgedBlock(
+		ocommon.NewPoint(slot, blockHash),
+		blockType,
+		blk,
+	)
+	if err != nil {
+		return fmt.Errorf("adopt forged block: %w", err)
+	}
+	for _, tx := range txs {
+		f.config.Mempool.RemoveTransaction(tx.hash)
+	}
+	f.config.Logger.Info(
+		fmt.Sprintf(
+			"forged block %x at slot %d with %d transactions",
+			blockHash,
+			slot,
+			len(txs),
+		),
+	)
+	return nil
+}
+
+// evolveKesKey evolves the KES key to the KES period for the specified slot and returns the period relative
+// to the start of the operational certificate
+func (f *Forger) evolveKesKey(slot uint64) (uint64, error) {
+	shelleyGenesis := f.config.CardanoNodeConfig.ShelleyGenesis()
+	if shelleyGenesis == nil {
+		return 0, errors.New("could not get genesis config")
+	}
+	if shelleyGenesis.SlotsPerKESPeriod <= 0 {
+		return 0, errors.New("invalid KES period length in genesis config")
+	}
+	currentKesPeriod := slot / uint64(shelleyGenesis.SlotsPerKESPeriod)
+	opCertKesPeriod := f.opCert.Body.KesPeriod
+	if currentKesPeriod < opCertKesPeriod {
+		return 0, fmt.Errorf(
+			"KES period %d is before operational certificate start period %d",
+			currentKesPeriod,
+			opCertKesPeriod,
+		)
+	}
+	if currentKesPeriod >= opCertKesPeriod+uint64(shelleyGenesis.MaxKESEvolutions) { // #nosec G115
+		return 0, fmt.Errorf(
+			"operational certificate with start period %d has expired in KES period %d",
+			opCertKesPeriod,
+			currentKesPeriod,
+		)
+	}
+	kesPeriod := currentKesPeriod - opCertKesPeriod
+	if kesPeriod < f.kesPeriod {
+		return 0, fmt.Errorf(
+			"KES key has already been evolved past period %d",
+			kesPeriod,
+		)
+	}
+	for f.kesPeriod < kesPeriod {
+		if err := f.kesSkey.evolve(f.kesPeriod); err != nil {
+			return 0, err
+		}
+		f.kesPeriod++
+	}
+	return kesPeriod, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+const (
+	// Depth of the Sum6KES scheme used for block signatures
+	kesDepth = 6
+)
+
+// kesSigningKey is a SumKES signing key. Each level of the tree contains the signing key for the current
+// half of its periods, the seed for generating the signing key for the second half, and the verification
+// keys for both halves. The bottom level is a single Ed25519 key
+type kesSigningKey struct {
+	depth uint64
+	// Ed25519 seed at depth 0
+	seed []byte
+	// Signing key for the current half of the periods, seed for the second half, and the verification key
+	// for each half
+	sub *kesSigningKey
+	r1  []byte
+	vk0 []byte
+	vk1 []byte
+}
+
+// newKesSigningKey decodes a SumKES signing key of the specified depth in the raw format used by cardano-node
+func newKesSigningKey(depth uint64, data []byte) (*kesSigningKey, error) {
+	if len(data) != kesKeySize(depth) {
+		return nil, fmt.Errorf(
+			"KES signing key has unexpected length: %d",
+			len(data),
+		)
+	}
+	if depth == 0 {
+		return &kesSigningKey{
+			seed: append([]byte{}, data...),
+		}, nil
+	}
+	subSize := kesKeySize(depth - 1)
+	sub, err := newKesSigningKey(depth-1, data[:subSize])
+	if err != nil {
+		return nil, err
+	}
+	data = data[subSize:]
+	return &kesSigningKey{
+		depth: depth,
+		sub:   sub,
+		r1:    append([]byte{}, data[:ed25519.SeedSize]...),
+		vk0: append(
+			[]byte{},
+			data[ed25519.SeedSize:ed25519.SeedSize+ed25519.PublicKeySize]...,
+		),
+		vk1: append(
+			[]byte{},
+			data[ed25519.SeedSize+ed25519.PublicKeySize:]...,
+		),
+	}, nil
+}
+
+// genKesSigningKey generates a SumKES signing key of the specified depth from a seed
+func genKesSigningKey(depth uint64, seed []byte) *kesSigningKey {
+	if depth == 0 {
+		return &kesSigningKey{
+			seed: append([]byte{}, seed...),
+		}
+	}
+	// Expand the seed into a seed for each half
+	r0 := lcommon.Blake2b256Hash(append([]byte{1}, seed...))
+	r1 := lcommon.Blake2b256Hash(append([]byte{2}, seed...))
+	sk0 := genKesSigningKey(depth-1, r0.Bytes())
+	sk1 := genKesSigningKey(depth-1, r1.Bytes())
+	return &kesSigningKey{
+		depth: depth,
+		sub:   sk0,
+		r1:    r1.Bytes(),
+		vk0:   sk0.verificationKey(),
+		vk1:   sk1.verificationKey(),
+	}
+}
+
+func kesKeySize(depth uint64) int {
+	return ed25519.SeedSize + int(depth)*(ed25519.SeedSize+2*ed25519.PublicKeySize) // #nosec G115
+}
+
+// kesPeriods returns the number of periods covered by a key of the specified depth
+func kesPeriods(depth uint64) uint64 {
+	return 1 << depth
+}
+
+// verificationKey returns the verification key, which is the hash of the verification keys for each half
+// at every level above the bottom
+func (k *kesSigningKey) verificationKey() []byte {
+	if k.depth == 0 {
+		return ed25519.NewKeyFromSeed(k.seed).Public().(ed25519.PublicKey)
+	}
+	return ledger.HashPair(k.vk0, k.vk1)
+}
+
+// sign creates a signature for the message using the key, which must have been evolved to the specified period
+func (k *kesSigningKey) sign(period uint64, msg []byte) []byte {
+	if k.depth == 0 {
+		return ed25519.Sign(ed25519.NewKeyFromSeed(k.seed), msg)
+	}
+	half := kesPeriods(k.depth - 1)
+	subPeriod := period
+	if period >= half {
+		subPeriod = period - half
+	}
+	ret := k.sub.sign(subPeriod, msg)
+	ret = append(ret, k.vk0...)
+	ret = append(ret, k.vk1...)
+	return ret
+}
+
+// evolve updates the key from the specified period to the next one. The key material for past periods is
+// discarded, so that it can't be used to forge signatures for those periods
+func (k *kesSigningKey) evolve(period uint64) error {
+	if period+1 >= kesPeriods(k.depth) {
+		return errors.New("KES key cannot be evolved past its last period")
+	}
+	half := kesPeriods(k.depth - 1)
+	switch {
+	case period+1 < half:
+		return k.sub.evolve(period)
+	case period+1 == half:
+		k.sub = genKesSigningKey(k.depth-1, k.r1)
+		clear(k.r1)
+		return nil
+	default:
+		return k.sub.evolve(period - half)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/ledger"
+)
+
+// kesKeyBytes serializes a KES signing key in the raw format used by cardano-node
+func kesKeyBytes(key *kesSigningKey) []byte {
+	if key.depth == 0 {
+		return key.seed
+	}
+	ret := kesKeyBytes(key.sub)
+	ret = append(ret, key.r1...)
+	ret = append(ret, key.vk0...)
+	ret = append(ret, key.vk1...)
+	return ret
+}
+
+func TestKesSignAndEvolve(t *testing.T) {
+	msg := []byte("test message")
+	key := genKesSigningKey(kesDepth, bytes.Repeat([]byte{0x01}, 32))
+	vkey := key.verificationKey()
+	// Round-trip the key through the raw format
+	keyBytes := kesKeyBytes(key)
+	if len(keyBytes) != kesKeySize(kesDepth) {
+		t.Fatalf(
+			"did not get expected key size: got %d, expected %d",
+			len(keyBytes),
+			kesKeySize(kesDepth),
+		)
+	}
+	key, err := newKesSigningKey(kesDepth, keyBytes)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for period := range kesPeriods(kesDepth) {
+		sig := key.sign(period, msg)
+		if !ledger.NewSumKesFromByte(kesDepth, sig).Verify(period, vkey, msg) {
+			t.Fatalf("signature did not verify for period %d", period)
+		}
+		// The signature must not verify for any other period
+		if period > 0 &&
+			ledger.NewSumKesFromByte(kesDepth, sig).Verify(period-1, vkey, msg) {
+			t.Fatalf(
+				"signature for period %d verified for period %d",
+				period,
+				period-1,
+			)
+		}
+		if period+1 < kesPeriods(kesDepth) {
+			if err := key.evolve(period); err != nil {
+				t.Fatalf(
+					"unexpected error evolving key for period %d: %s",
+					period,
+					err,
+				)
+			}
+		}
+	}
+	if err := key.evolve(kesPeriods(kesDepth) - 1); err == nil {
+		t.Fatalf("did not get expected error evolving key past last period")
+	}
+}
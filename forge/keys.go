@@ -0,0 +1,158 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+)
+
+// textEnvelope represents the JSON "text envelope" format used by cardano-cli for key and certificate files
+type textEnvelope struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	CborHex     string `json:"cborHex"`
+}
+
+// OpCert represents a node operational certificate, which delegates block signing from the pool cold key to
+// a KES key
+type OpCert struct {
+	cbor.StructAsArray
+	Body     OpCertBody
+	ColdVkey []byte
+}
+
+type OpCertBody struct {
+	cbor.StructAsArray
+	HotVkey        []byte
+	SequenceNumber uint64
+	KesPeriod      uint64
+	Signature      []byte
+}
+
+func loadTextEnvelope(path string) (*textEnvelope, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ret textEnvelope
+	if err := json.Unmarshal(buf, &ret); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return &ret, nil
+}
+
+// bytes returns the contents of a text envelope containing a CBOR-encoded bytestring, such as a signing key
+func (e *textEnvelope) bytes() ([]byte, error) {
+	cborData, err := hex.DecodeString(e.CborHex)
+	if err != nil {
+		return nil, err
+	}
+	var ret []byte
+	if _, err := cbor.Decode(cborData, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// loadTextEnvelopeBytes loads a text envelope file containing a CBOR-encoded bytestring, such as a signing key
+func loadTextEnvelopeBytes(path string) ([]byte, error) {
+	envelope, err := loadTextEnvelope(path)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := envelope.bytes()
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return ret, nil
+}
+
+// LoadVrfSigningKey loads a VRF signing key from a cardano-cli text envelope file
+func LoadVrfSigningKey(path string) ([]byte, error) {
+	ret, err := loadTextEnvelopeBytes(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(ret) != vrfSigningKeySize {
+		return nil, fmt.Errorf(
+			"VRF signing key has unexpected length: %d",
+			len(ret),
+		)
+	}
+	return ret, nil
+}
+
+// ParseVrfSigningKey decodes a VRF signing key in the cardano-cli text envelope format
+func ParseVrfSigningKey(data []byte) ([]byte, error) {
+	var envelope textEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("decode VRF signing key: %w", err)
+	}
+	ret, err := envelope.bytes()
+	if err != nil {
+		return nil, fmt.Errorf("decode VRF signing key: %w", err)
+	}
+	if len(ret) != vrfSigningKeySize {
+		return nil, fmt.Errorf(
+			"VRF signing key has unexpected length: %d",
+			len(ret),
+		)
+	}
+	return ret, nil
+}
+
+// loadKesSigningKey loads a Sum6KES signing key from a cardano-cli text envelope file
+func loadKesSigningKey(path string) (*kesSigningKey, error) {
+	keyBytes, err := loadTextEnvelopeBytes(path)
+	if err != nil {
+		return nil, err
+	}
+	return newKesSigningKey(kesDepth, keyBytes)
+}
+
+// LoadOpCert loads an operational certificate from a cardano-cli text envelope file
+func LoadOpCert(path string) (*OpCert, error) {
+	envelope, err := loadTextEnvelope(path)
+	if err != nil {
+		return nil, err
+	}
+	cborData, err := hex.DecodeString(envelope.CborHex)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	var ret OpCert
+	if _, err := cbor.Decode(cborData, &ret); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	if len(ret.ColdVkey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf(
+			"operational certificate cold key has unexpected length: %d",
+			len(ret.ColdVkey),
+		)
+	}
+	if len(ret.Body.HotVkey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf(
+			"operational certificate KES key has unexpected length: %d",
+			len(ret.Body.HotVkey),
+		)
+	}
+	return &ret, nil
+}
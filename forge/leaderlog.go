@@ -0,0 +1,160 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/blinklabs-io/dingo/state"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+// LeaderSchedule contains the values used for leader election for a pool in an epoch, along with the slots in
+// which the pool is elected to produce blocks once they have been calculated
+type LeaderSchedule struct {
+	Epoch           uint64       `json:"epoch"`
+	PoolId          string       `json:"poolId"`
+	StartSlot       uint64       `json:"startSlot"`
+	LengthInSlots   uint64       `json:"lengthInSlots"`
+	StartTime       time.Time    `json:"startTime"`
+	SlotLength      uint64       `json:"slotLength"`
+	Nonce           string       `json:"nonce"`
+	PoolStake       uint64       `json:"poolStake"`
+	TotalStake      uint64       `json:"totalStake"`
+	ActiveSlotCoeff string       `json:"activeSlotCoeff"`
+	Bootstrap       bool         `json:"bootstrap,omitempty"`
+	Slots           []LeaderSlot `json:"slots,omitempty"`
+}
+
+// LeaderSlot is a slot in which a pool is elected to produce a block
+type LeaderSlot struct {
+	Slot uint64    `json:"slot"`
+	Time time.Time `json:"time"`
+}
+
+// ParsePoolId parses a pool ID in bech32 (pool1...) or hex format
+func ParsePoolId(poolId string) (lcommon.PoolKeyHash, error) {
+	if strings.HasPrefix(poolId, "pool1") {
+		tmpPoolId, err := lcommon.NewPoolIdFromBech32(poolId)
+		if err != nil {
+			return lcommon.PoolKeyHash{}, fmt.Errorf(
+				"invalid pool ID: %w",
+				err,
+			)
+		}
+		return lcommon.PoolKeyHash(tmpPoolId), nil
+	}
+	poolIdBytes, err := hex.DecodeString(poolId)
+	if err != nil {
+		return lcommon.PoolKeyHash{}, fmt.Errorf("invalid pool ID: %w", err)
+	}
+	if len(poolIdBytes) != lcommon.Blake2b224Size {
+		return lcommon.PoolKeyHash{}, fmt.Errorf(
+			"invalid pool ID length: %d",
+			len(poolIdBytes),
+		)
+	}
+	return lcommon.PoolKeyHash(lcommon.NewBlake2b224(poolIdBytes)), nil
+}
+
+// NewLeaderSchedule returns the leader election values for the specified pool in the current or next epoch.
+// The slots are not calculated, since that requires the pool's VRF signing key
+func NewLeaderSchedule(
+	ls *state.LedgerState,
+	poolKeyHash lcommon.PoolKeyHash,
+	next bool,
+) (*LeaderSchedule, error) {
+	params, err := ls.EpochLeaderParams(next, poolKeyHash)
+	if err != nil {
+		return nil, err
+	}
+	startTime, err := ls.SlotToTime(params.StartSlot)
+	if err != nil {
+		return nil, err
+	}
+	nextSlotTime, err := ls.SlotToTime(params.StartSlot + 1)
+	if err != nil {
+		return nil, err
+	}
+	return &LeaderSchedule{
+		Epoch:           params.Epoch,
+		PoolId:          lcommon.PoolId(poolKeyHash).String(),
+		StartSlot:       params.StartSlot,
+		LengthInSlots:   params.LengthInSlots,
+		StartTime:       startTime.UTC(),
+		SlotLength:      uint64(nextSlotTime.Sub(startTime).Milliseconds()), // #nosec G115
+		Nonce:           hex.EncodeToString(params.Nonce),
+		PoolStake:       params.PoolStake,
+		TotalStake:      params.TotalStake,
+		ActiveSlotCoeff: params.ActiveSlotCoeff.RatString(),
+		Bootstrap:       params.Bootstrap,
+	}, nil
+}
+
+// CalculateSlots determines the slots in the epoch in which the pool is elected to produce blocks using the
+// pool's VRF signing key
+func (s *LeaderSchedule) CalculateSlots(vrfSkey []byte) error {
+	nonce, err := hex.DecodeString(s.Nonce)
+	if err != nil {
+		return fmt.Errorf("invalid epoch nonce: %w", err)
+	}
+	activeSlotCoeff, ok := new(big.Rat).SetString(s.ActiveSlotCoeff)
+	if !ok {
+		return fmt.Errorf(
+			"invalid active slot coefficient: %s",
+			s.ActiveSlotCoeff,
+		)
+	}
+	if s.SlotLength == 0 {
+		return errors.New("invalid slot length")
+	}
+	params := state.EpochLeaderParams{
+		Epoch:           s.Epoch,
+		StartSlot:       s.StartSlot,
+		LengthInSlots:   s.LengthInSlots,
+		Nonce:           nonce,
+		PoolStake:       s.PoolStake,
+		TotalStake:      s.TotalStake,
+		ActiveSlotCoeff: activeSlotCoeff,
+		Bootstrap:       s.Bootstrap,
+	}
+	slotLength := time.Duration(s.SlotLength) * time.Millisecond // #nosec G115
+	s.Slots = []LeaderSlot{}
+	for i := range s.LengthInSlots {
+		slot := s.StartSlot + i
+		vrfInput := ledger.MkInputVrf(int64(slot), nonce) // #nosec G115
+		output, err := vrfOutput(vrfSkey, vrfInput)
+		if err != nil {
+			return err
+		}
+		if !params.IsSlotLeader(state.PraosLeaderValue(output)) {
+			continue
+		}
+		s.Slots = append(
+			s.Slots,
+			LeaderSlot{
+				Slot: slot,
+				Time: s.StartTime.Add(slotLength * time.Duration(i)), // #nosec G115
+			},
+		)
+	}
+	return nil
+}
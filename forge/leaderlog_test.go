@@ -0,0 +1,136 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/dingo/state"
+	"github.com/blinklabs-io/gouroboros/ledger"
+)
+
+func TestLeaderScheduleCalculateSlots(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, 32)
+	publicKey := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+	vrfSkey := append(append([]byte{}, seed...), publicKey...)
+	nonce := bytes.Repeat([]byte{0xab}, 32)
+	startTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	schedule := &LeaderSchedule{
+		Epoch:           5,
+		StartSlot:       1000,
+		LengthInSlots:   200,
+		StartTime:       startTime,
+		SlotLength:      1000,
+		Nonce:           hex.EncodeToString(nonce),
+		PoolStake:       1,
+		TotalStake:      2,
+		ActiveSlotCoeff: "1/20",
+	}
+	if err := schedule.CalculateSlots(vrfSkey); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Compare against the leader check used when forging
+	params := state.EpochLeaderParams{
+		Nonce:           nonce,
+		PoolStake:       1,
+		TotalStake:      2,
+		ActiveSlotCoeff: big.NewRat(1, 20),
+	}
+	var expectedSlots []uint64
+	for slot := uint64(1000); slot < 1200; slot++ {
+		vrfInput := ledger.MkInputVrf(int64(slot), nonce) // #nosec G115
+		_, output, err := vrfProve(vrfSkey, vrfInput)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if params.IsSlotLeader(state.PraosLeaderValue(output)) {
+			expectedSlots = append(expectedSlots, slot)
+		}
+	}
+	if len(schedule.Slots) != len(expectedSlots) {
+		t.Fatalf(
+			"did not get expected number of slots: got %d, expected %d",
+			len(schedule.Slots),
+			len(expectedSlots),
+		)
+	}
+	for idx, leaderSlot := range schedule.Slots {
+		if leaderSlot.Slot != expectedSlots[idx] {
+			t.Fatalf(
+				"did not get expected slot: got %d, expected %d",
+				leaderSlot.Slot,
+				expectedSlots[idx],
+			)
+		}
+		expectedTime := startTime.Add(
+			time.Duration(leaderSlot.Slot-1000) * time.Second, // #nosec G115
+		)
+		if !leaderSlot.Time.Equal(expectedTime) {
+			t.Fatalf(
+				"did not get expected time for slot %d: got %s, expected %s",
+				leaderSlot.Slot,
+				leaderSlot.Time,
+				expectedTime,
+			)
+		}
+	}
+}
+
+func TestParseVrfSigningKey(t *testing.T) {
+	vrfSkey := bytes.Repeat([]byte{0x42}, 64)
+	testDefs := []struct {
+		envelope    string
+		expectedKey []byte
+	}{
+		{
+			envelope: `{"type": "VrfSigningKey_PraosVRF", "description": "VRF Signing Key", "cborHex": "5840` +
+				hex.EncodeToString(vrfSkey) + `"}`,
+			expectedKey: vrfSkey,
+		},
+		// Wrong key length
+		{
+			envelope: `{"type": "VrfSigningKey_PraosVRF", "description": "VRF Signing Key", "cborHex": "5820` +
+				hex.EncodeToString(vrfSkey[:32]) + `"}`,
+		},
+		// Not a text envelope
+		{
+			envelope: hex.EncodeToString(vrfSkey),
+		},
+	}
+	for _, testDef := range testDefs {
+		key, err := ParseVrfSigningKey([]byte(testDef.envelope))
+		if testDef.expectedKey == nil {
+			if err == nil {
+				t.Fatalf("did not get expected error for envelope: %s", testDef.envelope)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !bytes.Equal(key, testDef.expectedKey) {
+			t.Fatalf(
+				"did not get expected key: got %x, expected %x",
+				key,
+				testDef.expectedKey,
+			)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"crypto/sha512"
+	"errors"
+	"fmt"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+	"github.com/blinklabs-io/gouroboros/ledger"
+)
+
+const (
+	vrfSeedSize       = 32
+	vrfPublicKeySize  = 32
+	vrfSigningKeySize = vrfSeedSize + vrfPublicKeySize
+	vrfProofSize      = 80
+
+	// Suite identifier for ECVRF-ED25519-SHA512-Elligator2 (draft-irtf-cfrg-vrf-03)
+	vrfSuite = 0x04
+)
+
+// vrfProve generates a VRF proof for the specified input using a signing key consisting of the 32-byte seed
+// followed by the 32-byte public key, as used by cardano-node. It returns the proof and the corresponding
+// VRF output
+func vrfProve(signingKey []byte, alpha []byte) ([]byte, []byte, error) {
+	Y, az, x, err := vrfExpandSigningKey(signingKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKey := signingKey[vrfSeedSize:]
+	H, err := vrfHashToCurve(Y, alpha)
+	if err != nil {
+		return nil, nil, err
+	}
+	hBytes := H.Bytes()
+	Gamma := new(edwards25519.Point).ScalarMult(x, H)
+	// k = SHA512(az[32:64] || H) mod L
+	nonceHash := sha512.New()
+	nonceHash.Write(az[32:])
+	nonceHash.Write(hBytes)
+	k, err := edwards25519.NewScalar().SetUniformBytes(nonceHash.Sum(nil))
+	if err != nil {
+		return nil, nil, err
+	}
+	c := vrfHashPoints(
+		H,
+		Gamma,
+		new(edwards25519.Point).ScalarBaseMult(k),
+		new(edwards25519.Point).ScalarMult(k, H),
+	)
+	// s = k + c*x mod L
+	cScalarBytes := make([]byte, 32)
+	copy(cScalarBytes, c)
+	cScalar, err := edwards25519.NewScalar().SetCanonicalBytes(cScalarBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := edwards25519.NewScalar().MultiplyAdd(cScalar, x, k)
+	proof := make([]byte, 0, vrfProofSize)
+	proof = append(proof, Gamma.Bytes()...)
+	proof = append(proof, c...)
+	proof = append(proof, s.Bytes()...)
+	// Verify the proof and calculate the output
+	output, err := ledger.VrfVerifyAndHash(publicKey, proof, alpha)
+	if err != nil {
+		return nil, nil, fmt.Errorf("verify generated VRF proof: %w", err)
+	}
+	return proof, output, nil
+}
+
+// vrfOutput calculates the VRF output for the specified input without generating a proof. This is much cheaper
+// than vrfProve when only the leader value is needed, such as when calculating a leader schedule
+func vrfOutput(signingKey []byte, alpha []byte) ([]byte, error) {
+	Y, _, x, err := vrfExpandSigningKey(signingKey)
+	if err != nil {
+		return nil, err
+	}
+	H, err := vrfHashToCurve(Y, alpha)
+	if err != nil {
+		return nil, err
+	}
+	Gamma := new(edwards25519.Point).ScalarMult(x, H)
+	// output = SHA512(suite || 0x03 || cofactor * Gamma)
+	h := sha512.New()
+	h.Write([]byte{vrfSuite, 0x03})
+	h.Write(new(edwards25519.Point).MultByCofactor(Gamma).Bytes())
+	return h.Sum(nil), nil
+}
+
+// vrfExpandSigningKey returns the public key point, the expanded seed and the secret scalar for a VRF signing
+// key. The seed is expanded in the same way as Ed25519
+func vrfExpandSigningKey(
+	signingKey []byte,
+) (*edwards25519.Point, [64]byte, *edwards25519.Scalar, error) {
+	if len(signingKey) != vrfSigningKeySize {
+		return nil, [64]byte{}, nil, fmt.Errorf(
+			"VRF signing key has unexpected length: %d",
+			len(signingKey),
+		)
+	}
+	Y, err := new(edwards25519.Point).SetBytes(signingKey[vrfSeedSize:])
+	if err != nil {
+		return nil, [64]byte{}, nil, fmt.Errorf(
+			"invalid VRF public key: %w",
+			err,
+		)
+	}
+	az := sha512.Sum512(signingKey[:vrfSeedSize])
+	x, err := edwards25519.NewScalar().SetBytesWithClamping(az[:32])
+	if err != nil {
+		return nil, [64]byte{}, nil, err
+	}
+	return Y, az, x, nil
+}
+
+// vrfHashPoints returns the 16-byte challenge value calculated from the specified points
+func vrfHashPoints(points ...*edwards25519.Point) []byte {
+	h := sha512.New()
+	h.Write([]byte{vrfSuite, 0x02})
+	for _, point := range points {
+		h.Write(point.Bytes())
+	}
+	return h.Sum(nil)[:16]
+}
+
+// vrfHashToCurve maps the VRF input to a curve point using the Elligator2 method
+func vrfHashToCurve(
+	Y *edwards25519.Point,
+	alpha []byte,
+) (*edwards25519.Point, error) {
+	h := sha512.New()
+	h.Write([]byte{vrfSuite, 0x01})
+	h.Write(Y.Bytes())
+	h.Write(alpha)
+	r := h.Sum(nil)[:32]
+	// Clear the sign bit
+	r[31] &= 0x7f
+	return elligator2(r)
+}
+
+// elligator2 maps a 32-byte uniform string to a curve point in the prime order subgroup. This matches
+// ge25519_from_uniform() from the libsodium fork used by cardano-node
+func elligator2(r []byte) (*edwards25519.Point, error) {
+	one := new(field.Element).One()
+	curveA := new(field.Element).Mult32(one, 486662)
+	xSign := r[31] & 0x80
+	rr2, err := new(field.Element).SetBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	// x = -A / (1 + 2r^2)
+	rr2.Square(rr2)
+	rr2.Add(rr2, rr2)
+	rr2.Add(rr2, one)
+	rr2.Invert(rr2)
+	x := new(field.Element).Multiply(curveA, rr2)
+	x.Negate(x)
+	// e = x^3 + Ax^2 + x
+	x2 := new(field.Element).Square(x)
+	x3 := new(field.Element).Multiply(x, x2)
+	e := new(field.Element).Add(x3, x)
+	x2.Multiply(x2, curveA)
+	e.Add(x2, e)
+	// Use the Legendre symbol of e to choose between x and -x - A
+	eIsMinus1 := int(legendre(e).Bytes()[1] & 1)
+	negX := new(field.Element).Negate(x)
+	x.Select(negX, x, eIsMinus1)
+	x2.Zero()
+	x2.Select(curveA, x2, eIsMinus1)
+	x.Subtract(x, x2)
+	// Convert from Montgomery to Edwards form: y = (x - 1) / (x + 1)
+	xPlusOne := new(field.Element).Add(x, one)
+	xMinusOne := new(field.Element).Subtract(x, one)
+	y := new(field.Element).Multiply(
+		xMinusOne,
+		new(field.Element).Invert(xPlusOne),
+	)
+	yBytes := y.Bytes()
+	yBytes[31] |= xSign
+	ret, err := new(edwards25519.Point).SetBytes(yBytes)
+	if err != nil {
+		return nil, errors.New("hash to curve produced an invalid point")
+	}
+	return ret.MultByCofactor(ret), nil
+}
+
+// legendre returns z^((p-1)/2), which is 1 when z is a non-zero square, -1 when it isn't, and 0 for 0
+func legendre(z *field.Element) *field.Element {
+	// (p-1)/2 = 4 * (p-5)/8 + 2
+	ret := new(field.Element).Pow22523(z)
+	ret.Square(ret)
+	ret.Square(ret)
+	return ret.Multiply(ret, new(field.Element).Square(z))
+}
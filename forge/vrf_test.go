@@ -0,0 +1,86 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/ledger"
+)
+
+func TestVrfProve(t *testing.T) {
+	// Test vector from draft-irtf-cfrg-vrf-03 (ECVRF-ED25519-SHA512-Elligator2, empty input)
+	seed, _ := hex.DecodeString(
+		"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+	)
+	expectedProof, _ := hex.DecodeString(
+		"b6b4699f87d56126c9117a7da55bd0085246f4c56dbc95d20172612e9d38e8d7ca65e573a126ed88d4e30a46f80a666854d675cf3ba81de0de043c3774f061560f55edc256a787afe701677c0f602900",
+	)
+	expectedOutput, _ := hex.DecodeString(
+		"5b49b554d05c0cd5a5325376b3387de59d924fd1e13ded44648ab33c21349a603f25b84ec5ed887995b33da5e3bfcb87cd2f64521c4c62cf825cffabbe5d31cc",
+	)
+	publicKey := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+	signingKey := append(append([]byte{}, seed...), publicKey...)
+	proof, output, err := vrfProve(signingKey, []byte{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(proof, expectedProof) {
+		t.Fatalf(
+			"did not get expected proof: got %x, expected %x",
+			proof,
+			expectedProof,
+		)
+	}
+	if !bytes.Equal(output, expectedOutput) {
+		t.Fatalf(
+			"did not get expected output: got %x, expected %x",
+			output,
+			expectedOutput,
+		)
+	}
+	// Verify a proof for a slot input
+	vrfInput := ledger.MkInputVrf(12345, bytes.Repeat([]byte{0xab}, 32))
+	proof, output, err = vrfProve(signingKey, vrfInput)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	verifyOutput, err := ledger.VrfVerifyAndHash(publicKey, proof, vrfInput)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(output, verifyOutput) {
+		t.Fatalf(
+			"did not get expected output: got %x, expected %x",
+			output,
+			verifyOutput,
+		)
+	}
+	// Calculating the output without a proof gives the same result
+	output, err = vrfOutput(signingKey, vrfInput)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(output, verifyOutput) {
+		t.Fatalf(
+			"did not get expected output: got %x, expected %x",
+			output,
+			verifyOutput,
+		)
+	}
+}
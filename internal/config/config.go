@@ -16,27 +16,46 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"time"
 
+	"github.com/blinklabs-io/dingo/database/plugin"
 	"github.com/blinklabs-io/dingo/topology"
 	ouroboros "github.com/blinklabs-io/gouroboros"
 	"github.com/kelseyhightower/envconfig"
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	BindAddr        string `split_words:"true"`
-	CardanoConfig   string `                   envconfig:"config"`
-	DatabasePath    string `split_words:"true"`
-	SocketPath      string `split_words:"true"`
-	Network         string
-	TlsCertFilePath string `envconfig:"TLS_CERT_FILE_PATH"`
-	TlsKeyFilePath  string `envconfig:"TLS_KEY_FILE_PATH"`
-	Topology        string
-	MetricsPort     uint   `split_words:"true"`
-	PrivateBindAddr string `split_words:"true"`
-	PrivatePort     uint   `split_words:"true"`
-	RelayPort       uint   `envconfig:"port"`
-	UtxorpcPort     uint   `split_words:"true"`
-	IntersectTip    bool   `split_words:"true"`
+	BindAddr        string `split_words:"true"                  yaml:"bindAddr"`
+	CardanoConfig   string `envconfig:"config"                  yaml:"cardanoConfig"`
+	DatabasePath    string `split_words:"true"                  yaml:"databasePath"`
+	SocketPath      string `split_words:"true"                  yaml:"socketPath"`
+	Network         string `yaml:"network"`
+	TlsCertFilePath string `envconfig:"TLS_CERT_FILE_PATH"      yaml:"tlsCertFilePath"`
+	TlsKeyFilePath  string `envconfig:"TLS_KEY_FILE_PATH"       yaml:"tlsKeyFilePath"`
+	Topology        string `yaml:"topology"`
+	MetricsPort     uint   `split_words:"true"                  yaml:"metricsPort"`
+	PrivateBindAddr string `split_words:"true"                  yaml:"privateBindAddr"`
+	PrivatePort     uint   `split_words:"true"                  yaml:"privatePort"`
+	RelayPort       uint   `envconfig:"port"                    yaml:"relayPort"`
+	UtxorpcPort     uint   `split_words:"true"                  yaml:"utxorpcPort"`
+	IntersectTip    bool   `split_words:"true"                  yaml:"intersectTip"`
+	BlobPlugin      string `split_words:"true"                  yaml:"blobPlugin"`
+	MetadataPlugin  string `split_words:"true"                  yaml:"metadataPlugin"`
+	ValidationLevel string `split_words:"true"                  yaml:"validationLevel"`
+	// Mempool limits and persistence
+	MempoolMaxBytes uint64        `split_words:"true"                  yaml:"mempoolMaxBytes"`
+	MempoolMaxTxs   uint          `split_words:"true"                  yaml:"mempoolMaxTxs"`
+	MempoolTxTtl    time.Duration `split_words:"true"                  yaml:"mempoolTxTtl"`
+	MempoolPersist  bool          `split_words:"true"                  yaml:"mempoolPersist"`
+	MempoolOrdering string        `split_words:"true"                  yaml:"mempoolOrdering"`
+	// Block producer keys and operational certificate, in cardano-cli text envelope format
+	ShelleyVrfKey                 string `split_words:"true"                  yaml:"shelleyVrfKey"`
+	ShelleyKesKey                 string `split_words:"true"                  yaml:"shelleyKesKey"`
+	ShelleyOperationalCertificate string `split_words:"true"                  yaml:"shelleyOperationalCertificate"`
+	// Plugin options, keyed by plugin type, plugin name, and option name
+	Plugins map[string]map[string]map[interface{}]interface{} `ignored:"true" yaml:"plugins"`
 }
 
 var globalConfig = &Config{
@@ -54,13 +73,34 @@ var globalConfig = &Config{
 	Topology:        "",
 	TlsCertFilePath: "",
 	TlsKeyFilePath:  "",
+	BlobPlugin:      "badger",
+	MetadataPlugin:  "sqlite",
+	ValidationLevel: "trust",
+	MempoolOrdering: "fifo",
 }
 
-func LoadConfig() (*Config, error) {
+// LoadConfig loads the config from the specified YAML config file (if any) and the environment, in that
+// order of precedence. Database plugin options are loaded from the same sources
+func LoadConfig(configFile string) (*Config, error) {
+	if configFile != "" {
+		buf, err := os.ReadFile(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file: %w", err)
+		}
+		if err := yaml.Unmarshal(buf, globalConfig); err != nil {
+			return nil, fmt.Errorf("error parsing config file: %w", err)
+		}
+		if err := plugin.ProcessConfig(globalConfig.Plugins); err != nil {
+			return nil, fmt.Errorf("error processing plugin config: %w", err)
+		}
+	}
 	err := envconfig.Process("cardano", globalConfig)
 	if err != nil {
 		return nil, fmt.Errorf("error processing environment: %+w", err)
 	}
+	if err := plugin.ProcessEnvVars("cardano"); err != nil {
+		return nil, fmt.Errorf("error processing plugin environment: %w", err)
+	}
 	_, err = LoadTopologyConfig()
 	if err != nil {
 		return nil, fmt.Errorf("error loading topology: %+w", err)
@@ -0,0 +1,118 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/blinklabs-io/dingo/forge"
+	"github.com/blinklabs-io/dingo/internal/config"
+	"github.com/blinklabs-io/dingo/utxorpc"
+)
+
+const (
+	// Timeout for requesting the leader election values from a running node
+	leaderlogRequestTimeout = 60 * time.Second
+)
+
+// Leaderlog prints the slots in the current or next epoch in which a pool is elected to produce blocks. The
+// epoch nonce and stake distribution are fetched from a running node, and the slots are calculated locally so
+// that the VRF signing key never leaves this machine
+func Leaderlog(
+	cfg *config.Config,
+	logger *slog.Logger,
+	nodeUrl string,
+	vrfSkeyFile string,
+	poolId string,
+	next bool,
+) error {
+	logger.Debug(fmt.Sprintf("config: %+v", cfg), "component", "node")
+	if nodeUrl == "" {
+		nodeUrl = fmt.Sprintf("http://127.0.0.1:%d", cfg.UtxorpcPort)
+	}
+	vrfSkey, err := forge.LoadVrfSigningKey(vrfSkeyFile)
+	if err != nil {
+		return fmt.Errorf("load VRF signing key: %w", err)
+	}
+	if _, err := forge.ParsePoolId(poolId); err != nil {
+		return err
+	}
+	epoch := "current"
+	if next {
+		epoch = "next"
+	}
+	reqUrl := fmt.Sprintf(
+		"%s%s?%s",
+		strings.TrimSuffix(nodeUrl, "/"),
+		utxorpc.LeaderlogPath,
+		url.Values{
+			"epoch": []string{epoch},
+			"pool":  []string{poolId},
+		}.Encode(),
+	)
+	client := &http.Client{
+		Timeout: leaderlogRequestTimeout,
+	}
+	resp, err := client.Get(reqUrl) // #nosec G107
+	if err != nil {
+		return fmt.Errorf("query node: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf(
+			"query node: %s: %s",
+			resp.Status,
+			strings.TrimSpace(string(respBody)),
+		)
+	}
+	var schedule forge.LeaderSchedule
+	if err := json.NewDecoder(resp.Body).Decode(&schedule); err != nil {
+		return fmt.Errorf("decode leader schedule: %w", err)
+	}
+	if schedule.PoolStake == 0 && schedule.TotalStake > 0 {
+		return errors.New("pool has no active stake in the requested epoch")
+	}
+	if err := schedule.CalculateSlots(vrfSkey); err != nil {
+		return err
+	}
+	fmt.Printf("Pool:   %s\n", schedule.PoolId)
+	fmt.Printf("Epoch:  %d\n", schedule.Epoch)
+	fmt.Printf("Nonce:  %s\n", schedule.Nonce)
+	fmt.Printf(
+		"Stake:  %d / %d\n",
+		schedule.PoolStake,
+		schedule.TotalStake,
+	)
+	fmt.Printf("Slots:  %d\n\n", len(schedule.Slots))
+	fmt.Printf("%15s   %30s\n", "SlotNo", "UTC Time")
+	fmt.Println(strings.Repeat("-", 50))
+	for _, leaderSlot := range schedule.Slots {
+		fmt.Printf(
+			"%15d   %30s\n",
+			leaderSlot.Slot,
+			leaderSlot.Time.UTC().Format("2006-01-02 15:04:05 UTC"),
+		)
+	}
+	return nil
+}
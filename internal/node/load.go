@@ -0,0 +1,60 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/blinklabs-io/dingo/config/cardano"
+	"github.com/blinklabs-io/dingo/event"
+	"github.com/blinklabs-io/dingo/internal/config"
+	"github.com/blinklabs-io/dingo/state"
+)
+
+// Load imports blocks from the specified cardano-node ImmutableDB directory into the configured database
+func Load(cfg *config.Config, logger *slog.Logger, immutableDir string) error {
+	logger.Debug(fmt.Sprintf("config: %+v", cfg), "component", "node")
+	if cfg.CardanoConfig == "" {
+		return errors.New("a Cardano node config is required to load blocks")
+	}
+	nodeCfg, err := cardano.NewCardanoNodeConfigFromFile(cfg.CardanoConfig)
+	if err != nil {
+		return err
+	}
+	validationLevel, err := state.ParseValidationLevel(cfg.ValidationLevel)
+	if err != nil {
+		return err
+	}
+	ls, err := state.NewLedgerState(
+		state.LedgerStateConfig{
+			DataDir:           cfg.DatabasePath,
+			BlobPlugin:        cfg.BlobPlugin,
+			MetadataPlugin:    cfg.MetadataPlugin,
+			EventBus:          event.NewEventBus(nil),
+			Logger:            logger,
+			CardanoNodeConfig: nodeCfg,
+			ValidationLevel:   validationLevel,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to load state database: %w", err)
+	}
+	if err := ls.LoadImmutableDb(immutableDir); err != nil {
+		return errors.Join(err, ls.Close())
+	}
+	return ls.Close()
+}
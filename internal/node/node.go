@@ -25,15 +25,13 @@ import (
 	"github.com/blinklabs-io/dingo"
 	"github.com/blinklabs-io/dingo/config/cardano"
 	"github.com/blinklabs-io/dingo/internal/config"
+	"github.com/blinklabs-io/dingo/mempool"
+	"github.com/blinklabs-io/dingo/state"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func Run(logger *slog.Logger) error {
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		return err
-	}
+func Run(cfg *config.Config, logger *slog.Logger) error {
 	logger.Debug(fmt.Sprintf("config: %+v", cfg), "component", "node")
 	logger.Debug(
 		fmt.Sprintf("topology: %+v", config.GetTopologyConfig()),
@@ -58,6 +56,14 @@ func Run(logger *slog.Logger) error {
 			"component", "node",
 		)
 	}
+	validationLevel, err := state.ParseValidationLevel(cfg.ValidationLevel)
+	if err != nil {
+		return err
+	}
+	mempoolOrdering, err := mempool.ParseOrderingStrategy(cfg.MempoolOrdering)
+	if err != nil {
+		return err
+	}
 	listeners := []dingo.ListenerConfig{}
 	if cfg.RelayPort > 0 {
 		// Public "relay" port (node-to-node)
@@ -105,6 +111,8 @@ func Run(logger *slog.Logger) error {
 			dingo.WithIntersectTip(cfg.IntersectTip),
 			dingo.WithLogger(logger),
 			dingo.WithDatabasePath(cfg.DatabasePath),
+			dingo.WithBlobPlugin(cfg.BlobPlugin),
+			dingo.WithMetadataPlugin(cfg.MetadataPlugin),
 			dingo.WithNetwork(cfg.Network),
 			dingo.WithCardanoNodeConfig(nodeCfg),
 			dingo.WithListeners(listeners...),
@@ -112,6 +120,17 @@ func Run(logger *slog.Logger) error {
 			dingo.WithUtxorpcPort(cfg.UtxorpcPort),
 			dingo.WithUtxorpcTlsCertFilePath(cfg.TlsCertFilePath),
 			dingo.WithUtxorpcTlsKeyFilePath(cfg.TlsKeyFilePath),
+			dingo.WithValidationLevel(validationLevel),
+			dingo.WithMempoolMaxBytes(cfg.MempoolMaxBytes),
+			dingo.WithMempoolMaxTxs(cfg.MempoolMaxTxs),
+			dingo.WithMempoolTxTtl(cfg.MempoolTxTtl),
+			dingo.WithMempoolPersist(cfg.MempoolPersist),
+			dingo.WithMempoolOrdering(mempoolOrdering),
+			dingo.WithShelleyVrfKey(cfg.ShelleyVrfKey),
+			dingo.WithShelleyKesKey(cfg.ShelleyKesKey),
+			dingo.WithShelleyOperationalCertificate(
+				cfg.ShelleyOperationalCertificate,
+			),
 			// Enable metrics with default prometheus registry
 			dingo.WithPrometheusRegistry(prometheus.DefaultRegisterer),
 			// TODO: make this configurable (#387)
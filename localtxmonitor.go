@@ -15,11 +15,9 @@
 package dingo
 
 import (
-	olocaltxmonitor "github.com/blinklabs-io/gouroboros/protocol/localtxmonitor"
-)
+	"math"
 
-const (
-	localtxmonitorMempoolCapacity = 10 * 1024 * 1024 // TODO: replace with configurable value (#400)
+	olocaltxmonitor "github.com/blinklabs-io/gouroboros/protocol/localtxmonitor"
 )
 
 func (n *Node) localtxmonitorServerConnOpts() []olocaltxmonitor.LocalTxMonitorOptionFunc {
@@ -40,5 +38,6 @@ func (n *Node) localtxmonitorServerGetMempool(
 			Tx:    mempoolTxs[i].Cbor,
 		}
 	}
-	return tip.Point.Slot, localtxmonitorMempoolCapacity, retTxs, nil
+	capacity := min(n.mempool.Capacity(), math.MaxUint32)
+	return tip.Point.Slot, uint32(capacity), retTxs, nil
 }
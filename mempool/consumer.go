@@ -20,7 +20,7 @@ import (
 
 type MempoolConsumer struct {
 	mempool    *Mempool
-	nextTxIdx  int
+	sentTxs    map[string]bool
 	cache      map[string]*MempoolTransaction
 	cacheMutex sync.Mutex
 }
@@ -28,6 +28,7 @@ type MempoolConsumer struct {
 func newConsumer(mempool *Mempool) *MempoolConsumer {
 	return &MempoolConsumer{
 		mempool: mempool,
+		sentTxs: make(map[string]bool),
 		cache:   make(map[string]*MempoolTransaction),
 	}
 }
@@ -38,7 +39,19 @@ func (m *MempoolConsumer) NextTx(blocking bool) *MempoolTransaction {
 	}
 	m.mempool.RLock()
 	defer m.mempool.RUnlock()
-	if m.nextTxIdx >= len(m.mempool.transactions) {
+	for {
+		// Find the first TX that hasn't been sent yet, in mempool order
+		for _, nextTx := range m.mempool.orderedTransactions() {
+			if m.sentTxs[nextTx.Hash] {
+				continue
+			}
+			m.sentTxs[nextTx.Hash] = true
+			// Add transaction to cache
+			m.cacheMutex.Lock()
+			m.cache[nextTx.Hash] = nextTx
+			m.cacheMutex.Unlock()
+			return nextTx
+		}
 		if !blocking {
 			return nil
 		}
@@ -51,16 +64,6 @@ func (m *MempoolConsumer) NextTx(blocking bool) *MempoolTransaction {
 		m.mempool.eventBus.Unsubscribe(AddTransactionEventType, addTxSubId)
 		m.mempool.RLock()
 	}
-	nextTx := m.mempool.transactions[m.nextTxIdx]
-	if nextTx != nil {
-		// Increment next TX index
-		m.nextTxIdx++
-		// Add transaction to cache
-		m.cacheMutex.Lock()
-		m.cache[nextTx.Hash] = nextTx
-		m.cacheMutex.Unlock()
-	}
-	return nextTx
 }
 
 func (m *MempoolConsumer) GetTxFromCache(hash string) *MempoolTransaction {
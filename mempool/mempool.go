@@ -15,6 +15,8 @@
 package mempool
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"slices"
@@ -25,6 +27,7 @@ import (
 	"github.com/blinklabs-io/dingo/state"
 	ouroboros "github.com/blinklabs-io/gouroboros"
 	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -34,6 +37,21 @@ const (
 	RemoveTransactionEventType event.EventType = "mempool.remove_tx"
 )
 
+const (
+	// DefaultMaxBytes is the mempool capacity used when there's no max block body size available from the
+	// protocol parameters
+	DefaultMaxBytes = 10 * 1024 * 1024
+
+	// Interval for the periodic removal of expired transactions and saving of the mempool contents
+	maintenanceInterval = 1 * time.Minute
+)
+
+// ErrMempoolFull is returned when adding a transaction would exceed the mempool capacity
+var ErrMempoolFull = errors.New("mempool full")
+
+// ErrTxConflict is returned when adding a transaction that spends the same inputs as a pending transaction
+var ErrTxConflict = errors.New("transaction conflicts with a pending transaction")
+
 type AddTransactionEvent struct {
 	Hash string
 	Body []byte
@@ -49,45 +67,84 @@ type MempoolTransaction struct {
 	Type     uint
 	Cbor     []byte
 	LastSeen time.Time
+	tx       lcommon.Transaction
+}
+
+// MempoolConfig provides the configuration for a Mempool
+type MempoolConfig struct {
+	Logger       *slog.Logger
+	EventBus     *event.EventBus
+	PromRegistry prometheus.Registerer
+	LedgerState  *state.LedgerState
+	// Maximum combined size of transactions in bytes. Defaults to twice the max block body size from the
+	// current protocol parameters
+	MaxBytes uint64
+	// Maximum number of transactions. Defaults to no limit
+	MaxTxs uint
+	// Transactions not seen for this long are removed. Defaults to no expiry
+	TxTtl time.Duration
+	// Path to a file used to save the mempool contents across restarts. Defaults to no persistence
+	PersistPath string
+	// Order for offering transactions to peers and selecting them for blocks. Defaults to FifoOrdering
+	Ordering OrderingStrategy
 }
 
 type Mempool struct {
 	sync.RWMutex
-	logger         *slog.Logger
-	eventBus       *event.EventBus
-	ledgerState    *state.LedgerState
-	consumers      map[ouroboros.ConnectionId]*MempoolConsumer
-	consumersMutex sync.Mutex
-	transactions   []*MempoolTransaction
-	metrics        struct {
+	config           MempoolConfig
+	logger           *slog.Logger
+	eventBus         *event.EventBus
+	ledgerState      *state.LedgerState
+	consumers        map[ouroboros.ConnectionId]*MempoolConsumer
+	consumersMutex   sync.Mutex
+	transactions     []*MempoolTransaction
+	transactionBytes uint64
+	txsByHash        map[string]*MempoolTransaction
+	overlay          *UtxoOverlay
+	validatedEpoch   uint64
+	ordering         OrderingStrategy
+	ordered          []*MempoolTransaction
+	orderedMutex     sync.Mutex
+	dirty            bool
+	timerMaintenance *time.Timer
+	metrics          struct {
 		txsProcessedNum prometheus.Counter
 		txsInMempool    prometheus.Gauge
 		mempoolBytes    prometheus.Gauge
 	}
 }
 
-func NewMempool(
-	logger *slog.Logger,
-	eventBus *event.EventBus,
-	promRegistry prometheus.Registerer,
-	ledgerState *state.LedgerState,
-) *Mempool {
+func NewMempool(cfg MempoolConfig) *Mempool {
 	m := &Mempool{
-		eventBus:    eventBus,
+		config:      cfg,
+		eventBus:    cfg.EventBus,
 		consumers:   make(map[ouroboros.ConnectionId]*MempoolConsumer),
-		ledgerState: ledgerState,
+		ledgerState: cfg.LedgerState,
+		txsByHash:   make(map[string]*MempoolTransaction),
+		overlay:     NewUtxoOverlay(),
+		ordering:    cfg.Ordering,
+	}
+	if m.ordering == nil {
+		m.ordering = FifoOrdering{}
+	}
+	if m.ledgerState != nil {
+		m.validatedEpoch = m.ledgerState.CurrentEpoch()
 	}
-	if logger == nil {
+	if cfg.Logger == nil {
 		// Create logger to throw away logs
 		// We do this so we don't have to add guards around every log operation
 		m.logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
 	} else {
-		m.logger = logger
+		m.logger = cfg.Logger
 	}
-	// Subscribe to chain update events
-	go m.processChainEvents()
+	// Subscribe to chain update events. We subscribe before starting the goroutine so that events published
+	// after we return aren't missed
+	chainUpdateSubId, chainUpdateChan := m.eventBus.Subscribe(
+		state.ChainUpdateEventType,
+	)
+	go m.processChainEvents(chainUpdateSubId, chainUpdateChan)
 	// Init metrics
-	promautoFactory := promauto.With(promRegistry)
+	promautoFactory := promauto.With(cfg.PromRegistry)
 	m.metrics.txsProcessedNum = promautoFactory.NewCounter(
 		prometheus.CounterOpts{
 			Name: "cardano_node_metrics_txsProcessedNum_int",
@@ -102,9 +159,123 @@ func NewMempool(
 		Name: "cardano_node_metrics_mempoolBytes_int",
 		Help: "current size of mempool transactions in bytes",
 	})
+	// Restore saved transactions
+	if m.config.PersistPath != "" {
+		m.load()
+	}
+	// Schedule periodic removal of expired transactions
+	m.scheduleMaintenance()
 	return m
 }
 
+// Stop stops periodic maintenance and saves the mempool contents, if persistence is enabled
+func (m *Mempool) Stop() error {
+	m.Lock()
+	defer m.Unlock()
+	if m.timerMaintenance != nil {
+		m.timerMaintenance.Stop()
+		m.timerMaintenance = nil
+	}
+	return m.save()
+}
+
+// Capacity returns the maximum combined size of transactions in bytes
+func (m *Mempool) Capacity() uint64 {
+	if m.config.MaxBytes > 0 {
+		return m.config.MaxBytes
+	}
+	if m.ledgerState != nil {
+		if pparams := m.ledgerState.GetCurrentPParams(); pparams != nil {
+			if tmpPParams := pparams.Utxorpc(); tmpPParams != nil &&
+				tmpPParams.GetMaxBlockBodySize() > 0 {
+				return 2 * tmpPParams.GetMaxBlockBodySize()
+			}
+		}
+	}
+	return DefaultMaxBytes
+}
+
+func (m *Mempool) scheduleMaintenance() {
+	m.Lock()
+	defer m.Unlock()
+	if m.timerMaintenance != nil {
+		m.timerMaintenance.Stop()
+	}
+	m.timerMaintenance = time.AfterFunc(
+		maintenanceInterval,
+		func() {
+			m.Lock()
+			defer m.Unlock()
+			// Mempool has been stopped
+			if m.timerMaintenance == nil {
+				return
+			}
+			m.expireTransactions()
+			if err := m.save(); err != nil {
+				m.logger.Error(
+					"failed to save mempool",
+					"component", "mempool",
+					"error", err,
+				)
+			}
+			// Schedule the next run
+			m.timerMaintenance.Reset(maintenanceInterval)
+		},
+	)
+}
+
+// expireTransactions removes transactions that haven't been seen within the configured TTL. The caller
+// must hold the mempool lock
+func (m *Mempool) expireTransactions() {
+	if m.config.TxTtl <= 0 {
+		return
+	}
+	m.consumersMutex.Lock()
+	defer m.consumersMutex.Unlock()
+	var expiredTxHashes []string
+	for _, tx := range m.transactions {
+		if time.Since(tx.LastSeen) >= m.config.TxTtl {
+			expiredTxHashes = append(expiredTxHashes, tx.Hash)
+		}
+	}
+	m.evictTransactions(expiredTxHashes, "removed expired transaction")
+}
+
+// evictTransactions removes transactions that are being dropped from the mempool, along with any pending
+// transactions that spend their outputs. The caller must hold the mempool and consumers locks
+func (m *Mempool) evictTransactions(txHashes []string, logMsg string) {
+	evictTxHashes := make(map[string]bool)
+	var evictTx func(string, string)
+	evictTx = func(txHash string, parentTxHash string) {
+		tx, ok := m.txsByHash[txHash]
+		if !ok || evictTxHashes[txHash] {
+			return
+		}
+		evictTxHashes[txHash] = true
+		if parentTxHash == "" {
+			m.logger.Debug(
+				logMsg,
+				"component", "mempool",
+				"tx_hash", txHash,
+			)
+		} else {
+			m.logger.Debug(
+				"removed transaction spending the outputs of a removed transaction",
+				"component", "mempool",
+				"tx_hash", txHash,
+				"parent_tx_hash", parentTxHash,
+			)
+		}
+		for _, dependentTxHash := range m.overlay.Dependents(tx.tx) {
+			evictTx(dependentTxHash, txHash)
+		}
+	}
+	for _, txHash := range txHashes {
+		evictTx(txHash, "")
+	}
+	m.removeTransactions(evictTxHashes)
+}
+
 func (m *Mempool) AddConsumer(connId ouroboros.ConnectionId) *MempoolConsumer {
 	// Create consumer
 	m.consumersMutex.Lock()
@@ -126,85 +297,271 @@ func (m *Mempool) Consumer(connId ouroboros.ConnectionId) *MempoolConsumer {
 	return m.consumers[connId]
 }
 
-func (m *Mempool) processChainEvents() {
-	chainBlockSubId, chainBlockChan := m.eventBus.Subscribe(
-		state.ChainBlockEventType,
-	)
-	chainRollbackSubId, chainRollbackChan := m.eventBus.Subscribe(
-		state.ChainRollbackEventType,
-	)
-	defer func() {
-		m.eventBus.Unsubscribe(state.ChainBlockEventType, chainBlockSubId)
-		m.eventBus.Unsubscribe(state.ChainRollbackEventType, chainRollbackSubId)
-	}()
-	lastValidationTime := time.Now()
-	var ok bool
+// processChainEvents updates the mempool for blocks and rollbacks on the chain. These come from a single
+// subscription, since they must be processed in the order they happened
+func (m *Mempool) processChainEvents(
+	chainUpdateSubId event.EventSubscriberId,
+	chainUpdateChan <-chan event.Event,
+) {
+	defer m.eventBus.Unsubscribe(state.ChainUpdateEventType, chainUpdateSubId)
 	for {
 		// Wait for chain event
-		select {
-		case _, ok = <-chainBlockChan:
-			if !ok {
-				return
-			}
-		case _, ok = <-chainRollbackChan:
-			if !ok {
-				return
-			}
+		evt, ok := <-chainUpdateChan
+		if !ok {
+			return
 		}
-		// Only purge once every 30 seconds when there are more blocks available
-		if time.Since(lastValidationTime) < 30*time.Second &&
-			len(chainBlockChan) > 0 {
+		updateEvent, ok := evt.Data.(state.ChainUpdateEvent)
+		if !ok {
 			continue
 		}
-		m.Lock()
-		// Re-validate each TX in mempool
-		// We iterate backward to avoid issues with shifting indexes when deleting
-		for i := len(m.transactions) - 1; i >= 0; i-- {
-			tx := m.transactions[i]
-			// Decode transaction
-			tmpTx, err := ledger.NewTransactionFromCbor(tx.Type, tx.Cbor)
+		switch {
+		case updateEvent.Block != nil:
+			blk, err := updateEvent.Block.Block.Decode()
 			if err != nil {
-				m.removeTransactionByIndex(i)
 				m.logger.Error(
-					"removed transaction after decode failure",
+					"failed to decode block",
 					"component", "mempool",
-					"tx_hash", tx.Hash,
 					"error", err,
 				)
 				continue
 			}
-			// Validate transaction
-			if err := m.ledgerState.ValidateTx(tmpTx); err != nil {
-				m.removeTransactionByIndex(i)
-				m.logger.Debug(
-					"removed transaction after re-validation failure",
-					"component", "mempool",
-					"tx_hash", tx.Hash,
-					"error", err,
-				)
+			m.Lock()
+			m.consumersMutex.Lock()
+			m.processChainBlock(updateEvent.Block.Point.Slot, blk.Transactions())
+			m.consumersMutex.Unlock()
+			m.Unlock()
+		case updateEvent.Rollback != nil:
+			var rolledBackTxs []lcommon.Transaction
+			for _, tmpBlock := range updateEvent.Rollback.RolledBackBlocks {
+				blk, err := tmpBlock.Decode()
+				if err != nil {
+					m.logger.Error(
+						"failed to decode rolled-back block",
+						"component", "mempool",
+						"error", err,
+					)
+					continue
+				}
+				rolledBackTxs = append(rolledBackTxs, blk.Transactions()...)
 			}
+			m.Lock()
+			m.consumersMutex.Lock()
+			m.processChainRollback(rolledBackTxs)
+			m.consumersMutex.Unlock()
+			m.Unlock()
 		}
-		m.Unlock()
 	}
 }
 
+// processChainBlock updates the mempool for a new block on the chain. Transactions included in the block are
+// removed, along with transactions that spend the same inputs as a transaction in the block and transactions
+// whose TTL has passed. Only these transactions are touched, unless the epoch has changed, since a change in
+// protocol parameters or era can affect any transaction. The caller must hold the mempool and consumers locks
+func (m *Mempool) processChainBlock(
+	slot uint64,
+	blockTxs []lcommon.Transaction,
+) {
+	if m.ledgerState != nil && m.ledgerState.CurrentEpoch() != m.validatedEpoch {
+		m.revalidateTransactions()
+		return
+	}
+	if len(m.transactions) == 0 {
+		return
+	}
+	includedTxHashes := make(map[string]bool, len(blockTxs))
+	for _, tx := range blockTxs {
+		includedTxHashes[tx.Hash()] = true
+	}
+	// Find pending transactions that spend an input consumed by a different transaction in the block
+	var conflictTxHashes []string
+	for _, tx := range blockTxs {
+		for _, input := range tx.Consumed() {
+			pendingTxHash, ok := m.overlay.ConsumedBy(input)
+			if !ok || includedTxHashes[pendingTxHash] {
+				continue
+			}
+			conflictTxHashes = append(conflictTxHashes, pendingTxHash)
+		}
+	}
+	// Find transactions that can't be in a block after this one
+	var expiredTxHashes []string
+	for _, tx := range m.transactions {
+		if includedTxHashes[tx.Hash] {
+			continue
+		}
+		if ttl := tx.tx.TTL(); ttl > 0 && ttl <= slot+1 {
+			expiredTxHashes = append(expiredTxHashes, tx.Hash)
+		}
+	}
+	// Transactions that spend the outputs of an included transaction are still valid
+	for txHash := range includedTxHashes {
+		if _, ok := m.txsByHash[txHash]; ok {
+			m.logger.Debug(
+				"removed transaction included in block",
+				"component", "mempool",
+				"tx_hash", txHash,
+			)
+		}
+	}
+	m.removeTransactions(includedTxHashes)
+	m.evictTransactions(
+		conflictTxHashes,
+		"removed transaction spending inputs consumed by block",
+	)
+	m.evictTransactions(expiredTxHashes, "removed transaction past its TTL")
+}
+
+// processChainRollback updates the mempool after a rollback. Still-valid transactions from the rolled-back blocks
+// are re-added in their original order, ahead of pending transactions that may spend their outputs, and then all
+// transactions are re-validated, since they may depend on UTxOs that were removed by the rollback. The caller must
+// hold the mempool and consumers locks
+func (m *Mempool) processChainRollback(rolledBackTxs []lcommon.Transaction) {
+	overlay := NewUtxoOverlay()
+	var reinjectedTxs []*MempoolTransaction
+	var reinjectedBytes uint64
+	capacity := m.Capacity()
+	for _, tmpTx := range rolledBackTxs {
+		// Transactions that failed phase-2 validation only consumed their collateral
+		if !tmpTx.IsValid() {
+			continue
+		}
+		txHash := tmpTx.Hash()
+		if m.getTransaction(txHash) != nil {
+			continue
+		}
+		txCbor := tmpTx.Cbor()
+		if (m.config.MaxTxs > 0 && uint(len(m.transactions)+len(reinjectedTxs)) >= m.config.MaxTxs) ||
+			m.transactionBytes+reinjectedBytes+uint64(len(txCbor)) > capacity {
+			m.logger.Debug(
+				"dropped transaction from rolled-back block",
+				"component", "mempool",
+				"tx_hash", txHash,
+				"error", ErrMempoolFull,
+			)
+			continue
+		}
+		if err := m.validateTransaction(tmpTx, overlay); err != nil {
+			m.logger.Debug(
+				"dropped transaction from rolled-back block",
+				"component", "mempool",
+				"tx_hash", txHash,
+				"error", err,
+			)
+			continue
+		}
+		overlay.Add(tmpTx)
+		reinjectedTxs = append(
+			reinjectedTxs,
+			&MempoolTransaction{
+				Hash:     txHash,
+				Type:     uint(tmpTx.Type()), // #nosec G115
+				Cbor:     txCbor,
+				LastSeen: time.Now(),
+				tx:       tmpTx,
+			},
+		)
+		reinjectedBytes += uint64(len(txCbor))
+	}
+	if len(reinjectedTxs) > 0 {
+		m.transactions = slices.Concat(reinjectedTxs, m.transactions)
+		m.ordered = nil
+		m.dirty = true
+	}
+	for _, tx := range reinjectedTxs {
+		m.txsByHash[tx.Hash] = tx
+		m.transactionBytes += uint64(len(tx.Cbor))
+		m.logger.Debug(
+			"re-added transaction from rolled-back block",
+			"component", "mempool",
+			"tx_hash", tx.Hash,
+		)
+		m.metrics.txsProcessedNum.Inc()
+		m.metrics.txsInMempool.Inc()
+		m.metrics.mempoolBytes.Add(float64(len(tx.Cbor)))
+		// Generate event
+		m.eventBus.Publish(
+			AddTransactionEventType,
+			event.NewEvent(
+				AddTransactionEventType,
+				AddTransactionEvent{
+					Hash: tx.Hash,
+					Type: tx.Type,
+					Body: tx.Cbor[:],
+				},
+			),
+		)
+	}
+	m.revalidateTransactions()
+}
+
+// revalidateTransactions re-validates each transaction in the mempool, in order, against the ledger and the
+// transactions before it. This also removes transactions that depend on the outputs of a removed transaction.
+// The caller must hold the mempool and consumers locks
+func (m *Mempool) revalidateTransactions() {
+	if m.ledgerState != nil {
+		m.validatedEpoch = m.ledgerState.CurrentEpoch()
+	}
+	m.overlay = NewUtxoOverlay()
+	invalidTxHashes := make(map[string]bool)
+	for _, tx := range m.transactions {
+		if err := m.validateTransaction(tx.tx, m.overlay); err != nil {
+			invalidTxHashes[tx.Hash] = true
+			m.logger.Debug(
+				"removed transaction after re-validation failure",
+				"component", "mempool",
+				"tx_hash", tx.Hash,
+				"error", err,
+			)
+			continue
+		}
+		m.overlay.Add(tx.tx)
+	}
+	m.removeTransactions(invalidTxHashes)
+}
+
+// validateTransaction validates a transaction against the ledger and the transactions in the provided overlay
+func (m *Mempool) validateTransaction(
+	tx lcommon.Transaction,
+	overlay *UtxoOverlay,
+) error {
+	// Check for pending transactions spending the same inputs
+	if conflictHash, ok := overlay.Conflict(tx); ok {
+		return fmt.Errorf(
+			"%w: TX %s spends the same inputs as pending TX %s",
+			ErrTxConflict,
+			tx.Hash(),
+			conflictHash,
+		)
+	}
+	if m.ledgerState == nil {
+		return nil
+	}
+	// Validate transaction against the ledger and the outputs of pending transactions
+	return m.ledgerState.ValidateTxWithOverlay(tx, overlay)
+}
+
 func (m *Mempool) AddTransaction(txType uint, txBytes []byte) error {
+	return m.addTransaction(txType, txBytes, time.Now())
+}
+
+func (m *Mempool) addTransaction(
+	txType uint,
+	txBytes []byte,
+	lastSeen time.Time,
+) error {
 	// Decode transaction
 	tmpTx, err := ledger.NewTransactionFromCbor(txType, txBytes)
 	if err != nil {
 		return err
 	}
-	// Validate transaction
-	if err := m.ledgerState.ValidateTx(tmpTx); err != nil {
-		return err
-	}
 	// Build mempool entry
 	txHash := tmpTx.Hash()
 	tx := MempoolTransaction{
 		Hash:     txHash,
 		Type:     txType,
 		Cbor:     txBytes,
-		LastSeen: time.Now(),
+		LastSeen: lastSeen,
+		tx:       tmpTx,
 	}
 	m.Lock()
 	m.consumersMutex.Lock()
@@ -215,7 +572,10 @@ func (m *Mempool) AddTransaction(txType uint, txBytes []byte) error {
 	// Update last seen for existing TX
 	existingTx := m.getTransaction(tx.Hash)
 	if existingTx != nil {
-		tx.LastSeen = time.Now()
+		if tx.LastSeen.After(existingTx.LastSeen) {
+			existingTx.LastSeen = tx.LastSeen
+			m.dirty = true
+		}
 		m.logger.Debug(
 			"updated last seen for transaction",
 			"component", "mempool",
@@ -223,8 +583,35 @@ func (m *Mempool) AddTransaction(txType uint, txBytes []byte) error {
 		)
 		return nil
 	}
+	if err := m.validateTransaction(tmpTx, m.overlay); err != nil {
+		return err
+	}
+	// Check capacity
+	if m.config.MaxTxs > 0 && uint(len(m.transactions)) >= m.config.MaxTxs {
+		return fmt.Errorf(
+			"%w: transaction count limit (%d) reached",
+			ErrMempoolFull,
+			m.config.MaxTxs,
+		)
+	}
+	if capacity := m.Capacity(); m.transactionBytes+uint64(
+		len(tx.Cbor),
+	) > capacity {
+		return fmt.Errorf(
+			"%w: transaction size (%d) exceeds remaining capacity (%d of %d bytes used)",
+			ErrMempoolFull,
+			len(tx.Cbor),
+			m.transactionBytes,
+			capacity,
+		)
+	}
 	// Add transaction record
 	m.transactions = append(m.transactions, &tx)
+	m.txsByHash[tx.Hash] = &tx
+	m.transactionBytes += uint64(len(tx.Cbor))
+	m.overlay.Add(tmpTx)
+	m.ordered = nil
+	m.dirty = true
 	m.logger.Debug(
 		"added transaction",
 		"component", "mempool",
@@ -258,28 +645,31 @@ func (m *Mempool) GetTransaction(txHash string) (MempoolTransaction, bool) {
 	return *ret, true
 }
 
+// Transactions returns the mempool transactions in the order from the configured OrderingStrategy
 func (m *Mempool) Transactions() []MempoolTransaction {
-	m.Lock()
-	defer m.Unlock()
-	ret := make([]MempoolTransaction, len(m.transactions))
-	for i := 0; i < len(m.transactions); i++ {
-		ret[i] = *m.transactions[i]
+	m.RLock()
+	defer m.RUnlock()
+	orderedTxs := m.orderedTransactions()
+	ret := make([]MempoolTransaction, len(orderedTxs))
+	for i := 0; i < len(orderedTxs); i++ {
+		ret[i] = *orderedTxs[i]
 	}
 	return ret
 }
 
 func (m *Mempool) getTransaction(txHash string) *MempoolTransaction {
-	for _, tx := range m.transactions {
-		if tx.Hash == txHash {
-			return tx
-		}
-	}
-	return nil
+	return m.txsByHash[txHash]
 }
 
+// RemoveTransaction removes a transaction from the mempool, such as after it's included in a block. Pending
+// transactions that spend its outputs are kept
 func (m *Mempool) RemoveTransaction(txHash string) {
 	m.Lock()
-	defer m.Unlock()
+	m.consumersMutex.Lock()
+	defer func() {
+		m.consumersMutex.Unlock()
+		m.Unlock()
+	}()
 	if m.removeTransaction(txHash) {
 		m.logger.Debug(
 			"removed transaction",
@@ -290,42 +680,50 @@ func (m *Mempool) RemoveTransaction(txHash string) {
 }
 
 func (m *Mempool) removeTransaction(txHash string) bool {
-	for txIdx, tx := range m.transactions {
-		if tx.Hash == txHash {
-			return m.removeTransactionByIndex(txIdx)
-		}
-	}
-	return false
+	return m.removeTransactions(map[string]bool{txHash: true}) > 0
 }
 
-func (m *Mempool) removeTransactionByIndex(txIdx int) bool {
-	if txIdx >= len(m.transactions) {
-		return false
+// removeTransactions removes the specified transactions in a single pass and returns the number removed. The
+// caller must hold the mempool and consumers locks
+func (m *Mempool) removeTransactions(txHashes map[string]bool) int {
+	if len(txHashes) == 0 {
+		return 0
 	}
-	tx := m.transactions[txIdx]
-	m.transactions = slices.Delete(
+	var removedTxs []*MempoolTransaction
+	m.transactions = slices.DeleteFunc(
 		m.transactions,
-		txIdx,
-		txIdx+1,
+		func(tx *MempoolTransaction) bool {
+			if !txHashes[tx.Hash] {
+				return false
+			}
+			removedTxs = append(removedTxs, tx)
+			return true
+		},
 	)
-	m.metrics.txsInMempool.Dec()
-	m.metrics.mempoolBytes.Sub(float64(len(tx.Cbor)))
-	// Update consumer indexes to reflect removed TX
-	for _, consumer := range m.consumers {
-		// Decrement consumer index if the consumer has reached the removed TX
-		if consumer.nextTxIdx >= txIdx {
-			consumer.nextTxIdx--
+	for _, tx := range removedTxs {
+		delete(m.txsByHash, tx.Hash)
+		m.transactionBytes -= uint64(len(tx.Cbor))
+		m.overlay.Remove(tx.tx)
+		m.metrics.txsInMempool.Dec()
+		m.metrics.mempoolBytes.Sub(float64(len(tx.Cbor)))
+		// Forget that the removed TX was sent to consumers
+		for _, consumer := range m.consumers {
+			delete(consumer.sentTxs, tx.Hash)
 		}
-	}
-	// Generate event
-	m.eventBus.Publish(
-		RemoveTransactionEventType,
-		event.NewEvent(
+		// Generate event
+		m.eventBus.Publish(
 			RemoveTransactionEventType,
-			RemoveTransactionEvent{
-				Hash: tx.Hash,
-			},
-		),
-	)
-	return true
+			event.NewEvent(
+				RemoveTransactionEventType,
+				RemoveTransactionEvent{
+					Hash: tx.Hash,
+				},
+			),
+		)
+	}
+	if len(removedTxs) > 0 {
+		m.ordered = nil
+		m.dirty = true
+	}
+	return len(removedTxs)
 }
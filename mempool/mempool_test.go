@@ -0,0 +1,578 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mempool
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/event"
+	"github.com/blinklabs-io/dingo/state"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/conway"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const benchmarkMempoolSize = 10_000
+
+// testTxInput identifies a UTxO by transaction hash and output index
+type testTxInput struct {
+	cbor.StructAsArray
+	TxId  []byte
+	Index uint32
+}
+
+// testTxOutput is a legacy-format transaction output
+type testTxOutput struct {
+	cbor.StructAsArray
+	Address []byte
+	Amount  uint64
+}
+
+// testTxId returns a deterministic fake transaction ID for an on-chain UTxO
+func testTxId(seed uint64) []byte {
+	ret := make([]byte, 32)
+	binary.BigEndian.PutUint64(ret, seed)
+	return ret
+}
+
+// newTestTx builds a Conway transaction spending the specified inputs
+func newTestTx(
+	t testing.TB,
+	inputs []testTxInput,
+	fee uint64,
+	ttl uint64,
+) lcommon.Transaction {
+	// Enterprise address on testnet
+	addr := make([]byte, 29)
+	addr[0] = 0x60
+	body := map[uint]any{
+		0: inputs,
+		1: []testTxOutput{
+			{Address: addr, Amount: 1_000_000},
+			{Address: addr, Amount: fee},
+		},
+		2: fee,
+	}
+	if ttl > 0 {
+		body[3] = ttl
+	}
+	txCbor, err := cbor.Encode(
+		[]any{body, map[uint]any{}, true, nil},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error encoding TX: %s", err)
+	}
+	tx, err := ledger.NewTransactionFromCbor(ledger.TxTypeConway, txCbor)
+	if err != nil {
+		t.Fatalf("unexpected error decoding TX: %s", err)
+	}
+	return tx
+}
+
+// newTestMempool returns a Mempool without a ledger state, with the provided transactions added without validation
+func newTestMempool(txs []lcommon.Transaction) *Mempool {
+	m := NewMempool(
+		MempoolConfig{
+			EventBus:     event.NewEventBus(nil),
+			PromRegistry: prometheus.NewRegistry(),
+		},
+	)
+	for _, tx := range txs {
+		txCbor := tx.Cbor()
+		mempoolTx := &MempoolTransaction{
+			Hash:     tx.Hash(),
+			Type:     ledger.TxTypeConway,
+			Cbor:     txCbor,
+			LastSeen: time.Now(),
+			tx:       tx,
+		}
+		m.transactions = append(m.transactions, mempoolTx)
+		m.txsByHash[mempoolTx.Hash] = mempoolTx
+		m.transactionBytes += uint64(len(txCbor))
+		m.overlay.Add(tx)
+	}
+	return m
+}
+
+// newTestBlock builds a Conway block at the specified slot containing the provided transactions. Only the
+// transactions are meaningful, the header fields are placeholders
+func newTestBlock(
+	t testing.TB,
+	slot uint64,
+	txs []lcommon.Transaction,
+) database.Block {
+	txBodies := []cbor.RawMessage{}
+	txWitnessSets := []cbor.RawMessage{}
+	for _, tx := range txs {
+		var txParts []cbor.RawMessage
+		if _, err := cbor.Decode(tx.Cbor(), &txParts); err != nil {
+			t.Fatalf("unexpected error decoding TX: %s", err)
+		}
+		txBodies = append(txBodies, txParts[0])
+		txWitnessSets = append(txWitnessSets, txParts[1])
+	}
+	hash := testTxId(slot)
+	headerBody := []any{
+		slot,
+		slot,
+		hash,
+		make([]byte, 32),
+		make([]byte, 32),
+		[]any{make([]byte, 64), make([]byte, 80)},
+		0,
+		hash,
+		[]any{make([]byte, 32), 0, 0, make([]byte, 64)},
+		[]any{10, 0},
+	}
+	blockCbor, err := cbor.Encode(
+		[]any{
+			[]any{headerBody, make([]byte, 448)},
+			txBodies,
+			txWitnessSets,
+			map[uint]any{},
+			[]uint{},
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error encoding block: %s", err)
+	}
+	return database.Block{
+		Slot: slot,
+		Hash: hash,
+		Type: conway.BlockTypeConway,
+		Cbor: blockCbor,
+	}
+}
+
+func TestProcessChainBlock(t *testing.T) {
+	parentTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(1), Index: 0}},
+		200_000,
+		0,
+	)
+	childTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: parentTx.Produced()[0].Id.Id().Bytes(), Index: 0}},
+		200_000,
+		0,
+	)
+	conflictTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(2), Index: 0}},
+		200_000,
+		0,
+	)
+	conflictChildTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: conflictTx.Produced()[0].Id.Id().Bytes(), Index: 0}},
+		200_000,
+		0,
+	)
+	expiredTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(3), Index: 0}},
+		200_000,
+		1001,
+	)
+	unrelatedTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(4), Index: 0}},
+		200_000,
+		5000,
+	)
+	m := newTestMempool(
+		[]lcommon.Transaction{
+			parentTx,
+			childTx,
+			conflictTx,
+			conflictChildTx,
+			expiredTx,
+			unrelatedTx,
+		},
+	)
+	// The block contains the parent TX and a different TX spending the same input as the conflicting TX
+	blockTxs := []lcommon.Transaction{
+		parentTx,
+		newTestTx(
+			t,
+			[]testTxInput{{TxId: testTxId(2), Index: 0}},
+			300_000,
+			0,
+		),
+	}
+	m.processChainBlock(1000, blockTxs)
+	var remaining []string
+	for _, tx := range m.transactions {
+		remaining = append(remaining, tx.Hash)
+	}
+	expected := []string{childTx.Hash(), unrelatedTx.Hash()}
+	if fmt.Sprint(remaining) != fmt.Sprint(expected) {
+		t.Fatalf(
+			"did not get expected transactions\n  got:    %v\n  wanted: %v",
+			remaining,
+			expected,
+		)
+	}
+	// The child of the included TX no longer depends on a pending TX
+	if _, ok := m.overlay.ProducedUtxo(childTx.Consumed()[0]); ok {
+		t.Fatalf("included TX outputs still in overlay")
+	}
+	if txHash, ok := m.overlay.ConsumedBy(childTx.Consumed()[0]); !ok ||
+		txHash != childTx.Hash() {
+		t.Fatalf("remaining TX inputs missing from overlay")
+	}
+}
+
+func TestProcessChainRollback(t *testing.T) {
+	rolledBackTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(1), Index: 0}},
+		200_000,
+		0,
+	)
+	rolledBackChildTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: rolledBackTx.Produced()[0].Id.Id().Bytes(), Index: 0}},
+		200_000,
+		0,
+	)
+	pendingChildTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: rolledBackTx.Produced()[1].Id.Id().Bytes(), Index: 1}},
+		200_000,
+		0,
+	)
+	conflictTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(2), Index: 0}},
+		200_000,
+		0,
+	)
+	pendingConflictTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(2), Index: 0}},
+		300_000,
+		0,
+	)
+	unrelatedTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(3), Index: 0}},
+		200_000,
+		0,
+	)
+	m := newTestMempool(
+		[]lcommon.Transaction{
+			pendingChildTx,
+			pendingConflictTx,
+			unrelatedTx,
+		},
+	)
+	// The pending TX spending the same input as a rolled-back TX is dropped in favor of the rolled-back TX,
+	// and the TX that was already pending isn't added twice
+	m.processChainRollback(
+		[]lcommon.Transaction{
+			rolledBackTx,
+			rolledBackChildTx,
+			conflictTx,
+			unrelatedTx,
+		},
+	)
+	var remaining []string
+	for _, tx := range m.transactions {
+		remaining = append(remaining, tx.Hash)
+	}
+	expected := []string{
+		rolledBackTx.Hash(),
+		rolledBackChildTx.Hash(),
+		conflictTx.Hash(),
+		pendingChildTx.Hash(),
+		unrelatedTx.Hash(),
+	}
+	if fmt.Sprint(remaining) != fmt.Sprint(expected) {
+		t.Fatalf(
+			"did not get expected transactions\n  got:    %v\n  wanted: %v",
+			remaining,
+			expected,
+		)
+	}
+	if len(m.txsByHash) != len(expected) {
+		t.Fatalf(
+			"did not get expected TX index size: got %d, wanted %d",
+			len(m.txsByHash),
+			len(expected),
+		)
+	}
+	// The pending child TX depends on the re-added TX
+	if txHash, ok := m.overlay.ConsumedBy(pendingChildTx.Consumed()[0]); !ok ||
+		txHash != pendingChildTx.Hash() {
+		t.Fatalf("pending TX inputs missing from overlay")
+	}
+	if _, ok := m.overlay.ProducedUtxo(pendingChildTx.Consumed()[0]); !ok {
+		t.Fatalf("re-added TX outputs missing from overlay")
+	}
+}
+
+func TestProcessChainEventsOrdering(t *testing.T) {
+	includedTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(1), Index: 0}},
+		200_000,
+		0,
+	)
+	rolledBackTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(2), Index: 0}},
+		200_000,
+		0,
+	)
+	m := newTestMempool([]lcommon.Transaction{includedTx, rolledBackTx})
+	// Both transactions are included in a block that's rolled back, and only one of them is included in the
+	// block from the fork. The result depends on the rollback being processed between the blocks
+	rolledBackBlock := newTestBlock(
+		t,
+		1000,
+		[]lcommon.Transaction{includedTx, rolledBackTx},
+	)
+	forkBlock := newTestBlock(t, 1001, []lcommon.Transaction{includedTx})
+	chainEvents := []state.ChainUpdateEvent{
+		{
+			Block: &state.ChainBlockEvent{
+				Point: ocommon.NewPoint(rolledBackBlock.Slot, rolledBackBlock.Hash),
+				Block: rolledBackBlock,
+			},
+		},
+		{
+			Rollback: &state.ChainRollbackEvent{
+				Point:            ocommon.NewPoint(999, testTxId(999)),
+				RolledBackBlocks: []database.Block{rolledBackBlock},
+			},
+		},
+		{
+			Block: &state.ChainBlockEvent{
+				Point: ocommon.NewPoint(forkBlock.Slot, forkBlock.Hash),
+				Block: forkBlock,
+			},
+		},
+	}
+	for _, chainEvent := range chainEvents {
+		m.eventBus.Publish(
+			state.ChainUpdateEventType,
+			event.NewEvent(state.ChainUpdateEventType, chainEvent),
+		)
+	}
+	// The intermediate states never match the expected state, so wait until we see it
+	expected := []string{rolledBackTx.Hash()}
+	var remaining []string
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		m.RLock()
+		remaining = nil
+		for _, tx := range m.transactions {
+			remaining = append(remaining, tx.Hash)
+		}
+		m.RUnlock()
+		if fmt.Sprint(remaining) == fmt.Sprint(expected) {
+			return
+		}
+	}
+	t.Fatalf(
+		"did not get expected transactions\n  got:    %v\n  wanted: %v",
+		remaining,
+		expected,
+	)
+}
+
+func TestAddTransactionCapacity(t *testing.T) {
+	txs := []lcommon.Transaction{
+		newTestTx(t, []testTxInput{{TxId: testTxId(1), Index: 0}}, 200_000, 0),
+		newTestTx(t, []testTxInput{{TxId: testTxId(2), Index: 0}}, 200_000, 0),
+		newTestTx(t, []testTxInput{{TxId: testTxId(3), Index: 0}}, 200_000, 0),
+	}
+	testDefs := []struct {
+		name     string
+		maxTxs   uint
+		maxBytes uint64
+	}{
+		{
+			name:   "transaction count",
+			maxTxs: 2,
+		},
+		{
+			name:     "transaction bytes",
+			maxBytes: uint64(len(txs[0].Cbor()) + len(txs[1].Cbor())),
+		},
+	}
+	for _, testDef := range testDefs {
+		m := NewMempool(
+			MempoolConfig{
+				EventBus:     event.NewEventBus(nil),
+				PromRegistry: prometheus.NewRegistry(),
+				MaxTxs:       testDef.maxTxs,
+				MaxBytes:     testDef.maxBytes,
+			},
+		)
+		for _, tx := range txs[0:2] {
+			if err := m.AddTransaction(ledger.TxTypeConway, tx.Cbor()); err != nil {
+				t.Fatalf("%s: unexpected error: %s", testDef.name, err)
+			}
+		}
+		if err := m.AddTransaction(ledger.TxTypeConway, txs[2].Cbor()); !errors.Is(err, ErrMempoolFull) {
+			t.Fatalf("%s: did not get expected error: got %v", testDef.name, err)
+		}
+		// Re-adding a pending TX only updates its last seen time
+		if err := m.AddTransaction(ledger.TxTypeConway, txs[0].Cbor()); err != nil {
+			t.Fatalf("%s: unexpected error: %s", testDef.name, err)
+		}
+		if len(m.Transactions()) != 2 {
+			t.Fatalf("%s: did not get expected transaction count: got %d, wanted %d", testDef.name, len(m.Transactions()), 2)
+		}
+		_ = m.Stop()
+	}
+}
+
+func TestExpireTransactions(t *testing.T) {
+	staleTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(1), Index: 0}},
+		200_000,
+		0,
+	)
+	staleChildTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: staleTx.Produced()[0].Id.Id().Bytes(), Index: 0}},
+		200_000,
+		0,
+	)
+	freshTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(2), Index: 0}},
+		200_000,
+		0,
+	)
+	m := newTestMempool(
+		[]lcommon.Transaction{staleTx, staleChildTx, freshTx},
+	)
+	defer func() {
+		_ = m.Stop()
+	}()
+	m.config.TxTtl = time.Hour
+	// The stale TX hasn't been seen within the TTL, but its child was seen recently
+	m.txsByHash[staleTx.Hash()].LastSeen = time.Now().Add(-2 * time.Hour)
+	m.Lock()
+	m.expireTransactions()
+	m.Unlock()
+	var remaining []string
+	for _, tx := range m.transactions {
+		remaining = append(remaining, tx.Hash)
+	}
+	// The child TX can't be valid without the expired TX
+	expected := []string{freshTx.Hash()}
+	if fmt.Sprint(remaining) != fmt.Sprint(expected) {
+		t.Fatalf(
+			"did not get expected transactions\n  got:    %v\n  wanted: %v",
+			remaining,
+			expected,
+		)
+	}
+	if m.transactionBytes != uint64(len(freshTx.Cbor())) {
+		t.Fatalf(
+			"did not get expected mempool size: got %d, wanted %d",
+			m.transactionBytes,
+			len(freshTx.Cbor()),
+		)
+	}
+}
+
+// benchmarkTxs returns independent transactions spending distinct on-chain UTxOs, with varying fees
+func benchmarkTxs(b *testing.B, count int) []lcommon.Transaction {
+	ret := make([]lcommon.Transaction, 0, count)
+	for i := range count {
+		ret = append(
+			ret,
+			newTestTx(
+				b,
+				[]testTxInput{{TxId: testTxId(uint64(i)), Index: 0}},
+				uint64(170_000+(i*7919)%100_000),
+				0,
+			),
+		)
+	}
+	return ret
+}
+
+func BenchmarkProcessChainBlock(b *testing.B) {
+	txs := benchmarkTxs(b, benchmarkMempoolSize)
+	// The block includes 100 mempool transactions and 100 transactions that conflict with mempool transactions
+	var blockTxs []lcommon.Transaction
+	for i := range 100 {
+		blockTxs = append(blockTxs, txs[i*50])
+		blockTxs = append(
+			blockTxs,
+			newTestTx(
+				b,
+				[]testTxInput{{TxId: testTxId(uint64(i*50 + 25)), Index: 0}},
+				500_000,
+				0,
+			),
+		)
+	}
+	b.ResetTimer()
+	for range b.N {
+		b.StopTimer()
+		m := newTestMempool(txs)
+		// Don't count garbage collection from setup
+		runtime.GC()
+		b.StartTimer()
+		m.Lock()
+		m.consumersMutex.Lock()
+		m.processChainBlock(1000, blockTxs)
+		m.consumersMutex.Unlock()
+		m.Unlock()
+		b.StopTimer()
+		if len(m.transactions) != benchmarkMempoolSize-200 {
+			b.Fatalf(
+				"did not get expected mempool size: got %d, wanted %d",
+				len(m.transactions),
+				benchmarkMempoolSize-200,
+			)
+		}
+		_ = m.Stop()
+		b.StartTimer()
+	}
+}
+
+func BenchmarkOrderedTransactionsFeePerByte(b *testing.B) {
+	m := newTestMempool(benchmarkTxs(b, benchmarkMempoolSize))
+	defer func() {
+		_ = m.Stop()
+	}()
+	m.ordering = FeePerByteOrdering{}
+	b.ResetTimer()
+	for range b.N {
+		m.ordered = nil
+		if len(m.orderedTransactions()) != benchmarkMempoolSize {
+			b.Fatal("did not get expected transaction count")
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mempool
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// OrderingStrategy determines the order in which mempool transactions are offered to peers and selected for new
+// blocks. Regardless of the strategy, a transaction that spends the outputs of other pending transactions always
+// comes after them
+type OrderingStrategy interface {
+	// Less reports whether transaction a should come before transaction b. Transactions that compare equal keep
+	// their arrival order
+	Less(a, b *MempoolTransaction) bool
+}
+
+// FifoOrdering orders transactions by arrival. This is the default
+type FifoOrdering struct{}
+
+func (FifoOrdering) Less(a, b *MempoolTransaction) bool {
+	return false
+}
+
+// FeePerByteOrdering orders transactions by fee per byte, highest first
+type FeePerByteOrdering struct{}
+
+func (FeePerByteOrdering) Less(a, b *MempoolTransaction) bool {
+	return a.feePerByte() > b.feePerByte()
+}
+
+var orderingStrategyNames = map[string]OrderingStrategy{
+	"fifo":         FifoOrdering{},
+	"fee-per-byte": FeePerByteOrdering{},
+}
+
+// ParseOrderingStrategy returns the built-in OrderingStrategy for the specified name ("fifo" or "fee-per-byte").
+// An empty name returns the default strategy
+func ParseOrderingStrategy(name string) (OrderingStrategy, error) {
+	if name == "" {
+		return FifoOrdering{}, nil
+	}
+	for strategyName, strategy := range orderingStrategyNames {
+		if strings.EqualFold(name, strategyName) {
+			return strategy, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown mempool ordering strategy: %s", name)
+}
+
+// Fee returns the fee for the transaction in lovelace
+func (t *MempoolTransaction) Fee() uint64 {
+	if t.tx == nil {
+		return 0
+	}
+	return t.tx.Fee()
+}
+
+func (t *MempoolTransaction) feePerByte() float64 {
+	if len(t.Cbor) == 0 {
+		return 0
+	}
+	return float64(t.Fee()) / float64(len(t.Cbor))
+}
+
+// orderedTransactions returns the transactions in the order from the configured strategy, with any transactions
+// that spend the outputs of other pending transactions moved after them. The result is cached until the
+// transactions change. The caller must hold the mempool lock (read or write)
+func (m *Mempool) orderedTransactions() []*MempoolTransaction {
+	m.orderedMutex.Lock()
+	defer m.orderedMutex.Unlock()
+	if m.ordered != nil {
+		return m.ordered
+	}
+	sorted := slices.Clone(m.transactions)
+	slices.SortStableFunc(
+		sorted,
+		func(a, b *MempoolTransaction) int {
+			switch {
+			case m.ordering.Less(a, b):
+				return -1
+			case m.ordering.Less(b, a):
+				return 1
+			}
+			return 0
+		},
+	)
+	txsByHash := make(map[string]*MempoolTransaction, len(sorted))
+	for _, tx := range sorted {
+		txsByHash[tx.Hash] = tx
+	}
+	ret := make([]*MempoolTransaction, 0, len(sorted))
+	added := make(map[string]bool, len(sorted))
+	var addTx func(*MempoolTransaction)
+	addTx = func(tx *MempoolTransaction) {
+		if added[tx.Hash] {
+			return
+		}
+		added[tx.Hash] = true
+		// Add any pending transactions that produce our inputs first
+		if tx.tx != nil {
+			for _, input := range tx.tx.Consumed() {
+				if parentTx, ok := txsByHash[input.Id().String()]; ok {
+					addTx(parentTx)
+				}
+			}
+		}
+		ret = append(ret, tx)
+	}
+	for _, tx := range sorted {
+		addTx(tx)
+	}
+	m.ordered = ret
+	return ret
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mempool
+
+import (
+	"fmt"
+	"testing"
+
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+func TestParseOrderingStrategy(t *testing.T) {
+	testDefs := []struct {
+		name      string
+		expected  OrderingStrategy
+		expectErr bool
+	}{
+		{name: "", expected: FifoOrdering{}},
+		{name: "fifo", expected: FifoOrdering{}},
+		{name: "Fee-Per-Byte", expected: FeePerByteOrdering{}},
+		{name: "highest-fee", expectErr: true},
+	}
+	for _, testDef := range testDefs {
+		strategy, err := ParseOrderingStrategy(testDef.name)
+		if testDef.expectErr {
+			if err == nil {
+				t.Fatalf("did not get expected error for %q", testDef.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if strategy != testDef.expected {
+			t.Fatalf("did not get expected strategy for %q: got %T, expected %T", testDef.name, strategy, testDef.expected)
+		}
+	}
+}
+
+func TestOrderedTransactions(t *testing.T) {
+	lowFeeTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(1), Index: 0}},
+		200_000,
+		0,
+	)
+	highFeeTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(2), Index: 0}},
+		500_000,
+		0,
+	)
+	// This has the highest fee, but it spends the output of the low fee TX
+	childTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: lowFeeTx.Produced()[0].Id.Id().Bytes(), Index: 0}},
+		900_000,
+		0,
+	)
+	midFeeTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(3), Index: 0}},
+		300_000,
+		0,
+	)
+	testDefs := []struct {
+		ordering OrderingStrategy
+		expected []string
+	}{
+		{
+			ordering: FifoOrdering{},
+			expected: []string{lowFeeTx.Hash(), highFeeTx.Hash(), childTx.Hash(), midFeeTx.Hash()},
+		},
+		{
+			ordering: FeePerByteOrdering{},
+			expected: []string{lowFeeTx.Hash(), childTx.Hash(), highFeeTx.Hash(), midFeeTx.Hash()},
+		},
+	}
+	for _, testDef := range testDefs {
+		m := newTestMempool(
+			[]lcommon.Transaction{lowFeeTx, highFeeTx, childTx, midFeeTx},
+		)
+		m.ordering = testDef.ordering
+		var ordered []string
+		for _, tx := range m.Transactions() {
+			ordered = append(ordered, tx.Hash)
+		}
+		_ = m.Stop()
+		if fmt.Sprint(ordered) != fmt.Sprint(testDef.expected) {
+			t.Fatalf(
+				"did not get expected order for %T\n  got:    %v\n  wanted: %v",
+				testDef.ordering,
+				ordered,
+				testDef.expected,
+			)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mempool
+
+import (
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+// UtxoOverlay tracks the UTxOs consumed and produced by a set of pending transactions. It's used with
+// state.LedgerState.ValidateTxWithOverlay to validate transactions that spend the outputs of other pending
+// transactions, and to detect pending transactions that spend the same inputs
+type UtxoOverlay struct {
+	// Hash of the pending transaction consuming each UTxO
+	consumed map[string]string
+	// UTxOs produced by pending transactions
+	produced map[string]lcommon.Utxo
+}
+
+// NewUtxoOverlay returns a new, empty UtxoOverlay
+func NewUtxoOverlay() *UtxoOverlay {
+	return &UtxoOverlay{
+		consumed: make(map[string]string),
+		produced: make(map[string]lcommon.Utxo),
+	}
+}
+
+// ConsumedBy returns the hash of the pending transaction that consumes the specified UTxO, if any
+func (o *UtxoOverlay) ConsumedBy(
+	input lcommon.TransactionInput,
+) (string, bool) {
+	ret, ok := o.consumed[input.String()]
+	return ret, ok
+}
+
+// ProducedUtxo returns the specified UTxO if it's produced by a pending transaction
+func (o *UtxoOverlay) ProducedUtxo(
+	input lcommon.TransactionInput,
+) (lcommon.Utxo, bool) {
+	ret, ok := o.produced[input.String()]
+	return ret, ok
+}
+
+// Conflict returns the hash of a pending transaction that consumes any of the same UTxOs as the specified
+// transaction, if any
+func (o *UtxoOverlay) Conflict(tx lcommon.Transaction) (string, bool) {
+	for _, input := range tx.Consumed() {
+		if txHash, ok := o.consumed[input.String()]; ok &&
+			txHash != tx.Hash() {
+			return txHash, true
+		}
+	}
+	return "", false
+}
+
+// Dependents returns the hashes of the pending transactions that consume UTxOs produced by the specified
+// transaction
+func (o *UtxoOverlay) Dependents(tx lcommon.Transaction) []string {
+	var ret []string
+	for _, utxo := range tx.Produced() {
+		if txHash, ok := o.consumed[utxo.Id.String()]; ok {
+			ret = append(ret, txHash)
+		}
+	}
+	return ret
+}
+
+// Add records the UTxOs consumed and produced by a pending transaction
+func (o *UtxoOverlay) Add(tx lcommon.Transaction) {
+	txHash := tx.Hash()
+	for _, input := range tx.Consumed() {
+		o.consumed[input.String()] = txHash
+	}
+	for _, utxo := range tx.Produced() {
+		o.produced[utxo.Id.String()] = utxo
+	}
+}
+
+// Remove removes the UTxOs consumed and produced by a transaction that's no longer pending
+func (o *UtxoOverlay) Remove(tx lcommon.Transaction) {
+	txHash := tx.Hash()
+	for _, input := range tx.Consumed() {
+		key := input.String()
+		if o.consumed[key] == txHash {
+			delete(o.consumed, key)
+		}
+	}
+	for _, utxo := range tx.Produced() {
+		delete(o.produced, utxo.Id.String())
+	}
+}
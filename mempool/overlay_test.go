@@ -0,0 +1,130 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mempool
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+func TestUtxoOverlay(t *testing.T) {
+	parentTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(1), Index: 0}},
+		200_000,
+		0,
+	)
+	childTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: parentTx.Produced()[0].Id.Id().Bytes(), Index: 0}},
+		200_000,
+		0,
+	)
+	conflictTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(1), Index: 0}},
+		300_000,
+		0,
+	)
+	overlay := NewUtxoOverlay()
+	overlay.Add(parentTx)
+	overlay.Add(childTx)
+	// A TX spending the same input as a pending TX conflicts with it, but a pending TX doesn't conflict with itself
+	if txHash, ok := overlay.Conflict(conflictTx); !ok || txHash != parentTx.Hash() {
+		t.Fatalf("did not get expected conflict: got %q, %v", txHash, ok)
+	}
+	if txHash, ok := overlay.Conflict(parentTx); ok {
+		t.Fatalf("pending TX conflicts with %s", txHash)
+	}
+	if _, ok := overlay.ProducedUtxo(childTx.Consumed()[0]); !ok {
+		t.Fatalf("pending TX outputs missing from overlay")
+	}
+	if dependents := overlay.Dependents(parentTx); fmt.Sprint(dependents) != fmt.Sprint([]string{childTx.Hash()}) {
+		t.Fatalf("did not get expected dependents: got %v", dependents)
+	}
+	// Removing a TX that isn't pending doesn't remove the inputs of the pending TX that spends them
+	overlay.Remove(conflictTx)
+	if txHash, ok := overlay.ConsumedBy(parentTx.Consumed()[0]); !ok || txHash != parentTx.Hash() {
+		t.Fatalf("pending TX inputs missing from overlay")
+	}
+	overlay.Remove(parentTx)
+	if _, ok := overlay.ConsumedBy(parentTx.Consumed()[0]); ok {
+		t.Fatalf("removed TX inputs still in overlay")
+	}
+	if _, ok := overlay.ProducedUtxo(childTx.Consumed()[0]); ok {
+		t.Fatalf("removed TX outputs still in overlay")
+	}
+	if _, ok := overlay.Conflict(conflictTx); ok {
+		t.Fatalf("did not expect conflict after removing pending TX")
+	}
+}
+
+func TestAddTransactionConflict(t *testing.T) {
+	parentTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(1), Index: 0}},
+		200_000,
+		0,
+	)
+	childTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: parentTx.Produced()[0].Id.Id().Bytes(), Index: 0}},
+		200_000,
+		0,
+	)
+	conflictTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(1), Index: 0}},
+		300_000,
+		0,
+	)
+	m := newTestMempool([]lcommon.Transaction{parentTx})
+	defer func() {
+		_ = m.Stop()
+	}()
+	// A TX spending the outputs of a pending TX is accepted
+	if err := m.AddTransaction(ledger.TxTypeConway, childTx.Cbor()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// A TX spending the same input as a pending TX is rejected
+	if err := m.AddTransaction(ledger.TxTypeConway, conflictTx.Cbor()); !errors.Is(err, ErrTxConflict) {
+		t.Fatalf("did not get expected error: got %v", err)
+	}
+	var remaining []string
+	for _, tx := range m.Transactions() {
+		remaining = append(remaining, tx.Hash)
+	}
+	expected := []string{parentTx.Hash(), childTx.Hash()}
+	if fmt.Sprint(remaining) != fmt.Sprint(expected) {
+		t.Fatalf(
+			"did not get expected transactions\n  got:    %v\n  wanted: %v",
+			remaining,
+			expected,
+		)
+	}
+	// Removing the parent TX leaves the child TX pending
+	m.RemoveTransaction(parentTx.Hash())
+	if _, ok := m.GetTransaction(childTx.Hash()); !ok {
+		t.Fatalf("child TX was removed along with its parent")
+	}
+	// The input spent by the removed TX is free again
+	if err := m.AddTransaction(ledger.TxTypeConway, conflictTx.Cbor()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
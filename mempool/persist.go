@@ -0,0 +1,118 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mempool
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+)
+
+// persistedTransaction is the on-disk representation of a mempool transaction
+type persistedTransaction struct {
+	cbor.StructAsArray
+	Type     uint
+	Cbor     []byte
+	LastSeen int64
+}
+
+// load restores transactions saved by a previous run. Transactions are validated against the current ledger
+// state, and any that are no longer valid are dropped
+func (m *Mempool) load() {
+	data, err := os.ReadFile(m.config.PersistPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			m.logger.Error(
+				"failed to read saved mempool",
+				"component", "mempool",
+				"error", err,
+			)
+		}
+		return
+	}
+	var tmpTxs []persistedTransaction
+	if _, err := cbor.Decode(data, &tmpTxs); err != nil {
+		m.logger.Error(
+			"failed to decode saved mempool",
+			"component", "mempool",
+			"error", err,
+		)
+		return
+	}
+	var count int
+	for _, tmpTx := range tmpTxs {
+		lastSeen := time.UnixMilli(tmpTx.LastSeen)
+		if m.config.TxTtl > 0 && time.Since(lastSeen) >= m.config.TxTtl {
+			continue
+		}
+		if err := m.addTransaction(tmpTx.Type, tmpTx.Cbor, lastSeen); err != nil {
+			m.logger.Debug(
+				"dropped saved transaction",
+				"component", "mempool",
+				"error", err,
+			)
+			continue
+		}
+		count++
+	}
+	m.logger.Info(
+		fmt.Sprintf(
+			"restored %d of %d saved transactions",
+			count,
+			len(tmpTxs),
+		),
+		"component", "mempool",
+	)
+}
+
+// save writes the mempool contents to disk if they have changed since the last save. The caller must hold
+// the mempool lock
+func (m *Mempool) save() error {
+	if m.config.PersistPath == "" || !m.dirty {
+		return nil
+	}
+	tmpTxs := make([]persistedTransaction, 0, len(m.transactions))
+	for _, tx := range m.transactions {
+		tmpTxs = append(
+			tmpTxs,
+			persistedTransaction{
+				Type:     tx.Type,
+				Cbor:     tx.Cbor,
+				LastSeen: tx.LastSeen.UnixMilli(),
+			},
+		)
+	}
+	data, err := cbor.Encode(tmpTxs)
+	if err != nil {
+		return err
+	}
+	// Write to a temp file and rename it, so that a crash doesn't leave a partial file
+	if err := os.MkdirAll(filepath.Dir(m.config.PersistPath), 0o755); err != nil {
+		return err
+	}
+	tmpPath := m.config.PersistPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, m.config.PersistPath); err != nil {
+		return err
+	}
+	m.dirty = false
+	return nil
+}
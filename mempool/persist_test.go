@@ -0,0 +1,93 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mempool
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/dingo/event"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPersistence(t *testing.T) {
+	persistPath := filepath.Join(t.TempDir(), "mempool", "mempool.cbor")
+	newMempool := func() *Mempool {
+		return NewMempool(
+			MempoolConfig{
+				EventBus:     event.NewEventBus(nil),
+				PromRegistry: prometheus.NewRegistry(),
+				TxTtl:        time.Hour,
+				PersistPath:  persistPath,
+			},
+		)
+	}
+	parentTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(1), Index: 0}},
+		200_000,
+		0,
+	)
+	childTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: parentTx.Produced()[0].Id.Id().Bytes(), Index: 0}},
+		200_000,
+		0,
+	)
+	staleTx := newTestTx(
+		t,
+		[]testTxInput{{TxId: testTxId(2), Index: 0}},
+		200_000,
+		0,
+	)
+	lastSeen := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+	m := newMempool()
+	for _, tx := range []lcommon.Transaction{parentTx, childTx} {
+		if err := m.addTransaction(ledger.TxTypeConway, tx.Cbor(), lastSeen); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	// This TX expires before the mempool is restored
+	if err := m.addTransaction(ledger.TxTypeConway, staleTx.Cbor(), time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := m.Stop(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Restore the saved transactions in their original order
+	m = newMempool()
+	defer func() {
+		_ = m.Stop()
+	}()
+	var remaining []string
+	for _, tx := range m.Transactions() {
+		remaining = append(remaining, tx.Hash)
+		if !tx.LastSeen.Equal(lastSeen) {
+			t.Fatalf("did not get expected last seen time: got %s, wanted %s", tx.LastSeen, lastSeen)
+		}
+	}
+	expected := []string{parentTx.Hash(), childTx.Hash()}
+	if fmt.Sprint(remaining) != fmt.Sprint(expected) {
+		t.Fatalf(
+			"did not get expected transactions\n  got:    %v\n  wanted: %v",
+			remaining,
+			expected,
+		)
+	}
+}
@@ -18,11 +18,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"slices"
 
 	"github.com/blinklabs-io/dingo/chainsync"
 	"github.com/blinklabs-io/dingo/connmanager"
 	"github.com/blinklabs-io/dingo/event"
+	"github.com/blinklabs-io/dingo/forge"
 	"github.com/blinklabs-io/dingo/mempool"
 	"github.com/blinklabs-io/dingo/peergov"
 	"github.com/blinklabs-io/dingo/state"
@@ -37,6 +39,10 @@ import (
 	otxsubmission "github.com/blinklabs-io/gouroboros/protocol/txsubmission"
 )
 
+const (
+	mempoolPersistFile = "mempool.cbor"
+)
+
 type Node struct {
 	config         Config
 	connManager    *connmanager.ConnectionManager
@@ -46,6 +52,7 @@ type Node struct {
 	mempool        *mempool.Mempool
 	ledgerState    *state.LedgerState
 	utxorpc        *utxorpc.Utxorpc
+	forger         *forge.Forger
 	shutdownFuncs  []func(context.Context) error
 }
 
@@ -75,11 +82,15 @@ func (n *Node) Run() error {
 	state, err := state.NewLedgerState(
 		state.LedgerStateConfig{
 			DataDir:                    n.config.dataDir,
+			BlobPlugin:                 n.config.blobPlugin,
+			MetadataPlugin:             n.config.metadataPlugin,
 			EventBus:                   n.eventBus,
 			Logger:                     n.config.logger,
 			CardanoNodeConfig:          n.config.cardanoNodeConfig,
 			PromRegistry:               n.config.promRegistry,
+			ValidationLevel:            n.config.validationLevel,
 			BlockfetchRequestRangeFunc: n.blockfetchClientRequestRange,
+			ConnectionCloseFunc:        n.closeConnection,
 		},
 	)
 	if err != nil {
@@ -87,11 +98,22 @@ func (n *Node) Run() error {
 	}
 	n.ledgerState = state
 	// Initialize mempool
+	var mempoolPersistPath string
+	if n.config.mempoolPersist && n.config.dataDir != "" {
+		mempoolPersistPath = filepath.Join(n.config.dataDir, mempoolPersistFile)
+	}
 	n.mempool = mempool.NewMempool(
-		n.config.logger,
-		n.eventBus,
-		n.config.promRegistry,
-		n.ledgerState,
+		mempool.MempoolConfig{
+			Logger:       n.config.logger,
+			EventBus:     n.eventBus,
+			PromRegistry: n.config.promRegistry,
+			LedgerState:  n.ledgerState,
+			MaxBytes:     n.config.mempoolMaxBytes,
+			MaxTxs:       n.config.mempoolMaxTxs,
+			TxTtl:        n.config.mempoolTxTtl,
+			PersistPath:  mempoolPersistPath,
+			Ordering:     n.config.mempoolOrdering,
+		},
 	)
 	// Initialize chainsync state
 	n.chainsyncState = chainsync.NewState(
@@ -120,7 +142,24 @@ func (n *Node) Run() error {
 	if err := n.peerGov.Start(); err != nil {
 		return err
 	}
-	// Configure UTxO RPC
+	// Start block production
+	if n.config.shelleyOpCert != "" {
+		n.forger = forge.NewForger(
+			forge.ForgerConfig{
+				Logger:            n.config.logger,
+				LedgerState:       n.ledgerState,
+				Mempool:           n.mempool,
+				CardanoNodeConfig: n.config.cardanoNodeConfig,
+				VrfSigningKeyFile: n.config.shelleyVrfKey,
+				KesSigningKeyFile: n.config.shelleyKesKey,
+				OpCertFile:        n.config.shelleyOpCert,
+			},
+		)
+		if err := n.forger.Start(); err != nil {
+			return err
+		}
+	}
+	// Configure UTxO RPC. This blocks while serving requests
 	n.utxorpc = utxorpc.NewUtxorpc(
 		utxorpc.UtxorpcConfig{
 			Logger:      n.config.logger,
@@ -146,6 +185,14 @@ func (n *Node) Stop() error {
 func (n *Node) shutdown() error {
 	ctx := context.TODO()
 	var err error
+	// Stop block production
+	if n.forger != nil {
+		n.forger.Stop()
+	}
+	// Save mempool
+	if n.mempool != nil {
+		err = errors.Join(err, n.mempool.Stop())
+	}
 	// Shutdown ledger
 	err = errors.Join(err, n.ledgerState.Close())
 	// Call shutdown functions
@@ -283,28 +330,43 @@ func (n *Node) handleConnClosedEvent(evt event.Event) {
 	n.chainsyncState.RemoveClient(connId)
 	// Remove mempool consumer
 	n.mempool.RemoveConsumer(connId)
-	// Release chainsync client
-	n.chainsyncState.RemoveClientConnId(connId)
+}
+
+// closeConnection is called by the ledger to disconnect a peer that provided an invalid block
+func (n *Node) closeConnection(connId ouroboros.ConnectionId, err error) {
+	conn := n.connManager.GetConnectionById(connId)
+	if conn == nil {
+		return
+	}
+	n.config.logger.Warn(
+		fmt.Sprintf(
+			"closing connection %s: %s",
+			connId.String(),
+			err,
+		),
+		"component", "node",
+	)
+	if err := conn.Close(); err != nil {
+		n.config.logger.Error(
+			"failed to close connection",
+			"error",
+			err,
+		)
+	}
 }
 
 func (n *Node) handleOutboundConnEvent(evt event.Event) {
 	e := evt.Data.(peergov.OutboundConnectionEvent)
 	connId := e.ConnectionId
-	// TODO: replace this with handling for multiple chainsync clients (#385)
-	// Start chainsync client if we don't have another
-	n.chainsyncState.Lock()
-	defer n.chainsyncState.Unlock()
-	chainsyncClientConnId := n.chainsyncState.GetClientConnId()
-	if chainsyncClientConnId == nil {
-		if err := n.chainsyncClientStart(connId); err != nil {
-			n.config.logger.Error(
-				"failed to start chainsync client",
-				"error",
-				err,
-			)
-			return
-		}
-		n.chainsyncState.SetClientConnId(connId)
+	// Start chainsync client. The ledger state tracks the chain from each upstream
+	// peer and selects the best one
+	if err := n.chainsyncClientStart(connId); err != nil {
+		n.config.logger.Error(
+			"failed to start chainsync client",
+			"error",
+			err,
+		)
+		return
 	}
 	// Start txsubmission client
 	if err := n.txsubmissionClientStart(connId); err != nil {
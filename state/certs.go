@@ -83,6 +83,136 @@ func (ls *LedgerState) processTransactionCertificates(
 			if err != nil {
 				return err
 			}
+		case *lcommon.RegistrationCertificate:
+			err := txn.DB().SetRegistration(
+				cert,
+				blockPoint.Slot,
+				certDeposit,
+				txn,
+			)
+			if err != nil {
+				return err
+			}
+		case *lcommon.DeregistrationCertificate:
+			err := txn.DB().SetDeregistration(
+				cert,
+				blockPoint.Slot,
+				txn,
+			)
+			if err != nil {
+				return err
+			}
+		case *lcommon.VoteDelegationCertificate:
+			err := txn.DB().SetVoteDelegation(
+				cert,
+				blockPoint.Slot,
+				txn,
+			)
+			if err != nil {
+				return err
+			}
+		case *lcommon.StakeVoteDelegationCertificate:
+			err := txn.DB().SetStakeVoteDelegation(
+				cert,
+				blockPoint.Slot,
+				txn,
+			)
+			if err != nil {
+				return err
+			}
+		case *lcommon.StakeRegistrationDelegationCertificate:
+			err := txn.DB().SetStakeRegistrationDelegation(
+				cert,
+				blockPoint.Slot,
+				certDeposit,
+				txn,
+			)
+			if err != nil {
+				return err
+			}
+		case *lcommon.VoteRegistrationDelegationCertificate:
+			err := txn.DB().SetVoteRegistrationDelegation(
+				cert,
+				blockPoint.Slot,
+				certDeposit,
+				txn,
+			)
+			if err != nil {
+				return err
+			}
+		case *lcommon.StakeVoteRegistrationDelegationCertificate:
+			err := txn.DB().SetStakeVoteRegistrationDelegation(
+				cert,
+				blockPoint.Slot,
+				certDeposit,
+				txn,
+			)
+			if err != nil {
+				return err
+			}
+		case *lcommon.AuthCommitteeHotCertificate:
+			err := txn.DB().SetAuthCommitteeHot(
+				cert,
+				blockPoint.Slot,
+				txn,
+			)
+			if err != nil {
+				return err
+			}
+		case *lcommon.ResignCommitteeColdCertificate:
+			err := txn.DB().SetResignCommitteeCold(
+				cert,
+				blockPoint.Slot,
+				txn,
+			)
+			if err != nil {
+				return err
+			}
+		case *lcommon.RegistrationDrepCertificate:
+			err := txn.DB().SetRegistrationDrep(
+				cert,
+				blockPoint.Slot,
+				certDeposit,
+				txn,
+			)
+			if err != nil {
+				return err
+			}
+		case *lcommon.DeregistrationDrepCertificate:
+			// The deposit refund is included in the certificate
+			err := txn.DB().SetDeregistrationDrep(
+				cert,
+				blockPoint.Slot,
+				uint64(cert.Amount), // #nosec G115
+				txn,
+			)
+			if err != nil {
+				return err
+			}
+		case *lcommon.UpdateDrepCertificate:
+			err := txn.DB().SetUpdateDrep(
+				cert,
+				blockPoint.Slot,
+				txn,
+			)
+			if err != nil {
+				return err
+			}
+		case *lcommon.GenesisKeyDelegationCertificate:
+			// The new genesis delegate takes over after the stability window
+			stabilityWindow, err := ls.nonceStabilityWindow()
+			if err != nil {
+				return err
+			}
+			err = txn.DB().SetGenesisKeyDelegation(
+				cert,
+				blockPoint.Slot,
+				blockPoint.Slot+stabilityWindow,
+				txn,
+			)
+			if err != nil {
+				return err
+			}
 		default:
 			ls.config.Logger.Warn(
 				fmt.Sprintf("ignoring unsupported certificate type %T", cert),
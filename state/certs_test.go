@@ -0,0 +1,301 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+// testCertTx is a transaction that only provides certificates
+type testCertTx struct {
+	lcommon.Transaction
+	certs []lcommon.Certificate
+}
+
+func (t testCertTx) Certificates() []lcommon.Certificate {
+	return t.certs
+}
+
+// testCredential returns a key hash credential for use in tests
+func testCredential(id byte) lcommon.StakeCredential {
+	return lcommon.StakeCredential{
+		CredType:   lcommon.StakeCredentialTypeAddrKeyHash,
+		Credential: testKeyHash(id),
+	}
+}
+
+// testConwayCertState contains the parts of the ledger state affected by Conway certificates
+type testConwayCertState struct {
+	drepRegistered bool
+	stakeKeys      int
+	voteDelegs     int
+	hotKey         []byte
+}
+
+func testGetConwayCertState(t *testing.T, ls *LedgerState) testConwayCertState {
+	t.Helper()
+	var ret testConwayCertState
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		drepExpiries, err := ls.drepExpiries(txn, 1)
+		if err != nil {
+			return err
+		}
+		_, ret.drepRegistered = drepExpiries[string(testKeyHash(20))]
+		stakingKeys, err := ls.registeredStakingKeys(txn)
+		if err != nil {
+			return err
+		}
+		ret.stakeKeys = len(stakingKeys)
+		var voteDelegs []models.VoteDelegation
+		if result := txn.Metadata().Find(&voteDelegs); result.Error != nil {
+			return result.Error
+		}
+		ret.voteDelegs = len(voteDelegs)
+		hotKeys, err := ls.committeeHotKeys(txn)
+		if err != nil {
+			return err
+		}
+		ret.hotKey = hotKeys[string(testKeyHash(40))]
+		return nil
+	})
+	return ret
+}
+
+func TestProcessTransactionCertificatesConway(t *testing.T) {
+	ls := testConwayLedgerState(t)
+	drep := lcommon.Drep{
+		Type:       lcommon.DrepTypeAddrKeyHash,
+		Credential: testKeyHash(20),
+	}
+	txs := []struct {
+		slot  uint64
+		certs []lcommon.Certificate
+	}{
+		{
+			slot: 110,
+			certs: []lcommon.Certificate{
+				&lcommon.RegistrationDrepCertificate{
+					CertType:       lcommon.CertificateTypeRegistrationDrep,
+					DrepCredential: testCredential(20),
+					Amount:         500000000,
+				},
+				&lcommon.RegistrationCertificate{
+					CertType:        lcommon.CertificateTypeRegistration,
+					StakeCredential: testCredential(30),
+					Amount:          2000000,
+				},
+				&lcommon.VoteDelegationCertificate{
+					CertType:        lcommon.CertificateTypeVoteDelegation,
+					StakeCredential: testCredential(30),
+					Drep:            drep,
+				},
+				&lcommon.StakeVoteRegistrationDelegationCertificate{
+					CertType:        lcommon.CertificateTypeStakeVoteRegistrationDelegation,
+					StakeCredential: testCredential(31),
+					PoolKeyHash:     testKeyHash(1),
+					Drep:            drep,
+					Amount:          2000000,
+				},
+				&lcommon.AuthCommitteeHotCertificate{
+					CertType:       lcommon.CertificateTypeAuthCommitteeHot,
+					ColdCredential: testCredential(40),
+					HostCredential: testCredential(41),
+				},
+				&lcommon.UpdateDrepCertificate{
+					CertType:       lcommon.CertificateTypeUpdateDrep,
+					DrepCredential: testCredential(20),
+				},
+			},
+		},
+		{
+			slot: 120,
+			certs: []lcommon.Certificate{
+				&lcommon.DeregistrationDrepCertificate{
+					CertType:       lcommon.CertificateTypeDeregistrationDrep,
+					DrepCredential: testCredential(20),
+					Amount:         500000000,
+				},
+				&lcommon.DeregistrationCertificate{
+					CertType:        lcommon.CertificateTypeDeregistration,
+					StakeCredential: testCredential(30),
+					Amount:          2000000,
+				},
+				&lcommon.ResignCommitteeColdCertificate{
+					CertType:       lcommon.CertificateTypeResignCommitteeCold,
+					ColdCredential: testCredential(40),
+				},
+			},
+		},
+	}
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		testAddEpoch(t, ls, txn, 0)
+		testAddEpoch(t, ls, txn, 1)
+		for _, tx := range txs {
+			err := ls.processTransactionCertificates(
+				txn,
+				ocommon.Point{Slot: tx.slot},
+				testCertTx{certs: tx.certs},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	testDefs := []struct {
+		rollbackSlot uint64
+		expected     testConwayCertState
+	}{
+		// After both transactions
+		{
+			rollbackSlot: 120,
+			expected: testConwayCertState{
+				stakeKeys:  1,
+				voteDelegs: 2,
+			},
+		},
+		// Rolling back the deregistrations and resignation restores the first transaction
+		{
+			rollbackSlot: 110,
+			expected: testConwayCertState{
+				drepRegistered: true,
+				stakeKeys:      2,
+				voteDelegs:     2,
+				hotKey:         testKeyHash(41),
+			},
+		},
+		// Rolling back the first transaction removes everything
+		{
+			rollbackSlot: 100,
+			expected:     testConwayCertState{},
+		},
+	}
+	for _, testDef := range testDefs {
+		testLedgerTxn(t, ls, func(txn *database.Txn) error {
+			return ls.rollbackLedgerRecords(txn, testDef.rollbackSlot)
+		})
+		state := testGetConwayCertState(t, ls)
+		if state.drepRegistered != testDef.expected.drepRegistered ||
+			state.stakeKeys != testDef.expected.stakeKeys ||
+			state.voteDelegs != testDef.expected.voteDelegs ||
+			!bytes.Equal(state.hotKey, testDef.expected.hotKey) {
+			t.Fatalf(
+				"did not get expected state after rollback to slot %d: got %+v, expected %+v",
+				testDef.rollbackSlot,
+				state,
+				testDef.expected,
+			)
+		}
+	}
+}
+
+func TestGenesisKeyDelegationOverlaySlotDelegate(t *testing.T) {
+	ls := newTestLedgerState(t)
+	testHardFork(t, ls, 1)
+	// The genesis key that's assigned the first active overlay slot in each round, and its delegate from the
+	// genesis config
+	genesisKey := "12b0f443d02861948a0fce9541916b014e8402984c7b83ad70a834ce"
+	genesisDelegateFromConfig := genesisDelegate{
+		delegate: "7c54a168c731f2f44ced620f3cca7c2bd90731cab223d5167aa994e6",
+		vrf:      "62d546a35e1be66a2b06e29558ef33f4222f1c466adbb59b52d800964d4e60ec",
+	}
+	genesisHash, err := hex.DecodeString(genesisKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	newVrfKeyHash := bytes.Repeat([]byte{51}, 32)
+	genesisDelegateFromCert := genesisDelegate{
+		delegate: hex.EncodeToString(testKeyHash(50)),
+		vrf:      hex.EncodeToString(newVrfKeyHash),
+	}
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		testAddEpoch(t, ls, txn, 0)
+		return ls.processTransactionCertificates(
+			txn,
+			ocommon.Point{Slot: 100},
+			testCertTx{
+				certs: []lcommon.Certificate{
+					&lcommon.GenesisKeyDelegationCertificate{
+						CertType:            lcommon.CertificateTypeGenesisKeyDelegation,
+						GenesisHash:         genesisHash,
+						GenesisDelegateHash: testKeyHash(50),
+						VrfKeyHash:          lcommon.VrfKeyHash(newVrfKeyHash),
+					},
+				},
+			},
+		)
+	})
+	shelleyGenesis := ls.config.CardanoNodeConfig.ShelleyGenesis()
+	epochState := &praosEpochState{}
+	// With the test genesis config, every 20th slot is an active overlay slot and there are 7 genesis keys, so
+	// the genesis key above is assigned every 140th slot. The certificate becomes active 25920 slots after it's
+	// submitted
+	testDefs := []struct {
+		rollbackSlot uint64
+		slot         uint64
+		expected     genesisDelegate
+	}{
+		// Before the certificate is active
+		{
+			rollbackSlot: 100,
+			slot:         25900,
+			expected:     genesisDelegateFromConfig,
+		},
+		// After the certificate is active
+		{
+			rollbackSlot: 100,
+			slot:         26040,
+			expected:     genesisDelegateFromCert,
+		},
+		// Rolling back the certificate restores the delegate from the genesis config
+		{
+			rollbackSlot: 50,
+			slot:         26040,
+			expected:     genesisDelegateFromConfig,
+		},
+	}
+	for _, testDef := range testDefs {
+		var delegate *genesisDelegate
+		testLedgerTxn(t, ls, func(txn *database.Txn) error {
+			if err := ls.rollbackLedgerRecords(txn, testDef.rollbackSlot); err != nil {
+				return err
+			}
+			var err error
+			delegate, err = ls.overlaySlotDelegate(
+				txn,
+				&praosHeader{tpraos: true, slot: testDef.slot},
+				epochState,
+				shelleyGenesis,
+			)
+			return err
+		})
+		if delegate == nil || *delegate != testDef.expected {
+			t.Fatalf(
+				"did not get expected delegate for slot %d after rollback to slot %d: got %+v, expected %+v",
+				testDef.slot,
+				testDef.rollbackSlot,
+				delegate,
+				testDef.expected,
+			)
+		}
+	}
+}
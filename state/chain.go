@@ -18,6 +18,7 @@ import (
 	"errors"
 
 	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/gouroboros/ledger"
 	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
 	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
 )
@@ -28,6 +29,8 @@ type ChainIterator struct {
 	ls          *LedgerState
 	startPoint  ocommon.Point
 	blockNumber uint64
+	// Set when any Byron EBB following the previous block has already been returned
+	ebbDone bool
 }
 
 type ChainIteratorResult struct {
@@ -54,10 +57,19 @@ func newChainIterator(
 			}
 			return nil, err
 		}
-		ci.blockNumber = tmpBlock.Number
-		// Increment next block number if non-inclusive
-		if !inclusive {
-			ci.blockNumber++
+		if tmpBlock.Type == ledger.BlockTypeByronEbb && tmpBlock.Number > 0 {
+			// An EBB shares its block number with the block before it, so the next
+			// block will have the following number
+			ci.blockNumber = tmpBlock.Number + 1
+			ci.ebbDone = !inclusive
+		} else {
+			ci.blockNumber = tmpBlock.Number
+			ci.ebbDone = true
+			// Increment next block number if non-inclusive
+			if !inclusive {
+				ci.blockNumber++
+				ci.ebbDone = false
+			}
 		}
 	}
 	return ci, nil
@@ -82,13 +94,28 @@ func (ci *ChainIterator) Tip() (ochainsync.Tip, error) {
 func (ci *ChainIterator) Next(blocking bool) (*ChainIteratorResult, error) {
 	ci.ls.RLock()
 	ret := &ChainIteratorResult{}
+	// Check for a Byron EBB following the previous block
+	if !ci.ebbDone && ci.blockNumber > 0 {
+		tmpBlock, err := database.BlockEbbByNumber(ci.ls.db, ci.blockNumber-1)
+		if err == nil {
+			ret.Point = ocommon.NewPoint(tmpBlock.Slot, tmpBlock.Hash)
+			ret.Block = tmpBlock
+			ci.advance(tmpBlock)
+			ci.ls.RUnlock()
+			return ret, nil
+		}
+		if !errors.Is(err, database.ErrBlockNotFound) {
+			ci.ls.RUnlock()
+			return ret, err
+		}
+	}
 	// Lookup next block in metadata DB
 	tmpBlock, err := database.BlockByNumber(ci.ls.db, ci.blockNumber)
 	// Return immedidately if a block is found
 	if err == nil {
 		ret.Point = ocommon.NewPoint(tmpBlock.Slot, tmpBlock.Hash)
 		ret.Block = tmpBlock
-		ci.blockNumber++
+		ci.advance(tmpBlock)
 		ci.ls.RUnlock()
 		return ret, nil
 	}
@@ -131,7 +158,7 @@ func (ci *ChainIterator) Next(blocking bool) (*ChainIteratorResult, error) {
 		blockData := blockEvt.Data.(ChainBlockEvent)
 		ret.Point = blockData.Point
 		ret.Block = blockData.Block
-		ci.blockNumber++
+		ci.advance(blockData.Block)
 	case rollbackEvt, ok := <-rollbackChan:
 		if !ok {
 			// TODO: return an actual error (#389)
@@ -145,3 +172,13 @@ func (ci *ChainIterator) Next(blocking bool) (*ChainIteratorResult, error) {
 	ci.ls.config.EventBus.Unsubscribe(ChainRollbackEventType, rollbackSubId)
 	return ret, nil
 }
+
+// advance updates the iterator position after returning the specified block
+func (ci *ChainIterator) advance(block database.Block) {
+	if block.Type == ledger.BlockTypeByronEbb && block.Number > 0 {
+		ci.ebbDone = true
+		return
+	}
+	ci.blockNumber = block.Number + 1
+	ci.ebbDone = false
+}
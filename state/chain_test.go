@@ -0,0 +1,125 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+func TestChainIteratorByronEbb(t *testing.T) {
+	// An EBB shares its block number with the block before it, except for the EBB at the start of the chain
+	testBlocks := []database.Block{
+		{Slot: 0, Number: 0, Type: ledger.BlockTypeByronEbb},
+		{Slot: 1, Number: 1, Type: ledger.BlockTypeByronMain},
+		{Slot: 2, Number: 2, Type: ledger.BlockTypeByronMain},
+		{Slot: 3, Number: 2, Type: ledger.BlockTypeByronEbb},
+		{Slot: 4, Number: 3, Type: ledger.BlockTypeByronMain},
+	}
+	for idx := range testBlocks {
+		testBlocks[idx].Hash = bytes.Repeat([]byte{byte(idx + 1)}, 32)
+		testBlocks[idx].Cbor = []byte{0x80}
+	}
+	testDefs := []struct {
+		startIdx      int
+		inclusive     bool
+		expectedSlots []uint64
+	}{
+		// Origin
+		{
+			startIdx:      -1,
+			expectedSlots: []uint64{0, 1, 2, 3, 4},
+		},
+		{
+			startIdx:      2,
+			expectedSlots: []uint64{3, 4},
+		},
+		{
+			startIdx:      2,
+			inclusive:     true,
+			expectedSlots: []uint64{2, 3, 4},
+		},
+		{
+			startIdx:      3,
+			expectedSlots: []uint64{4},
+		},
+		{
+			startIdx:      3,
+			inclusive:     true,
+			expectedSlots: []uint64{3, 4},
+		},
+	}
+	ls := newTestLedgerState(t)
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		for _, block := range testBlocks {
+			if err := database.BlockCreateTxn(txn, block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	// The EBB is indexed separately from the main block with the same number
+	tmpBlock, err := database.BlockByNumber(ls.db, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if tmpBlock.Slot != 2 {
+		t.Fatalf("did not get expected block: got slot %d, expected %d", tmpBlock.Slot, 2)
+	}
+	tmpBlock, err = database.BlockEbbByNumber(ls.db, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if tmpBlock.Slot != 3 {
+		t.Fatalf("did not get expected EBB: got slot %d, expected %d", tmpBlock.Slot, 3)
+	}
+	for _, testDef := range testDefs {
+		var startPoint ocommon.Point
+		if testDef.startIdx >= 0 {
+			startBlock := testBlocks[testDef.startIdx]
+			startPoint = ocommon.NewPoint(startBlock.Slot, startBlock.Hash)
+		}
+		ci, err := newChainIterator(ls, startPoint, testDef.inclusive)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		var slots []uint64
+		for {
+			result, err := ci.Next(false)
+			if err != nil {
+				if errors.Is(err, ErrIteratorChainTip) {
+					break
+				}
+				t.Fatalf("unexpected error: %s", err)
+			}
+			slots = append(slots, result.Point.Slot)
+		}
+		if !slices.Equal(slots, testDef.expectedSlots) {
+			t.Fatalf(
+				"did not get expected blocks from start index %d (inclusive %v): got slots %v, expected %v",
+				testDef.startIdx,
+				testDef.inclusive,
+				slots,
+				testDef.expectedSlots,
+			)
+		}
+	}
+}
@@ -0,0 +1,555 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blinklabs-io/dingo/connmanager"
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/event"
+	ouroboros "github.com/blinklabs-io/gouroboros"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+const (
+	// Maximum number of headers that we track past our current tip for an upstream peer
+	// that we're not currently fetching blocks from
+	chainsyncCandidateMaxHeaders = blockfetchBatchSize * 2
+
+	// Maximum slot distance between two blocks for the VRF tiebreak to apply as of Conway
+	praosRestrictedVrfTiebreakMaxSlots = 5
+)
+
+// Maximum time that ChainsyncWait blocks waiting for our chain to catch up with an upstream peer. This is a
+// variable so that it can be shortened in tests
+var chainsyncWaitTimeout = 2 * time.Minute
+
+// chainsyncCandidate tracks the chain fragment announced by an upstream peer via chainsync. The
+// fragment is anchored at a point on our own chain and contains the headers received since that point
+type chainsyncCandidate struct {
+	connId       ouroboros.ConnectionId
+	anchor       ocommon.Point
+	anchorNumber uint64
+	anchorValid  bool
+	headers      []chainsyncCandidateHeader
+}
+
+type chainsyncCandidateHeader struct {
+	point      ocommon.Point
+	isEbb      bool
+	selectView *praosChainSelectView
+}
+
+// blockNumber returns the block number at the tip of the candidate chain
+func (c *chainsyncCandidate) blockNumber() uint64 {
+	ret := c.anchorNumber
+	for _, header := range c.headers {
+		// Byron EBBs don't count toward the chain length
+		if header.isEbb {
+			continue
+		}
+		ret++
+	}
+	return ret
+}
+
+// tip returns the point at the tip of the candidate chain
+func (c *chainsyncCandidate) tip() ocommon.Point {
+	if len(c.headers) == 0 {
+		return c.anchor
+	}
+	return c.headers[len(c.headers)-1].point
+}
+
+// tipSelectView returns the chain selection view for the header at the tip of the candidate chain, or nil if the
+// candidate chain has no headers past its anchor
+func (c *chainsyncCandidate) tipSelectView() *praosChainSelectView {
+	if len(c.headers) == 0 {
+		return nil
+	}
+	return c.headers[len(c.headers)-1].selectView
+}
+
+// addHeader extends the candidate chain with the provided block header
+func (c *chainsyncCandidate) addHeader(
+	point ocommon.Point,
+	header ledger.BlockHeader,
+) error {
+	prevHash, err := hex.DecodeString(header.PrevHash())
+	if err != nil {
+		return err
+	}
+	tipPoint := c.tip()
+	// The first block after origin won't reference a previous block that we know about
+	if tipPoint.Slot > 0 || len(tipPoint.Hash) > 0 {
+		if !bytes.Equal(prevHash, tipPoint.Hash) {
+			return fmt.Errorf(
+				"block header %x (with prev hash %x) does not fit on candidate chain tip (%x)",
+				point.Hash,
+				prevHash,
+				tipPoint.Hash,
+			)
+		}
+	}
+	_, isEbb := header.(*ledger.ByronEpochBounaryBlockHeader)
+	c.headers = append(
+		c.headers,
+		chainsyncCandidateHeader{
+			point:      point,
+			isEbb:      isEbb,
+			selectView: newPraosChainSelectView(header),
+		},
+	)
+	return nil
+}
+
+// rollback removes headers after the specified point from the candidate chain. It returns false if
+// the point is not part of the candidate chain
+func (c *chainsyncCandidate) rollback(point ocommon.Point) bool {
+	if pointsEqual(point, c.anchor) {
+		c.headers = nil
+		return true
+	}
+	for idx, header := range c.headers {
+		if pointsEqual(point, header.point) {
+			c.headers = c.headers[:idx+1]
+			return true
+		}
+	}
+	return false
+}
+
+// headerPoints returns the points for all headers in the candidate chain
+func (c *chainsyncCandidate) headerPoints() []ocommon.Point {
+	ret := make([]ocommon.Point, 0, len(c.headers))
+	for _, header := range c.headers {
+		ret = append(ret, header.point)
+	}
+	return ret
+}
+
+// compareChainsyncCandidates compares two candidate chains using the Praos chain selection rules.
+// It returns a positive value if a is preferred, a negative value if b is preferred, and 0 if
+// neither is preferred
+func compareChainsyncCandidates(a *chainsyncCandidate, b *chainsyncCandidate) int {
+	// Prefer the longer chain
+	if ret := cmp.Compare(a.blockNumber(), b.blockNumber()); ret != 0 {
+		return ret
+	}
+	return comparePraosChainSelectViews(a.tipSelectView(), b.tipSelectView())
+}
+
+// praosChainSelectView contains the fields from a block header that the Praos chain selection rules use to break
+// the tie between two chains of the same length
+type praosChainSelectView struct {
+	slot    uint64
+	issuer  []byte
+	issueNo uint64
+	// This is the VRF output before it's turned into a leader value, which is the nonce VRF output for TPraos
+	tieBreakVrf []byte
+	// As of Conway, the VRF tiebreak only applies to blocks that are close together
+	restrictedVrfTiebreak bool
+}
+
+// newPraosChainSelectView returns the chain selection view for a block header. It returns nil for Byron headers,
+// which don't have a tiebreak
+func newPraosChainSelectView(header ledger.BlockHeader) *praosChainSelectView {
+	praosHdr, err := newPraosHeader(header)
+	if err != nil {
+		return nil
+	}
+	ret := &praosChainSelectView{
+		slot:        praosHdr.slot,
+		issuer:      praosHdr.issuerVkey[:],
+		issueNo:     uint64(praosHdr.opCertSequenceNumber),
+		tieBreakVrf: praosHdr.leaderVrf.Output,
+	}
+	if praosHdr.tpraos {
+		ret.tieBreakVrf = praosHdr.nonceVrf.Output
+	}
+	_, ret.restrictedVrfTiebreak = header.(*ledger.ConwayBlockHeader)
+	return ret
+}
+
+// comparePraosChainSelectViews breaks the tie between the tips of two chains of the same length. A block from the
+// same issuer in the same slot is preferred if it has a higher operational certificate issue number, since the
+// issuer has rotated its hot key. Otherwise the block with the lower VRF output is preferred. It returns 0 if
+// neither is preferred, which includes when either view isn't known
+func comparePraosChainSelectViews(
+	a *praosChainSelectView,
+	b *praosChainSelectView,
+) int {
+	if a == nil || b == nil {
+		return 0
+	}
+	if a.slot == b.slot && bytes.Equal(a.issuer, b.issuer) {
+		if ret := cmp.Compare(a.issueNo, b.issueNo); ret != 0 {
+			return ret
+		}
+	}
+	if a.restrictedVrfTiebreak || b.restrictedVrfTiebreak {
+		if max(a.slot, b.slot)-min(a.slot, b.slot) > praosRestrictedVrfTiebreakMaxSlots {
+			return 0
+		}
+	}
+	return bytes.Compare(b.tieBreakVrf, a.tieBreakVrf)
+}
+
+// blockSelectView returns the chain selection view for a stored block
+func blockSelectView(tmpBlock database.Block) (*praosChainSelectView, error) {
+	blk, err := ledger.NewBlockFromCbor(tmpBlock.Type, tmpBlock.Cbor)
+	if err != nil {
+		return nil, err
+	}
+	return newPraosChainSelectView(blk.Header()), nil
+}
+
+// forkPreferred returns whether the tip of a fork that we've switched to is preferred over the tip of the chain that
+// we rolled back, using the Praos chain selection rules
+func forkPreferred(
+	prevTip ochainsync.Tip,
+	rolledBackBlocks []database.Block,
+	newTip ochainsync.Tip,
+	events []BlockfetchEvent,
+) (bool, error) {
+	if newTip.BlockNumber != prevTip.BlockNumber || len(rolledBackBlocks) == 0 || len(events) == 0 {
+		return newTip.BlockNumber > prevTip.BlockNumber, nil
+	}
+	prevView, err := blockSelectView(rolledBackBlocks[len(rolledBackBlocks)-1])
+	if err != nil {
+		return false, err
+	}
+	newView := newPraosChainSelectView(events[len(events)-1].Block.Header())
+	return comparePraosChainSelectViews(newView, prevView) > 0, nil
+}
+
+func pointsEqual(a ocommon.Point, b ocommon.Point) bool {
+	return a.Slot == b.Slot && bytes.Equal(a.Hash, b.Hash)
+}
+
+// securityParam returns the security parameter (k) for the current era
+func (ls *LedgerState) securityParam() uint64 {
+	// Byron
+	if ls.currentEra.Id == 0 {
+		byronGenesis := ls.config.CardanoNodeConfig.ByronGenesis()
+		if byronGenesis != nil {
+			return uint64(byronGenesis.ProtocolConsts.K)
+		}
+	}
+	shelleyGenesis := ls.config.CardanoNodeConfig.ShelleyGenesis()
+	if shelleyGenesis != nil {
+		return uint64(shelleyGenesis.SecurityParam)
+	}
+	return 0
+}
+
+// chainsyncCandidate returns the candidate chain for the specified connection, creating it if it
+// doesn't already exist. The caller must hold chainsyncCandidatesMutex
+func (ls *LedgerState) chainsyncCandidate(
+	connId ouroboros.ConnectionId,
+) *chainsyncCandidate {
+	cand, ok := ls.chainsyncCandidates[connId]
+	if !ok {
+		cand = &chainsyncCandidate{
+			connId: connId,
+		}
+		ls.chainsyncCandidates[connId] = cand
+	}
+	return cand
+}
+
+// chainsyncIsSelected returns whether the specified connection is the source for our current chain
+func (ls *LedgerState) chainsyncIsSelected(connId ouroboros.ConnectionId) bool {
+	ls.chainsyncCandidatesMutex.Lock()
+	defer ls.chainsyncCandidatesMutex.Unlock()
+	return ls.chainsyncSelectedConnId != nil &&
+		*ls.chainsyncSelectedConnId == connId
+}
+
+// chainsyncAnchorCandidate resets the candidate chain to start at the specified point, which should be
+// on our current chain. The caller must hold chainsyncCandidatesMutex
+func (ls *LedgerState) chainsyncAnchorCandidate(
+	cand *chainsyncCandidate,
+	point ocommon.Point,
+) error {
+	cand.anchor = point
+	cand.anchorNumber = 0
+	cand.anchorValid = false
+	cand.headers = nil
+	tip := ls.Tip()
+	if pointsEqual(point, tip.Point) {
+		cand.anchorNumber = tip.BlockNumber
+		cand.anchorValid = true
+		return nil
+	}
+	// Origin
+	if point.Slot == 0 && len(point.Hash) == 0 {
+		cand.anchorValid = true
+		return nil
+	}
+	tmpBlock, err := database.BlockByPoint(ls.db, point)
+	if err != nil {
+		if !errors.Is(err, database.ErrBlockNotFound) {
+			return err
+		}
+		// We may have started chainsync from an explicit intersect point with an empty chain
+		if tip.Point.Slot == 0 && len(tip.Point.Hash) == 0 {
+			cand.anchorValid = true
+		}
+		return nil
+	}
+	cand.anchorNumber = tmpBlock.Number
+	cand.anchorValid = true
+	return nil
+}
+
+// chainsyncPruneCandidate moves the anchor of a candidate chain forward past any headers for
+// blocks that we've already added to our chain. The caller must hold chainsyncCandidatesMutex
+func (ls *LedgerState) chainsyncPruneCandidate(cand *chainsyncCandidate) error {
+	if !cand.anchorValid {
+		return nil
+	}
+	tip := ls.Tip()
+	pruneCount := 0
+	for _, header := range cand.headers {
+		if header.point.Slot > tip.Point.Slot {
+			break
+		}
+		if _, err := database.BlockByPoint(ls.db, header.point); err != nil {
+			if errors.Is(err, database.ErrBlockNotFound) {
+				break
+			}
+			return err
+		}
+		cand.anchor = header.point
+		if !header.isEbb {
+			cand.anchorNumber++
+		}
+		pruneCount++
+	}
+	if pruneCount > 0 {
+		cand.headers = cand.headers[pruneCount:]
+	}
+	return nil
+}
+
+// chainsyncPruneCandidates prunes all candidate chains after our chain has been extended
+func (ls *LedgerState) chainsyncPruneCandidates() error {
+	ls.chainsyncCandidatesMutex.Lock()
+	defer ls.chainsyncCandidatesMutex.Unlock()
+	for _, cand := range ls.chainsyncCandidates {
+		if err := ls.chainsyncPruneCandidate(cand); err != nil {
+			return err
+		}
+	}
+	// Wake up any chainsync clients waiting for our chain to catch up
+	ls.chainsyncCandidatesCond.Broadcast()
+	return nil
+}
+
+// chainsyncCandidateEligible returns whether we can switch to the specified candidate chain. The
+// caller must hold chainsyncCandidatesMutex
+func (ls *LedgerState) chainsyncCandidateEligible(
+	cand *chainsyncCandidate,
+	tip ochainsync.Tip,
+	securityParam uint64,
+) (bool, error) {
+	if !cand.anchorValid {
+		return false, nil
+	}
+	// We never roll back more than k blocks
+	if tip.BlockNumber > cand.anchorNumber+securityParam {
+		return false, nil
+	}
+	if pointsEqual(cand.anchor, tip.Point) {
+		return true, nil
+	}
+	// Only switch to a fork if it's preferred over our current chain
+	candBlockNumber := cand.blockNumber()
+	if candBlockNumber != tip.BlockNumber {
+		return candBlockNumber > tip.BlockNumber, nil
+	}
+	tipBlock, err := database.BlockByPoint(ls.db, tip.Point)
+	if err != nil {
+		if errors.Is(err, database.ErrBlockNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	tipView, err := blockSelectView(tipBlock)
+	if err != nil {
+		return false, err
+	}
+	return comparePraosChainSelectViews(cand.tipSelectView(), tipView) > 0, nil
+}
+
+// chainsyncSelectChain compares the candidate chains from all upstream peers and switches to the best
+// one if it's preferred over the currently selected chain
+func (ls *LedgerState) chainsyncSelectChain() error {
+	ls.chainsyncCandidatesMutex.Lock()
+	defer ls.chainsyncCandidatesMutex.Unlock()
+	tip := ls.Tip()
+	securityParam := ls.securityParam()
+	var current, best *chainsyncCandidate
+	if ls.chainsyncSelectedConnId != nil {
+		current = ls.chainsyncCandidates[*ls.chainsyncSelectedConnId]
+	}
+	for _, cand := range ls.chainsyncCandidates {
+		if err := ls.chainsyncPruneCandidate(cand); err != nil {
+			return err
+		}
+		eligible, err := ls.chainsyncCandidateEligible(cand, tip, securityParam)
+		if err != nil {
+			return err
+		}
+		if !eligible {
+			continue
+		}
+		if best == nil {
+			best = cand
+			continue
+		}
+		ret := compareChainsyncCandidates(cand, best)
+		// Stick with the current chain when neither is preferred
+		if ret > 0 || (ret == 0 && cand == current) {
+			best = cand
+		}
+	}
+	if best == nil || best == current {
+		return nil
+	}
+	return ls.chainsyncSwitchChain(best)
+}
+
+// chainsyncSwitchChain starts fetching blocks from the connection associated with the specified candidate
+// chain. If the candidate chain forks off from before our tip, we don't roll back our chain until the blocks
+// from the fork have been fetched, so that we can keep our chain if they turn out to be invalid. The caller
+// must hold chainsyncCandidatesMutex
+func (ls *LedgerState) chainsyncSwitchChain(cand *chainsyncCandidate) error {
+	connId := cand.connId
+	ls.chainsyncSelectedConnId = &connId
+	ls.chainsyncForkPoint = nil
+	if !pointsEqual(cand.anchor, ls.Tip().Point) {
+		forkPoint := cand.anchor
+		ls.chainsyncForkPoint = &forkPoint
+	}
+	ls.config.Logger.Info(
+		fmt.Sprintf(
+			"switched to chain from %s with tip %x at slot %d",
+			connId.String(),
+			cand.tip().Hash,
+			cand.tip().Slot,
+		),
+		"component",
+		"ledger",
+	)
+	// Wake up the chainsync client for the newly selected connection if it's waiting
+	ls.chainsyncCandidatesCond.Broadcast()
+	// Replace any pending header points with those from the new chain
+	ls.chainsyncHeaderPointsMutex.Lock()
+	defer ls.chainsyncHeaderPointsMutex.Unlock()
+	ls.chainsyncHeaderPoints = cand.headerPoints()
+	if len(ls.chainsyncHeaderPoints) == 0 {
+		return nil
+	}
+	return ls.chainsyncRequestBlocks(connId)
+}
+
+// ChainsyncWait blocks while the candidate chain for the specified connection is too far ahead of
+// our current chain and it isn't the source for our chain. This is called by the chainsync client
+// to prevent unbounded growth of candidate chains from upstream peers that we aren't fetching blocks from.
+// It returns ErrChainsyncWaitTimeout if our chain doesn't catch up within chainsyncWaitTimeout
+func (ls *LedgerState) ChainsyncWait(connId ouroboros.ConnectionId) error {
+	ls.chainsyncCandidatesMutex.Lock()
+	defer ls.chainsyncCandidatesMutex.Unlock()
+	timedOut := false
+	timer := time.AfterFunc(
+		chainsyncWaitTimeout,
+		func() {
+			ls.chainsyncCandidatesMutex.Lock()
+			timedOut = true
+			ls.chainsyncCandidatesCond.Broadcast()
+			ls.chainsyncCandidatesMutex.Unlock()
+		},
+	)
+	defer timer.Stop()
+	for {
+		cand, ok := ls.chainsyncCandidates[connId]
+		if !ok {
+			return nil
+		}
+		if ls.chainsyncSelectedConnId != nil &&
+			*ls.chainsyncSelectedConnId == connId {
+			return nil
+		}
+		if len(cand.headers) < chainsyncCandidateMaxHeaders {
+			return nil
+		}
+		if timedOut {
+			return ErrChainsyncWaitTimeout
+		}
+		ls.chainsyncCandidatesCond.Wait()
+	}
+}
+
+func (ls *LedgerState) handleEventConnectionClosed(evt event.Event) {
+	e := evt.Data.(connmanager.ConnectionClosedEvent)
+	// Release any in-progress blockfetch operation for the connection
+	ls.chainsyncBlockfetchMutex.Lock()
+	if ls.chainsyncBlockfetchBusy &&
+		ls.chainsyncBlockfetchConnId == e.ConnectionId {
+		ls.chainsyncBlockEvents = nil
+		// Unblock chainsync block headers
+		ls.chainsyncHeaderPointsMutex.Lock()
+		ls.chainsyncBlockfetchDone()
+		ls.chainsyncHeaderPointsMutex.Unlock()
+	}
+	ls.chainsyncBlockfetchMutex.Unlock()
+	ls.chainsyncMutex.Lock()
+	defer ls.chainsyncMutex.Unlock()
+	// Remove candidate chain for connection
+	ls.chainsyncCandidatesMutex.Lock()
+	if _, ok := ls.chainsyncCandidates[e.ConnectionId]; !ok {
+		ls.chainsyncCandidatesMutex.Unlock()
+		return
+	}
+	delete(ls.chainsyncCandidates, e.ConnectionId)
+	if ls.chainsyncSelectedConnId != nil &&
+		*ls.chainsyncSelectedConnId == e.ConnectionId {
+		ls.chainsyncSelectedConnId = nil
+		ls.chainsyncForkPoint = nil
+		ls.chainsyncHeaderPointsMutex.Lock()
+		ls.chainsyncHeaderPoints = nil
+		ls.chainsyncHeaderPointsMutex.Unlock()
+	}
+	ls.chainsyncCandidatesCond.Broadcast()
+	ls.chainsyncCandidatesMutex.Unlock()
+	// Select a new chain from the remaining upstream peers
+	if err := ls.chainsyncSelectChain(); err != nil {
+		ls.config.Logger.Error(
+			"failed to select chain",
+			"component", "ledger",
+			"error", err,
+		)
+	}
+}
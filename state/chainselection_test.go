@@ -0,0 +1,386 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	ouroboros "github.com/blinklabs-io/gouroboros"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+func testConnId(port int) ouroboros.ConnectionId {
+	return ouroboros.ConnectionId{
+		LocalAddr:  &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 3001},
+		RemoteAddr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port},
+	}
+}
+
+func testCandidate(anchorNumber uint64, slots ...uint64) *chainsyncCandidate {
+	ret := &chainsyncCandidate{
+		anchor:       ocommon.NewPoint(100, []byte{0xab}),
+		anchorNumber: anchorNumber,
+		anchorValid:  true,
+	}
+	for _, slot := range slots {
+		ret.headers = append(
+			ret.headers,
+			chainsyncCandidateHeader{
+				point: ocommon.NewPoint(slot, []byte{byte(slot)}),
+			},
+		)
+	}
+	return ret
+}
+
+// testCandidateTip sets the chain selection view for the header at the tip of a candidate chain
+func testCandidateTip(
+	cand *chainsyncCandidate,
+	selectView *praosChainSelectView,
+) *chainsyncCandidate {
+	cand.headers[len(cand.headers)-1].selectView = selectView
+	return cand
+}
+
+func testSelectView(
+	slot uint64,
+	issuer byte,
+	issueNo uint64,
+	vrf byte,
+) *praosChainSelectView {
+	return &praosChainSelectView{
+		slot:        slot,
+		issuer:      []byte{issuer},
+		issueNo:     issueNo,
+		tieBreakVrf: []byte{vrf},
+	}
+}
+
+func TestComparePraosChainSelectViews(t *testing.T) {
+	restricted := func(view *praosChainSelectView) *praosChainSelectView {
+		view.restrictedVrfTiebreak = true
+		return view
+	}
+	testDefs := []struct {
+		a        *praosChainSelectView
+		b        *praosChainSelectView
+		expected int
+	}{
+		// Lower VRF output is preferred
+		{
+			a:        testSelectView(100, 0x01, 1, 0x10),
+			b:        testSelectView(101, 0x02, 1, 0x20),
+			expected: 1,
+		},
+		{
+			a:        testSelectView(100, 0x01, 1, 0x30),
+			b:        testSelectView(100, 0x02, 1, 0x20),
+			expected: -1,
+		},
+		// Higher issue number is preferred for the same issuer in the same slot, regardless of VRF output
+		{
+			a:        testSelectView(100, 0x01, 2, 0x30),
+			b:        testSelectView(100, 0x01, 1, 0x20),
+			expected: 1,
+		},
+		// Issue number isn't considered for different slots
+		{
+			a:        testSelectView(101, 0x01, 2, 0x30),
+			b:        testSelectView(100, 0x01, 1, 0x20),
+			expected: -1,
+		},
+		// Issue number isn't considered for different issuers
+		{
+			a:        testSelectView(100, 0x01, 2, 0x30),
+			b:        testSelectView(100, 0x02, 1, 0x20),
+			expected: -1,
+		},
+		// Restricted VRF tiebreak within the maximum slot distance
+		{
+			a:        restricted(testSelectView(105, 0x01, 1, 0x10)),
+			b:        restricted(testSelectView(100, 0x02, 1, 0x20)),
+			expected: 1,
+		},
+		// Restricted VRF tiebreak past the maximum slot distance
+		{
+			a:        restricted(testSelectView(106, 0x01, 1, 0x10)),
+			b:        restricted(testSelectView(100, 0x02, 1, 0x20)),
+			expected: 0,
+		},
+		// Unknown view
+		{
+			a:        testSelectView(100, 0x01, 1, 0x10),
+			b:        nil,
+			expected: 0,
+		},
+	}
+	for _, testDef := range testDefs {
+		ret := comparePraosChainSelectViews(testDef.a, testDef.b)
+		if ret != testDef.expected {
+			t.Fatalf(
+				"did not get expected result: got %d, expected %d",
+				ret,
+				testDef.expected,
+			)
+		}
+	}
+}
+
+func TestNewPraosChainSelectView(t *testing.T) {
+	events := testImmutableDbBlockEvents(t, 2)
+	selectView := newPraosChainSelectView(events[1].Block.Header())
+	if selectView == nil {
+		t.Fatalf("did not get expected chain selection view")
+	}
+	praosHdr, err := newPraosHeader(events[1].Block.Header())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if selectView.slot != events[1].Point.Slot {
+		t.Fatalf("did not get expected slot: got %d, expected %d", selectView.slot, events[1].Point.Slot)
+	}
+	expectedVrf := praosHdr.leaderVrf.Output
+	if praosHdr.tpraos {
+		expectedVrf = praosHdr.nonceVrf.Output
+	}
+	if len(expectedVrf) == 0 || !bytes.Equal(selectView.tieBreakVrf, expectedVrf) {
+		t.Fatalf("did not get expected tiebreak VRF output: got %x, expected %x", selectView.tieBreakVrf, expectedVrf)
+	}
+}
+
+func TestCompareChainsyncCandidates(t *testing.T) {
+	testDefs := []struct {
+		a        *chainsyncCandidate
+		b        *chainsyncCandidate
+		expected int
+	}{
+		// Longer chain is preferred
+		{
+			a:        testCandidate(10, 101, 102, 103),
+			b:        testCandidate(10, 101, 102),
+			expected: 1,
+		},
+		{
+			a:        testCandidate(10, 101),
+			b:        testCandidate(11, 110),
+			expected: -1,
+		},
+		// Lower VRF output at the tip is preferred for equal length
+		{
+			a:        testCandidateTip(testCandidate(10, 101, 102), testSelectView(102, 0x01, 1, 0x20)),
+			b:        testCandidateTip(testCandidate(10, 105, 120), testSelectView(120, 0x02, 1, 0x10)),
+			expected: -1,
+		},
+		// Density isn't considered for equal length
+		{
+			a:        testCandidate(10, 101, 102),
+			b:        testCandidate(10, 105, 120),
+			expected: 0,
+		},
+		// Identical chains
+		{
+			a:        testCandidate(10, 101, 102),
+			b:        testCandidate(10, 101, 102),
+			expected: 0,
+		},
+	}
+	for _, testDef := range testDefs {
+		ret := compareChainsyncCandidates(testDef.a, testDef.b)
+		if ret != testDef.expected {
+			t.Fatalf(
+				"did not get expected result: got %d, expected %d",
+				ret,
+				testDef.expected,
+			)
+		}
+	}
+}
+
+func TestChainsyncCandidateRollback(t *testing.T) {
+	cand := testCandidate(10, 101, 102, 103)
+	if !cand.rollback(ocommon.NewPoint(102, []byte{102})) {
+		t.Fatalf("rollback point was not found in candidate chain")
+	}
+	if cand.blockNumber() != 12 {
+		t.Fatalf(
+			"did not get expected block number after rollback: got %d, expected %d",
+			cand.blockNumber(),
+			12,
+		)
+	}
+	if cand.rollback(ocommon.NewPoint(99, []byte{99})) {
+		t.Fatalf("rollback point should not be found in candidate chain")
+	}
+}
+
+func TestChainsyncSwitchChainFork(t *testing.T) {
+	ls := newTestLedgerState(t)
+	ls.chainsyncCandidates = make(map[ouroboros.ConnectionId]*chainsyncCandidate)
+	ls.chainsyncCandidatesCond = sync.NewCond(&ls.chainsyncCandidatesMutex)
+	ls.currentTip.Point = ocommon.NewPoint(105, []byte{105})
+	ls.currentTip.BlockNumber = 12
+	var requestStart, requestEnd ocommon.Point
+	ls.config.BlockfetchRequestRangeFunc = func(connId ouroboros.ConnectionId, start ocommon.Point, end ocommon.Point) error {
+		requestStart = start
+		requestEnd = end
+		return nil
+	}
+	cand := testCandidate(10, 101, 102, 103, 104)
+	cand.connId = testConnId(3002)
+	ls.chainsyncCandidates[cand.connId] = cand
+	ls.chainsyncCandidatesMutex.Lock()
+	err := ls.chainsyncSwitchChain(cand)
+	ls.chainsyncCandidatesMutex.Unlock()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Our chain isn't rolled back until the blocks from the fork have been fetched
+	if ls.currentTip.Point.Slot != 105 {
+		t.Fatalf("did not get expected tip slot: got %d, expected %d", ls.currentTip.Point.Slot, 105)
+	}
+	if ls.chainsyncForkPoint == nil || !pointsEqual(*ls.chainsyncForkPoint, cand.anchor) {
+		t.Fatalf("did not get expected fork point: got %v, expected %v", ls.chainsyncForkPoint, cand.anchor)
+	}
+	if requestStart.Slot != 101 || requestEnd.Slot != 104 {
+		t.Fatalf(
+			"did not get expected blockfetch range: got %d to %d, expected %d to %d",
+			requestStart.Slot,
+			requestEnd.Slot,
+			101,
+			104,
+		)
+	}
+}
+
+func TestApplyForkBlockEvents(t *testing.T) {
+	// Our chain has the first 3 blocks from the test data, and the fork point is the first block
+	testDefs := []struct {
+		name        string
+		forkStart   int
+		forkEnd     int
+		expectedTip int
+		expectErr   bool
+	}{
+		{
+			name:        "longer fork",
+			forkStart:   1,
+			forkEnd:     4,
+			expectedTip: 3,
+		},
+		{
+			name:        "invalid block",
+			forkStart:   2,
+			forkEnd:     4,
+			expectedTip: 2,
+			expectErr:   true,
+		},
+		{
+			name:        "same length fork that doesn't win the tiebreak",
+			forkStart:   1,
+			forkEnd:     3,
+			expectedTip: 2,
+			expectErr:   true,
+		},
+		{
+			name:        "shorter fork",
+			forkStart:   1,
+			forkEnd:     2,
+			expectedTip: 2,
+			expectErr:   true,
+		},
+	}
+	for _, testDef := range testDefs {
+		// Each test case needs its own in-memory database
+		t.Run(testDef.name, func(t *testing.T) {
+			ls := testImmutableDbLedgerState(t)
+			events := testImmutableDbBlockEvents(t, 4)
+			ls.Lock()
+			defer ls.Unlock()
+			if err := ls.applyBlockEvents(events[1:3]); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			err := ls.applyForkBlockEvents(events[0].Point, events[testDef.forkStart:testDef.forkEnd])
+			if testDef.expectErr && err == nil {
+				t.Fatalf("did not get expected error")
+			}
+			if !testDef.expectErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			expectedPoint := events[testDef.expectedTip].Point
+			if !pointsEqual(ls.currentTip.Point, expectedPoint) {
+				t.Fatalf(
+					"did not get expected tip: got %x at slot %d, expected %x at slot %d",
+					ls.currentTip.Point.Hash,
+					ls.currentTip.Point.Slot,
+					expectedPoint.Hash,
+					expectedPoint.Slot,
+				)
+			}
+		})
+	}
+}
+
+func TestChainsyncBlockfetchDone(t *testing.T) {
+	ls := newTestLedgerState(t)
+	ls.chainsyncBlockfetchStarted(testConnId(3002))
+	doneChan := ls.chainsyncBlockfetchDoneChan
+	if doneChan == nil {
+		t.Fatalf("did not get expected blockfetch done channel")
+	}
+	// Clearing the blockfetch operation is safe to do more than once
+	ls.chainsyncBlockfetchDone()
+	ls.chainsyncBlockfetchDone()
+	select {
+	case <-doneChan:
+	default:
+		t.Fatalf("blockfetch done channel was not closed")
+	}
+	if ls.chainsyncBlockfetchBusy {
+		t.Fatalf("blockfetch operation is still marked as busy")
+	}
+}
+
+func TestChainsyncWaitTimeout(t *testing.T) {
+	origTimeout := chainsyncWaitTimeout
+	chainsyncWaitTimeout = 10 * time.Millisecond
+	t.Cleanup(func() {
+		chainsyncWaitTimeout = origTimeout
+	})
+	ls := newTestLedgerState(t)
+	ls.chainsyncCandidates = make(map[ouroboros.ConnectionId]*chainsyncCandidate)
+	ls.chainsyncCandidatesCond = sync.NewCond(&ls.chainsyncCandidatesMutex)
+	connId := testConnId(3002)
+	slots := make([]uint64, chainsyncCandidateMaxHeaders)
+	for idx := range slots {
+		slots[idx] = uint64(101 + idx) // #nosec G115
+	}
+	cand := testCandidate(10, slots...)
+	cand.connId = connId
+	ls.chainsyncCandidates[connId] = cand
+	// We give up waiting if our chain doesn't catch up
+	if err := ls.ChainsyncWait(connId); !errors.Is(err, ErrChainsyncWaitTimeout) {
+		t.Fatalf("did not get expected error: got %v", err)
+	}
+	// We don't wait for the connection that's the source of our chain
+	ls.chainsyncSelectedConnId = &connId
+	if err := ls.ChainsyncWait(connId); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
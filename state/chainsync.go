@@ -19,11 +19,14 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
 	"time"
 
 	"github.com/blinklabs-io/dingo/database"
 	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
 	"github.com/blinklabs-io/dingo/event"
+	"github.com/blinklabs-io/dingo/state/eras"
+	ouroboros "github.com/blinklabs-io/gouroboros"
 	"github.com/blinklabs-io/gouroboros/cbor"
 	"github.com/blinklabs-io/gouroboros/ledger"
 	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
@@ -31,8 +34,11 @@ import (
 )
 
 const (
-	blockfetchBatchSize          = 500
-	blockfetchBatchSlotThreshold = 2500 * 20 // TODO: calculate from protocol params
+	blockfetchBatchSize = 500
+
+	// Number of fetched blocks to apply in each database transaction. This is chosen to stay well under the
+	// badger transaction size limit
+	blockfetchTxnBatchSize = 10
 
 	// Timeout for updates on a blockfetch operation. This is based on a 2s BatchStart
 	// and a 2s Block timeout for blockfetch
@@ -89,38 +95,101 @@ func (ls *LedgerState) handleEventBlockfetch(evt event.Event) {
 }
 
 func (ls *LedgerState) handleEventChainsyncRollback(e ChainsyncEvent) error {
-	ls.Lock()
-	defer ls.Unlock()
-	return ls.rollback(e.Point)
+	// Update candidate chain for connection
+	ls.chainsyncCandidatesMutex.Lock()
+	cand := ls.chainsyncCandidate(e.ConnectionId)
+	if !cand.rollback(e.Point) {
+		// The rollback point is outside of the candidate chain, so we start a new one
+		if err := ls.chainsyncAnchorCandidate(cand, e.Point); err != nil {
+			ls.chainsyncCandidatesMutex.Unlock()
+			return err
+		}
+	}
+	isSelected := ls.chainsyncSelectedConnId != nil &&
+		*ls.chainsyncSelectedConnId == e.ConnectionId
+	// We haven't rolled back our chain yet when we're still fetching the blocks for a fork that we're
+	// switching to, so we only need to move the fork point to the candidate chain anchor
+	isForking := isSelected && ls.chainsyncForkPoint != nil
+	if isForking {
+		forkPoint := cand.anchor
+		ls.chainsyncForkPoint = &forkPoint
+	}
+	ls.chainsyncCandidatesMutex.Unlock()
+	if isSelected {
+		// Discard pending header points after the rollback point
+		ls.chainsyncHeaderPointsMutex.Lock()
+		ls.chainsyncHeaderPoints = slices.DeleteFunc(
+			ls.chainsyncHeaderPoints,
+			func(point ocommon.Point) bool {
+				return point.Slot > e.Point.Slot
+			},
+		)
+		ls.chainsyncHeaderPointsMutex.Unlock()
+		// Roll back our chain if the rollback point is before our tip
+		ls.Lock()
+		if !isForking && e.Point.Slot < ls.currentTip.Point.Slot {
+			if err := ls.rollback(e.Point); err != nil {
+				ls.Unlock()
+				if errors.Is(err, ErrRollbackTooDeep) {
+					// Stop following the chain from a peer that tries to roll back past our immutable tip
+					ls.chainsyncCandidatesMutex.Lock()
+					cand.anchorValid = false
+					ls.chainsyncSelectedConnId = nil
+					ls.chainsyncForkPoint = nil
+					ls.chainsyncCandidatesMutex.Unlock()
+					if ls.config.ConnectionCloseFunc != nil {
+						ls.config.ConnectionCloseFunc(e.ConnectionId, err)
+					}
+					if err2 := ls.chainsyncSelectChain(); err2 != nil {
+						return errors.Join(err, err2)
+					}
+				}
+				return err
+			}
+		}
+		ls.Unlock()
+	}
+	// A rollback may change which chain we prefer
+	return ls.chainsyncSelectChain()
 }
 
 func (ls *LedgerState) handleEventChainsyncBlockHeader(e ChainsyncEvent) error {
-	// Check for out-of-order block headers
-	// This is a stop-gap to handle disconnects during sync until we get chain selection working
-	ls.chainsyncHeaderPointsMutex.Lock()
-	if ls.chainsyncHeaderPoints != nil {
-		if pointsLen := len(ls.chainsyncHeaderPoints); pointsLen > 0 &&
-			e.Point.Slot < ls.chainsyncHeaderPoints[pointsLen-1].Slot {
-			tmpHeaderPoints := make([]ocommon.Point, 0, pointsLen)
-			for _, point := range ls.chainsyncHeaderPoints {
-				if point.Slot >= e.Point.Slot {
-					break
-				}
-				tmpHeaderPoints = append(tmpHeaderPoints, point)
-			}
-			ls.chainsyncHeaderPoints = tmpHeaderPoints
+	// Validate the header before it can become part of a candidate chain
+	if ls.config.ValidationLevel >= ValidationLevelFull {
+		if err := ls.validateChainsyncHeader(e.BlockHeader); err != nil {
+			return ls.chainsyncRejectHeader(
+				e.ConnectionId,
+				BlockValidationError{Point: e.Point, Err: err},
+			)
 		}
 	}
-	ls.chainsyncHeaderPointsMutex.Unlock()
+	// Extend candidate chain for connection
+	ls.chainsyncCandidatesMutex.Lock()
+	cand := ls.chainsyncCandidate(e.ConnectionId)
+	if err := cand.addHeader(e.Point, e.BlockHeader); err != nil {
+		ls.chainsyncCandidatesMutex.Unlock()
+		return err
+	}
+	isSelected := ls.chainsyncSelectedConnId != nil &&
+		*ls.chainsyncSelectedConnId == e.ConnectionId
+	ls.chainsyncCandidatesMutex.Unlock()
+	// Compare against our current chain if the header came from another peer
+	if !isSelected {
+		return ls.chainsyncSelectChain()
+	}
 	// Wait for current blockfetch to finish if we've already got another batch worth queued up
 	// This prevents us exceeding the configured recv queue size in the block-fetch protocol
-	if len(ls.chainsyncHeaderPoints) >= blockfetchBatchSize {
-		// Lock and immediately unlock to pause for current blockfetch process without blocking
-		// anything else. This is clunky, but the alternative is a waiting on a channel, which
-		// adds unnecessary complexity
-		ls.chainsyncHeaderMutex.Lock()
-		//nolint:staticcheck
-		ls.chainsyncHeaderMutex.Unlock()
+	ls.chainsyncHeaderPointsMutex.Lock()
+	blockfetchDoneChan := ls.chainsyncBlockfetchDoneChan
+	pendingCount := len(ls.chainsyncHeaderPoints)
+	ls.chainsyncHeaderPointsMutex.Unlock()
+	if blockfetchDoneChan != nil && pendingCount >= blockfetchBatchSize {
+		// We stop waiting after the blockfetch timeout, and a stalled blockfetch operation is then
+		// cleared when we next request blocks
+		select {
+		case <-blockfetchDoneChan:
+		case <-time.After(blockfetchBusyTimeout):
+		}
 	}
 	// Add to cached header points
 	ls.chainsyncHeaderPointsMutex.Lock()
@@ -129,19 +198,57 @@ func (ls *LedgerState) handleEventChainsyncBlockHeader(e ChainsyncEvent) error {
 		ls.chainsyncHeaderPoints,
 		e.Point,
 	)
-	// Wait for additional block headers before fetching block bodies if we're
-	// far enough out from tip
-	if e.Point.Slot < e.Tip.Point.Slot &&
-		(e.Tip.Point.Slot-e.Point.Slot > blockfetchBatchSlotThreshold) &&
+	// Wait for additional block headers before fetching block bodies if the header
+	// is already immutable on the upstream chain
+	if ls.headerIsImmutable(e.BlockHeader, e.Tip) &&
 		len(ls.chainsyncHeaderPoints) < blockfetchBatchSize {
 		return nil
 	}
+	return ls.chainsyncRequestBlocks(e.ConnectionId)
+}
+
+// chainsyncRejectHeader stops following the chain from an upstream peer that provided an invalid block header and
+// disconnects the peer
+func (ls *LedgerState) chainsyncRejectHeader(
+	connId ouroboros.ConnectionId,
+	err error,
+) error {
+	ls.chainsyncCandidatesMutex.Lock()
+	if cand, ok := ls.chainsyncCandidates[connId]; ok {
+		cand.anchorValid = false
+	}
+	isSelected := ls.chainsyncSelectedConnId != nil &&
+		*ls.chainsyncSelectedConnId == connId
+	if isSelected {
+		ls.chainsyncSelectedConnId = nil
+		ls.chainsyncForkPoint = nil
+	}
+	ls.chainsyncCandidatesMutex.Unlock()
+	if isSelected {
+		// Discard pending header points from the rejected chain
+		ls.chainsyncHeaderPointsMutex.Lock()
+		ls.chainsyncHeaderPoints = nil
+		ls.chainsyncHeaderPointsMutex.Unlock()
+	}
+	if ls.config.ConnectionCloseFunc != nil {
+		ls.config.ConnectionCloseFunc(connId, err)
+	}
+	if err2 := ls.chainsyncSelectChain(); err2 != nil {
+		return errors.Join(err, err2)
+	}
+	return err
+}
+
+// chainsyncRequestBlocks starts a blockfetch request for the pending header points. The caller must
+// hold chainsyncHeaderPointsMutex
+func (ls *LedgerState) chainsyncRequestBlocks(
+	connId ouroboros.ConnectionId,
+) error {
 	// Don't start fetch if there's already one in progress
 	if ls.chainsyncBlockfetchBusy {
 		// Clear busy flag on timeout
 		if time.Since(ls.chainsyncBlockfetchBusyTime) > blockfetchBusyTimeout {
-			ls.chainsyncBlockfetchBusy = false
-			ls.chainsyncBlockfetchWaiting = false
+			ls.chainsyncBlockfetchDone()
 			ls.config.Logger.Warn(
 				fmt.Sprintf(
 					"blockfetch operation timed out after %s",
@@ -150,49 +257,56 @@ func (ls *LedgerState) handleEventChainsyncBlockHeader(e ChainsyncEvent) error {
 				"component",
 				"ledger",
 			)
-			// Unblock new chainsync block headers
-			ls.chainsyncHeaderMutex.Unlock()
 			return nil
 		}
 		ls.chainsyncBlockfetchWaiting = true
 		return nil
 	}
-	// Block new chainsync block headers until we fetch pending block bodies
-	ls.chainsyncHeaderMutex.Lock()
 	// Request current bulk range
 	err := ls.config.BlockfetchRequestRangeFunc(
-		e.ConnectionId,
+		connId,
 		ls.chainsyncHeaderPoints[0],
 		ls.chainsyncHeaderPoints[len(ls.chainsyncHeaderPoints)-1],
 	)
 	if err != nil {
-		// Unblock chainsync block headers
-		ls.chainsyncHeaderMutex.Unlock()
 		return err
 	}
-	ls.chainsyncBlockfetchBusy = true
-	ls.chainsyncBlockfetchBusyTime = time.Now()
+	ls.chainsyncBlockfetchStarted(connId)
 	// Reset cached header points
 	ls.chainsyncHeaderPoints = nil
 	return nil
 }
 
+// chainsyncBlockfetchStarted records a blockfetch operation for the specified connection as in progress. New
+// chainsync block headers are paused while it's in progress if we've already got another batch worth queued up.
+// The caller must hold chainsyncHeaderPointsMutex
+func (ls *LedgerState) chainsyncBlockfetchStarted(connId ouroboros.ConnectionId) {
+	ls.chainsyncBlockfetchBusy = true
+	ls.chainsyncBlockfetchBusyTime = time.Now()
+	ls.chainsyncBlockfetchConnId = connId
+	if ls.chainsyncBlockfetchDoneChan == nil {
+		ls.chainsyncBlockfetchDoneChan = make(chan struct{})
+	}
+}
+
+// chainsyncBlockfetchDone clears the in-progress blockfetch operation and unblocks new chainsync block headers.
+// It's safe to call when there's no blockfetch operation in progress. The caller must hold
+// chainsyncHeaderPointsMutex
+func (ls *LedgerState) chainsyncBlockfetchDone() {
+	ls.chainsyncBlockfetchBusy = false
+	ls.chainsyncBlockfetchWaiting = false
+	if ls.chainsyncBlockfetchDoneChan != nil {
+		close(ls.chainsyncBlockfetchDoneChan)
+		ls.chainsyncBlockfetchDoneChan = nil
+	}
+}
+
 //nolint:unparam
 func (ls *LedgerState) handleEventBlockfetchBlock(e BlockfetchEvent) error {
-	// Check for out-of-order block events
-	// This is a stop-gap to handle disconnects during sync until we get chain selection working
-	if ls.chainsyncBlockEvents != nil {
-		if eventsLen := len(ls.chainsyncBlockEvents); eventsLen > 0 &&
-			e.Point.Slot < ls.chainsyncBlockEvents[eventsLen-1].Point.Slot {
-			tmpBlockEvents := make([]BlockfetchEvent, 0, eventsLen)
-			for _, tmpEvent := range ls.chainsyncBlockEvents {
-				if tmpEvent.Point.Slot >= e.Point.Slot {
-					break
-				}
-				tmpBlockEvents = append(tmpBlockEvents, tmpEvent)
-			}
-			ls.chainsyncBlockEvents = tmpBlockEvents
-		}
+	// Ignore blocks from a connection that's no longer the source for our chain. This can
+	// happen when we switch chains while a blockfetch operation is in progress
+	if !ls.chainsyncIsSelected(e.ConnectionId) {
+		return nil
 	}
 	ls.chainsyncBlockEvents = append(
 		ls.chainsyncBlockEvents,
@@ -203,35 +317,24 @@ func (ls *LedgerState) handleEventBlockfetchBlock(e BlockfetchEvent) error {
 	return nil
 }
 
-func (ls *LedgerState) processBlockEvents() error {
+// processBlockEvents applies the pending block events to our chain. When switching to a fork, the fork point
+// specifies where the fetched blocks branch off from our chain
+func (ls *LedgerState) processBlockEvents(forkPoint *ocommon.Point) error {
 	// XXX: move this into the loop?
 	ls.Lock()
 	defer ls.Unlock()
-	batchOffset := 0
-	for {
-		batchSize := min(
-			10, // Chosen to stay well under badger transaction size limit
-			len(ls.chainsyncBlockEvents)-batchOffset,
-		)
-		if batchSize <= 0 {
-			break
-		}
-		// Start a transaction
-		txn := ls.db.Transaction(true)
-		err := txn.Do(func(txn *database.Txn) error {
-			for _, evt := range ls.chainsyncBlockEvents[batchOffset : batchOffset+batchSize] {
-				if err := ls.processBlockEvent(txn, evt); err != nil {
-					return err
-				}
-			}
-			return nil
-		})
-		if err != nil {
+	// Discard pending block events when we're done, even on failure, so that we don't
+	// keep trying to apply the same blocks
+	defer func() {
+		ls.chainsyncBlockEvents = nil
+	}()
+	if forkPoint != nil {
+		if err := ls.applyForkBlockEvents(*forkPoint, ls.chainsyncBlockEvents); err != nil {
 			return err
 		}
-		batchOffset += batchSize
+	} else if err := ls.applyBlockEvents(ls.chainsyncBlockEvents); err != nil {
+		return err
 	}
-	ls.chainsyncBlockEvents = nil
 	ls.config.Logger.Info(
 		fmt.Sprintf(
 			"chain extended, new tip: %x at slot %d",
@@ -244,6 +347,129 @@ func (ls *LedgerState) processBlockEvents() error {
 	return nil
 }
 
+// applyBlockEvents processes the specified block events in small transaction batches. The caller
+// must hold the ledger state lock
+func (ls *LedgerState) applyBlockEvents(events []BlockfetchEvent) error {
+	for batchOffset := 0; batchOffset < len(events); batchOffset += blockfetchTxnBatchSize {
+		batchEnd := min(batchOffset+blockfetchTxnBatchSize, len(events))
+		if err := ls.applyBlockEventsTxn(events[batchOffset:batchEnd]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyForkBlockEvents switches our chain to a fork by rolling back to the fork point and applying the blocks
+// fetched from the fork. Our previous chain is restored if any of the blocks fail validation or the fork doesn't
+// end up longer than our previous chain. The caller must hold the ledger state lock
+func (ls *LedgerState) applyForkBlockEvents(
+	forkPoint ocommon.Point,
+	events []BlockfetchEvent,
+) error {
+	prevTip := ls.currentTip
+	var rolledBackBlocks []database.Block
+	txn := ls.db.Transaction(false)
+	err := txn.Do(func(txn *database.Txn) error {
+		var err error
+		rolledBackBlocks, err = database.BlocksAfterSlotTxn(txn, forkPoint.Slot)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("query blocks: %w", err)
+	}
+	if err := ls.rollback(forkPoint); err != nil {
+		return err
+	}
+	err = ls.applyBlockEvents(events)
+	if err == nil {
+		var preferred bool
+		preferred, err = forkPreferred(prevTip, rolledBackBlocks, ls.currentTip, events)
+		if err == nil && !preferred {
+			err = fmt.Errorf(
+				"fork from slot %d ending at block %d is not preferred over our previous tip at block %d",
+				forkPoint.Slot,
+				ls.currentTip.BlockNumber,
+				prevTip.BlockNumber,
+			)
+		}
+	}
+	if err == nil {
+		return nil
+	}
+	if err2 := ls.restoreBlocks(forkPoint, rolledBackBlocks); err2 != nil {
+		return errors.Join(err, fmt.Errorf("restore chain: %w", err2))
+	}
+	return err
+}
+
+// restoreBlocks rolls back to the specified point and re-applies the provided blocks from our previous chain.
+// The caller must hold the ledger state lock
+func (ls *LedgerState) restoreBlocks(
+	point ocommon.Point,
+	blocks []database.Block,
+) error {
+	if err := ls.rollback(point); err != nil {
+		return err
+	}
+	events := make([]BlockfetchEvent, 0, len(blocks))
+	for _, tmpBlock := range blocks {
+		blk, err := ledger.NewBlockFromCbor(tmpBlock.Type, tmpBlock.Cbor)
+		if err != nil {
+			return err
+		}
+		events = append(
+			events,
+			BlockfetchEvent{
+				Point: ocommon.NewPoint(tmpBlock.Slot, tmpBlock.Hash),
+				Block: blk,
+				Type:  tmpBlock.Type,
+			},
+		)
+	}
+	return ls.applyBlockEvents(events)
+}
+
+// applyBlockEventsTxn applies the block events in a single database transaction. The chain events for the
+// blocks are only published once the transaction has been committed
+func (ls *LedgerState) applyBlockEventsTxn(events []BlockfetchEvent) error {
+	var chainEvents []ChainBlockEvent
+	txn := ls.db.Transaction(true)
+	err := txn.Do(func(txn *database.Txn) error {
+		for _, evt := range events {
+			chainEvent, err := ls.processBlockEvent(txn, evt)
+			if err != nil {
+				return err
+			}
+			chainEvents = append(chainEvents, chainEvent)
+		}
+		return nil
+	})
+	if err != nil {
+		// Reload our in-memory state, since it may have been updated by blocks from the
+		// discarded transaction batch
+		return errors.Join(
+			err,
+			ls.loadEpoch(),
+			ls.loadPParams(),
+			ls.loadTip(),
+		)
+	}
+	for _, chainEvent := range chainEvents {
+		ls.config.EventBus.Publish(
+			ChainBlockEventType,
+			event.NewEvent(ChainBlockEventType, chainEvent),
+		)
+		ls.config.EventBus.Publish(
+			ChainUpdateEventType,
+			event.NewEvent(
+				ChainUpdateEventType,
+				ChainUpdateEvent{Block: &chainEvent},
+			),
+		)
+	}
+	return nil
+}
+
 func (ls *LedgerState) vacuumMetadata() error {
 	ls.config.Logger.Debug(
 		"vacuuming metadata database",
@@ -259,7 +485,8 @@ func (ls *LedgerState) vacuumMetadata() error {
 }
 
 func (ls *LedgerState) validateBlock(e BlockfetchEvent) error {
-	if ls.currentTip.BlockNumber > 0 {
+	// Skip the check when our tip is at origin
+	if len(ls.currentTip.Point.Hash) > 0 {
 		prevHashBytes, err := hex.DecodeString(e.Block.PrevHash())
 		if err != nil {
 			return err
@@ -276,6 +503,38 @@ func (ls *LedgerState) validateBlock(e BlockfetchEvent) error {
 	return nil
 }
 
+// blockNumber returns the block number for a block. Byron blocks don't include the block number, so we use the
+// chain difficulty from the block header, which counts the main blocks since genesis. An EBB has the same chain
+// difficulty as the block before it. The result is checked against the block number of our current tip
+func (ls *LedgerState) blockNumber(e BlockfetchEvent) (uint64, error) {
+	var ret uint64
+	var isEbb bool
+	switch b := e.Block.(type) {
+	case *ledger.ByronEpochBoundaryBlock:
+		ret = b.BlockHeader.ConsensusData.Difficulty.Value
+		isEbb = true
+	case *ledger.ByronMainBlock:
+		ret = b.BlockHeader.ConsensusData.Difficulty.Unknown
+	default:
+		return e.Block.BlockNumber(), nil
+	}
+	if len(ls.currentTip.Point.Hash) > 0 {
+		expected := ls.currentTip.BlockNumber
+		if !isEbb {
+			expected++
+		}
+		if ret != expected {
+			return 0, fmt.Errorf(
+				"block %x has chain difficulty %d, expected %d",
+				e.Point.Hash,
+				ret,
+				expected,
+			)
+		}
+	}
+	return ret, nil
+}
+
 func (ls *LedgerState) processGenesisBlock(
 	txn *database.Txn,
 	point ocommon.Point,
@@ -347,6 +606,7 @@ func (ls *LedgerState) processGenesisBlock(
 				AddedSlot:  0,
 				PaymentKey: outAddr.PaymentKeyHash().Bytes(),
 				StakingKey: outAddr.StakeKeyHash().Bytes(),
+				Amount:     utxo.Output.Amount(),
 				Cbor:       outputCbor,
 			}
 			if err := ls.addUtxo(txn, tmpUtxo); err != nil {
@@ -357,6 +617,23 @@ func (ls *LedgerState) processGenesisBlock(
 	return nil
 }
 
+// nonceStabilityWindow returns the number of slots before the end of an epoch after which blocks no longer
+// contribute to the nonce for the following epoch
+func (ls *LedgerState) nonceStabilityWindow() (uint64, error) {
+	byronGenesis := ls.config.CardanoNodeConfig.ByronGenesis()
+	shelleyGenesis := ls.config.CardanoNodeConfig.ShelleyGenesis()
+	if byronGenesis == nil || shelleyGenesis == nil {
+		return 0, errors.New("could not get genesis config")
+	}
+	return new(big.Rat).Quo(
+		big.NewRat(
+			int64(3*byronGenesis.ProtocolConsts.K),
+			1,
+		),
+		shelleyGenesis.ActiveSlotsCoeff.Rat,
+	).Num().Uint64(), nil
+}
+
 func (ls *LedgerState) calculateEpochNonce(
 	txn *database.Txn,
 	epochStartSlot uint64,
@@ -373,18 +650,10 @@ func (ls *LedgerState) calculateEpochNonce(
 		return genesisHashBytes, err
 	}
 	// Calculate stability window
-	byronGenesis := ls.config.CardanoNodeConfig.ByronGenesis()
-	shelleyGenesis := ls.config.CardanoNodeConfig.ShelleyGenesis()
-	if byronGenesis == nil || shelleyGenesis == nil {
-		return nil, errors.New("could not get genesis config")
+	stabilityWindow, err := ls.nonceStabilityWindow()
+	if err != nil {
+		return nil, err
 	}
-	stabilityWindow := new(big.Rat).Quo(
-		big.NewRat(
-			int64(3*byronGenesis.ProtocolConsts.K),
-			1,
-		),
-		shelleyGenesis.ActiveSlotsCoeff.Rat,
-	).Num().Uint64()
 	stabilityWindowStartSlot := epochStartSlot - stabilityWindow
 	// Get last block before stability window
 	blockBeforeStabilityWindow, err := database.BlockBeforeSlotTxn(
@@ -419,13 +688,33 @@ func (ls *LedgerState) processEpochRollover(
 	point ocommon.Point,
 	block ledger.Block,
 ) error {
-	// Check for epoch rollover
-	if point.Slot > ls.currentEpoch.StartSlot+uint64(
+	// Check for epoch rollover. The first slot of the next epoch belongs to the new epoch, which
+	// includes the Byron EBB for the new epoch
+	if point.Slot >= ls.currentEpoch.StartSlot+uint64(
 		ls.currentEpoch.LengthInSlots,
 	) {
-		// Apply pending pparam updates
-		if err := ls.applyPParamUpdates(txn, ls.currentEpoch.EpochId, point.Slot); err != nil {
-			return err
+		epochStartSlot := ls.currentEpoch.StartSlot + uint64(
+			ls.currentEpoch.LengthInSlots,
+		)
+		// Calculate and pay rewards. This uses the protocol parameters from the
+		// previous epoch, so it must happen before applying pparam updates
+		if err := ls.processRewards(txn, ls.currentEpoch.EpochId+1, epochStartSlot); err != nil {
+			return fmt.Errorf("calculate rewards: %w", err)
+		}
+		// Apply pending pparam updates. Starting in Conway, pparam updates are made
+		// through governance actions instead
+		if ls.currentEra.GovParamsFunc != nil {
+			if err := ls.processGovernance(txn, ls.currentEpoch.EpochId+1, epochStartSlot); err != nil {
+				return fmt.Errorf("process governance: %w", err)
+			}
+		} else {
+			if err := ls.applyPParamUpdates(txn, ls.currentEpoch.EpochId, epochStartSlot); err != nil {
+				return err
+			}
+		}
+		// Retire pools and refund their deposits
+		if err := ls.processPoolRetirements(txn, ls.currentEpoch.EpochId+1, epochStartSlot); err != nil {
+			return fmt.Errorf("process pool retirements: %w", err)
 		}
 		// Create next epoch record
 		epochSlotLength, epochLength, err := ls.currentEra.EpochLengthFunc(
@@ -434,9 +723,6 @@ func (ls *LedgerState) processEpochRollover(
 		if err != nil {
 			return err
 		}
-		epochStartSlot := ls.currentEpoch.StartSlot + uint64(
-			ls.currentEpoch.LengthInSlots,
-		)
 		tmpNonce, err := ls.calculateEpochNonce(txn, epochStartSlot)
 		if err != nil {
 			return err
@@ -459,6 +745,21 @@ func (ls *LedgerState) processEpochRollover(
 		}
 		ls.currentEpoch = newEpoch
 		ls.metrics.epochNum.Set(float64(newEpoch.EpochId))
+		// Take a stake snapshot at the start of the new epoch. There is no
+		// stake delegation in the Byron era, so we skip this
+		if ls.currentEra.Id != eras.ByronEraDesc.Id {
+			if err := ls.snapshotStake(txn, newEpoch.EpochId, epochStartSlot); err != nil {
+				return fmt.Errorf("take stake snapshot: %w", err)
+			}
+		}
+		// Take a DRep stake snapshot for ratifying governance actions at the end of the new epoch. There's
+		// no snapshot for the epoch in which we transition to Conway, but DReps don't vote until the end of
+		// the bootstrap phase anyway
+		if ls.currentEra.GovParamsFunc != nil {
+			if err := ls.snapshotDrepStake(txn, newEpoch.EpochId, epochStartSlot); err != nil {
+				return fmt.Errorf("take DRep stake snapshot: %w", err)
+			}
+		}
 		ls.config.Logger.Debug(
 			"added next epoch to DB",
 			"epoch", fmt.Sprintf("%+v", newEpoch),
@@ -475,21 +776,23 @@ func (ls *LedgerState) processEpochRollover(
 	return nil
 }
 
+// processBlockEvent adds a block to the ledger and returns the event for it, which must not be published until
+// the transaction has been committed
 func (ls *LedgerState) processBlockEvent(
 	txn *database.Txn,
 	e BlockfetchEvent,
-) error {
+) (ChainBlockEvent, error) {
 	// Check that the block fits on our current chain
 	if err := ls.validateBlock(e); err != nil {
-		return err
+		return ChainBlockEvent{}, err
 	}
 	// Special handling for genesis block
 	if err := ls.processGenesisBlock(txn, e.Point, e.Block); err != nil {
-		return err
+		return ChainBlockEvent{}, err
 	}
 	// Check for epoch rollover
 	if err := ls.processEpochRollover(txn, e.Point, e.Block); err != nil {
-		return err
+		return ChainBlockEvent{}, err
 	}
 	// TODO: track this using protocol params and hard forks
 	// Check for era change
@@ -498,10 +801,21 @@ func (ls *LedgerState) processBlockEvent(
 		// Transition through every era between the current and the target era
 		for nextEraId := ls.currentEra.Id + 1; nextEraId <= targetEraId; nextEraId++ {
 			if err := ls.transitionToEra(txn, nextEraId, ls.currentEpoch.EpochId, e.Point.Slot); err != nil {
-				return err
+				return ChainBlockEvent{}, err
 			}
 		}
 	}
+	// Validate block body and header
+	if ls.config.ValidationLevel >= ValidationLevelBody {
+		if err := ls.validateBlockBody(e); err != nil {
+			return ChainBlockEvent{}, BlockValidationError{Point: e.Point, Err: err}
+		}
+	}
+	if ls.config.ValidationLevel >= ValidationLevelFull {
+		if err := ls.validateBlockHeader(txn, e); err != nil {
+			return ChainBlockEvent{}, BlockValidationError{Point: e.Point, Err: err}
+		}
+	}
 	// Calculate block rolling nonce
 	var blockNonce []byte
 	if ls.currentEra.CalculateEtaVFunc != nil {
@@ -511,47 +825,57 @@ func (ls *LedgerState) processBlockEvent(
 			e.Block,
 		)
 		if err != nil {
-			return err
+			return ChainBlockEvent{}, err
 		}
 		blockNonce = tmpNonce
 	}
 	// Add block to database
 	prevHashBytes, err := hex.DecodeString(e.Block.PrevHash())
 	if err != nil {
-		return err
+		return ChainBlockEvent{}, err
+	}
+	blockNumber, err := ls.blockNumber(e)
+	if err != nil {
+		return ChainBlockEvent{}, err
 	}
 	tmpBlock := database.Block{
-		Slot: e.Point.Slot,
-		Hash: e.Point.Hash,
-		// TODO: figure out something for Byron. this won't work, since the
-		// block number isn't stored in the block itself
-		Number:   e.Block.BlockNumber(),
+		Slot:     e.Point.Slot,
+		Hash:     e.Point.Hash,
+		Number:   blockNumber,
 		Type:     e.Type,
 		PrevHash: prevHashBytes,
 		Nonce:    blockNonce,
 		Cbor:     e.Block.Cbor(),
 	}
 	if err := ls.addBlock(txn, tmpBlock); err != nil {
-		return fmt.Errorf("add block: %w", err)
+		return ChainBlockEvent{}, fmt.Errorf("add block: %w", err)
+	}
+	// Process transactions. Byron transactions are wrapped with their TxAux size for the fee check
+	txs := e.Block.Transactions()
+	if byronBlock, ok := e.Block.(*ledger.ByronMainBlock); ok {
+		txs, err = eras.ByronBlockTransactions(byronBlock)
+		if err != nil {
+			return ChainBlockEvent{}, err
+		}
 	}
-	// Process transactions
-	for _, tx := range e.Block.Transactions() {
+	for _, tx := range txs {
 		if err := ls.processTransaction(txn, tx, e.Point); err != nil {
-			return err
+			return ChainBlockEvent{}, err
 		}
 	}
-	// Generate event
-	ls.config.EventBus.Publish(
-		ChainBlockEventType,
-		event.NewEvent(
-			ChainBlockEventType,
-			ChainBlockEvent{
-				Point: e.Point,
-				Block: tmpBlock,
-			},
-		),
-	)
-	return nil
+	// Record block producer for calculating rewards. There are no stake pools in Byron
+	if ls.currentEra.Id != eras.ByronEraDesc.Id {
+		if err := ls.recordPoolBlock(txn, e.Point, e.Block); err != nil {
+			return ChainBlockEvent{}, fmt.Errorf("record pool block: %w", err)
+		}
+		if err := ls.recordOpCertCounter(txn, e.Point.Slot, e.Block); err != nil {
+			return ChainBlockEvent{}, fmt.Errorf("record opcert counter: %w", err)
+		}
+	}
+	return ChainBlockEvent{
+		Point: e.Point,
+		Block: tmpBlock,
+	}, nil
 }
 
 func (ls *LedgerState) processTransaction(
@@ -572,10 +896,15 @@ func (ls *LedgerState) processTransaction(
 			ls.currentPParams,
 		)
 		if err != nil {
+			if ls.config.ValidationLevel >= ValidationLevelTx {
+				return BlockValidationError{
+					Point: point,
+					Err:   fmt.Errorf("TX %s: %w", tx.Hash(), err),
+				}
+			}
 			ls.config.Logger.Warn(
 				"TX " + tx.Hash() + " failed validation: " + err.Error(),
 			)
-			// return fmt.Errorf("TX validation failure: %w", err)
 		}
 	}
 	// Process consumed UTxOs
@@ -593,6 +922,7 @@ func (ls *LedgerState) processTransaction(
 			AddedSlot:  point.Slot,
 			PaymentKey: outAddr.PaymentKeyHash().Bytes(),
 			StakingKey: outAddr.StakeKeyHash().Bytes(),
+			Amount:     produced.Output.Amount(),
 			Cbor:       produced.Output.Cbor(),
 		}
 		if err := ls.addUtxo(txn, tmpUtxo); err != nil {
@@ -619,41 +949,94 @@ func (ls *LedgerState) processTransaction(
 	if err := ls.processTransactionCertificates(txn, point, tx); err != nil {
 		return err
 	}
+	// Governance proposals and votes
+	if err := ls.processTransactionGovernance(txn, point, tx); err != nil {
+		return err
+	}
+	// Reward account withdrawals. These aren't applied for TXs that fail phase-2 validation
+	if tx.IsValid() {
+		for addr, amount := range tx.Withdrawals() {
+			err := txn.DB().Metadata().SetWithdrawal(
+				addr.StakeKeyHash().Bytes(),
+				amount,
+				point.Slot,
+				txn.Metadata(),
+			)
+			if err != nil {
+				return fmt.Errorf("record withdrawal: %w", err)
+			}
+		}
+	}
 	return nil
 }
 
 func (ls *LedgerState) handleEventBlockfetchBatchDone(e BlockfetchEvent) error {
+	// Check for a pending switch to a fork from the connection that we fetched the blocks from
+	var forkPoint *ocommon.Point
+	ls.chainsyncCandidatesMutex.Lock()
+	if len(ls.chainsyncBlockEvents) > 0 &&
+		ls.chainsyncSelectedConnId != nil &&
+		*ls.chainsyncSelectedConnId == e.ConnectionId {
+		forkPoint = ls.chainsyncForkPoint
+		ls.chainsyncForkPoint = nil
+	}
+	ls.chainsyncCandidatesMutex.Unlock()
 	// Process pending block events
-	if err := ls.processBlockEvents(); err != nil {
-		ls.chainsyncBlockfetchBusy = false
-		ls.chainsyncBlockfetchWaiting = false
-		ls.chainsyncHeaderMutex.Unlock()
+	if err := ls.processBlockEvents(forkPoint); err != nil {
+		ls.chainsyncHeaderPointsMutex.Lock()
+		ls.chainsyncBlockfetchDone()
+		ls.chainsyncHeaderPointsMutex.Unlock()
+		// Stop following the chain from the connection that provided the bad block
+		ls.chainsyncCandidatesMutex.Lock()
+		if cand, ok := ls.chainsyncCandidates[e.ConnectionId]; ok {
+			cand.anchorValid = false
+		}
+		if ls.chainsyncSelectedConnId != nil &&
+			*ls.chainsyncSelectedConnId == e.ConnectionId {
+			ls.chainsyncSelectedConnId = nil
+			ls.chainsyncForkPoint = nil
+		}
+		ls.chainsyncCandidatesMutex.Unlock()
+		// Disconnect the peer that provided an invalid block
+		var validationErr BlockValidationError
+		if errors.As(err, &validationErr) &&
+			ls.config.ConnectionCloseFunc != nil {
+			ls.config.ConnectionCloseFunc(e.ConnectionId, err)
+		}
+		if err2 := ls.chainsyncSelectChain(); err2 != nil {
+			return errors.Join(err, err2)
+		}
 		return err
 	}
+	// Update candidate chains now that our chain has been extended
+	if err := ls.chainsyncPruneCandidates(); err != nil {
+		return err
+	}
+	// Determine connection to fetch further blocks from
+	ls.chainsyncCandidatesMutex.Lock()
+	selectedConnId := ls.chainsyncSelectedConnId
+	ls.chainsyncCandidatesMutex.Unlock()
 	ls.chainsyncHeaderPointsMutex.Lock()
 	defer ls.chainsyncHeaderPointsMutex.Unlock()
 	// Check for pending block range request
 	if !ls.chainsyncBlockfetchWaiting ||
-		len(ls.chainsyncHeaderPoints) == 0 {
-		ls.chainsyncBlockfetchBusy = false
-		ls.chainsyncBlockfetchWaiting = false
+		len(ls.chainsyncHeaderPoints) == 0 ||
+		selectedConnId == nil {
 		// Allow collection of more block headers via chainsync
-		ls.chainsyncHeaderMutex.Unlock()
+		ls.chainsyncBlockfetchDone()
 		return nil
 	}
 	// Request waiting bulk range
 	err := ls.config.BlockfetchRequestRangeFunc(
-		e.ConnectionId,
+		*selectedConnId,
 		ls.chainsyncHeaderPoints[0],
 		ls.chainsyncHeaderPoints[len(ls.chainsyncHeaderPoints)-1],
 	)
 	if err != nil {
-		ls.chainsyncBlockfetchBusy = false
-		ls.chainsyncBlockfetchWaiting = false
-		ls.chainsyncHeaderMutex.Unlock()
+		ls.chainsyncBlockfetchDone()
 		return err
 	}
-	ls.chainsyncBlockfetchBusyTime = time.Now()
+	ls.chainsyncBlockfetchStarted(*selectedConnId)
 	ls.chainsyncBlockfetchWaiting = false
 	// Reset cached header points
 	ls.chainsyncHeaderPoints = nil
@@ -0,0 +1,147 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/ledger"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+func TestBlockNumberByron(t *testing.T) {
+	testEbb := func(difficulty uint64) ledger.Block {
+		header := &ledger.ByronEpochBounaryBlockHeader{}
+		header.ConsensusData.Difficulty.Value = difficulty
+		return &ledger.ByronEpochBoundaryBlock{BlockHeader: header}
+	}
+	testMainBlock := func(difficulty uint64) ledger.Block {
+		header := &ledger.ByronMainBlockHeader{}
+		header.ConsensusData.Difficulty.Unknown = difficulty
+		return &ledger.ByronMainBlock{BlockHeader: header}
+	}
+	testDefs := []struct {
+		tipBlockNumber uint64
+		emptyTip       bool
+		block          ledger.Block
+		expectedNumber uint64
+		expectedErr    bool
+	}{
+		// EBB at the start of the chain
+		{
+			emptyTip: true,
+			block:    testEbb(0),
+		},
+		{
+			tipBlockNumber: 0,
+			block:          testMainBlock(1),
+			expectedNumber: 1,
+		},
+		// An EBB has the same block number as the block before it
+		{
+			tipBlockNumber: 10,
+			block:          testEbb(10),
+			expectedNumber: 10,
+		},
+		{
+			tipBlockNumber: 10,
+			block:          testMainBlock(11),
+			expectedNumber: 11,
+		},
+		{
+			tipBlockNumber: 10,
+			block:          testEbb(11),
+			expectedErr:    true,
+		},
+		{
+			tipBlockNumber: 10,
+			block:          testMainBlock(10),
+			expectedErr:    true,
+		},
+		{
+			tipBlockNumber: 10,
+			block:          testMainBlock(12),
+			expectedErr:    true,
+		},
+	}
+	for _, testDef := range testDefs {
+		ls := &LedgerState{}
+		if !testDef.emptyTip {
+			ls.currentTip = ochainsync.Tip{
+				Point:       ocommon.NewPoint(1, []byte{0x01}),
+				BlockNumber: testDef.tipBlockNumber,
+			}
+		}
+		blockNumber, err := ls.blockNumber(BlockfetchEvent{Block: testDef.block})
+		if testDef.expectedErr {
+			if err == nil {
+				t.Fatalf("did not get expected error for block after tip %d", testDef.tipBlockNumber)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if blockNumber != testDef.expectedNumber {
+			t.Fatalf("did not get expected block number: got %d, expected %d", blockNumber, testDef.expectedNumber)
+		}
+	}
+}
+
+func TestApplyBlockEventsTxnPublishesAfterCommit(t *testing.T) {
+	ls := testImmutableDbLedgerState(t)
+	_, blockEvtChan := ls.config.EventBus.Subscribe(ChainBlockEventType)
+	blockEvents := testImmutableDbBlockEvents(t, 4)
+	testDefs := []struct {
+		events         []BlockfetchEvent
+		expectError    bool
+		expectedPoints []ocommon.Point
+	}{
+		// The second block doesn't fit on the first, so the batch is discarded along with the event for the first
+		{
+			events:      []BlockfetchEvent{blockEvents[1], blockEvents[3]},
+			expectError: true,
+		},
+		{
+			events:         blockEvents[1:3],
+			expectedPoints: []ocommon.Point{blockEvents[1].Point, blockEvents[2].Point},
+		},
+	}
+	for _, testDef := range testDefs {
+		err := ls.applyBlockEventsTxn(testDef.events)
+		if testDef.expectError != (err != nil) {
+			t.Fatalf("did not get expected error result: got %v", err)
+		}
+		if len(blockEvtChan) != len(testDef.expectedPoints) {
+			t.Fatalf(
+				"did not get expected number of block events: got %d, expected %d",
+				len(blockEvtChan),
+				len(testDef.expectedPoints),
+			)
+		}
+		for _, expectedPoint := range testDef.expectedPoints {
+			evt := <-blockEvtChan
+			blockEvt := evt.Data.(ChainBlockEvent)
+			if blockEvt.Point.Slot != expectedPoint.Slot {
+				t.Fatalf(
+					"did not get expected block event: got slot %d, expected slot %d",
+					blockEvt.Point.Slot,
+					expectedPoint.Slot,
+				)
+			}
+		}
+	}
+}
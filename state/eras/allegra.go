@@ -30,6 +30,7 @@ import (
 var AllegraEraDesc = EraDesc{
 	Id:                      allegra.EraIdAllegra,
 	Name:                    allegra.EraNameAllegra,
+	MinMajorVersion:         3,
 	DecodePParamsFunc:       DecodePParamsAllegra,
 	DecodePParamsUpdateFunc: DecodePParamsUpdateAllegra,
 	PParamsUpdateFunc:       PParamsUpdateAllegra,
@@ -38,6 +39,7 @@ var AllegraEraDesc = EraDesc{
 	CalculateEtaVFunc:       CalculateEtaVAllegra,
 	CertDepositFunc:         CertDepositAllegra,
 	ValidateTxFunc:          ValidateTxAllegra,
+	RewardParamsFunc:        RewardParamsAllegra,
 }
 
 func DecodePParamsAllegra(data []byte) (lcommon.ProtocolParameters, error) {
@@ -152,3 +154,19 @@ func ValidateTxAllegra(
 	}
 	return errors.Join(errs...)
 }
+
+func RewardParamsAllegra(
+	pp lcommon.ProtocolParameters,
+) (RewardParams, error) {
+	tmpPparams, ok := pp.(*allegra.AllegraProtocolParameters)
+	if !ok {
+		return RewardParams{}, errors.New("pparams are not expected type")
+	}
+	return RewardParams{
+		NOpt:             tmpPparams.NOpt,
+		A0:               tmpPparams.A0.Rat,
+		Rho:              tmpPparams.Rho.Rat,
+		Tau:              tmpPparams.Tau.Rat,
+		Decentralization: tmpPparams.Decentralization.Rat,
+	}, nil
+}
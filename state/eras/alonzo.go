@@ -30,6 +30,7 @@ import (
 var AlonzoEraDesc = EraDesc{
 	Id:                      alonzo.EraIdAlonzo,
 	Name:                    alonzo.EraNameAlonzo,
+	MinMajorVersion:         5,
 	DecodePParamsFunc:       DecodePParamsAlonzo,
 	DecodePParamsUpdateFunc: DecodePParamsUpdateAlonzo,
 	PParamsUpdateFunc:       PParamsUpdateAlonzo,
@@ -38,6 +39,7 @@ var AlonzoEraDesc = EraDesc{
 	CalculateEtaVFunc:       CalculateEtaVAlonzo,
 	CertDepositFunc:         CertDepositAlonzo,
 	ValidateTxFunc:          ValidateTxAlonzo,
+	RewardParamsFunc:        RewardParamsAlonzo,
 }
 
 func DecodePParamsAlonzo(data []byte) (lcommon.ProtocolParameters, error) {
@@ -154,3 +156,19 @@ func ValidateTxAlonzo(
 	}
 	return errors.Join(errs...)
 }
+
+func RewardParamsAlonzo(
+	pp lcommon.ProtocolParameters,
+) (RewardParams, error) {
+	tmpPparams, ok := pp.(*alonzo.AlonzoProtocolParameters)
+	if !ok {
+		return RewardParams{}, errors.New("pparams are not expected type")
+	}
+	return RewardParams{
+		NOpt:             tmpPparams.NOpt,
+		A0:               tmpPparams.A0.Rat,
+		Rho:              tmpPparams.Rho.Rat,
+		Tau:              tmpPparams.Tau.Rat,
+		Decentralization: tmpPparams.Decentralization.Rat,
+	}, nil
+}
@@ -18,6 +18,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/blinklabs-io/dingo/config/cardano"
 	"github.com/blinklabs-io/gouroboros/cbor"
@@ -30,6 +31,7 @@ import (
 var BabbageEraDesc = EraDesc{
 	Id:                      babbage.EraIdBabbage,
 	Name:                    babbage.EraNameBabbage,
+	MinMajorVersion:         7,
 	DecodePParamsFunc:       DecodePParamsBabbage,
 	DecodePParamsUpdateFunc: DecodePParamsUpdateBabbage,
 	PParamsUpdateFunc:       PParamsUpdateBabbage,
@@ -38,6 +40,7 @@ var BabbageEraDesc = EraDesc{
 	CalculateEtaVFunc:       CalculateEtaVBabbage,
 	CertDepositFunc:         CertDepositBabbage,
 	ValidateTxFunc:          ValidateTxBabbage,
+	RewardParamsFunc:        RewardParamsBabbage,
 }
 
 func DecodePParamsBabbage(data []byte) (lcommon.ProtocolParameters, error) {
@@ -152,3 +155,21 @@ func ValidateTxBabbage(
 	}
 	return errors.Join(errs...)
 }
+
+func RewardParamsBabbage(
+	pp lcommon.ProtocolParameters,
+) (RewardParams, error) {
+	tmpPparams, ok := pp.(*babbage.BabbageProtocolParameters)
+	if !ok {
+		return RewardParams{}, errors.New("pparams are not expected type")
+	}
+	// The decentralization parameter was removed in Babbage, and blocks are
+	// always produced by stake pools
+	return RewardParams{
+		NOpt:             tmpPparams.NOpt,
+		A0:               tmpPparams.A0.Rat,
+		Rho:              tmpPparams.Rho.Rat,
+		Tau:              tmpPparams.Tau.Rat,
+		Decentralization: new(big.Rat),
+	}, nil
+}
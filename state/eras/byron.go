@@ -16,15 +16,134 @@ package eras
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/blinklabs-io/dingo/config/cardano"
+	"github.com/blinklabs-io/gouroboros/cbor"
 	"github.com/blinklabs-io/gouroboros/ledger/byron"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/shelley"
+	utxorpc "github.com/utxorpc/go-codegen/utxorpc/v1alpha/cardano"
 )
 
 var ByronEraDesc = EraDesc{
 	Id:              byron.EraIdByron,
 	Name:            byron.EraNameByron,
+	MinMajorVersion: 0,
 	EpochLengthFunc: EpochLengthByron,
+	ValidateTxFunc:  ValidateTxByron,
+}
+
+// Byron transaction fee policy values are in units of 1/1000000000 lovelace
+const byronTxFeePolicyDenominator = 1_000_000_000
+
+// ByronProtocolParameters contains the Byron protocol parameters used for transaction validation. These aren't
+// stored on-chain like the protocol parameters for later eras, so they're taken from the Byron genesis
+type ByronProtocolParameters struct {
+	MaxTxSize       uint64
+	TxFeeSummand    uint64
+	TxFeeMultiplier uint64
+}
+
+func (p *ByronProtocolParameters) Utxorpc() *utxorpc.PParams {
+	return &utxorpc.PParams{
+		MaxTxSize:         p.MaxTxSize,
+		MinFeeConstant:    p.TxFeeSummand / byronTxFeePolicyDenominator,
+		MinFeeCoefficient: p.TxFeeMultiplier / byronTxFeePolicyDenominator,
+	}
+}
+
+// MinFee returns the minimum fee for a transaction of the specified size
+func (p *ByronProtocolParameters) MinFee(txSize uint64) uint64 {
+	fee := p.TxFeeSummand + (p.TxFeeMultiplier * txSize)
+	return fee / byronTxFeePolicyDenominator
+}
+
+// ByronTxAux is a Byron transaction along with the size of its TxAux (the transaction body and witnesses), which
+// is what the fee policy and max TX size apply to. The transaction body alone is all that's otherwise available
+type ByronTxAux struct {
+	*byron.ByronTransaction
+	txAuxSize uint64
+}
+
+// NewByronTxAuxFromCbor decodes a CBOR-encoded Byron TxAux
+func NewByronTxAuxFromCbor(data []byte) (*ByronTxAux, error) {
+	var txAux []cbor.RawMessage
+	if _, err := cbor.Decode(data, &txAux); err != nil {
+		return nil, fmt.Errorf("decode Byron TxAux: %w", err)
+	}
+	if len(txAux) != 2 {
+		return nil, fmt.Errorf(
+			"decode Byron TxAux: expected 2 items, got %d",
+			len(txAux),
+		)
+	}
+	tx, err := byron.NewByronTransactionFromCbor(txAux[0])
+	if err != nil {
+		return nil, err
+	}
+	return &ByronTxAux{
+		ByronTransaction: tx,
+		txAuxSize:        uint64(len(data)),
+	}, nil
+}
+
+// TxAuxSize returns the size of the CBOR-encoded TxAux
+func (t *ByronTxAux) TxAuxSize() uint64 {
+	return t.txAuxSize
+}
+
+// ByronBlockTransactions returns the transactions from a Byron main block with their TxAux sizes
+func ByronBlockTransactions(
+	block *byron.ByronMainBlock,
+) ([]lcommon.Transaction, error) {
+	// The block body is [txPayload, sscPayload, dlgPayload, updPayload], and the TX payload is a list of TxAux
+	var body []cbor.RawMessage
+	if _, err := cbor.Decode(block.Body.Cbor(), &body); err != nil {
+		return nil, fmt.Errorf("decode Byron block body: %w", err)
+	}
+	if len(body) == 0 {
+		return nil, errors.New("decode Byron block body: missing TX payload")
+	}
+	var txPayload []cbor.RawMessage
+	if _, err := cbor.Decode(body[0], &txPayload); err != nil {
+		return nil, fmt.Errorf("decode Byron block TX payload: %w", err)
+	}
+	ret := make([]lcommon.Transaction, 0, len(txPayload))
+	for _, txAuxCbor := range txPayload {
+		txAux, err := NewByronTxAuxFromCbor(txAuxCbor)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, txAux)
+	}
+	return ret, nil
+}
+
+// byronTxSize returns the size used for the size-based checks on a Byron transaction. Only the transaction body
+// is available for a transaction that isn't a ByronTxAux, so its size is used as a lower bound
+func byronTxSize(tx lcommon.Transaction) uint64 {
+	if txAux, ok := tx.(*ByronTxAux); ok {
+		return txAux.TxAuxSize()
+	}
+	return uint64(len(tx.Cbor()))
+}
+
+// PParamsByron returns the Byron protocol parameters from the Byron genesis
+func PParamsByron(
+	nodeConfig *cardano.CardanoNodeConfig,
+) (*ByronProtocolParameters, error) {
+	byronGenesis := nodeConfig.ByronGenesis()
+	if byronGenesis == nil {
+		return nil, errors.New("unable to get byron genesis")
+	}
+	// These are known to be non-negative
+	// #nosec G115
+	return &ByronProtocolParameters{
+		MaxTxSize:       uint64(byronGenesis.BlockVersionData.MaxTxSize),
+		TxFeeSummand:    uint64(byronGenesis.BlockVersionData.TxFeePolicy.Summand),
+		TxFeeMultiplier: uint64(byronGenesis.BlockVersionData.TxFeePolicy.Multiplier),
+	}, nil
 }
 
 func EpochLengthByron(
@@ -40,3 +159,93 @@ func EpochLengthByron(
 		uint(byronGenesis.ProtocolConsts.K * 10),
 		nil
 }
+
+// ValidateTxByron validates a Byron transaction against the UTxO rules. The transaction witnesses aren't verified.
+// The size-based checks use the full TxAux size when the transaction is a ByronTxAux
+func ValidateTxByron(
+	tx lcommon.Transaction,
+	slot uint64,
+	ls lcommon.LedgerState,
+	pp lcommon.ProtocolParameters,
+) error {
+	tmpPparams, ok := pp.(*ByronProtocolParameters)
+	if !ok {
+		return errors.New("pparams are not expected type")
+	}
+	errs := []error{}
+	if len(tx.Inputs()) == 0 {
+		errs = append(errs, shelley.InputSetEmptyUtxoError{})
+	}
+	if len(tx.Outputs()) == 0 {
+		errs = append(errs, errors.New("transaction has no outputs"))
+	}
+	// Inputs must exist in the UTxO set and can't be spent more than once in the same TX
+	var consumed uint64
+	var badInputs []lcommon.TransactionInput
+	seenInputs := make(map[string]bool)
+	for _, tmpInput := range tx.Inputs() {
+		if seenInputs[tmpInput.String()] {
+			errs = append(
+				errs,
+				fmt.Errorf("duplicate input: %s", tmpInput.String()),
+			)
+			continue
+		}
+		seenInputs[tmpInput.String()] = true
+		utxo, err := ls.UtxoById(tmpInput)
+		if err != nil {
+			badInputs = append(badInputs, tmpInput)
+			continue
+		}
+		consumed += utxo.Output.Amount()
+	}
+	if len(badInputs) > 0 {
+		errs = append(errs, shelley.BadInputsUtxoError{Inputs: badInputs})
+	}
+	// Outputs must have a non-zero amount
+	var produced uint64
+	var badOutputs []lcommon.TransactionOutput
+	for _, tmpOutput := range tx.Outputs() {
+		if tmpOutput.Amount() == 0 {
+			badOutputs = append(badOutputs, tmpOutput)
+		}
+		produced += tmpOutput.Amount()
+	}
+	if len(badOutputs) > 0 {
+		errs = append(errs, shelley.OutputTooSmallUtxoError{Outputs: badOutputs})
+	}
+	// The fee is the difference between the consumed and produced amounts, and there is no fee
+	// to check if any inputs are missing
+	if len(badInputs) == 0 {
+		if produced > consumed {
+			errs = append(
+				errs,
+				shelley.ValueNotConservedUtxoError{
+					Consumed: consumed,
+					Produced: produced,
+				},
+			)
+		} else {
+			minFee := tmpPparams.MinFee(byronTxSize(tx))
+			if fee := consumed - produced; fee < minFee {
+				errs = append(
+					errs,
+					shelley.FeeTooSmallUtxoError{
+						Provided: fee,
+						Min:      minFee,
+					},
+				)
+			}
+		}
+	}
+	if txSize := byronTxSize(tx); tmpPparams.MaxTxSize > 0 && txSize > tmpPparams.MaxTxSize {
+		errs = append(
+			errs,
+			shelley.MaxTxSizeUtxoError{
+				TxSize:    uint(txSize),
+				MaxTxSize: uint(tmpPparams.MaxTxSize),
+			},
+		)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,204 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eras_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/config/cardano"
+	"github.com/blinklabs-io/dingo/state/eras"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/byron"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/shelley"
+)
+
+// Byron TxAux with one input, two outputs and one witness, in the mainnet format. The transaction body is 150
+// bytes and the TxAux is 291 bytes
+const (
+	testByronTxHex    = "83818200d8185824825820abababababababababababababababababababababababababababababababab00828282d818582483581c171850d32f1635626a08a10b975cc5fd91956e543a0010750f9f3c09a1024102001a1d725a431a000f42408282d818582483581c1908de5efe4b7c9d692c1044fdf467663c70a655fc29ac667e9ae668a1024102001a32d7277f1a0086cfb8a0"
+	testByronTxAuxHex = "82" + testByronTxHex + "818200d818588582584001010101010101010101010101010101010101010101010101010101010101010101010101010101010101010101010101010101010101010101010101010101584002020202020202020202020202020202020202020202020202020202020202020202020202020202020202020202020202020202020202020202020202020202"
+	// Byron block body containing the above TxAux and empty SSC, delegation and update payloads
+	testByronBlockBodyHex = "8481" + testByronTxAuxHex + "8200a080828080"
+	// The outputs of the test transaction add up to this
+	testByronTxProduced = 9_835_000
+)
+
+// testByronLedgerState provides the UTxO lookups needed for validating the test transaction
+type testByronLedgerState struct {
+	utxos map[string]lcommon.Utxo
+}
+
+func (s *testByronLedgerState) UtxoById(
+	input lcommon.TransactionInput,
+) (lcommon.Utxo, error) {
+	utxo, ok := s.utxos[input.String()]
+	if !ok {
+		return lcommon.Utxo{}, errors.New("not found")
+	}
+	return utxo, nil
+}
+
+func (s *testByronLedgerState) StakeRegistration(
+	[]byte,
+) ([]lcommon.StakeRegistrationCertificate, error) {
+	return nil, nil
+}
+
+func (s *testByronLedgerState) PoolRegistration(
+	[]byte,
+) ([]lcommon.PoolRegistrationCertificate, error) {
+	return nil, nil
+}
+
+func (s *testByronLedgerState) NetworkId() uint {
+	return 0
+}
+
+func testDecodeHex(t *testing.T, data string) []byte {
+	t.Helper()
+	ret, err := hex.DecodeString(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return ret
+}
+
+func TestNewByronTxAuxFromCbor(t *testing.T) {
+	txAux, err := eras.NewByronTxAuxFromCbor(
+		testDecodeHex(t, testByronTxAuxHex),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if txAux.TxAuxSize() != 291 {
+		t.Fatalf("did not get expected TxAux size: got %d, expected %d", txAux.TxAuxSize(), 291)
+	}
+	// The transaction hash only covers the transaction body
+	if hex.EncodeToString(txAux.Cbor()) != testByronTxHex {
+		t.Fatalf("did not get expected TX body CBOR: got %x", txAux.Cbor())
+	}
+	// A transaction body on its own isn't a TxAux
+	if _, err := eras.NewByronTxAuxFromCbor(testDecodeHex(t, testByronTxHex)); err == nil {
+		t.Fatalf("did not get expected error")
+	}
+}
+
+func TestByronBlockTransactions(t *testing.T) {
+	block := &byron.ByronMainBlock{}
+	if _, err := cbor.Decode(testDecodeHex(t, testByronBlockBodyHex), &block.Body); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	txs, err := eras.ByronBlockTransactions(block)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("did not get expected number of transactions: got %d, expected %d", len(txs), 1)
+	}
+	txAux, ok := txs[0].(*eras.ByronTxAux)
+	if !ok {
+		t.Fatalf("did not get expected transaction type: %T", txs[0])
+	}
+	if txAux.TxAuxSize() != 291 {
+		t.Fatalf("did not get expected TxAux size: got %d, expected %d", txAux.TxAuxSize(), 291)
+	}
+	if txAux.Hash() != block.Transactions()[0].Hash() {
+		t.Fatalf("did not get expected transaction hash: got %s, expected %s", txAux.Hash(), block.Transactions()[0].Hash())
+	}
+}
+
+func TestValidateTxByronFee(t *testing.T) {
+	nodeConfig, err := cardano.NewCardanoNodeConfigFromFile(
+		"../../config/cardano/testdata/config.json",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// The test genesis has the mainnet fee policy of 155381 + 43.946 per byte
+	pparams, err := eras.PParamsByron(nodeConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	txAux, err := eras.NewByronTxAuxFromCbor(
+		testDecodeHex(t, testByronTxAuxHex),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	txBody, err := byron.NewByronTransactionFromCbor(
+		testDecodeHex(t, testByronTxHex),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testDefs := []struct {
+		tx          lcommon.Transaction
+		fee         uint64
+		expectedMin uint64
+	}{
+		// The minimum fee for the 291 byte TxAux is 168169
+		{
+			tx:          txAux,
+			fee:         165_000,
+			expectedMin: 168169,
+		},
+		{
+			tx:  txAux,
+			fee: 170_000,
+		},
+		// Only the 150 byte body is available without the witnesses, which gives a minimum fee of 161972
+		{
+			tx:  txBody,
+			fee: 165_000,
+		},
+	}
+	for _, testDef := range testDefs {
+		input := byron.NewByronTransactionInput(strings.Repeat("ab", 32), 0)
+		ls := &testByronLedgerState{
+			utxos: map[string]lcommon.Utxo{
+				input.String(): {
+					Id: input,
+					Output: byron.ByronTransactionOutput{
+						OutputAmount: testByronTxProduced + testDef.fee,
+					},
+				},
+			},
+		}
+		err := eras.ValidateTxByron(testDef.tx, 0, ls, pparams)
+		if testDef.expectedMin == 0 {
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			continue
+		}
+		var feeErr shelley.FeeTooSmallUtxoError
+		if !errors.As(err, &feeErr) {
+			t.Fatalf("did not get expected error: %v", err)
+		}
+		if feeErr.Provided != testDef.fee || feeErr.Min != testDef.expectedMin {
+			t.Fatalf(
+				"did not get expected fee error: got provided %d and min %d, expected provided %d and min %d",
+				feeErr.Provided,
+				feeErr.Min,
+				testDef.fee,
+				testDef.expectedMin,
+			)
+		}
+	}
+}
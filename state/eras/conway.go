@@ -18,6 +18,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/blinklabs-io/dingo/config/cardano"
 	"github.com/blinklabs-io/gouroboros/cbor"
@@ -30,6 +31,7 @@ import (
 var ConwayEraDesc = EraDesc{
 	Id:                      conway.EraIdConway,
 	Name:                    conway.EraNameConway,
+	MinMajorVersion:         9,
 	DecodePParamsFunc:       DecodePParamsConway,
 	DecodePParamsUpdateFunc: DecodePParamsUpdateConway,
 	PParamsUpdateFunc:       PParamsUpdateConway,
@@ -38,6 +40,8 @@ var ConwayEraDesc = EraDesc{
 	CalculateEtaVFunc:       CalculateEtaVConway,
 	CertDepositFunc:         CertDepositConway,
 	ValidateTxFunc:          ValidateTxConway,
+	RewardParamsFunc:        RewardParamsConway,
+	GovParamsFunc:           GovParamsConway,
 }
 
 func DecodePParamsConway(data []byte) (lcommon.ProtocolParameters, error) {
@@ -132,8 +136,14 @@ func CertDepositConway(
 	switch cert.(type) {
 	case *lcommon.PoolRegistrationCertificate:
 		return uint64(tmpPparams.PoolDeposit), nil
-	case *lcommon.StakeRegistrationCertificate:
+	case *lcommon.StakeRegistrationCertificate,
+		*lcommon.RegistrationCertificate,
+		*lcommon.StakeRegistrationDelegationCertificate,
+		*lcommon.VoteRegistrationDelegationCertificate,
+		*lcommon.StakeVoteRegistrationDelegationCertificate:
 		return uint64(tmpPparams.KeyDeposit), nil
+	case *lcommon.RegistrationDrepCertificate:
+		return tmpPparams.DRepDeposit, nil
 	default:
 		return 0, nil
 	}
@@ -154,3 +164,40 @@ func ValidateTxConway(
 	}
 	return errors.Join(errs...)
 }
+
+func RewardParamsConway(
+	pp lcommon.ProtocolParameters,
+) (RewardParams, error) {
+	tmpPparams, ok := pp.(*conway.ConwayProtocolParameters)
+	if !ok {
+		return RewardParams{}, errors.New("pparams are not expected type")
+	}
+	// The decentralization parameter was removed in Conway, and blocks are
+	// always produced by stake pools
+	return RewardParams{
+		NOpt:             tmpPparams.NOpt,
+		A0:               tmpPparams.A0.Rat,
+		Rho:              tmpPparams.Rho.Rat,
+		Tau:              tmpPparams.Tau.Rat,
+		Decentralization: new(big.Rat),
+	}, nil
+}
+
+func GovParamsConway(
+	pp lcommon.ProtocolParameters,
+) (GovParams, error) {
+	tmpPparams, ok := pp.(*conway.ConwayProtocolParameters)
+	if !ok {
+		return GovParams{}, errors.New("pparams are not expected type")
+	}
+	return GovParams{
+		ProtocolMajor:           tmpPparams.ProtocolVersion.Major,
+		PoolVotingThresholds:    tmpPparams.PoolVotingThresholds,
+		DRepVotingThresholds:    tmpPparams.DRepVotingThresholds,
+		MinCommitteeSize:        tmpPparams.MinCommitteeSize,
+		CommitteeTermLimit:      tmpPparams.CommitteeTermLimit,
+		GovActionValidityPeriod: tmpPparams.GovActionValidityPeriod,
+		GovActionDeposit:        tmpPparams.GovActionDeposit,
+		DRepInactivityPeriod:    tmpPparams.DRepInactivityPeriod,
+	}, nil
+}
@@ -15,14 +15,18 @@
 package eras
 
 import (
+	"math/big"
+
 	"github.com/blinklabs-io/dingo/config/cardano"
 	"github.com/blinklabs-io/gouroboros/ledger"
 	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/conway"
 )
 
 type EraDesc struct {
 	Id                      uint
 	Name                    string
+	MinMajorVersion         uint
 	DecodePParamsFunc       func([]byte) (lcommon.ProtocolParameters, error)
 	DecodePParamsUpdateFunc func([]byte) (any, error)
 	PParamsUpdateFunc       func(lcommon.ProtocolParameters, any) (lcommon.ProtocolParameters, error)
@@ -31,6 +35,29 @@ type EraDesc struct {
 	CalculateEtaVFunc       func(*cardano.CardanoNodeConfig, []byte, ledger.Block) ([]byte, error)
 	CertDepositFunc         func(lcommon.Certificate, lcommon.ProtocolParameters) (uint64, error)
 	ValidateTxFunc          func(lcommon.Transaction, uint64, lcommon.LedgerState, lcommon.ProtocolParameters) error
+	RewardParamsFunc        func(lcommon.ProtocolParameters) (RewardParams, error)
+	GovParamsFunc           func(lcommon.ProtocolParameters) (GovParams, error)
+}
+
+// RewardParams contains the protocol parameters used for calculating rewards
+type RewardParams struct {
+	NOpt             uint
+	A0               *big.Rat
+	Rho              *big.Rat
+	Tau              *big.Rat
+	Decentralization *big.Rat
+}
+
+// GovParams contains the protocol parameters used for on-chain governance
+type GovParams struct {
+	ProtocolMajor           uint
+	PoolVotingThresholds    conway.PoolVotingThresholds
+	DRepVotingThresholds    conway.DRepVotingThresholds
+	MinCommitteeSize        uint
+	CommitteeTermLimit      uint64
+	GovActionValidityPeriod uint64
+	GovActionDeposit        uint64
+	DRepInactivityPeriod    uint64
 }
 
 var Eras = []EraDesc{
@@ -42,3 +69,15 @@ var Eras = []EraDesc{
 	BabbageEraDesc,
 	ConwayEraDesc,
 }
+
+// EraForProtocolMajor returns the era for the specified protocol major version. This is the latest era that
+// starts at or before the protocol version, so protocol versions beyond the latest known era map to that era
+func EraForProtocolMajor(protocolMajor uint) EraDesc {
+	ret := Eras[0]
+	for _, era := range Eras {
+		if era.MinMajorVersion <= protocolMajor {
+			ret = era
+		}
+	}
+	return ret
+}
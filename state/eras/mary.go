@@ -30,6 +30,7 @@ import (
 var MaryEraDesc = EraDesc{
 	Id:                      mary.EraIdMary,
 	Name:                    mary.EraNameMary,
+	MinMajorVersion:         4,
 	DecodePParamsFunc:       DecodePParamsMary,
 	DecodePParamsUpdateFunc: DecodePParamsUpdateMary,
 	PParamsUpdateFunc:       PParamsUpdateMary,
@@ -38,6 +39,7 @@ var MaryEraDesc = EraDesc{
 	CalculateEtaVFunc:       CalculateEtaVMary,
 	CertDepositFunc:         CertDepositMary,
 	ValidateTxFunc:          ValidateTxMary,
+	RewardParamsFunc:        RewardParamsMary,
 }
 
 func DecodePParamsMary(data []byte) (lcommon.ProtocolParameters, error) {
@@ -152,3 +154,19 @@ func ValidateTxMary(
 	}
 	return errors.Join(errs...)
 }
+
+func RewardParamsMary(
+	pp lcommon.ProtocolParameters,
+) (RewardParams, error) {
+	tmpPparams, ok := pp.(*mary.MaryProtocolParameters)
+	if !ok {
+		return RewardParams{}, errors.New("pparams are not expected type")
+	}
+	return RewardParams{
+		NOpt:             tmpPparams.NOpt,
+		A0:               tmpPparams.A0.Rat,
+		Rho:              tmpPparams.Rho.Rat,
+		Tau:              tmpPparams.Tau.Rat,
+		Decentralization: tmpPparams.Decentralization.Rat,
+	}, nil
+}
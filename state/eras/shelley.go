@@ -29,6 +29,7 @@ import (
 var ShelleyEraDesc = EraDesc{
 	Id:                      shelley.EraIdShelley,
 	Name:                    shelley.EraNameShelley,
+	MinMajorVersion:         2,
 	DecodePParamsFunc:       DecodePParamsShelley,
 	DecodePParamsUpdateFunc: DecodePParamsUpdateShelley,
 	PParamsUpdateFunc:       PParamsUpdateShelley,
@@ -37,6 +38,7 @@ var ShelleyEraDesc = EraDesc{
 	CalculateEtaVFunc:       CalculateEtaVShelley,
 	CertDepositFunc:         CertDepositShelley,
 	ValidateTxFunc:          ValidateTxShelley,
+	RewardParamsFunc:        RewardParamsShelley,
 }
 
 func DecodePParamsShelley(data []byte) (lcommon.ProtocolParameters, error) {
@@ -162,3 +164,19 @@ func ValidateTxShelley(
 	}
 	return errors.Join(errs...)
 }
+
+func RewardParamsShelley(
+	pp lcommon.ProtocolParameters,
+) (RewardParams, error) {
+	tmpPparams, ok := pp.(*shelley.ShelleyProtocolParameters)
+	if !ok {
+		return RewardParams{}, errors.New("pparams are not expected type")
+	}
+	return RewardParams{
+		NOpt:             tmpPparams.NOpt,
+		A0:               tmpPparams.A0.Rat,
+		Rho:              tmpPparams.Rho.Rat,
+		Tau:              tmpPparams.Tau.Rat,
+		Decentralization: tmpPparams.Decentralization.Rat,
+	}, nil
+}
@@ -17,3 +17,12 @@ package state
 import "errors"
 
 var ErrBlockNotFound = errors.New("block not found")
+
+// ErrRollbackTooDeep is returned when a rollback would remove blocks that are more than k blocks behind the
+// chain tip, where k is the security parameter
+var ErrRollbackTooDeep = errors.New("rollback exceeds security parameter")
+
+// ErrChainsyncWaitTimeout is returned by ChainsyncWait when our chain doesn't catch up with an upstream peer in time
+var ErrChainsyncWaitTimeout = errors.New(
+	"timed out waiting for our chain to catch up with upstream peer",
+)
@@ -26,6 +26,7 @@ import (
 const (
 	ChainBlockEventType    = "ledger.chain-block"
 	ChainRollbackEventType = "ledger.chain-rollback"
+	ChainUpdateEventType   = "ledger.chain-update"
 )
 
 type ChainBlockEvent struct {
@@ -34,7 +35,16 @@ type ChainBlockEvent struct {
 }
 
 type ChainRollbackEvent struct {
-	Point ocommon.Point
+	Point            ocommon.Point
+	RolledBackBlocks []database.Block // Blocks removed by the rollback, in chain order
+}
+
+// ChainUpdateEvent represents either a ChainBlockEvent or a ChainRollbackEvent. We use a single event type
+// for both so that consumers receive blocks and rollbacks in the order they happened, which isn't guaranteed
+// across separate subscriptions.
+type ChainUpdateEvent struct {
+	Block    *ChainBlockEvent    // Set for a new block
+	Rollback *ChainRollbackEvent // Set for a rollback
 }
 
 const (
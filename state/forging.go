@@ -0,0 +1,368 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"github.com/blinklabs-io/dingo/state/eras"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+// EpochLeaderParams contains the values used to determine whether a pool is eligible to produce blocks in the
+// slots of an epoch
+type EpochLeaderParams struct {
+	Epoch           uint64
+	StartSlot       uint64
+	LengthInSlots   uint64
+	Nonce           []byte
+	PoolStake       uint64
+	TotalStake      uint64
+	ActiveSlotCoeff *big.Rat
+	// Bootstrap is set for the first epochs of a chain, before the first stake snapshot is used for leader
+	// election
+	Bootstrap bool
+}
+
+// IsSlotLeader returns whether a VRF leader value makes the pool eligible to produce a block. During the
+// bootstrap epochs of a new chain, such as a devnet started from genesis, the pool is treated as holding all of
+// the stake. Otherwise a pool is never elected without a stake distribution
+func (p *EpochLeaderParams) IsSlotLeader(leaderValue []byte) bool {
+	if p.Bootstrap {
+		return isSlotLeader(leaderValue, big.NewRat(1, 1), p.ActiveSlotCoeff)
+	}
+	if p.TotalStake == 0 {
+		return false
+	}
+	sigma := new(big.Rat).SetFrac(
+		new(big.Int).SetUint64(p.PoolStake),
+		new(big.Int).SetUint64(p.TotalStake),
+	)
+	return isSlotLeader(leaderValue, sigma, p.ActiveSlotCoeff)
+}
+
+// ForgingContext contains the ledger state used for forging a block in a particular slot
+type ForgingContext struct {
+	EpochLeaderParams
+	Tip     ochainsync.Tip
+	EraId   uint
+	PParams lcommon.ProtocolParameters
+}
+
+// ForgingContext returns the ledger state needed to forge a block for the specified pool in the specified slot
+func (ls *LedgerState) ForgingContext(
+	slot uint64,
+	poolKeyHash lcommon.PoolKeyHash,
+) (*ForgingContext, error) {
+	ls.RLock()
+	defer ls.RUnlock()
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	leaderParams, err := ls.epochLeaderParams(txn, slot, poolKeyHash)
+	if err != nil {
+		return nil, err
+	}
+	ret := &ForgingContext{
+		EpochLeaderParams: *leaderParams,
+		Tip:               ls.currentTip,
+		EraId:             ls.currentEra.Id,
+		PParams:           ls.currentPParams,
+	}
+	// A new chain starts in the latest era, as if all hard forks happened at epoch 0. The ledger transitions
+	// through each era when the first block is added
+	if len(ls.currentTip.Point.Hash) == 0 {
+		pparams := ls.currentPParams
+		for _, era := range eras.Eras[ls.currentEra.Id+1:] {
+			if era.HardForkFunc == nil {
+				continue
+			}
+			pparams, err = era.HardForkFunc(ls.config.CardanoNodeConfig, pparams)
+			if err != nil {
+				return nil, err
+			}
+		}
+		ret.EraId = eras.Eras[len(eras.Eras)-1].Id
+		ret.PParams = pparams
+	}
+	return ret, nil
+}
+
+// EpochLeaderParams returns the values used for leader election for the specified pool in the current epoch,
+// or in the next epoch once its nonce is known
+func (ls *LedgerState) EpochLeaderParams(
+	next bool,
+	poolKeyHash lcommon.PoolKeyHash,
+) (*EpochLeaderParams, error) {
+	ls.RLock()
+	defer ls.RUnlock()
+	if next && ls.currentEpoch.ID == 0 {
+		return nil, errors.New("next epoch is not known until the chain has started")
+	}
+	slot := ls.currentEpoch.StartSlot
+	if next {
+		slot += uint64(ls.currentEpoch.LengthInSlots)
+	}
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	return ls.epochLeaderParams(txn, slot, poolKeyHash)
+}
+
+// epochLeaderParams returns the epoch nonce and stake distribution used for leader election in the epoch
+// containing the specified slot, which must be in the current or next epoch
+func (ls *LedgerState) epochLeaderParams(
+	txn *database.Txn,
+	slot uint64,
+	poolKeyHash lcommon.PoolKeyHash,
+) (*EpochLeaderParams, error) {
+	shelleyGenesis := ls.config.CardanoNodeConfig.ShelleyGenesis()
+	if shelleyGenesis == nil {
+		return nil, errors.New("could not get genesis config")
+	}
+	ret := &EpochLeaderParams{
+		ActiveSlotCoeff: shelleyGenesis.ActiveSlotsCoeff.Rat,
+	}
+	currentEpochEnd := ls.currentEpoch.StartSlot + uint64(
+		ls.currentEpoch.LengthInSlots,
+	)
+	var snapshot stakeSnapshot
+	switch {
+	case ls.currentEpoch.ID == 0:
+		// There is no epoch record until the first block is added. A new chain starts in the latest era, with
+		// the Shelley genesis hash as the initial epoch nonce
+		_, epochLength, err := eras.Eras[len(eras.Eras)-1].EpochLengthFunc(
+			ls.config.CardanoNodeConfig,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if slot >= uint64(epochLength) {
+			return nil, fmt.Errorf("slot %d is beyond the first epoch", slot)
+		}
+		genesisHashBytes, err := hex.DecodeString(
+			ls.config.CardanoNodeConfig.ShelleyGenesisHash,
+		)
+		if err != nil {
+			return nil, err
+		}
+		ret.LengthInSlots = uint64(epochLength)
+		ret.Nonce = genesisHashBytes
+		ret.Bootstrap = true
+		return ret, nil
+	case slot < ls.currentEpoch.StartSlot:
+		return nil, fmt.Errorf(
+			"slot %d is before the current epoch",
+			slot,
+		)
+	case slot < currentEpochEnd:
+		ret.Epoch = ls.currentEpoch.EpochId
+		ret.StartSlot = ls.currentEpoch.StartSlot
+		ret.LengthInSlots = uint64(ls.currentEpoch.LengthInSlots)
+		ret.Nonce = ls.currentEpoch.Nonce
+		snapshot = stakeSnapshotSet
+	default:
+		_, epochLength, err := ls.currentEra.EpochLengthFunc(
+			ls.config.CardanoNodeConfig,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if slot >= currentEpochEnd+uint64(epochLength) {
+			return nil, fmt.Errorf("slot %d is beyond the next epoch", slot)
+		}
+		// The nonce for the next epoch is fixed once the chain reaches the stability window before the start
+		// of the epoch
+		stabilityWindow, err := ls.nonceStabilityWindow()
+		if err != nil {
+			return nil, err
+		}
+		if currentEpochEnd > stabilityWindow &&
+			ls.currentTip.Point.Slot < currentEpochEnd-stabilityWindow {
+			return nil, fmt.Errorf(
+				"epoch nonce for epoch %d is not known yet",
+				ls.currentEpoch.EpochId+1,
+			)
+		}
+		nonce, err := ls.calculateEpochNonce(txn, currentEpochEnd)
+		if err != nil {
+			return nil, err
+		}
+		ret.Epoch = ls.currentEpoch.EpochId + 1
+		ret.StartSlot = currentEpochEnd
+		ret.LengthInSlots = uint64(epochLength)
+		ret.Nonce = nonce
+		// The current "mark" snapshot becomes the "set" snapshot at the start of the next epoch
+		snapshot = stakeSnapshotMark
+	}
+	// The first stake snapshot is taken at the start of epoch 1, and is used for leader election from epoch 2
+	if ret.Epoch < 2 {
+		ret.Bootstrap = true
+		return ret, nil
+	}
+	poolStakes, err := ls.poolStakeSnapshot(txn, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	for _, poolStake := range poolStakes {
+		ret.TotalStake += poolStake.Stake
+		if string(poolStake.PoolKeyHash) == string(poolKeyHash[:]) {
+			ret.PoolStake = poolStake.Stake
+		}
+	}
+	return ret, nil
+}
+
+// AddForgedBlock adds a block produced by this node to the chain. The block must fit on the current chain tip
+func (ls *LedgerState) AddForgedBlock(
+	point ocommon.Point,
+	blockType uint,
+	block ledger.Block,
+) error {
+	ls.Lock()
+	defer ls.Unlock()
+	err := ls.applyBlockEvents(
+		[]BlockfetchEvent{
+			{
+				Point: point,
+				Type:  blockType,
+				Block: block,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	ls.config.Logger.Info(
+		fmt.Sprintf(
+			"chain extended with forged block, new tip: %x at slot %d",
+			ls.currentTip.Point.Hash,
+			ls.currentTip.Point.Slot,
+		),
+		"component",
+		"ledger",
+	)
+	return nil
+}
+
+// epochTime contains the wall clock time at the start of an epoch, along with its slot length
+type epochTime struct {
+	startSlot  uint64
+	startTime  time.Time
+	slotLength time.Duration
+}
+
+// epochTimes returns the start time of each known epoch. When there are no epochs yet, it returns the start of
+// the first epoch in the latest era
+func (ls *LedgerState) epochTimes() ([]epochTime, error) {
+	shelleyGenesis := ls.config.CardanoNodeConfig.ShelleyGenesis()
+	if shelleyGenesis == nil {
+		return nil, errors.New("could not get genesis config")
+	}
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	var epochs []models.Epoch
+	result := txn.Metadata().Order("start_slot ASC").Find(&epochs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query epochs: %w", result.Error)
+	}
+	if len(epochs) == 0 {
+		slotLength, _, err := eras.Eras[len(eras.Eras)-1].EpochLengthFunc(
+			ls.config.CardanoNodeConfig,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return []epochTime{
+			{
+				startTime:  shelleyGenesis.SystemStart,
+				slotLength: time.Duration(slotLength) * time.Millisecond,
+			},
+		}, nil
+	}
+	ret := make([]epochTime, 0, len(epochs))
+	startTime := shelleyGenesis.SystemStart
+	for _, epoch := range epochs {
+		slotLength := time.Duration(epoch.SlotLength) * time.Millisecond
+		ret = append(
+			ret,
+			epochTime{
+				startSlot:  epoch.StartSlot,
+				startTime:  startTime,
+				slotLength: slotLength,
+			},
+		)
+		startTime = startTime.Add(slotLength * time.Duration(epoch.LengthInSlots))
+	}
+	return ret, nil
+}
+
+// SlotToTime returns the wall clock time at the start of the specified slot. Slots after the last known epoch
+// are assumed to have the same length as the slots in that epoch
+func (ls *LedgerState) SlotToTime(slot uint64) (time.Time, error) {
+	epochTimes, err := ls.epochTimes()
+	if err != nil {
+		return time.Time{}, err
+	}
+	idx := sort.Search(
+		len(epochTimes),
+		func(i int) bool {
+			return epochTimes[i].startSlot > slot
+		},
+	)
+	if idx == 0 {
+		return time.Time{}, fmt.Errorf("slot %d is before the first epoch", slot)
+	}
+	epoch := epochTimes[idx-1]
+	return epoch.startTime.Add(
+		epoch.slotLength * time.Duration(slot-epoch.startSlot), // #nosec G115
+	), nil
+}
+
+// TimeToSlot returns the slot containing the specified wall clock time
+func (ls *LedgerState) TimeToSlot(t time.Time) (uint64, error) {
+	epochTimes, err := ls.epochTimes()
+	if err != nil {
+		return 0, err
+	}
+	idx := sort.Search(
+		len(epochTimes),
+		func(i int) bool {
+			return epochTimes[i].startTime.After(t)
+		},
+	)
+	if idx == 0 {
+		return 0, fmt.Errorf("time %s is before the start of the chain", t)
+	}
+	epoch := epochTimes[idx-1]
+	if epoch.slotLength <= 0 {
+		return 0, errors.New("invalid slot length")
+	}
+	return epoch.startSlot + uint64(t.Sub(epoch.startTime)/epoch.slotLength), nil
+}
@@ -0,0 +1,1379 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"cmp"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"github.com/blinklabs-io/dingo/state/eras"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+const (
+	// First protocol major version after the Conway bootstrap phase. During the bootstrap phase, DReps don't
+	// vote on governance actions
+	govBootstrapEndProtocolMajor = 10
+)
+
+// govActionPurpose identifies the chain of governance actions that an action belongs to. Actions with a purpose
+// must reference the most recently ratified action with the same purpose
+type govActionPurpose int
+
+const (
+	govActionPurposeNone govActionPurpose = iota
+	govActionPurposePParams
+	govActionPurposeHardFork
+	govActionPurposeCommittee
+	govActionPurposeConstitution
+)
+
+// govVotes contains the most recent vote from each voter on a governance action, keyed by voter credential
+type govVotes struct {
+	committee map[string]uint8
+	drep      map[string]uint8
+	spo       map[string]uint8
+}
+
+// drepStakeDistribution contains the stake delegated to each registered DRep, along with the stake delegated to
+// the predefined "always abstain" and "always no confidence" options
+type drepStakeDistribution struct {
+	dreps              map[string]uint64
+	drepTypes          map[string]int
+	alwaysAbstain      uint64
+	alwaysNoConfidence uint64
+}
+
+// govRatifyState contains the ledger state used when checking governance actions for ratification
+type govRatifyState struct {
+	epoch            uint64
+	govParams        eras.GovParams
+	committee        models.Committee
+	committeeMembers []models.CommitteeMember
+	committeeHotKeys map[string][]byte
+	drepStake        drepStakeDistribution
+	poolStake        map[string]uint64
+}
+
+// processTransactionGovernance records the governance action proposals and votes in a transaction
+func (ls *LedgerState) processTransactionGovernance(
+	txn *database.Txn,
+	point ocommon.Point,
+	tx ledger.Transaction,
+) error {
+	// Proposals and votes aren't applied for TXs that fail phase-2 validation
+	if ls.currentEra.GovParamsFunc == nil || !tx.IsValid() {
+		return nil
+	}
+	proposals := tx.ProposalProcedures()
+	if len(proposals) > 0 {
+		govParams, err := ls.currentEra.GovParamsFunc(ls.currentPParams)
+		if err != nil {
+			return err
+		}
+		txId, err := hex.DecodeString(tx.Hash())
+		if err != nil {
+			return err
+		}
+		for idx := range proposals {
+			err := txn.DB().Metadata().SetGovernanceProposal(
+				&proposals[idx],
+				txId,
+				uint32(idx), // #nosec G115
+				ls.currentEpoch.EpochId,
+				ls.currentEpoch.EpochId+govParams.GovActionValidityPeriod,
+				point.Slot,
+				txn.Metadata(),
+			)
+			if err != nil {
+				return fmt.Errorf("record governance proposal: %w", err)
+			}
+		}
+	}
+	for voter, votes := range tx.VotingProcedures() {
+		for actionId, vote := range votes {
+			err := txn.DB().Metadata().SetGovernanceVote(
+				voter,
+				actionId,
+				&vote,
+				point.Slot,
+				txn.Metadata(),
+			)
+			if err != nil {
+				return fmt.Errorf("record governance vote: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// processGovernance processes governance actions at the start of the specified epoch. Actions that were ratified at
+// the previous epoch boundary are enacted, actions that can no longer be enacted are removed, pending actions are
+// checked for ratification, and actions that have reached the end of their lifetime are expired. This replaces
+// protocol parameter update proposals starting in Conway, and must be called after the rewards for the new epoch
+// have been calculated
+func (ls *LedgerState) processGovernance(
+	txn *database.Txn,
+	epoch uint64,
+	slot uint64,
+) error {
+	proposals, err := txn.DB().Metadata().GetGovernanceProposals(txn.Metadata())
+	if err != nil {
+		return err
+	}
+	if len(proposals) == 0 {
+		return nil
+	}
+	// Actions are processed in priority order, and in the order they were proposed within the same priority
+	slices.SortStableFunc(
+		proposals,
+		func(a, b models.GovernanceProposal) int {
+			return cmp.Compare(govActionPriority(a.ActionType), govActionPriority(b.ActionType))
+		},
+	)
+	pots, err := txn.DB().Metadata().GetAdaPots(epoch, txn.Metadata())
+	if err != nil {
+		return err
+	}
+	treasury := uint64(pots.Treasury)
+	registeredStakingKeys, err := ls.registeredStakingKeys(txn)
+	if err != nil {
+		return err
+	}
+	rewards := []models.Reward{}
+	// Funds paid to an unregistered reward account go to the treasury
+	payRewardAccount := func(stakingKey []byte, rewardType uint8, amount uint64) {
+		if amount == 0 {
+			return
+		}
+		if !registeredStakingKeys[string(stakingKey)] {
+			treasury += amount
+			return
+		}
+		rewards = append(
+			rewards,
+			models.Reward{
+				StakingKey: stakingKey,
+				Epoch:      epoch,
+				Type:       rewardType,
+				Amount:     amount,
+				AddedSlot:  slot,
+			},
+		)
+	}
+	// Enact actions ratified at the previous epoch boundary
+	pending := make([]models.GovernanceProposal, 0, len(proposals))
+	for _, proposal := range proposals {
+		if proposal.RatifiedSlot == 0 {
+			pending = append(pending, proposal)
+			continue
+		}
+		action, err := decodeGovAction(proposal.ActionCbor)
+		if err != nil {
+			return err
+		}
+		if withdrawal, ok := action.(*lcommon.TreasuryWithdrawalGovAction); ok {
+			for addr, amount := range withdrawal.Withdrawals {
+				treasury -= amount
+				payRewardAccount(
+					addr.StakeKeyHash().Bytes(),
+					models.RewardTypeTreasuryWithdrawal,
+					amount,
+				)
+			}
+		} else {
+			if err := ls.enactGovAction(txn, action, epoch, slot); err != nil {
+				return fmt.Errorf(
+					"enact governance action %x#%d: %w",
+					proposal.TxId,
+					proposal.ActionIdx,
+					err,
+				)
+			}
+		}
+		payRewardAccount(
+			proposal.RewardAccount,
+			models.RewardTypeProposalRefund,
+			proposal.Deposit,
+		)
+		result := txn.Metadata().
+			Model(&models.GovernanceProposal{}).
+			Where("id = ?", proposal.ID).
+			Updates(map[string]any{"enacted_epoch": epoch, "enacted_slot": slot})
+		if result.Error != nil {
+			return fmt.Errorf("update governance proposal: %w", result.Error)
+		}
+		ls.config.Logger.Info(
+			fmt.Sprintf(
+				"enacted governance action %x#%d",
+				proposal.TxId,
+				proposal.ActionIdx,
+			),
+			"component", "ledger",
+		)
+	}
+	// Remove pending actions that can no longer be enacted, because the action that they reference has been
+	// superseded by an enacted action with the same purpose. Their deposits are refunded
+	if len(pending) > 0 {
+		roots, err := ls.govActionRoots(txn, true)
+		if err != nil {
+			return err
+		}
+		obsolete, err := obsoleteGovProposals(pending, roots)
+		if err != nil {
+			return err
+		}
+		remaining := make([]models.GovernanceProposal, 0, len(pending))
+		for _, proposal := range pending {
+			if !obsolete[proposal.ID] {
+				remaining = append(remaining, proposal)
+				continue
+			}
+			result := txn.Metadata().
+				Model(&models.GovernanceProposal{}).
+				Where("id = ?", proposal.ID).
+				Updates(map[string]any{"expired_epoch": epoch, "expired_slot": slot})
+			if result.Error != nil {
+				return fmt.Errorf("update governance proposal: %w", result.Error)
+			}
+			payRewardAccount(
+				proposal.RewardAccount,
+				models.RewardTypeProposalRefund,
+				proposal.Deposit,
+			)
+			ls.config.Logger.Debug(
+				fmt.Sprintf(
+					"removed obsolete governance action %x#%d",
+					proposal.TxId,
+					proposal.ActionIdx,
+				),
+				"component", "ledger",
+			)
+		}
+		pending = remaining
+	}
+	// Ratify pending actions
+	if len(pending) > 0 {
+		ratifyState, err := ls.govRatifyState(txn, epoch)
+		if err != nil {
+			return err
+		}
+		roots, err := ls.govActionRoots(txn, false)
+		if err != nil {
+			return err
+		}
+		var withdrawals uint64
+		for _, proposal := range pending {
+			// Info actions can't be ratified
+			if proposal.ActionType == lcommon.GovActionTypeInfo {
+				continue
+			}
+			action, err := decodeGovAction(proposal.ActionCbor)
+			if err != nil {
+				return err
+			}
+			// The previous action must be the most recently ratified action with the same purpose
+			purpose, prevActionId := govActionPrev(action)
+			if purpose != govActionPurposeNone &&
+				!govActionIdEqual(prevActionId, roots[purpose]) {
+				continue
+			}
+			// The treasury must be able to cover all ratified withdrawals
+			if withdrawal, ok := action.(*lcommon.TreasuryWithdrawalGovAction); ok {
+				var total uint64
+				for _, amount := range withdrawal.Withdrawals {
+					total += amount
+				}
+				if withdrawals+total > treasury {
+					continue
+				}
+				withdrawals += total
+			}
+			votes, err := ls.govActionVotes(txn, proposal)
+			if err != nil {
+				return err
+			}
+			accepted, err := ratifyState.accepted(action, votes)
+			if err != nil {
+				return fmt.Errorf(
+					"check governance action %x#%d: %w",
+					proposal.TxId,
+					proposal.ActionIdx,
+					err,
+				)
+			}
+			if !accepted {
+				continue
+			}
+			result := txn.Metadata().
+				Model(&models.GovernanceProposal{}).
+				Where("id = ?", proposal.ID).
+				Updates(map[string]any{"ratified_epoch": epoch, "ratified_slot": slot})
+			if result.Error != nil {
+				return fmt.Errorf("update governance proposal: %w", result.Error)
+			}
+			ls.config.Logger.Info(
+				fmt.Sprintf(
+					"ratified governance action %x#%d",
+					proposal.TxId,
+					proposal.ActionIdx,
+				),
+				"component", "ledger",
+			)
+			if purpose != govActionPurposeNone {
+				roots[purpose] = &lcommon.GovActionId{
+					TransactionId: [32]byte(proposal.TxId),
+					GovActionIdx:  proposal.ActionIdx,
+				}
+			}
+			// Ratifying an action that changes the rules for ratification delays all remaining actions
+			// until the next epoch boundary
+			if govActionDelaying(proposal.ActionType) {
+				break
+			}
+		}
+	}
+	// Expire actions that have reached the end of their lifetime without being ratified. Actions that reference
+	// an expired action can no longer be enacted, and are removed at the next epoch boundary
+	for _, proposal := range pending {
+		if proposal.ExpiresEpoch >= epoch {
+			continue
+		}
+		result := txn.Metadata().
+			Model(&models.GovernanceProposal{}).
+			Where("id = ? AND ratified_slot = 0", proposal.ID).
+			Updates(map[string]any{"expired_epoch": epoch, "expired_slot": slot})
+		if result.Error != nil {
+			return fmt.Errorf("update governance proposal: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		payRewardAccount(
+			proposal.RewardAccount,
+			models.RewardTypeProposalRefund,
+			proposal.Deposit,
+		)
+	}
+	if err := txn.DB().Metadata().SetRewards(rewards, txn.Metadata()); err != nil {
+		return err
+	}
+	// Record the updated treasury. This overrides the pots recorded for the epoch when calculating rewards
+	if treasury != uint64(pots.Treasury) {
+		err := txn.DB().Metadata().SetAdaPots(
+			epoch,
+			uint64(pots.Reserves),
+			treasury,
+			slot,
+			txn.Metadata(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enactGovAction applies the effects of a ratified governance action, other than treasury withdrawals
+func (ls *LedgerState) enactGovAction(
+	txn *database.Txn,
+	action lcommon.GovAction,
+	epoch uint64,
+	slot uint64,
+) error {
+	switch a := action.(type) {
+	case *lcommon.ParameterChangeGovAction:
+		return ls.applyPParamUpdate(txn, a.ParamUpdate, epoch, slot)
+	case *lcommon.HardForkInitiationGovAction:
+		// The new protocol version is applied as a protocol parameter update
+		updateCbor, err := cbor.Encode(
+			map[uint][]uint{
+				14: {a.ProtocolVersion.Major, a.ProtocolVersion.Minor},
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if err := ls.applyPParamUpdate(txn, updateCbor, epoch, slot); err != nil {
+			return err
+		}
+		// Move to the era for the new protocol version, starting with the epoch in which the hard fork
+		// is enacted. Intra-era hard forks only change the protocol version
+		targetEra := eras.EraForProtocolMajor(a.ProtocolVersion.Major)
+		for nextEraId := ls.currentEra.Id + 1; nextEraId <= targetEra.Id; nextEraId++ {
+			if err := ls.transitionToEra(txn, nextEraId, epoch, slot); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *lcommon.NoConfidenceGovAction:
+		committee, err := txn.DB().Metadata().GetCommittee(txn.Metadata())
+		if err != nil {
+			return err
+		}
+		members, err := txn.DB().Metadata().GetCommitteeMembers(txn.Metadata())
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			err := txn.DB().Metadata().DeleteCommitteeMember(
+				member.ColdCredential,
+				slot,
+				txn.Metadata(),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		quorum := new(big.Rat)
+		if committee.Quorum != nil && committee.Quorum.Rat != nil {
+			quorum = committee.Quorum.Rat
+		}
+		return txn.DB().Metadata().SetCommittee(
+			quorum,
+			true,
+			slot,
+			txn.Metadata(),
+		)
+	case *lcommon.UpdateCommitteeGovAction:
+		for _, cred := range a.Credentials {
+			err := txn.DB().Metadata().DeleteCommitteeMember(
+				cred.Credential,
+				slot,
+				txn.Metadata(),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		for cred, expiresEpoch := range a.CredEpochs {
+			// Adding an existing member updates their term
+			err := txn.DB().Metadata().DeleteCommitteeMember(
+				cred.Credential,
+				slot,
+				txn.Metadata(),
+			)
+			if err != nil {
+				return err
+			}
+			err = txn.DB().Metadata().SetCommitteeMember(
+				cred.Credential,
+				uint64(expiresEpoch),
+				slot,
+				txn.Metadata(),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		quorum := a.Unknown.Rat
+		if quorum == nil {
+			quorum = new(big.Rat)
+		}
+		return txn.DB().Metadata().SetCommittee(
+			quorum,
+			false,
+			slot,
+			txn.Metadata(),
+		)
+	case *lcommon.NewConstitutionGovAction:
+		return txn.DB().Metadata().SetConstitution(
+			&a.Constitution.Anchor,
+			a.Constitution.ScriptHash,
+			slot,
+			txn.Metadata(),
+		)
+	default:
+		return fmt.Errorf("unsupported governance action type: %T", action)
+	}
+}
+
+// obsoleteGovProposals returns the IDs of pending proposals that can no longer be enacted. A proposal with a
+// purpose must reference the most recently enacted action with the same purpose, or a pending proposal that
+// can still be enacted. This removes both competing proposals and their descendants when an action is enacted
+func obsoleteGovProposals(
+	pending []models.GovernanceProposal,
+	roots map[govActionPurpose]*lcommon.GovActionId,
+) (map[uint]bool, error) {
+	// Proposals can only reference earlier proposals, so they're checked in the order they were submitted
+	sorted := slices.Clone(pending)
+	slices.SortFunc(
+		sorted,
+		func(a, b models.GovernanceProposal) int {
+			return cmp.Compare(a.ID, b.ID)
+		},
+	)
+	valid := make(map[govActionPurpose][]*lcommon.GovActionId)
+	for purpose, root := range roots {
+		valid[purpose] = []*lcommon.GovActionId{root}
+	}
+	ret := make(map[uint]bool)
+	for _, proposal := range sorted {
+		purpose := govActionTypePurpose(proposal.ActionType)
+		if purpose == govActionPurposeNone {
+			continue
+		}
+		action, err := decodeGovAction(proposal.ActionCbor)
+		if err != nil {
+			return nil, err
+		}
+		_, prevActionId := govActionPrev(action)
+		// A purpose without an enacted action starts from the genesis state, which is referenced as nil
+		validIds, ok := valid[purpose]
+		if !ok {
+			validIds = []*lcommon.GovActionId{nil}
+			valid[purpose] = validIds
+		}
+		if !slices.ContainsFunc(
+			validIds,
+			func(id *lcommon.GovActionId) bool {
+				return govActionIdEqual(id, prevActionId)
+			},
+		) {
+			ret[proposal.ID] = true
+			continue
+		}
+		valid[purpose] = append(
+			validIds,
+			&lcommon.GovActionId{
+				TransactionId: [32]byte(proposal.TxId),
+				GovActionIdx:  proposal.ActionIdx,
+			},
+		)
+	}
+	return ret, nil
+}
+
+// govActionRoots returns the most recently ratified action for each governance action purpose. Only enacted
+// actions are considered if enactedOnly is true
+func (ls *LedgerState) govActionRoots(
+	txn *database.Txn,
+	enactedOnly bool,
+) (map[govActionPurpose]*lcommon.GovActionId, error) {
+	slotColumn := "ratified_slot"
+	if enactedOnly {
+		slotColumn = "enacted_slot"
+	}
+	var proposals []models.GovernanceProposal
+	result := txn.Metadata().
+		Select("tx_id, action_idx, action_type").
+		Where(slotColumn + " > 0").
+		Order(slotColumn + " ASC, id ASC").
+		Find(&proposals)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query ratified governance proposals: %w", result.Error)
+	}
+	ret := make(map[govActionPurpose]*lcommon.GovActionId)
+	for _, proposal := range proposals {
+		purpose := govActionTypePurpose(proposal.ActionType)
+		if purpose == govActionPurposeNone {
+			continue
+		}
+		ret[purpose] = &lcommon.GovActionId{
+			TransactionId: [32]byte(proposal.TxId),
+			GovActionIdx:  proposal.ActionIdx,
+		}
+	}
+	return ret, nil
+}
+
+// govActionVotes returns the most recent vote from each voter on a governance action
+func (ls *LedgerState) govActionVotes(
+	txn *database.Txn,
+	proposal models.GovernanceProposal,
+) (govVotes, error) {
+	ret := govVotes{
+		committee: make(map[string]uint8),
+		drep:      make(map[string]uint8),
+		spo:       make(map[string]uint8),
+	}
+	votes, err := txn.DB().Metadata().GetGovernanceVotes(
+		proposal.TxId,
+		proposal.ActionIdx,
+		txn.Metadata(),
+	)
+	if err != nil {
+		return ret, err
+	}
+	for _, vote := range votes {
+		switch vote.VoterType {
+		case lcommon.VoterTypeConstitutionalCommitteeHotKeyHash,
+			lcommon.VoterTypeConstitutionalCommitteeHotScriptHash:
+			ret.committee[string(vote.VoterHash)] = vote.Vote
+		case lcommon.VoterTypeDRepKeyHash, lcommon.VoterTypeDRepScriptHash:
+			ret.drep[string(vote.VoterHash)] = vote.Vote
+		case lcommon.VoterTypeStakingPoolKeyHash:
+			ret.spo[string(vote.VoterHash)] = vote.Vote
+		}
+	}
+	return ret, nil
+}
+
+// govRatifyState gathers the committee state and voting stake distributions for checking governance actions
+// for ratification at the start of the specified epoch
+func (ls *LedgerState) govRatifyState(
+	txn *database.Txn,
+	epoch uint64,
+) (*govRatifyState, error) {
+	govParams, err := ls.currentEra.GovParamsFunc(ls.currentPParams)
+	if err != nil {
+		return nil, err
+	}
+	committee, err := txn.DB().Metadata().GetCommittee(txn.Metadata())
+	if err != nil {
+		return nil, err
+	}
+	committeeMembers, err := txn.DB().Metadata().GetCommitteeMembers(txn.Metadata())
+	if err != nil {
+		return nil, err
+	}
+	committeeHotKeys, err := ls.committeeHotKeys(txn)
+	if err != nil {
+		return nil, err
+	}
+	// DRep votes are weighted using the DRep stake distribution taken at the start of the previous epoch
+	var drepStake drepStakeDistribution
+	if epoch > 0 {
+		drepStake, err = ls.drepStakeSnapshot(txn, epoch-1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// SPO votes are weighted using the stake snapshot taken at the start of the previous epoch
+	poolStakes, err := ls.poolStakeSnapshot(txn, stakeSnapshotMark)
+	if err != nil {
+		return nil, err
+	}
+	poolStake := make(map[string]uint64, len(poolStakes))
+	for _, tmpPoolStake := range poolStakes {
+		poolStake[string(tmpPoolStake.PoolKeyHash)] = tmpPoolStake.Stake
+	}
+	return &govRatifyState{
+		epoch:            epoch,
+		govParams:        govParams,
+		committee:        committee,
+		committeeMembers: committeeMembers,
+		committeeHotKeys: committeeHotKeys,
+		drepStake:        drepStake,
+		poolStake:        poolStake,
+	}, nil
+}
+
+// committeeHotKeys returns the hot credential authorized by each constitutional committee member, excluding
+// members that have resigned
+func (ls *LedgerState) committeeHotKeys(
+	txn *database.Txn,
+) (map[string][]byte, error) {
+	var authHots []models.AuthCommitteeHot
+	result := txn.Metadata().Order("id ASC").Find(&authHots)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query committee hot key authorizations: %w", result.Error)
+	}
+	hotKeys := make(map[string]models.AuthCommitteeHot)
+	for _, authHot := range authHots {
+		hotKeys[string(authHot.ColdCredential)] = authHot
+	}
+	var resignations []models.ResignCommitteeCold
+	result = txn.Metadata().Order("id ASC").Find(&resignations)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query committee resignations: %w", result.Error)
+	}
+	for _, resignation := range resignations {
+		// A new hot key authorization after resigning rejoins the committee
+		authHot, ok := hotKeys[string(resignation.ColdCredential)]
+		if ok && resignation.AddedSlot >= authHot.AddedSlot {
+			delete(hotKeys, string(resignation.ColdCredential))
+		}
+	}
+	ret := make(map[string][]byte, len(hotKeys))
+	for coldCredential, authHot := range hotKeys {
+		ret[coldCredential] = authHot.HotCredential
+	}
+	return ret, nil
+}
+
+// drepStakeDistribution calculates the voting stake delegated to each active DRep from the current ledger state.
+// The voting stake for a staking key includes its unspent UTxOs, its reward account balance and the deposits of
+// pending governance action proposals that are refunded to its reward account
+func (ls *LedgerState) drepStakeDistribution(
+	txn *database.Txn,
+	epoch uint64,
+) (drepStakeDistribution, error) {
+	ret := drepStakeDistribution{
+		dreps:     make(map[string]uint64),
+		drepTypes: make(map[string]int),
+	}
+	// Determine registered DReps and when they become inactive
+	drepExpiries, err := ls.drepExpiries(txn, epoch)
+	if err != nil {
+		return ret, err
+	}
+	// Determine current vote delegation for each staking key
+	var stakeDeregs []models.StakeDeregistration
+	result := txn.Metadata().
+		Select("staking_key, added_slot").
+		Order("id ASC").
+		Find(&stakeDeregs)
+	if result.Error != nil {
+		return ret, fmt.Errorf("query stake deregistrations: %w", result.Error)
+	}
+	stakeDeregSlots := make(map[string]uint64)
+	for _, stakeDereg := range stakeDeregs {
+		stakeDeregSlots[string(stakeDereg.StakingKey)] = stakeDereg.AddedSlot
+	}
+	var voteDelegs []models.VoteDelegation
+	result = txn.Metadata().Order("id ASC").Find(&voteDelegs)
+	if result.Error != nil {
+		return ret, fmt.Errorf("query vote delegations: %w", result.Error)
+	}
+	stakeKeyDreps := make(map[string]models.VoteDelegation)
+	for _, voteDeleg := range voteDelegs {
+		// Deregistering a staking key removes any existing delegation
+		if deregSlot, ok := stakeDeregSlots[string(voteDeleg.StakingKey)]; ok &&
+			deregSlot >= voteDeleg.AddedSlot {
+			delete(stakeKeyDreps, string(voteDeleg.StakingKey))
+			continue
+		}
+		stakeKeyDreps[string(voteDeleg.StakingKey)] = voteDeleg
+	}
+	if len(stakeKeyDreps) == 0 {
+		return ret, nil
+	}
+	stakeKeyAmounts, err := ls.stakeKeyAmounts(txn)
+	if err != nil {
+		return ret, err
+	}
+	// Add the deposits of pending proposals to the stake of their reward accounts
+	proposals, err := txn.DB().Metadata().GetGovernanceProposals(txn.Metadata())
+	if err != nil {
+		return ret, err
+	}
+	for _, proposal := range proposals {
+		stakeKeyAmounts[string(proposal.RewardAccount)] += proposal.Deposit
+	}
+	for stakingKey, voteDeleg := range stakeKeyDreps {
+		amount := stakeKeyAmounts[stakingKey]
+		switch voteDeleg.DrepType {
+		case lcommon.DrepTypeAbstain:
+			ret.alwaysAbstain += amount
+		case lcommon.DrepTypeNoConfidence:
+			ret.alwaysNoConfidence += amount
+		default:
+			// Ignore delegations to DReps that aren't registered or have been inactive for longer than the
+			// DRep inactivity period. Stake delegated to inactive DReps doesn't count towards the total
+			expiry, ok := drepExpiries[string(voteDeleg.DrepCredential)]
+			if !ok || expiry < epoch {
+				continue
+			}
+			ret.dreps[string(voteDeleg.DrepCredential)] += amount
+			ret.drepTypes[string(voteDeleg.DrepCredential)] = voteDeleg.DrepType
+		}
+	}
+	return ret, nil
+}
+
+// drepExpiries returns the last epoch in which each registered DRep is active. A DRep becomes inactive once the
+// DRep inactivity period has passed since it last registered, updated its metadata or voted. Dormant epochs, in
+// which there were no pending governance actions to vote on, don't count towards the inactivity period
+func (ls *LedgerState) drepExpiries(
+	txn *database.Txn,
+	epoch uint64,
+) (map[string]uint64, error) {
+	govParams, err := ls.currentEra.GovParamsFunc(ls.currentPParams)
+	if err != nil {
+		return nil, err
+	}
+	// Registrations and updates are processed in order, so that a deregistration removes the DRep and a
+	// later registration starts over
+	var drepRegs []models.DrepRegistration
+	result := txn.Metadata().
+		Select("drep_credential, added_slot").
+		Order("id ASC").
+		Find(&drepRegs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query DRep registrations: %w", result.Error)
+	}
+	drepActivity := make(map[string]uint64)
+	for _, drepReg := range drepRegs {
+		drepActivity[string(drepReg.DrepCredential)] = drepReg.AddedSlot
+	}
+	var drepDeregs []models.DrepDeregistration
+	result = txn.Metadata().
+		Select("drep_credential, added_slot").
+		Order("id ASC").
+		Find(&drepDeregs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query DRep deregistrations: %w", result.Error)
+	}
+	for _, drepDereg := range drepDeregs {
+		regSlot, ok := drepActivity[string(drepDereg.DrepCredential)]
+		if ok && drepDereg.AddedSlot >= regSlot {
+			delete(drepActivity, string(drepDereg.DrepCredential))
+		}
+	}
+	var drepUpdates []models.DrepUpdate
+	result = txn.Metadata().
+		Select("drep_credential, added_slot").
+		Order("id ASC").
+		Find(&drepUpdates)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query DRep updates: %w", result.Error)
+	}
+	for _, drepUpdate := range drepUpdates {
+		activitySlot, ok := drepActivity[string(drepUpdate.DrepCredential)]
+		if ok && drepUpdate.AddedSlot > activitySlot {
+			drepActivity[string(drepUpdate.DrepCredential)] = drepUpdate.AddedSlot
+		}
+	}
+	// Voting also counts as activity
+	var drepVotes []struct {
+		VoterHash []byte
+		AddedSlot uint64
+	}
+	result = txn.Metadata().
+		Model(&models.GovernanceVote{}).
+		Select("voter_hash, MAX(added_slot) AS added_slot").
+		// A []uint8 would be treated as a single blob value
+		Where(
+			"voter_type IN ?",
+			[]uint{uint(lcommon.VoterTypeDRepKeyHash), uint(lcommon.VoterTypeDRepScriptHash)},
+		).
+		Group("voter_hash").
+		Scan(&drepVotes)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query DRep votes: %w", result.Error)
+	}
+	for _, drepVote := range drepVotes {
+		activitySlot, ok := drepActivity[string(drepVote.VoterHash)]
+		if ok && drepVote.AddedSlot > activitySlot {
+			drepActivity[string(drepVote.VoterHash)] = drepVote.AddedSlot
+		}
+	}
+	// Determine the epochs in which there were pending governance actions. A proposal is pending from the
+	// epoch in which it was submitted until the epoch boundary at which it's enacted or removed
+	var proposals []models.GovernanceProposal
+	result = txn.Metadata().
+		Select("proposed_epoch, enacted_epoch, expired_epoch").
+		Find(&proposals)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query governance proposals: %w", result.Error)
+	}
+	activeEpochs := make(map[uint64]bool)
+	for _, proposal := range proposals {
+		endEpoch := epoch + 1
+		if proposal.EnactedEpoch > 0 {
+			endEpoch = proposal.EnactedEpoch
+		} else if proposal.ExpiredEpoch > 0 {
+			endEpoch = proposal.ExpiredEpoch
+		}
+		for tmpEpoch := proposal.ProposedEpoch; tmpEpoch < endEpoch && tmpEpoch <= epoch; tmpEpoch++ {
+			activeEpochs[tmpEpoch] = true
+		}
+	}
+	ret := make(map[string]uint64, len(drepActivity))
+	for drepCredential, activitySlot := range drepActivity {
+		activityEpoch, err := ls.slotEpoch(txn, activitySlot)
+		if err != nil {
+			return nil, err
+		}
+		expiry := activityEpoch + govParams.DRepInactivityPeriod
+		for tmpEpoch := activityEpoch + 1; tmpEpoch <= epoch; tmpEpoch++ {
+			if !activeEpochs[tmpEpoch] {
+				expiry++
+			}
+		}
+		ret[drepCredential] = expiry
+	}
+	return ret, nil
+}
+
+// slotEpoch returns the epoch containing the specified slot
+func (ls *LedgerState) slotEpoch(txn *database.Txn, slot uint64) (uint64, error) {
+	var tmpEpoch models.Epoch
+	result := txn.Metadata().
+		Where("start_slot <= ?", slot).
+		Order("start_slot DESC").
+		First(&tmpEpoch)
+	if result.Error != nil {
+		return 0, fmt.Errorf("query epoch for slot %d: %w", slot, result.Error)
+	}
+	return tmpEpoch.EpochId, nil
+}
+
+// snapshotDrepStake calculates and saves the DRep stake distribution at the start of the specified epoch. This is
+// used to weight DRep votes when checking governance actions for ratification at the end of the epoch
+func (ls *LedgerState) snapshotDrepStake(
+	txn *database.Txn,
+	epoch uint64,
+	slot uint64,
+) error {
+	drepStake, err := ls.drepStakeDistribution(txn, epoch)
+	if err != nil {
+		return err
+	}
+	drepStakes := make([]models.DrepStake, 0, len(drepStake.dreps)+2)
+	for drepCredential, stake := range drepStake.dreps {
+		drepStakes = append(
+			drepStakes,
+			models.DrepStake{
+				DrepCredential: []byte(drepCredential),
+				DrepType:       drepStake.drepTypes[drepCredential],
+				Epoch:          epoch,
+				Stake:          stake,
+				AddedSlot:      slot,
+			},
+		)
+	}
+	if drepStake.alwaysAbstain > 0 {
+		drepStakes = append(
+			drepStakes,
+			models.DrepStake{
+				DrepType:  lcommon.DrepTypeAbstain,
+				Epoch:     epoch,
+				Stake:     drepStake.alwaysAbstain,
+				AddedSlot: slot,
+			},
+		)
+	}
+	if drepStake.alwaysNoConfidence > 0 {
+		drepStakes = append(
+			drepStakes,
+			models.DrepStake{
+				DrepType:  lcommon.DrepTypeNoConfidence,
+				Epoch:     epoch,
+				Stake:     drepStake.alwaysNoConfidence,
+				AddedSlot: slot,
+			},
+		)
+	}
+	return txn.DB().Metadata().SetDrepStakes(drepStakes, txn.Metadata())
+}
+
+// drepStakeSnapshot returns the DRep stake distribution taken at the start of the specified epoch
+func (ls *LedgerState) drepStakeSnapshot(
+	txn *database.Txn,
+	epoch uint64,
+) (drepStakeDistribution, error) {
+	ret := drepStakeDistribution{
+		dreps:     make(map[string]uint64),
+		drepTypes: make(map[string]int),
+	}
+	drepStakes, err := txn.DB().Metadata().GetDrepStakes(epoch, txn.Metadata())
+	if err != nil {
+		return ret, err
+	}
+	for _, drepStake := range drepStakes {
+		switch drepStake.DrepType {
+		case lcommon.DrepTypeAbstain:
+			ret.alwaysAbstain += drepStake.Stake
+		case lcommon.DrepTypeNoConfidence:
+			ret.alwaysNoConfidence += drepStake.Stake
+		default:
+			ret.dreps[string(drepStake.DrepCredential)] += drepStake.Stake
+			ret.drepTypes[string(drepStake.DrepCredential)] = drepStake.DrepType
+		}
+	}
+	return ret, nil
+}
+
+// seedGovernance initializes the constitutional committee and constitution from the Conway genesis config
+func (ls *LedgerState) seedGovernance(
+	txn *database.Txn,
+	slot uint64,
+) error {
+	conwayGenesis := ls.config.CardanoNodeConfig.ConwayGenesis()
+	if conwayGenesis == nil {
+		return errors.New("could not get Conway genesis config")
+	}
+	anchor := lcommon.GovAnchor{
+		Url: conwayGenesis.Constitution.Anchor.Url,
+	}
+	if conwayGenesis.Constitution.Anchor.DataHash != "" {
+		dataHash, err := hex.DecodeString(conwayGenesis.Constitution.Anchor.DataHash)
+		if err != nil {
+			return fmt.Errorf("decode constitution anchor hash: %w", err)
+		}
+		copy(anchor.DataHash[:], dataHash)
+	}
+	var scriptHash []byte
+	if conwayGenesis.Constitution.Script != "" {
+		tmpScriptHash, err := hex.DecodeString(conwayGenesis.Constitution.Script)
+		if err != nil {
+			return fmt.Errorf("decode constitution script hash: %w", err)
+		}
+		scriptHash = tmpScriptHash
+	}
+	err := txn.DB().Metadata().SetConstitution(
+		&anchor,
+		scriptHash,
+		slot,
+		txn.Metadata(),
+	)
+	if err != nil {
+		return err
+	}
+	quorum := new(big.Rat)
+	if denominator := conwayGenesis.Committee.Threshold["denominator"]; denominator > 0 {
+		quorum.SetFrac64(
+			int64(conwayGenesis.Committee.Threshold["numerator"]),
+			int64(denominator),
+		)
+	}
+	err = txn.DB().Metadata().SetCommittee(
+		quorum,
+		false,
+		slot,
+		txn.Metadata(),
+	)
+	if err != nil {
+		return err
+	}
+	// Committee members are specified as "keyHash-<hex>" or "scriptHash-<hex>"
+	for _, member := range slices.Sorted(maps.Keys(conwayGenesis.Committee.Members)) {
+		_, credHex, ok := strings.Cut(member, "-")
+		if !ok {
+			return fmt.Errorf("invalid committee member in genesis config: %s", member)
+		}
+		cred, err := hex.DecodeString(credHex)
+		if err != nil {
+			return fmt.Errorf("decode committee member credential: %w", err)
+		}
+		err = txn.DB().Metadata().SetCommitteeMember(
+			cred,
+			uint64(conwayGenesis.Committee.Members[member]), // #nosec G115
+			slot,
+			txn.Metadata(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bootstrap returns whether governance is in the Conway bootstrap phase
+func (s *govRatifyState) bootstrap() bool {
+	return s.govParams.ProtocolMajor < govBootstrapEndProtocolMajor
+}
+
+// accepted returns whether a governance action has been accepted by each of the groups that vote on it
+func (s *govRatifyState) accepted(
+	action lcommon.GovAction,
+	votes govVotes,
+) (bool, error) {
+	drepThreshold, spoThreshold, committee, err := govActionThresholds(
+		action,
+		s.govParams,
+		s.committee.ID == 0 || s.committee.NoConfidence,
+	)
+	if err != nil {
+		return false, err
+	}
+	if committee && !s.committeeAccepted(votes.committee) {
+		return false, nil
+	}
+	_, noConfidence := action.(*lcommon.NoConfidenceGovAction)
+	if drepThreshold != nil && !s.drepAccepted(drepThreshold, votes.drep, noConfidence) {
+		return false, nil
+	}
+	_, hardFork := action.(*lcommon.HardForkInitiationGovAction)
+	if spoThreshold != nil && !s.spoAccepted(spoThreshold, votes.spo, hardFork) {
+		return false, nil
+	}
+	return true, nil
+}
+
+// committeeAccepted returns whether the constitutional committee has approved a governance action
+func (s *govRatifyState) committeeAccepted(votes map[string]uint8) bool {
+	// There is no committee to approve actions in a state of no confidence
+	if s.committee.ID == 0 || s.committee.NoConfidence {
+		return false
+	}
+	var yes, no uint64
+	var activeMembers uint
+	for _, member := range s.committeeMembers {
+		// Expired members are counted as abstaining
+		if member.ExpiresEpoch < s.epoch {
+			continue
+		}
+		activeMembers++
+		// Members without a hot key are counted as abstaining
+		hotKey, ok := s.committeeHotKeys[string(member.ColdCredential)]
+		if !ok {
+			continue
+		}
+		vote, ok := votes[string(hotKey)]
+		switch {
+		case !ok, vote == lcommon.GovVoteNo:
+			no++
+		case vote == lcommon.GovVoteYes:
+			yes++
+		}
+	}
+	if !s.bootstrap() && activeMembers < s.govParams.MinCommitteeSize {
+		return false
+	}
+	quorum := new(big.Rat)
+	if s.committee.Quorum != nil && s.committee.Quorum.Rat != nil {
+		quorum = s.committee.Quorum.Rat
+	}
+	return govVoteRatio(yes, no).Cmp(quorum) >= 0
+}
+
+// drepAccepted returns whether DReps have approved a governance action with the specified threshold
+func (s *govRatifyState) drepAccepted(
+	threshold *big.Rat,
+	votes map[string]uint8,
+	noConfidence bool,
+) bool {
+	// DReps don't vote during the bootstrap phase
+	if s.bootstrap() || threshold.Sign() == 0 {
+		return true
+	}
+	var yes, no uint64
+	for drep, stake := range s.drepStake.dreps {
+		vote, ok := votes[drep]
+		switch {
+		case !ok, vote == lcommon.GovVoteNo:
+			no += stake
+		case vote == lcommon.GovVoteYes:
+			yes += stake
+		}
+	}
+	// Stake delegated to "always no confidence" votes yes on no confidence actions and no on everything else
+	if noConfidence {
+		yes += s.drepStake.alwaysNoConfidence
+	} else {
+		no += s.drepStake.alwaysNoConfidence
+	}
+	return govVoteRatio(yes, no).Cmp(threshold) >= 0
+}
+
+// spoAccepted returns whether SPOs have approved a governance action with the specified threshold
+func (s *govRatifyState) spoAccepted(
+	threshold *big.Rat,
+	votes map[string]uint8,
+	hardFork bool,
+) bool {
+	if threshold.Sign() == 0 {
+		return true
+	}
+	var yes, no uint64
+	for pool, stake := range s.poolStake {
+		vote, ok := votes[pool]
+		switch {
+		case !ok:
+			// Pools that haven't voted are counted as abstaining during the bootstrap phase, except on
+			// hard fork initiation
+			if s.bootstrap() && !hardFork {
+				continue
+			}
+			no += stake
+		case vote == lcommon.GovVoteNo:
+			no += stake
+		case vote == lcommon.GovVoteYes:
+			yes += stake
+		}
+	}
+	return govVoteRatio(yes, no).Cmp(threshold) >= 0
+}
+
+// govActionThresholds returns the DRep and SPO voting thresholds for a governance action, along with whether the
+// constitutional committee must approve it. A nil threshold means that the group doesn't vote on the action
+func govActionThresholds(
+	action lcommon.GovAction,
+	govParams eras.GovParams,
+	noConfidence bool,
+) (*big.Rat, *big.Rat, bool, error) {
+	drepThresholds := govParams.DRepVotingThresholds
+	poolThresholds := govParams.PoolVotingThresholds
+	switch a := action.(type) {
+	case *lcommon.NoConfidenceGovAction:
+		return govThreshold(drepThresholds.MotionNoConfidence),
+			govThreshold(poolThresholds.MotionNoConfidence),
+			false,
+			nil
+	case *lcommon.UpdateCommitteeGovAction:
+		if noConfidence {
+			return govThreshold(drepThresholds.CommitteeNoConfidence),
+				govThreshold(poolThresholds.CommitteeNoConfidence),
+				false,
+				nil
+		}
+		return govThreshold(drepThresholds.CommitteeNormal),
+			govThreshold(poolThresholds.CommitteeNormal),
+			false,
+			nil
+	case *lcommon.NewConstitutionGovAction:
+		return govThreshold(drepThresholds.UpdateToConstitution), nil, true, nil
+	case *lcommon.HardForkInitiationGovAction:
+		return govThreshold(drepThresholds.HardForkInitiation),
+			govThreshold(poolThresholds.HardForkInitiation),
+			true,
+			nil
+	case *lcommon.ParameterChangeGovAction:
+		// The thresholds depend on which groups of protocol parameters are changed
+		var paramUpdate map[uint]cbor.RawMessage
+		if _, err := cbor.Decode(a.ParamUpdate, &paramUpdate); err != nil {
+			return nil, nil, false, fmt.Errorf("decode protocol parameter update: %w", err)
+		}
+		drepThreshold := new(big.Rat)
+		var security bool
+		for key := range paramUpdate {
+			var groupThreshold cbor.Rat
+			switch key {
+			case 2, 3, 4, 20, 21, 22, 24:
+				groupThreshold = drepThresholds.PpNetworkGroup
+			case 0, 1, 5, 6, 10, 11, 16, 17, 19, 33:
+				groupThreshold = drepThresholds.PpEconomicGroup
+			case 7, 8, 9, 18, 23:
+				groupThreshold = drepThresholds.PpTechnicalGroup
+			case 25, 26, 27, 28, 29, 30, 31, 32:
+				groupThreshold = drepThresholds.PpGovGroup
+			default:
+				return nil, nil, false, fmt.Errorf("unknown protocol parameter in update: %d", key)
+			}
+			if tmpThreshold := govThreshold(groupThreshold); tmpThreshold.Cmp(drepThreshold) > 0 {
+				drepThreshold = tmpThreshold
+			}
+			// SPOs only vote on changes to parameters in the security group
+			switch key {
+			case 0, 1, 2, 3, 4, 17, 21, 22, 30, 33:
+				security = true
+			}
+		}
+		if !security {
+			return drepThreshold, nil, true, nil
+		}
+		return drepThreshold, govThreshold(poolThresholds.PpSecurityGroup), true, nil
+	case *lcommon.TreasuryWithdrawalGovAction:
+		return govThreshold(drepThresholds.TreasuryWithdrawal), nil, true, nil
+	default:
+		return nil, nil, false, fmt.Errorf("unsupported governance action type: %T", action)
+	}
+}
+
+// govThreshold returns the value of a voting threshold, treating an unset threshold as 0
+func govThreshold(threshold cbor.Rat) *big.Rat {
+	if threshold.Rat == nil {
+		return new(big.Rat)
+	}
+	return threshold.Rat
+}
+
+// govVoteRatio returns the ratio of "yes" votes to the total of "yes" and "no" votes
+func govVoteRatio(yes, no uint64) *big.Rat {
+	if yes+no == 0 {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Quo(ratFromUint64(yes), ratFromUint64(yes+no))
+}
+
+// govActionPriority returns the order in which governance actions of the specified type are ratified and enacted
+func govActionPriority(actionType uint) int {
+	switch actionType {
+	case lcommon.GovActionTypeNoConfidence:
+		return 0
+	case lcommon.GovActionTypeUpdateCommittee:
+		return 1
+	case lcommon.GovActionTypeNewConstitution:
+		return 2
+	case lcommon.GovActionTypeHardForkInitiation:
+		return 3
+	case lcommon.GovActionTypeParameterChange:
+		return 4
+	case lcommon.GovActionTypeTreasuryWithdrawal:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// govActionDelaying returns whether ratifying a governance action of the specified type delays the ratification
+// of other actions until the next epoch boundary
+func govActionDelaying(actionType uint) bool {
+	switch actionType {
+	case lcommon.GovActionTypeNoConfidence,
+		lcommon.GovActionTypeUpdateCommittee,
+		lcommon.GovActionTypeNewConstitution,
+		lcommon.GovActionTypeHardForkInitiation:
+		return true
+	default:
+		return false
+	}
+}
+
+// govActionTypePurpose returns the purpose for governance actions of the specified type
+func govActionTypePurpose(actionType uint) govActionPurpose {
+	switch actionType {
+	case lcommon.GovActionTypeParameterChange:
+		return govActionPurposePParams
+	case lcommon.GovActionTypeHardForkInitiation:
+		return govActionPurposeHardFork
+	case lcommon.GovActionTypeNoConfidence, lcommon.GovActionTypeUpdateCommittee:
+		return govActionPurposeCommittee
+	case lcommon.GovActionTypeNewConstitution:
+		return govActionPurposeConstitution
+	default:
+		return govActionPurposeNone
+	}
+}
+
+// govActionPrev returns the purpose of a governance action and the previous action with the same purpose that
+// it references
+func govActionPrev(action lcommon.GovAction) (govActionPurpose, *lcommon.GovActionId) {
+	switch a := action.(type) {
+	case *lcommon.ParameterChangeGovAction:
+		return govActionPurposePParams, a.ActionId
+	case *lcommon.HardForkInitiationGovAction:
+		return govActionPurposeHardFork, a.ActionId
+	case *lcommon.NoConfidenceGovAction:
+		return govActionPurposeCommittee, a.ActionId
+	case *lcommon.UpdateCommitteeGovAction:
+		return govActionPurposeCommittee, a.ActionId
+	case *lcommon.NewConstitutionGovAction:
+		return govActionPurposeConstitution, a.ActionId
+	default:
+		return govActionPurposeNone, nil
+	}
+}
+
+// govActionIdEqual returns whether two governance action IDs are equal, where a nil ID refers to the genesis state
+func govActionIdEqual(a, b *lcommon.GovActionId) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.TransactionId == b.TransactionId && a.GovActionIdx == b.GovActionIdx
+}
+
+// decodeGovAction decodes a governance action from its stored CBOR
+func decodeGovAction(actionCbor []byte) (lcommon.GovAction, error) {
+	var tmpAction lcommon.GovActionWrapper
+	if _, err := cbor.Decode(actionCbor, &tmpAction); err != nil {
+		return nil, fmt.Errorf("decode governance action: %w", err)
+	}
+	return tmpAction.Action, nil
+}
@@ -0,0 +1,647 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"github.com/blinklabs-io/dingo/state/eras"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/conway"
+)
+
+func testGovParams() eras.GovParams {
+	return eras.GovParams{
+		ProtocolMajor: 10,
+		PoolVotingThresholds: conway.PoolVotingThresholds{
+			PpSecurityGroup: cbor.Rat{Rat: big.NewRat(51, 100)},
+		},
+		DRepVotingThresholds: conway.DRepVotingThresholds{
+			PpNetworkGroup:   cbor.Rat{Rat: big.NewRat(67, 100)},
+			PpEconomicGroup:  cbor.Rat{Rat: big.NewRat(67, 100)},
+			PpTechnicalGroup: cbor.Rat{Rat: big.NewRat(67, 100)},
+			PpGovGroup:       cbor.Rat{Rat: big.NewRat(75, 100)},
+		},
+		MinCommitteeSize: 1,
+	}
+}
+
+func TestGovActionThresholdsParameterChange(t *testing.T) {
+	testDefs := []struct {
+		paramUpdate  map[uint]uint64
+		drepExpected *big.Rat
+		spoExpected  *big.Rat
+	}{
+		// Technical group only (nOpt)
+		{
+			paramUpdate:  map[uint]uint64{8: 500},
+			drepExpected: big.NewRat(67, 100),
+		},
+		// Governance group (dRepDeposit)
+		{
+			paramUpdate:  map[uint]uint64{31: 500000000},
+			drepExpected: big.NewRat(75, 100),
+		},
+		// Security group (maxTxSize)
+		{
+			paramUpdate:  map[uint]uint64{3: 16384},
+			drepExpected: big.NewRat(67, 100),
+			spoExpected:  big.NewRat(51, 100),
+		},
+	}
+	for _, testDef := range testDefs {
+		updateCbor, err := cbor.Encode(testDef.paramUpdate)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		action := &lcommon.ParameterChangeGovAction{
+			ParamUpdate: updateCbor,
+		}
+		drepThreshold, spoThreshold, committee, err := govActionThresholds(
+			action,
+			testGovParams(),
+			false,
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !committee {
+			t.Fatalf("committee approval should be required")
+		}
+		if drepThreshold.Cmp(testDef.drepExpected) != 0 {
+			t.Fatalf(
+				"did not get expected DRep threshold: got %s, expected %s",
+				drepThreshold.String(),
+				testDef.drepExpected.String(),
+			)
+		}
+		if (spoThreshold == nil) != (testDef.spoExpected == nil) ||
+			(spoThreshold != nil && spoThreshold.Cmp(testDef.spoExpected) != 0) {
+			t.Fatalf(
+				"did not get expected SPO threshold: got %v, expected %v",
+				spoThreshold,
+				testDef.spoExpected,
+			)
+		}
+	}
+}
+
+func TestGovRatifyStateVotes(t *testing.T) {
+	s := &govRatifyState{
+		epoch:     100,
+		govParams: testGovParams(),
+		committee: models.Committee{
+			ID:     1,
+			Quorum: &models.Rat{Rat: big.NewRat(2, 3)},
+		},
+		committeeMembers: []models.CommitteeMember{
+			{ColdCredential: []byte("cold1"), ExpiresEpoch: 200},
+			{ColdCredential: []byte("cold2"), ExpiresEpoch: 200},
+			{ColdCredential: []byte("cold3"), ExpiresEpoch: 200},
+			// Expired
+			{ColdCredential: []byte("cold4"), ExpiresEpoch: 50},
+		},
+		committeeHotKeys: map[string][]byte{
+			"cold1": []byte("hot1"),
+			"cold2": []byte("hot2"),
+			"cold3": []byte("hot3"),
+			"cold4": []byte("hot4"),
+		},
+		drepStake: drepStakeDistribution{
+			dreps: map[string]uint64{
+				"drep1": 600,
+				"drep2": 300,
+			},
+			alwaysAbstain:      1000,
+			alwaysNoConfidence: 100,
+		},
+	}
+	// Expired member votes don't count
+	if !s.committeeAccepted(map[string]uint8{"hot1": lcommon.GovVoteYes, "hot2": lcommon.GovVoteYes, "hot4": lcommon.GovVoteNo}) {
+		t.Fatalf("committee should have accepted")
+	}
+	// Missing votes count as no
+	if s.committeeAccepted(map[string]uint8{"hot1": lcommon.GovVoteYes}) {
+		t.Fatalf("committee should not have accepted")
+	}
+	// Abstaining members are excluded from the ratio
+	if !s.committeeAccepted(map[string]uint8{"hot1": lcommon.GovVoteYes, "hot2": lcommon.GovVoteAbstain, "hot3": lcommon.GovVoteAbstain}) {
+		t.Fatalf("committee should have accepted")
+	}
+	// 600 / (600 + 300 + 100)
+	drepVotes := map[string]uint8{"drep1": lcommon.GovVoteYes}
+	if s.drepAccepted(big.NewRat(61, 100), drepVotes, false) {
+		t.Fatalf("DReps should not have accepted")
+	}
+	if !s.drepAccepted(big.NewRat(60, 100), drepVotes, false) {
+		t.Fatalf("DReps should have accepted")
+	}
+	// "Always no confidence" stake votes yes on no confidence actions
+	if !s.drepAccepted(big.NewRat(70, 100), drepVotes, true) {
+		t.Fatalf("DReps should have accepted")
+	}
+	// DReps don't vote during the bootstrap phase
+	s.govParams.ProtocolMajor = 9
+	if !s.drepAccepted(big.NewRat(1, 1), map[string]uint8{}, false) {
+		t.Fatalf("DReps should have accepted during bootstrap")
+	}
+}
+
+// testConwayLedgerState returns a test LedgerState in the Conway era, after the end of the bootstrap phase
+func testConwayLedgerState(t *testing.T) *LedgerState {
+	ls := newTestLedgerState(t)
+	testHardFork(t, ls, eras.ConwayEraDesc.Id)
+	pparams, ok := ls.currentPParams.(*conway.ConwayProtocolParameters)
+	if !ok {
+		t.Fatalf("unexpected pparams type: %T", ls.currentPParams)
+	}
+	pparams.ProtocolVersion.Major = 10
+	pparams.DRepInactivityPeriod = 2
+	pparams.GovActionValidityPeriod = 6
+	return ls
+}
+
+// testHardFork moves a test LedgerState to the specified era, using the protocol parameters from the test
+// genesis config
+func testHardFork(t *testing.T, ls *LedgerState, eraId uint) {
+	t.Helper()
+	for _, era := range eras.Eras[1 : eraId+1] {
+		pparams, err := era.HardForkFunc(ls.config.CardanoNodeConfig, ls.currentPParams)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ls.currentEra = era
+		ls.currentPParams = pparams
+	}
+}
+
+// testRegisterDrep adds a DRep registration for the specified key hash
+func testRegisterDrep(t *testing.T, txn *database.Txn, drepId byte, slot uint64) {
+	t.Helper()
+	err := txn.DB().Metadata().SetRegistrationDrep(
+		&lcommon.RegistrationDrepCertificate{
+			CertType: lcommon.CertificateTypeRegistrationDrep,
+			DrepCredential: lcommon.StakeCredential{
+				CredType:   lcommon.StakeCredentialTypeAddrKeyHash,
+				Credential: testKeyHash(drepId),
+			},
+		},
+		slot,
+		500000000,
+		txn.Metadata(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+// testDelegateVote registers the specified staking key, delegates its vote and adds a UTxO with the specified
+// amount for it. The DRep ID is ignored for the predefined DRep types
+func testDelegateVote(
+	t *testing.T,
+	txn *database.Txn,
+	stakingKey byte,
+	drepType int,
+	drepId byte,
+	slot uint64,
+	amount uint64,
+) {
+	t.Helper()
+	stakeCred := lcommon.StakeCredential{
+		CredType:   lcommon.StakeCredentialTypeAddrKeyHash,
+		Credential: testKeyHash(stakingKey),
+	}
+	err := txn.DB().Metadata().SetStakeRegistration(
+		&lcommon.StakeRegistrationCertificate{
+			CertType:          lcommon.CertificateTypeStakeRegistration,
+			StakeRegistration: stakeCred,
+		},
+		slot,
+		2000000,
+		txn.Metadata(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	drep := lcommon.Drep{Type: drepType}
+	if drepType == lcommon.DrepTypeAddrKeyHash {
+		drep.Credential = testKeyHash(drepId)
+	}
+	err = txn.DB().Metadata().SetVoteDelegation(
+		&lcommon.VoteDelegationCertificate{
+			CertType:        lcommon.CertificateTypeVoteDelegation,
+			StakeCredential: stakeCred,
+			Drep:            drep,
+		},
+		slot,
+		txn.Metadata(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testAddUtxo(t, txn, stakingKey, slot, amount)
+}
+
+// testAddGovProposal adds a pending governance action proposal with a transaction ID made from the specified
+// byte, and returns its action ID
+func testAddGovProposal(
+	t *testing.T,
+	txn *database.Txn,
+	txId byte,
+	actionType uint,
+	action lcommon.GovAction,
+	rewardAccount byte,
+	deposit uint64,
+	epoch uint64,
+	slot uint64,
+) *lcommon.GovActionId {
+	t.Helper()
+	actionCbor, err := cbor.Encode(
+		&lcommon.GovActionWrapper{Type: actionType, Action: action},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	result := txn.Metadata().Create(
+		&models.GovernanceProposal{
+			TxId:          bytes.Repeat([]byte{txId}, 32),
+			ActionType:    actionType,
+			ActionCbor:    actionCbor,
+			Deposit:       deposit,
+			RewardAccount: testKeyHash(rewardAccount),
+			ProposedEpoch: epoch,
+			ExpiresEpoch:  epoch + 6,
+			AddedSlot:     slot,
+		},
+	)
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	return &lcommon.GovActionId{
+		TransactionId: [32]byte(bytes.Repeat([]byte{txId}, 32)),
+	}
+}
+
+func TestDrepStakeDistribution(t *testing.T) {
+	ls := testConwayLedgerState(t)
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		for epoch := range uint64(6) {
+			testAddEpoch(t, ls, txn, epoch)
+		}
+		// A proposal has been pending since epoch 0, so there are no dormant epochs
+		actionId := testAddGovProposal(
+			t,
+			txn,
+			1,
+			lcommon.GovActionTypeInfo,
+			&lcommon.InfoGovAction{Type: lcommon.GovActionTypeInfo},
+			30,
+			50,
+			0,
+			10,
+		)
+		// DRep 20 voted in epoch 4, DRep 21 has been inactive since registering in epoch 0 and
+		// DRep 22 has deregistered
+		for _, drepId := range []byte{20, 21, 22} {
+			testRegisterDrep(t, txn, drepId, 10)
+		}
+		err := txn.DB().Metadata().SetGovernanceVote(
+			&lcommon.Voter{
+				Type: lcommon.VoterTypeDRepKeyHash,
+				Hash: [28]byte(testKeyHash(20)),
+			},
+			actionId,
+			&lcommon.VotingProcedure{Vote: lcommon.GovVoteYes},
+			410,
+			txn.Metadata(),
+		)
+		if err != nil {
+			return err
+		}
+		err = txn.DB().Metadata().SetDeregistrationDrep(
+			&lcommon.DeregistrationDrepCertificate{
+				CertType: lcommon.CertificateTypeDeregistrationDrep,
+				DrepCredential: lcommon.StakeCredential{
+					CredType:   lcommon.StakeCredentialTypeAddrKeyHash,
+					Credential: testKeyHash(22),
+				},
+			},
+			20,
+			500000000,
+			txn.Metadata(),
+		)
+		if err != nil {
+			return err
+		}
+		testDelegateVote(t, txn, 30, lcommon.DrepTypeAddrKeyHash, 20, 30, 100)
+		testDelegateVote(t, txn, 31, lcommon.DrepTypeAddrKeyHash, 21, 31, 200)
+		testDelegateVote(t, txn, 32, lcommon.DrepTypeAbstain, 0, 32, 300)
+		testDelegateVote(t, txn, 33, lcommon.DrepTypeNoConfidence, 0, 33, 400)
+		testDelegateVote(t, txn, 34, lcommon.DrepTypeAddrKeyHash, 22, 34, 500)
+		// Reward account balances count towards the voting stake
+		return txn.DB().Metadata().SetRewards(
+			[]models.Reward{
+				{StakingKey: testKeyHash(30), Epoch: 2, Amount: 25, AddedSlot: 200},
+			},
+			txn.Metadata(),
+		)
+	})
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	drepStake, err := ls.drepStakeDistribution(txn, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// UTxO, reward balance and proposal deposit for staking key 30. The inactive and deregistered DReps
+	// aren't included
+	expectedDreps := map[string]uint64{
+		string(testKeyHash(20)): 175,
+	}
+	if len(drepStake.dreps) != len(expectedDreps) ||
+		drepStake.dreps[string(testKeyHash(20))] != expectedDreps[string(testKeyHash(20))] {
+		t.Fatalf(
+			"did not get expected DRep stake: got %v, expected %v",
+			drepStake.dreps,
+			expectedDreps,
+		)
+	}
+	if drepStake.alwaysAbstain != 300 || drepStake.alwaysNoConfidence != 400 {
+		t.Fatalf(
+			"did not get expected predefined DRep stake: got %d/%d, expected 300/400",
+			drepStake.alwaysAbstain,
+			drepStake.alwaysNoConfidence,
+		)
+	}
+}
+
+func TestDrepExpiriesDormantEpochs(t *testing.T) {
+	ls := testConwayLedgerState(t)
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		for epoch := range uint64(6) {
+			testAddEpoch(t, ls, txn, epoch)
+		}
+		testRegisterDrep(t, txn, 20, 10)
+		// The proposal is pending in epochs 2 and 3, leaving epochs 1, 4 and 5 dormant
+		testAddGovProposal(
+			t,
+			txn,
+			1,
+			lcommon.GovActionTypeInfo,
+			&lcommon.InfoGovAction{Type: lcommon.GovActionTypeInfo},
+			30,
+			50,
+			2,
+			210,
+		)
+		result := txn.Metadata().
+			Model(&models.GovernanceProposal{}).
+			Where("1 = 1").
+			Updates(map[string]any{"expired_epoch": 4, "expired_slot": 400})
+		return result.Error
+	})
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	drepExpiries, err := ls.drepExpiries(txn, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Registered in epoch 0 with an inactivity period of 2, extended by 3 dormant epochs
+	if expiry := drepExpiries[string(testKeyHash(20))]; expiry != 5 {
+		t.Fatalf("did not get expected DRep expiry: got %d, expected 5", expiry)
+	}
+}
+
+func TestGovRatifyStateDrepSnapshot(t *testing.T) {
+	ls := testConwayLedgerState(t)
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		testAddEpoch(t, ls, txn, 0)
+		testRegisterDrep(t, txn, 20, 10)
+		testDelegateVote(t, txn, 30, lcommon.DrepTypeAddrKeyHash, 20, 30, 100)
+		testDelegateVote(t, txn, 31, lcommon.DrepTypeAbstain, 0, 31, 200)
+		if err := ls.snapshotDrepStake(txn, 0, 0); err != nil {
+			return err
+		}
+		// Stake added after the snapshot doesn't count until the next snapshot
+		testAddUtxo(t, txn, 30, 50, 1000)
+		return nil
+	})
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	ratifyState, err := ls.govRatifyState(txn, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if stake := ratifyState.drepStake.dreps[string(testKeyHash(20))]; stake != 100 {
+		t.Fatalf("did not get expected DRep stake: got %d, expected 100", stake)
+	}
+	if ratifyState.drepStake.alwaysAbstain != 200 {
+		t.Fatalf(
+			"did not get expected always abstain stake: got %d, expected 200",
+			ratifyState.drepStake.alwaysAbstain,
+		)
+	}
+}
+
+func TestObsoleteGovProposals(t *testing.T) {
+	// Proposal 1 has been enacted. Proposal 2 competes with it, and proposal 3 builds on proposal 2. Proposal 4
+	// builds on proposal 1 and proposal 5 builds on proposal 4. Proposal 6 has a different purpose
+	testProposal := func(id uint, action lcommon.GovAction, actionType uint) models.GovernanceProposal {
+		actionCbor, err := cbor.Encode(
+			&lcommon.GovActionWrapper{Type: actionType, Action: action},
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return models.GovernanceProposal{
+			ID:         id,
+			TxId:       bytes.Repeat([]byte{byte(id)}, 32),
+			ActionType: actionType,
+			ActionCbor: actionCbor,
+		}
+	}
+	testActionId := func(id byte) *lcommon.GovActionId {
+		return &lcommon.GovActionId{TransactionId: [32]byte(bytes.Repeat([]byte{id}, 32))}
+	}
+	paramChange := func(prev *lcommon.GovActionId) lcommon.GovAction {
+		return &lcommon.ParameterChangeGovAction{
+			Type:        lcommon.GovActionTypeParameterChange,
+			ActionId:    prev,
+			ParamUpdate: []byte{0xa0},
+		}
+	}
+	pending := []models.GovernanceProposal{
+		// Out of order, as if sorted by priority
+		testProposal(5, paramChange(testActionId(4)), lcommon.GovActionTypeParameterChange),
+		testProposal(2, paramChange(nil), lcommon.GovActionTypeParameterChange),
+		testProposal(3, paramChange(testActionId(2)), lcommon.GovActionTypeParameterChange),
+		testProposal(4, paramChange(testActionId(1)), lcommon.GovActionTypeParameterChange),
+		testProposal(
+			6,
+			&lcommon.NewConstitutionGovAction{Type: lcommon.GovActionTypeNewConstitution},
+			lcommon.GovActionTypeNewConstitution,
+		),
+		testProposal(
+			7,
+			&lcommon.InfoGovAction{Type: lcommon.GovActionTypeInfo},
+			lcommon.GovActionTypeInfo,
+		),
+	}
+	roots := map[govActionPurpose]*lcommon.GovActionId{
+		govActionPurposePParams: testActionId(1),
+	}
+	obsolete, err := obsoleteGovProposals(pending, roots)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[uint]bool{2: true, 3: true}
+	if len(obsolete) != len(expected) || !obsolete[2] || !obsolete[3] {
+		t.Fatalf(
+			"did not get expected obsolete proposals: got %v, expected %v",
+			obsolete,
+			expected,
+		)
+	}
+}
+
+func TestProcessGovernanceObsoleteProposals(t *testing.T) {
+	ls := testConwayLedgerState(t)
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		testAddEpoch(t, ls, txn, 0)
+		testDelegateVote(t, txn, 30, lcommon.DrepTypeAbstain, 0, 10, 1000)
+		paramChange := func(prev *lcommon.GovActionId) lcommon.GovAction {
+			return &lcommon.ParameterChangeGovAction{
+				Type:        lcommon.GovActionTypeParameterChange,
+				ActionId:    prev,
+				ParamUpdate: []byte{0xa1, 0x08, 0x19, 0x01, 0xf4}, // {8: 500}
+			}
+		}
+		// Proposal 1 was ratified at the previous epoch boundary
+		actionId1 := testAddGovProposal(t, txn, 1, lcommon.GovActionTypeParameterChange, paramChange(nil), 30, 100, 0, 20)
+		result := txn.Metadata().
+			Model(&models.GovernanceProposal{}).
+			Where("tx_id = ?", actionId1.TransactionId[:]).
+			Updates(map[string]any{"ratified_epoch": 0, "ratified_slot": 50})
+		if result.Error != nil {
+			return result.Error
+		}
+		// Proposal 2 competes with proposal 1, and proposal 3 builds on proposal 2
+		actionId2 := testAddGovProposal(t, txn, 2, lcommon.GovActionTypeParameterChange, paramChange(nil), 30, 200, 0, 21)
+		testAddGovProposal(t, txn, 3, lcommon.GovActionTypeParameterChange, paramChange(actionId2), 30, 400, 0, 22)
+		// Proposal 4 builds on proposal 1
+		testAddGovProposal(t, txn, 4, lcommon.GovActionTypeParameterChange, paramChange(actionId1), 30, 800, 0, 23)
+		return ls.processGovernance(txn, 1, testEpochLength)
+	})
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	var proposals []models.GovernanceProposal
+	if result := txn.Metadata().Order("id ASC").Find(&proposals); result.Error != nil {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	// Proposal 1 is enacted, proposals 2 and 3 are removed and proposal 4 is still pending
+	expectedEnacted := []bool{true, false, false, false}
+	expectedRemoved := []bool{false, true, true, false}
+	for idx, proposal := range proposals {
+		if (proposal.EnactedSlot > 0) != expectedEnacted[idx] ||
+			(proposal.ExpiredSlot > 0) != expectedRemoved[idx] {
+			t.Fatalf(
+				"did not get expected state for proposal %d: enacted slot %d, expired slot %d",
+				idx+1,
+				proposal.EnactedSlot,
+				proposal.ExpiredSlot,
+			)
+		}
+	}
+	// The deposits for the enacted and removed proposals are refunded
+	var refunds uint64
+	result := txn.Metadata().
+		Model(&models.Reward{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("staking_key = ? AND type = ?", testKeyHash(30), models.RewardTypeProposalRefund).
+		Scan(&refunds)
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if refunds != 700 {
+		t.Fatalf("did not get expected proposal refunds: got %d, expected 700", refunds)
+	}
+}
+
+func TestEnactHardForkInitiation(t *testing.T) {
+	ls := newTestLedgerState(t)
+	testHardFork(t, ls, eras.BabbageEraDesc.Id)
+	pparams, ok := ls.currentPParams.(*babbage.BabbageProtocolParameters)
+	if !ok {
+		t.Fatalf("unexpected pparams type: %T", ls.currentPParams)
+	}
+	pparams.ProtocolMajor = 8
+	action := &lcommon.HardForkInitiationGovAction{
+		Type: lcommon.GovActionTypeHardForkInitiation,
+	}
+	action.ProtocolVersion.Major = 9
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		testAddEpoch(t, ls, txn, 0)
+		return ls.enactGovAction(txn, action, 1, testEpochLength)
+	})
+	// Enacting the hard fork moves the ledger to the era for the new protocol version
+	if ls.currentEra.Id != eras.ConwayEraDesc.Id {
+		t.Fatalf(
+			"did not get expected era: got %s, expected %s",
+			ls.currentEra.Name,
+			eras.ConwayEraDesc.Name,
+		)
+	}
+	govParams, err := ls.currentEra.GovParamsFunc(ls.currentPParams)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if govParams.ProtocolMajor != 9 {
+		t.Fatalf(
+			"did not get expected protocol major version: got %d, expected 9",
+			govParams.ProtocolMajor,
+		)
+	}
+	// Intra-era hard forks only update the protocol version
+	action.ProtocolVersion.Major = 10
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		return ls.enactGovAction(txn, action, 2, 2*testEpochLength)
+	})
+	if ls.currentEra.Id != eras.ConwayEraDesc.Id {
+		t.Fatalf("did not get expected era: got %s", ls.currentEra.Name)
+	}
+	govParams, err = ls.currentEra.GovParamsFunc(ls.currentPParams)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if govParams.ProtocolMajor != 10 {
+		t.Fatalf(
+			"did not get expected protocol major version: got %d, expected 10",
+			govParams.ProtocolMajor,
+		)
+	}
+}
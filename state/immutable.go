@@ -0,0 +1,179 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+// ImmutableTip returns the most recent block that is at least k blocks behind the chain tip, where k is the
+// security parameter. Blocks up to this point can no longer be rolled back. An empty tip is returned when the
+// chain doesn't contain more than k blocks
+func (ls *LedgerState) ImmutableTip() (ochainsync.Tip, error) {
+	ls.RLock()
+	defer ls.RUnlock()
+	return ls.immutableTip()
+}
+
+// immutableTip returns the current immutable tip. The caller must hold the ledger state lock
+func (ls *LedgerState) immutableTip() (ochainsync.Tip, error) {
+	securityParam := ls.securityParam()
+	if len(ls.currentTip.Point.Hash) == 0 ||
+		ls.currentTip.BlockNumber < securityParam {
+		return ochainsync.Tip{}, nil
+	}
+	block, err := database.BlockByNumber(
+		ls.db,
+		ls.currentTip.BlockNumber-securityParam,
+	)
+	if err != nil {
+		// The first Byron block number only belongs to an EBB
+		if errors.Is(err, database.ErrBlockNotFound) {
+			return ochainsync.Tip{}, nil
+		}
+		return ochainsync.Tip{}, err
+	}
+	return ochainsync.Tip{
+		Point:       ocommon.NewPoint(block.Slot, block.Hash),
+		BlockNumber: block.Number,
+	}, nil
+}
+
+// checkRollbackDepth returns ErrRollbackTooDeep if rolling back to the specified point would remove more
+// than k blocks from our chain
+func (ls *LedgerState) checkRollbackDepth(
+	txn *database.Txn,
+	point ocommon.Point,
+) error {
+	securityParam := ls.securityParam()
+	if securityParam == 0 || len(ls.currentTip.Point.Hash) == 0 {
+		return nil
+	}
+	// Rolling back to the origin removes every block
+	depth := ls.currentTip.BlockNumber + 1
+	if len(point.Hash) > 0 {
+		block, err := database.BlockByPointTxn(txn, point)
+		if err != nil {
+			return fmt.Errorf("query rollback point: %w", err)
+		}
+		depth = 0
+		if ls.currentTip.BlockNumber > block.Number {
+			depth = ls.currentTip.BlockNumber - block.Number
+		}
+	}
+	if depth > securityParam {
+		return fmt.Errorf(
+			"%w: rollback to slot %d would remove %d blocks (k = %d)",
+			ErrRollbackTooDeep,
+			point.Slot,
+			depth,
+			securityParam,
+		)
+	}
+	return nil
+}
+
+// headerIsImmutable returns whether a block header from an upstream peer is more than k blocks behind that
+// peer's chain tip. Such headers can't be rolled back by the peer
+func (ls *LedgerState) headerIsImmutable(
+	header ledger.BlockHeader,
+	tip ochainsync.Tip,
+) bool {
+	var blockNumber uint64
+	// Byron block headers don't include the block number, so we use the chain difficulty
+	switch h := header.(type) {
+	case *ledger.ByronEpochBounaryBlockHeader:
+		blockNumber = h.ConsensusData.Difficulty.Value
+	case *ledger.ByronMainBlockHeader:
+		blockNumber = h.ConsensusData.Difficulty.Unknown
+	default:
+		blockNumber = header.BlockNumber()
+	}
+	return tip.BlockNumber > blockNumber+ls.securityParam()
+}
+
+func (ls *LedgerState) scheduleArchiveBlocks() {
+	ls.Lock()
+	defer ls.Unlock()
+	if ls.timerArchiveBlocks != nil {
+		ls.timerArchiveBlocks.Stop()
+	}
+	ls.timerArchiveBlocks = time.AfterFunc(
+		archiveBlocksInterval,
+		func() {
+			defer func() {
+				// Schedule the next run
+				ls.scheduleArchiveBlocks()
+			}()
+			ls.archiveBlocks()
+		},
+	)
+}
+
+// archiveBlocks moves the content of blocks at or before the immutable tip to the ImmutableDB chunk store
+func (ls *LedgerState) archiveBlocks() {
+	// The chunk size matches the Byron epoch length, as with cardano-node
+	byronGenesis := ls.config.CardanoNodeConfig.ByronGenesis()
+	if byronGenesis == nil {
+		return
+	}
+	chunkSize := uint64(byronGenesis.ProtocolConsts.K) * 10
+	ls.RLock()
+	immutableTip, err := ls.immutableTip()
+	ls.RUnlock()
+	if err != nil {
+		ls.config.Logger.Error(
+			"failed to determine immutable tip",
+			"error",
+			err,
+		)
+		return
+	}
+	if len(immutableTip.Point.Hash) == 0 {
+		return
+	}
+	for {
+		ls.Lock()
+		count, err := database.BlocksArchiveBatch(
+			ls.db,
+			chunkSize,
+			immutableTip.Point.Slot,
+			archiveBlocksBatchSize,
+		)
+		ls.Unlock()
+		if err != nil {
+			ls.config.Logger.Error(
+				"failed to archive immutable blocks",
+				"component", "ledger",
+				"error", err,
+			)
+			return
+		}
+		if count == 0 {
+			return
+		}
+		ls.config.Logger.Debug(
+			fmt.Sprintf("archived %d immutable blocks", count),
+			"component", "ledger",
+		)
+	}
+}
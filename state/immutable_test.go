@@ -0,0 +1,138 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+// testAddBlock adds a block with the specified number to the chain, with a recognizable hash based on the slot
+func testAddBlock(t *testing.T, txn *database.Txn, slot uint64, number uint64) ocommon.Point {
+	t.Helper()
+	point := ocommon.NewPoint(slot, bytes.Repeat([]byte{byte(slot)}, 32))
+	err := database.BlockCreateTxn(
+		txn,
+		database.Block{
+			Slot:   point.Slot,
+			Hash:   point.Hash,
+			Number: number,
+			Type:   ledger.BlockTypeBabbage,
+			Cbor:   []byte{0x80},
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return point
+}
+
+func TestCheckRollbackDepth(t *testing.T) {
+	ls := newTestLedgerState(t)
+	securityParam := ls.securityParam()
+	var immutablePoint, recentPoint ocommon.Point
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		immutablePoint = testAddBlock(t, txn, 10, 100)
+		recentPoint = testAddBlock(t, txn, 20, 100+securityParam)
+		tipPoint := testAddBlock(t, txn, 30, 101+securityParam)
+		ls.currentTip = ochainsync.Tip{
+			Point:       tipPoint,
+			BlockNumber: 101 + securityParam,
+		}
+		return nil
+	})
+	testDefs := []struct {
+		point       ocommon.Point
+		tooDeep     bool
+		expectedErr bool
+	}{
+		// Within k blocks of our tip
+		{
+			point: recentPoint,
+		},
+		// More than k blocks behind our tip
+		{
+			point:       immutablePoint,
+			tooDeep:     true,
+			expectedErr: true,
+		},
+		// Origin
+		{
+			point:       ocommon.NewPointOrigin(),
+			tooDeep:     true,
+			expectedErr: true,
+		},
+		// Unknown block
+		{
+			point:       ocommon.NewPoint(25, bytes.Repeat([]byte{0xff}, 32)),
+			expectedErr: true,
+		},
+	}
+	for _, testDef := range testDefs {
+		txn := ls.db.Transaction(false)
+		err := ls.checkRollbackDepth(txn, testDef.point)
+		_ = txn.Rollback()
+		if testDef.expectedErr != (err != nil) {
+			t.Fatalf("did not get expected result for rollback to slot %d: got error %v", testDef.point.Slot, err)
+		}
+		if testDef.tooDeep != errors.Is(err, ErrRollbackTooDeep) {
+			t.Fatalf("did not get expected error for rollback to slot %d: got %v", testDef.point.Slot, err)
+		}
+	}
+}
+
+func TestImmutableTip(t *testing.T) {
+	ls := newTestLedgerState(t)
+	securityParam := ls.securityParam()
+	var immutablePoint ocommon.Point
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		testAddBlock(t, txn, 5, 99)
+		immutablePoint = testAddBlock(t, txn, 10, 100)
+		tipPoint := testAddBlock(t, txn, 30, 100+securityParam)
+		ls.currentTip = ochainsync.Tip{
+			Point:       tipPoint,
+			BlockNumber: 100 + securityParam,
+		}
+		return nil
+	})
+	tip, err := ls.ImmutableTip()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !pointsEqual(tip.Point, immutablePoint) || tip.BlockNumber != 100 {
+		t.Fatalf(
+			"did not get expected immutable tip: got block %d at slot %d, expected block %d at slot %d",
+			tip.BlockNumber,
+			tip.Point.Slot,
+			100,
+			immutablePoint.Slot,
+		)
+	}
+	// Nothing is immutable until our chain is longer than k blocks
+	ls.currentTip.BlockNumber = securityParam - 1
+	tip, err = ls.ImmutableTip()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if tip.BlockNumber != 0 || len(tip.Point.Hash) != 0 {
+		t.Fatalf("did not get expected empty immutable tip: got %+v", tip)
+	}
+}
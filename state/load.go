@@ -0,0 +1,158 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/blinklabs-io/dingo/database/immutable"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+const (
+	// Maximum number of blocks to apply in each database transaction when loading from an ImmutableDB
+	immutableLoadBatchSize = 5000
+
+	// Maximum total size of the block CBOR to apply in each database transaction when loading from an
+	// ImmutableDB. The block and UTxO CBOR are written to the blob store in the same transaction, so this is
+	// chosen to stay well under the badger transaction size limit
+	immutableLoadBatchMaxCborSize = 2 << 20
+
+	// Interval for logging progress when loading from an ImmutableDB
+	immutableLoadLogInterval = 30 * time.Second
+)
+
+// LoadImmutableDb imports blocks from a cardano-node ImmutableDB directory into the ledger state. Blocks
+// are processed in the same way as blocks fetched from upstream peers. Loading starts after our
+// current tip, so an interrupted load can be resumed
+func (ls *LedgerState) LoadImmutableDb(immutableDir string) error {
+	immutableDb, err := immutable.New(immutableDir)
+	if err != nil {
+		return fmt.Errorf("open immutable DB: %w", err)
+	}
+	immutableTip, err := immutableDb.GetTip()
+	if err != nil {
+		return fmt.Errorf("get immutable DB tip: %w", err)
+	}
+	if immutableTip == nil {
+		return fmt.Errorf("immutable DB contains no blocks: %s", immutableDir)
+	}
+	startTip := ls.Tip()
+	if startTip.Point.Slot >= immutableTip.Slot {
+		ls.config.Logger.Info(
+			fmt.Sprintf(
+				"current tip at slot %d is already at or past immutable DB tip at slot %d",
+				startTip.Point.Slot,
+				immutableTip.Slot,
+			),
+			"component", "ledger",
+		)
+		return nil
+	}
+	iter, err := immutableDb.BlocksFromPoint(startTip.Point)
+	if err != nil {
+		return fmt.Errorf("read immutable DB: %w", err)
+	}
+	defer iter.Close()
+	ls.config.Logger.Info(
+		fmt.Sprintf(
+			"loading blocks from immutable DB %s, starting at slot %d, ending at slot %d",
+			immutableDir,
+			startTip.Point.Slot,
+			immutableTip.Slot,
+		),
+		"component", "ledger",
+	)
+	blockCount := 0
+	lastLog := time.Now()
+	blockEvents := make([]BlockfetchEvent, 0, immutableLoadBatchSize)
+	batchCborSize := 0
+	for {
+		next, err := iter.Next()
+		if err != nil {
+			return fmt.Errorf("read immutable DB: %w", err)
+		}
+		if next != nil {
+			// Skip blocks that we already have
+			if next.Slot < startTip.Point.Slot ||
+				(next.Slot == startTip.Point.Slot && bytes.Equal(next.Hash, startTip.Point.Hash)) {
+				continue
+			}
+			blk, err := ledger.NewBlockFromCbor(next.Type, next.Cbor)
+			if err != nil {
+				return fmt.Errorf(
+					"decode block %x at slot %d: %w",
+					next.Hash,
+					next.Slot,
+					err,
+				)
+			}
+			blockEvents = append(
+				blockEvents,
+				BlockfetchEvent{
+					Point: ocommon.NewPoint(next.Slot, next.Hash),
+					Type:  next.Type,
+					Block: blk,
+				},
+			)
+			batchCborSize += len(next.Cbor)
+		}
+		// Process batch in a single transaction when full or when we've run out of blocks
+		if len(blockEvents) >= immutableLoadBatchSize ||
+			batchCborSize >= immutableLoadBatchMaxCborSize ||
+			(next == nil && len(blockEvents) > 0) {
+			ls.Lock()
+			err := ls.applyBlockEventsTxn(blockEvents)
+			ls.Unlock()
+			if err != nil {
+				return err
+			}
+			blockCount += len(blockEvents)
+			blockEvents = blockEvents[:0]
+			batchCborSize = 0
+			if time.Since(lastLog) > immutableLoadLogInterval {
+				tip := ls.Tip()
+				ls.config.Logger.Info(
+					fmt.Sprintf(
+						"loaded %d blocks from immutable DB, current tip: %x at slot %d (%.2f%%)",
+						blockCount,
+						tip.Point.Hash,
+						tip.Point.Slot,
+						float64(tip.Point.Slot)/float64(immutableTip.Slot)*100,
+					),
+					"component", "ledger",
+				)
+				lastLog = time.Now()
+			}
+		}
+		if next == nil {
+			break
+		}
+	}
+	tip := ls.Tip()
+	ls.config.Logger.Info(
+		fmt.Sprintf(
+			"finished loading %d blocks from immutable DB, new tip: %x at slot %d",
+			blockCount,
+			tip.Point.Hash,
+			tip.Point.Slot,
+		),
+		"component", "ledger",
+	)
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/immutable"
+	"github.com/blinklabs-io/dingo/event"
+	"github.com/blinklabs-io/dingo/state/eras"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+const (
+	// ImmutableDB test data from the preview network, which contains 347 Babbage blocks in epoch 444
+	testImmutableDbDir       = "../database/immutable/testdata"
+	testImmutableDbEpoch     = 444
+	testImmutableDbBlocks    = 347
+	testPreviewEpochLength   = 86400
+	testImmutableDbTipHash   = "7ada6ed78f6caa499370da6548b143c59320f5e5283e5e80e202a994ba7bfebf"
+	testImmutableDbTipSlot   = 38426380
+	testImmutableDbStartSlot = testImmutableDbEpoch * testPreviewEpochLength
+)
+
+// testImmutableDbBlockEvents returns block events for the specified number of blocks from the start of the
+// ImmutableDB test data
+func testImmutableDbBlockEvents(t *testing.T, count int) []BlockfetchEvent {
+	t.Helper()
+	immutableDb, err := immutable.New(testImmutableDbDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	iter, err := immutableDb.BlocksFromPoint(ocommon.NewPointOrigin())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer iter.Close()
+	ret := make([]BlockfetchEvent, 0, count)
+	for range count {
+		tmpBlock, err := iter.Next()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		blk, err := ledger.NewBlockFromCbor(tmpBlock.Type, tmpBlock.Cbor)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ret = append(
+			ret,
+			BlockfetchEvent{
+				Point: ocommon.NewPoint(tmpBlock.Slot, tmpBlock.Hash),
+				Block: blk,
+				Type:  tmpBlock.Type,
+			},
+		)
+	}
+	return ret
+}
+
+// testImmutableDbLedgerState returns a ledger with the first block from the ImmutableDB test data as its tip.
+// The test data starts partway through the chain, so we add the first block directly along with an epoch
+// record for the epoch containing it. This sets up the in-memory state directly, since the in-memory
+// database doesn't allow writes while the read transactions from loading it are still open
+func testImmutableDbLedgerState(t *testing.T) *LedgerState {
+	t.Helper()
+	ls := newTestLedgerState(t)
+	ls.config.EventBus = event.NewEventBus(nil)
+	ls.metrics.init(nil)
+	firstBlock := testImmutableDbBlockEvents(t, 1)[0]
+	prevHash, err := hex.DecodeString(firstBlock.Block.PrevHash())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		err := txn.DB().Metadata().SetEpoch(
+			testImmutableDbStartSlot,
+			testImmutableDbEpoch,
+			nil,
+			eras.BabbageEraDesc.Id,
+			1,
+			testPreviewEpochLength,
+			txn.Metadata(),
+		)
+		if err != nil {
+			return err
+		}
+		err = database.BlockCreateTxn(
+			txn,
+			database.Block{
+				Slot:     firstBlock.Point.Slot,
+				Hash:     firstBlock.Point.Hash,
+				Number:   firstBlock.Block.BlockNumber(),
+				Type:     firstBlock.Type,
+				PrevHash: prevHash,
+				Cbor:     firstBlock.Block.Cbor(),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		ls.currentTip = ochainsync.Tip{
+			Point:       firstBlock.Point,
+			BlockNumber: firstBlock.Block.BlockNumber(),
+		}
+		if err := ls.db.SetTip(ls.currentTip, txn); err != nil {
+			return err
+		}
+		ls.currentEpoch, err = txn.DB().GetEpochLatest(txn)
+		if err != nil {
+			return err
+		}
+		ls.currentEra = eras.BabbageEraDesc
+		// Certificate deposits are the only protocol parameters needed for these blocks
+		ls.currentPParams = &babbage.BabbageProtocolParameters{
+			KeyDeposit:  2_000_000,
+			PoolDeposit: 500_000_000,
+		}
+		return nil
+	})
+	return ls
+}
+
+func TestLoadImmutableDb(t *testing.T) {
+	ls := testImmutableDbLedgerState(t)
+	firstBlockNumber := ls.Tip().BlockNumber
+	// Load the rest of the blocks
+	if err := ls.LoadImmutableDb(testImmutableDbDir); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tip := ls.Tip()
+	if tip.Point.Slot != testImmutableDbTipSlot ||
+		hex.EncodeToString(tip.Point.Hash) != testImmutableDbTipHash {
+		t.Fatalf(
+			"did not get expected tip: got %x at slot %d, expected %s at slot %d",
+			tip.Point.Hash,
+			tip.Point.Slot,
+			testImmutableDbTipHash,
+			testImmutableDbTipSlot,
+		)
+	}
+	if tip.BlockNumber != firstBlockNumber+testImmutableDbBlocks-1 {
+		t.Fatalf(
+			"did not get expected tip block number: got %d, expected %d",
+			tip.BlockNumber,
+			firstBlockNumber+testImmutableDbBlocks-1,
+		)
+	}
+	// The loaded blocks were committed to the database
+	tmpBlock, err := database.BlockByNumber(ls.db, tip.BlockNumber)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(tmpBlock.Hash, tip.Point.Hash) {
+		t.Fatalf("did not get expected block: got %x, expected %x", tmpBlock.Hash, tip.Point.Hash)
+	}
+	// Loading again does nothing, since we're already at the ImmutableDB tip
+	if err := ls.LoadImmutableDb(testImmutableDbDir); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if ls.Tip().Point.Slot != testImmutableDbTipSlot {
+		t.Fatalf("did not get expected tip slot: got %d, expected %d", ls.Tip().Point.Slot, testImmutableDbTipSlot)
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+)
+
+// poolState represents the state of a registered stake pool
+type poolState struct {
+	// Parameters currently in effect
+	params models.PoolRegistration
+	// Parameters from a re-registration, which take effect at the start of the next epoch
+	futureParams *models.PoolRegistration
+	// Epoch in which the pool will retire, or zero if the pool is not retiring
+	retiringEpoch uint64
+	// Deposit paid when the pool was first registered
+	deposit uint64
+	// Slot of the registration that the pool has been continuously registered since
+	registeredSlot uint64
+	// Epoch of the slot in which the future parameters were registered
+	futureParamsEpoch uint64
+}
+
+// advance applies the changes that happen to a pool at the start of the specified epoch. It returns false if the
+// pool has retired
+func (p *poolState) advance(epoch uint64) bool {
+	if p.retiringEpoch > 0 && p.retiringEpoch <= epoch {
+		return false
+	}
+	if p.futureParams != nil && p.futureParamsEpoch < epoch {
+		p.params = *p.futureParams
+		p.futureParams = nil
+	}
+	return true
+}
+
+// poolStates returns the state of all registered stake pools as of the specified epoch, which must be the current
+// epoch or the following epoch. Pool registrations and retirements are replayed in the order they were added, with
+// a re-registration of an active pool updating its parameters starting in the following epoch and cancelling any
+// pending retirement
+func (ls *LedgerState) poolStates(
+	txn *database.Txn,
+	epoch uint64,
+) (map[string]*poolState, error) {
+	return ls.poolStatesBefore(txn, epoch, math.MaxUint64)
+}
+
+// poolSnapshotStates returns the state of all registered stake pools at the start of the specified epoch, which is
+// when the stake snapshot for the epoch is taken. Registrations and retirements from the epoch itself aren't included
+func (ls *LedgerState) poolSnapshotStates(
+	txn *database.Txn,
+	epoch uint64,
+) (map[string]*poolState, error) {
+	return ls.poolStatesBefore(txn, epoch, epoch)
+}
+
+// poolStatesBefore returns the state of all registered stake pools as of the specified epoch, only including the
+// registrations and retirements from before the specified cutoff epoch
+func (ls *LedgerState) poolStatesBefore(
+	txn *database.Txn,
+	epoch uint64,
+	cutoffEpoch uint64,
+) (map[string]*poolState, error) {
+	var poolRegs []models.PoolRegistration
+	result := txn.Metadata().
+		Preload("Owners").
+		Preload("Relays").
+		Order("id ASC").
+		Find(&poolRegs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query pool registrations: %w", result.Error)
+	}
+	var poolRetirements []models.PoolRetirement
+	result = txn.Metadata().Order("id ASC").Find(&poolRetirements)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query pool retirements: %w", result.Error)
+	}
+	var epochs []models.Epoch
+	result = txn.Metadata().Order("start_slot ASC").Find(&epochs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query epochs: %w", result.Error)
+	}
+	slotEpoch := func(slot uint64) uint64 {
+		idx := sort.Search(
+			len(epochs),
+			func(i int) bool {
+				return epochs[i].StartSlot > slot
+			},
+		)
+		if idx == 0 {
+			return 0
+		}
+		return epochs[idx-1].EpochId
+	}
+	// Merge registrations and retirements into a single list of events in the order they were added
+	type poolEvent struct {
+		slot       uint64
+		reg        *models.PoolRegistration
+		retirement *models.PoolRetirement
+	}
+	events := make([]poolEvent, 0, len(poolRegs)+len(poolRetirements))
+	for idx := range poolRegs {
+		events = append(
+			events,
+			poolEvent{slot: poolRegs[idx].AddedSlot, reg: &poolRegs[idx]},
+		)
+	}
+	for idx := range poolRetirements {
+		events = append(
+			events,
+			poolEvent{
+				slot:       poolRetirements[idx].AddedSlot,
+				retirement: &poolRetirements[idx],
+			},
+		)
+	}
+	slices.SortStableFunc(
+		events,
+		func(a, b poolEvent) int {
+			return cmp.Compare(a.slot, b.slot)
+		},
+	)
+	ret := make(map[string]*poolState)
+	for _, event := range events {
+		eventEpoch := slotEpoch(event.slot)
+		// Events are in slot order, so the rest are also after the cutoff
+		if eventEpoch >= cutoffEpoch {
+			break
+		}
+		if event.reg != nil {
+			poolKeyHash := string(event.reg.PoolKeyHash)
+			pool, ok := ret[poolKeyHash]
+			if ok && !pool.advance(eventEpoch) {
+				delete(ret, poolKeyHash)
+				ok = false
+			}
+			if !ok {
+				ret[poolKeyHash] = &poolState{
+					params:         *event.reg,
+					deposit:        event.reg.DepositAmount,
+					registeredSlot: event.reg.AddedSlot,
+				}
+				continue
+			}
+			pool.futureParams = event.reg
+			pool.futureParamsEpoch = eventEpoch
+			pool.retiringEpoch = 0
+			continue
+		}
+		poolKeyHash := string(event.retirement.PoolKeyHash)
+		pool, ok := ret[poolKeyHash]
+		if !ok {
+			continue
+		}
+		if !pool.advance(eventEpoch) {
+			delete(ret, poolKeyHash)
+			continue
+		}
+		pool.retiringEpoch = event.retirement.Epoch
+	}
+	for poolKeyHash, pool := range ret {
+		if !pool.advance(epoch) {
+			delete(ret, poolKeyHash)
+		}
+	}
+	return ret, nil
+}
+
+// processPoolRetirements retires the pools that are retiring at the start of the specified epoch and refunds their
+// deposits to the reward account from the pool parameters. Refunds for unregistered reward accounts go to the
+// treasury. This must be called before the epoch record is created for the new epoch
+func (ls *LedgerState) processPoolRetirements(
+	txn *database.Txn,
+	epoch uint64,
+	slot uint64,
+) error {
+	if epoch == 0 {
+		return nil
+	}
+	pools, err := ls.poolStates(txn, epoch-1)
+	if err != nil {
+		return err
+	}
+	var retiring []*poolState
+	for _, pool := range pools {
+		if pool.retiringEpoch == epoch {
+			retiring = append(retiring, pool)
+		}
+	}
+	if len(retiring) == 0 {
+		return nil
+	}
+	// Process in a consistent order
+	slices.SortFunc(
+		retiring,
+		func(a, b *poolState) int {
+			return cmp.Compare(a.registeredSlot, b.registeredSlot)
+		},
+	)
+	registeredStakingKeys, err := ls.registeredStakingKeys(txn)
+	if err != nil {
+		return err
+	}
+	rewards := []models.Reward{}
+	var unclaimed uint64
+	for _, pool := range retiring {
+		// Any re-registration parameters take effect before the pool is retired
+		params := pool.params
+		if pool.futureParams != nil {
+			params = *pool.futureParams
+		}
+		if pool.deposit > 0 {
+			if registeredStakingKeys[string(params.RewardAccount)] {
+				rewards = append(
+					rewards,
+					models.Reward{
+						StakingKey:  params.RewardAccount,
+						PoolKeyHash: params.PoolKeyHash,
+						Epoch:       epoch,
+						Type:        models.RewardTypePoolDepositRefund,
+						Amount:      pool.deposit,
+						AddedSlot:   slot,
+					},
+				)
+			} else {
+				unclaimed += pool.deposit
+			}
+		}
+		ls.config.Logger.Info(
+			fmt.Sprintf(
+				"retired pool %x at epoch %d",
+				params.PoolKeyHash,
+				epoch,
+			),
+			"component", "ledger",
+		)
+	}
+	if err := txn.DB().Metadata().SetRewards(rewards, txn.Metadata()); err != nil {
+		return err
+	}
+	if unclaimed > 0 {
+		pots, err := txn.DB().Metadata().GetAdaPots(epoch, txn.Metadata())
+		if err != nil {
+			return err
+		}
+		err = txn.DB().Metadata().SetAdaPots(
+			epoch,
+			uint64(pots.Reserves),
+			uint64(pots.Treasury)+unclaimed,
+			slot,
+			txn.Metadata(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+const (
+	testPoolDeposit = 500000000
+)
+
+// testPoolLedgerState returns a ledger in epoch 1 with two pools that are retiring at the start of epoch 2.
+// The reward account of pool 1 is registered and the reward account of pool 2 is not
+func testPoolLedgerState(t *testing.T) *LedgerState {
+	ls := newTestLedgerState(t)
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		testAddEpoch(t, ls, txn, 0)
+		testRegisterPool(t, txn, 1, 10, 5, testPoolDeposit)
+		testRegisterPool(t, txn, 2, 11, 6, testPoolDeposit)
+		testDelegateStake(t, txn, 10, 1, 7, 300)
+		for _, poolId := range []byte{1, 2} {
+			err := txn.DB().Metadata().SetPoolRetirement(
+				&lcommon.PoolRetirementCertificate{
+					CertType:    lcommon.CertificateTypePoolRetirement,
+					PoolKeyHash: lcommon.PoolKeyHash(lcommon.NewBlake2b224(testKeyHash(poolId))),
+					Epoch:       2,
+				},
+				50,
+				txn.Metadata(),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		testAddEpoch(t, ls, txn, 1)
+		return txn.DB().Metadata().SetAdaPots(2, 1000, 0, 2*testEpochLength, txn.Metadata())
+	})
+	return ls
+}
+
+func TestQueryShelleyPoolState(t *testing.T) {
+	ls := testPoolLedgerState(t)
+	pool1 := ledger.PoolId(ledger.NewBlake2b224(testKeyHash(1)))
+	pool2 := ledger.PoolId(ledger.NewBlake2b224(testKeyHash(2)))
+	testDefs := []struct {
+		poolIds  []ledger.PoolId
+		expected []ledger.PoolId
+	}{
+		{
+			expected: []ledger.PoolId{pool1, pool2},
+		},
+		{
+			poolIds:  []ledger.PoolId{pool2},
+			expected: []ledger.PoolId{pool2},
+		},
+		{
+			poolIds:  []ledger.PoolId{},
+			expected: []ledger.PoolId{},
+		},
+	}
+	for _, testDef := range testDefs {
+		tmpResult, err := ls.queryShelleyPoolState(testDef.poolIds)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		result := tmpResult.([]any)[0].(poolStateResult)
+		tmpResult, err = ls.queryShelleyStakePoolParams(testDef.poolIds)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		params := tmpResult.([]any)[0].(map[ledger.PoolId]poolParamsResult)
+		if len(result.Params) != len(testDef.expected) ||
+			len(params) != len(testDef.expected) {
+			t.Fatalf(
+				"did not get expected number of pools: got %d/%d, expected %d",
+				len(result.Params),
+				len(params),
+				len(testDef.expected),
+			)
+		}
+		for _, poolId := range testDef.expected {
+			if _, ok := params[poolId]; !ok {
+				t.Fatalf("did not get expected params for pool %s", poolId.String())
+			}
+			if result.Retiring[poolId] != 2 {
+				t.Fatalf(
+					"did not get expected retiring epoch for pool %s: got %d, expected 2",
+					poolId.String(),
+					result.Retiring[poolId],
+				)
+			}
+			if result.Deposits[poolId] != testPoolDeposit {
+				t.Fatalf(
+					"did not get expected deposit for pool %s: got %d, expected %d",
+					poolId.String(),
+					result.Deposits[poolId],
+					testPoolDeposit,
+				)
+			}
+		}
+	}
+}
+
+func TestProcessPoolRetirements(t *testing.T) {
+	ls := testPoolLedgerState(t)
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		if err := ls.processPoolRetirements(txn, 2, 2*testEpochLength); err != nil {
+			return err
+		}
+		testAddEpoch(t, ls, txn, 2)
+		return nil
+	})
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	pools, err := ls.poolStates(txn, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(pools) != 0 {
+		t.Fatalf("did not get expected retired pools: %d pools still registered", len(pools))
+	}
+	// The deposit for pool 1 is refunded to its reward account
+	rewardBalances, err := ls.rewardBalances(txn, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(rewardBalances) != 1 || rewardBalances[string(testKeyHash(10))] != testPoolDeposit {
+		t.Fatalf(
+			"did not get expected reward balances: got %v, expected %d for the pool 1 reward account",
+			rewardBalances,
+			testPoolDeposit,
+		)
+	}
+	// The deposit for pool 2 goes to the treasury, since its reward account isn't registered
+	pots, err := txn.DB().Metadata().GetAdaPots(2, txn.Metadata())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if pots.Treasury != testPoolDeposit {
+		t.Fatalf(
+			"did not get expected treasury: got %d, expected %d",
+			pots.Treasury,
+			testPoolDeposit,
+		)
+	}
+}
@@ -0,0 +1,685 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	"github.com/blinklabs-io/gouroboros/ledger/allegra"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/shelley"
+)
+
+const (
+	// Depth of the Sum6KES scheme used for block signatures
+	kesDepth = 6
+	// Size of a Sum6KES signature, which is an Ed25519 signature followed by a pair of verification keys for
+	// each level of the tree
+	kesSignatureSize = ed25519.SignatureSize + kesDepth*2*ed25519.PublicKeySize
+
+	// Precision used for the leader eligibility calculation
+	leaderCheckPrecision = 512
+)
+
+// Seeds mixed into the VRF input for the nonce and leader values in Shelley through Alonzo (TPraos)
+var (
+	tpraosSeedEta = tpraosSeed(0)
+	tpraosSeedL   = tpraosSeed(1)
+)
+
+// praosHeader contains the fields from a Shelley-era or later block header that are used for header validation
+type praosHeader struct {
+	// Raw header body, which is what the KES signature covers
+	bodyCbor   []byte
+	slot       uint64
+	issuerVkey lcommon.IssuerVkey
+	vrfKey     []byte
+	// Shelley through Alonzo (TPraos) headers include separate VRF results for the nonce and leader values
+	tpraos               bool
+	nonceVrf             lcommon.VrfResult
+	leaderVrf            lcommon.VrfResult
+	opCertHotVkey        []byte
+	opCertSequenceNumber uint32
+	opCertKesPeriod      uint32
+	opCertSignature      []byte
+	signature            []byte
+}
+
+func newPraosHeader(header ledger.BlockHeader) (*praosHeader, error) {
+	var ret praosHeader
+	var shelleyHeader *shelley.ShelleyBlockHeader
+	switch h := header.(type) {
+	case *ledger.ShelleyBlockHeader:
+		shelleyHeader = h
+	case *allegra.AllegraBlockHeader:
+		shelleyHeader = &h.ShelleyBlockHeader
+	case *ledger.MaryBlockHeader:
+		shelleyHeader = &h.ShelleyBlockHeader
+	case *ledger.AlonzoBlockHeader:
+		shelleyHeader = &h.ShelleyBlockHeader
+	case *ledger.BabbageBlockHeader:
+		ret.setBabbage(h)
+	case *ledger.ConwayBlockHeader:
+		ret.setBabbage(&h.BabbageBlockHeader)
+	default:
+		return nil, fmt.Errorf("unsupported block header type: %T", header)
+	}
+	if shelleyHeader != nil {
+		ret.slot = shelleyHeader.Body.Slot
+		ret.issuerVkey = shelleyHeader.Body.IssuerVkey
+		ret.vrfKey = shelleyHeader.Body.VrfKey
+		ret.tpraos = true
+		ret.nonceVrf = shelleyHeader.Body.NonceVrf
+		ret.leaderVrf = shelleyHeader.Body.LeaderVrf
+		ret.opCertHotVkey = shelleyHeader.Body.OpCertHotVkey
+		ret.opCertSequenceNumber = shelleyHeader.Body.OpCertSequenceNumber
+		ret.opCertKesPeriod = shelleyHeader.Body.OpCertKesPeriod
+		ret.opCertSignature = shelleyHeader.Body.OpCertSignature
+		ret.signature = shelleyHeader.Signature
+	}
+	// Grab the original header body bytes
+	var headerParts []cbor.RawMessage
+	if _, err := cbor.Decode(header.Cbor(), &headerParts); err != nil {
+		return nil, fmt.Errorf("decode block header: %w", err)
+	}
+	if len(headerParts) != 2 {
+		return nil, fmt.Errorf(
+			"block header has unexpected number of items: %d",
+			len(headerParts),
+		)
+	}
+	ret.bodyCbor = headerParts[0]
+	return &ret, nil
+}
+
+func (h *praosHeader) setBabbage(header *ledger.BabbageBlockHeader) {
+	h.slot = header.Body.Slot
+	h.issuerVkey = header.Body.IssuerVkey
+	h.vrfKey = header.Body.VrfKey
+	h.leaderVrf = header.Body.VrfResult
+	h.opCertHotVkey = header.Body.OpCert.HotVkey
+	h.opCertSequenceNumber = header.Body.OpCert.SequenceNumber
+	h.opCertKesPeriod = header.Body.OpCert.KesPeriod
+	h.opCertSignature = header.Body.OpCert.Signature
+	h.signature = header.Signature
+}
+
+// praosEpochState contains the per-epoch state used for validating block headers. It's calculated on first use for
+// each epoch
+type praosEpochState struct {
+	epoch        uint64
+	startSlot    uint64
+	nonce        []byte
+	poolStake    map[string]uint64
+	totalStake   uint64
+	vrfKeyHashes map[string][]byte
+}
+
+// errPraosEpochStateUnknown is returned when we don't know the state needed to validate a block header yet, such as
+// for a header from an epoch after the next one
+var errPraosEpochStateUnknown = errors.New("epoch state for block header is not known")
+
+// praosEpochState returns the state for validating block headers in the epoch containing the specified slot. This
+// can be the current epoch, or the next epoch once its nonce is fixed. The stake distribution and VRF keys come from
+// the stake snapshot used for leader election in the epoch, which is taken at the start of the previous epoch
+func (ls *LedgerState) praosEpochState(
+	txn *database.Txn,
+	slot uint64,
+) (*praosEpochState, error) {
+	if slot < ls.currentEpoch.StartSlot {
+		return nil, fmt.Errorf(
+			"%w: slot %d is before the current epoch",
+			errPraosEpochStateUnknown,
+			slot,
+		)
+	}
+	currentEpochEnd := ls.currentEpoch.StartSlot + uint64(
+		ls.currentEpoch.LengthInSlots,
+	)
+	epoch := ls.currentEpoch.EpochId
+	if slot >= currentEpochEnd {
+		epoch++
+	}
+	if ret, ok := ls.praosEpochStates[epoch]; ok {
+		return ret, nil
+	}
+	ret := &praosEpochState{
+		epoch:        epoch,
+		startSlot:    ls.currentEpoch.StartSlot,
+		nonce:        ls.currentEpoch.Nonce,
+		poolStake:    make(map[string]uint64),
+		vrfKeyHashes: make(map[string][]byte),
+	}
+	if epoch > ls.currentEpoch.EpochId {
+		_, epochLength, err := ls.currentEra.EpochLengthFunc(
+			ls.config.CardanoNodeConfig,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if slot >= currentEpochEnd+uint64(epochLength) {
+			return nil, fmt.Errorf(
+				"%w: slot %d is beyond the next epoch",
+				errPraosEpochStateUnknown,
+				slot,
+			)
+		}
+		// The nonce for the next epoch is fixed once the chain reaches the stability window before the start
+		// of the epoch
+		stabilityWindow, err := ls.nonceStabilityWindow()
+		if err != nil {
+			return nil, err
+		}
+		if currentEpochEnd > stabilityWindow &&
+			ls.currentTip.Point.Slot < currentEpochEnd-stabilityWindow {
+			return nil, fmt.Errorf(
+				"%w: epoch nonce for epoch %d is not fixed yet",
+				errPraosEpochStateUnknown,
+				epoch,
+			)
+		}
+		nonce, err := ls.calculateEpochNonce(txn, currentEpochEnd)
+		if err != nil {
+			return nil, err
+		}
+		ret.startSlot = currentEpochEnd
+		ret.nonce = nonce
+	}
+	if epoch > 0 {
+		snapshotEpoch := epoch - 1
+		poolStakes, err := txn.DB().Metadata().GetPoolStakes(
+			snapshotEpoch,
+			txn.Metadata(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, poolStake := range poolStakes {
+			ret.poolStake[string(poolStake.PoolKeyHash)] = poolStake.Stake
+			ret.totalStake += poolStake.Stake
+		}
+		// The VRF keys come from the pool parameters captured in the stake snapshot
+		pools, err := ls.poolSnapshotStates(txn, snapshotEpoch)
+		if err != nil {
+			return nil, err
+		}
+		for poolKeyHash, pool := range pools {
+			ret.vrfKeyHashes[poolKeyHash] = pool.params.VrfKeyHash
+		}
+	}
+	// Discard the state for past epochs
+	for cachedEpoch := range ls.praosEpochStates {
+		if cachedEpoch < ls.currentEpoch.EpochId {
+			delete(ls.praosEpochStates, cachedEpoch)
+		}
+	}
+	if ls.praosEpochStates == nil {
+		ls.praosEpochStates = make(map[uint64]*praosEpochState)
+	}
+	ls.praosEpochStates[epoch] = ret
+	return ret, nil
+}
+
+// validateBlockHeaderPraos validates the VRF proofs, leader eligibility, KES signature, and operational
+// certificate from a Shelley-era or later block header. The operational certificate issue number is checked against
+// the last one seen on our chain when checkOpCertCounter is set
+func (ls *LedgerState) validateBlockHeaderPraos(
+	txn *database.Txn,
+	blockHeader ledger.BlockHeader,
+	checkOpCertCounter bool,
+) error {
+	shelleyGenesis := ls.config.CardanoNodeConfig.ShelleyGenesis()
+	if shelleyGenesis == nil {
+		return errors.New("could not get genesis config")
+	}
+	header, err := newPraosHeader(blockHeader)
+	if err != nil {
+		return err
+	}
+	issuerHash := header.issuerVkey.Hash()
+	epochState, err := ls.praosEpochState(txn, header.slot)
+	if err != nil {
+		return err
+	}
+	epochNonce := epochState.nonce
+	if len(epochNonce) != lcommon.Blake2b256Size {
+		return fmt.Errorf(
+			"epoch nonce has unexpected length: %d",
+			len(epochNonce),
+		)
+	}
+	// Verify VRF proofs and calculate the leader value
+	var leaderValue []byte
+	if header.tpraos {
+		nonceVrfInput := tpraosVrfInput(tpraosSeedEta, header.slot, epochNonce)
+		if err := verifyVrf(header.vrfKey, header.nonceVrf, nonceVrfInput); err != nil {
+			return fmt.Errorf("nonce VRF: %w", err)
+		}
+		leaderVrfInput := tpraosVrfInput(tpraosSeedL, header.slot, epochNonce)
+		if err := verifyVrf(header.vrfKey, header.leaderVrf, leaderVrfInput); err != nil {
+			return fmt.Errorf("leader VRF: %w", err)
+		}
+		leaderValue = header.leaderVrf.Output
+	} else {
+		vrfInput := ledger.MkInputVrf(int64(header.slot), epochNonce) // #nosec G115
+		if err := verifyVrf(header.vrfKey, header.leaderVrf, vrfInput); err != nil {
+			return fmt.Errorf("VRF: %w", err)
+		}
+		leaderValue = PraosLeaderValue(header.leaderVrf.Output)
+	}
+	vrfKeyHash := lcommon.Blake2b256Hash(header.vrfKey)
+	// Check that the issuer was eligible to produce a block in this slot
+	overlayDelegate, err := ls.overlaySlotDelegate(
+		txn,
+		header,
+		epochState,
+		shelleyGenesis,
+	)
+	if err != nil {
+		return err
+	}
+	if overlayDelegate != nil {
+		if issuerHash.String() != overlayDelegate.delegate {
+			return fmt.Errorf(
+				"issuer %s is not the genesis delegate for overlay slot %d",
+				issuerHash.String(),
+				header.slot,
+			)
+		}
+		if vrfKeyHash.String() != overlayDelegate.vrf {
+			return fmt.Errorf(
+				"VRF key hash %s does not match genesis delegate",
+				vrfKeyHash.String(),
+			)
+		}
+	} else {
+		poolStake, ok := epochState.poolStake[string(issuerHash.Bytes())]
+		if !ok || epochState.totalStake == 0 {
+			return fmt.Errorf(
+				"issuer %s is not in the stake distribution",
+				issuerHash.String(),
+			)
+		}
+		poolVrfKeyHash := epochState.vrfKeyHashes[string(issuerHash.Bytes())]
+		if string(poolVrfKeyHash) != string(vrfKeyHash.Bytes()) {
+			return fmt.Errorf(
+				"VRF key hash %s does not match pool registration",
+				vrfKeyHash.String(),
+			)
+		}
+		sigma := new(big.Rat).SetFrac(
+			new(big.Int).SetUint64(poolStake),
+			new(big.Int).SetUint64(epochState.totalStake),
+		)
+		if !isSlotLeader(leaderValue, sigma, shelleyGenesis.ActiveSlotsCoeff.Rat) {
+			return fmt.Errorf(
+				"issuer %s is not the slot leader for slot %d",
+				issuerHash.String(),
+				header.slot,
+			)
+		}
+	}
+	if checkOpCertCounter {
+		err := ls.validateOpCertCounter(
+			txn,
+			header,
+			lcommon.PoolKeyHash(issuerHash),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return ls.validateOpCert(header, shelleyGenesis)
+}
+
+// validateOpCert validates the operational certificate and KES signature from a block header
+func (ls *LedgerState) validateOpCert(
+	header *praosHeader,
+	shelleyGenesis *shelley.ShelleyGenesis,
+) error {
+	if shelleyGenesis.SlotsPerKESPeriod <= 0 {
+		return errors.New("invalid KES period length in genesis config")
+	}
+	kesPeriod := header.slot / uint64(shelleyGenesis.SlotsPerKESPeriod)
+	opCertKesPeriod := uint64(header.opCertKesPeriod)
+	if kesPeriod < opCertKesPeriod {
+		return fmt.Errorf(
+			"KES period %d is before operational certificate start period %d",
+			kesPeriod,
+			opCertKesPeriod,
+		)
+	}
+	if kesPeriod >= opCertKesPeriod+uint64(shelleyGenesis.MaxKESEvolutions) { // #nosec G115
+		return fmt.Errorf(
+			"operational certificate with start period %d has expired in KES period %d",
+			opCertKesPeriod,
+			kesPeriod,
+		)
+	}
+	// The operational certificate is signed by the pool cold key
+	opCertSignable := make([]byte, 0, len(header.opCertHotVkey)+16)
+	opCertSignable = append(opCertSignable, header.opCertHotVkey...)
+	opCertSignable = binary.BigEndian.AppendUint64(
+		opCertSignable,
+		uint64(header.opCertSequenceNumber),
+	)
+	opCertSignable = binary.BigEndian.AppendUint64(
+		opCertSignable,
+		opCertKesPeriod,
+	)
+	if !ed25519.Verify(header.issuerVkey[:], opCertSignable, header.opCertSignature) {
+		return errors.New("invalid operational certificate signature")
+	}
+	// The header body is signed by the KES key for the current period, relative to the start of the operational
+	// certificate
+	if len(header.signature) != kesSignatureSize {
+		return fmt.Errorf(
+			"KES signature has unexpected length: %d",
+			len(header.signature),
+		)
+	}
+	if len(header.opCertHotVkey) != ed25519.PublicKeySize {
+		return fmt.Errorf(
+			"KES verification key has unexpected length: %d",
+			len(header.opCertHotVkey),
+		)
+	}
+	kesSig := ledger.NewSumKesFromByte(kesDepth, header.signature)
+	if !kesSig.Verify(kesPeriod-opCertKesPeriod, header.opCertHotVkey, header.bodyCbor) {
+		return errors.New("invalid KES signature")
+	}
+	return nil
+}
+
+// validateOpCertCounter checks the operational certificate issue number from a block header against the last one
+// that we've seen for the pool on our chain. We don't have a counter for pools whose blocks were applied before we
+// started tracking them, so we accept any issue number in that case
+func (ls *LedgerState) validateOpCertCounter(
+	txn *database.Txn,
+	header *praosHeader,
+	issuerKeyHash lcommon.PoolKeyHash,
+) error {
+	lastCounter, err := txn.DB().Metadata().GetOpCertCounter(
+		issuerKeyHash,
+		txn.Metadata(),
+	)
+	if err != nil {
+		return err
+	}
+	if lastCounter.ID == 0 {
+		return nil
+	}
+	sequenceNumber := uint64(header.opCertSequenceNumber)
+	if sequenceNumber < lastCounter.Counter {
+		return fmt.Errorf(
+			"operational certificate issue number %d is lower than last seen (%d)",
+			sequenceNumber,
+			lastCounter.Counter,
+		)
+	}
+	// Praos only allows the issue number to increase by one at a time
+	if !header.tpraos && sequenceNumber > lastCounter.Counter+1 {
+		return fmt.Errorf(
+			"operational certificate issue number %d is too far beyond last seen (%d)",
+			sequenceNumber,
+			lastCounter.Counter,
+		)
+	}
+	return nil
+}
+
+// recordOpCertCounter saves the operational certificate issue number from a block header when it's changed
+// from the last one that we've seen for the pool
+func (ls *LedgerState) recordOpCertCounter(
+	txn *database.Txn,
+	slot uint64,
+	block ledger.Block,
+) error {
+	header, err := newPraosHeader(block.Header())
+	if err != nil {
+		return err
+	}
+	issuerKeyHash := lcommon.PoolKeyHash(header.issuerVkey.Hash())
+	lastCounter, err := txn.DB().Metadata().GetOpCertCounter(
+		issuerKeyHash,
+		txn.Metadata(),
+	)
+	if err != nil {
+		return err
+	}
+	sequenceNumber := uint64(header.opCertSequenceNumber)
+	if lastCounter.ID > 0 && lastCounter.Counter == sequenceNumber {
+		return nil
+	}
+	return txn.DB().Metadata().SetOpCertCounter(
+		issuerKeyHash,
+		sequenceNumber,
+		slot,
+		txn.Metadata(),
+	)
+}
+
+// genesisDelegate represents the delegate for a genesis key
+type genesisDelegate struct {
+	delegate string
+	vrf      string
+}
+
+// overlaySlotDelegate returns the genesis delegate assigned to a slot by the overlay schedule, which reserves a
+// portion of slots for the genesis delegates while the decentralization parameter is non-zero. It returns nil for
+// slots that aren't in the overlay schedule
+func (ls *LedgerState) overlaySlotDelegate(
+	txn *database.Txn,
+	header *praosHeader,
+	epochState *praosEpochState,
+	shelleyGenesis *shelley.ShelleyGenesis,
+) (*genesisDelegate, error) {
+	if !header.tpraos || ls.currentEra.RewardParamsFunc == nil {
+		return nil, nil
+	}
+	// The decentralization parameter comes from the protocol parameters, which we only know for the current epoch
+	if epochState.epoch != ls.currentEpoch.EpochId {
+		return nil, fmt.Errorf(
+			"%w: protocol parameters for epoch %d are not known yet",
+			errPraosEpochStateUnknown,
+			epochState.epoch,
+		)
+	}
+	rewardParams, err := ls.currentEra.RewardParamsFunc(ls.currentPParams)
+	if err != nil {
+		return nil, err
+	}
+	d := rewardParams.Decentralization
+	if d == nil || d.Sign() == 0 {
+		return nil, nil
+	}
+	slotInEpoch := header.slot - epochState.startSlot
+	position := ceilRat(new(big.Rat).Mul(ratFromUint64(slotInEpoch), d))
+	nextPosition := ceilRat(new(big.Rat).Mul(ratFromUint64(slotInEpoch+1), d))
+	if position >= nextPosition {
+		return nil, nil
+	}
+	// Only some of the overlay slots are active, based on the active slot coefficient
+	ascInv := floorRat(
+		new(big.Rat).Inv(shelleyGenesis.ActiveSlotsCoeff.Rat),
+	)
+	if ascInv == 0 || position%ascInv != 0 {
+		return nil, fmt.Errorf(
+			"no block is expected in non-active overlay slot %d",
+			header.slot,
+		)
+	}
+	// Active overlay slots are assigned to the genesis keys in order
+	genesisKeys := make([]string, 0, len(shelleyGenesis.GenDelegs))
+	for genesisKey := range shelleyGenesis.GenDelegs {
+		genesisKeys = append(genesisKeys, genesisKey)
+	}
+	if len(genesisKeys) == 0 {
+		return nil, errors.New("no genesis delegates in genesis config")
+	}
+	slices.Sort(genesisKeys)
+	genesisKey := genesisKeys[(position/ascInv)%uint64(len(genesisKeys))]
+	// The delegate from the latest genesis key delegation certificate that's active as of this slot replaces the
+	// one from the genesis config
+	genesisKeyDelegs, err := txn.DB().Metadata().GetGenesisKeyDelegations(
+		header.slot,
+		txn.Metadata(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, genesisKeyDeleg := range genesisKeyDelegs {
+		if hex.EncodeToString(genesisKeyDeleg.GenesisHash) == genesisKey {
+			return &genesisDelegate{
+				delegate: hex.EncodeToString(genesisKeyDeleg.GenesisDelegateHash),
+				vrf:      hex.EncodeToString(genesisKeyDeleg.VrfKeyHash),
+			}, nil
+		}
+	}
+	genesisDelegation := shelleyGenesis.GenDelegs[genesisKey]
+	return &genesisDelegate{
+		delegate: genesisDelegation["delegate"],
+		vrf:      genesisDelegation["vrf"],
+	}, nil
+}
+
+// verifyVrf verifies a VRF proof against the specified input and checks that it produces the expected output
+func verifyVrf(vrfKey []byte, vrfResult lcommon.VrfResult, input []byte) error {
+	output, err := ledger.VrfVerifyAndHash(vrfKey, vrfResult.Proof, input)
+	if err != nil {
+		return err
+	}
+	if string(output) != string(vrfResult.Output) {
+		return fmt.Errorf(
+			"VRF output %s does not match proof",
+			hex.EncodeToString(vrfResult.Output),
+		)
+	}
+	return nil
+}
+
+// tpraosSeed returns the seed for the specified VRF use in TPraos, which is the hash of the number
+func tpraosSeed(num uint64) lcommon.Blake2b256 {
+	return lcommon.Blake2b256Hash(binary.BigEndian.AppendUint64(nil, num))
+}
+
+// tpraosVrfInput returns the VRF input for the specified slot and epoch nonce, mixed with the seed for a particular
+// VRF use
+func tpraosVrfInput(
+	seed lcommon.Blake2b256,
+	slot uint64,
+	epochNonce []byte,
+) []byte {
+	ret := ledger.MkInputVrf(int64(slot), epochNonce) // #nosec G115
+	for idx := range ret {
+		ret[idx] ^= seed[idx]
+	}
+	return ret
+}
+
+// PraosLeaderValue returns the leader value derived from a VRF output in Babbage and later eras (Praos)
+func PraosLeaderValue(vrfOutput []byte) []byte {
+	tmpHash := lcommon.Blake2b256Hash(
+		append([]byte("L"), vrfOutput...),
+	)
+	return tmpHash.Bytes()
+}
+
+// isSlotLeader determines whether a VRF leader value qualifies a pool with the specified relative stake to produce
+// a block. The leader value is interpreted as a fraction p of its maximum value, and the pool is eligible when
+//
+//	1 / (1 - p) < exp(-sigma * ln(1 - f))
+//
+// where f is the active slot coefficient
+func isSlotLeader(
+	leaderValue []byte,
+	sigma *big.Rat,
+	activeSlotCoeff *big.Rat,
+) bool {
+	if activeSlotCoeff.Cmp(big.NewRat(1, 1)) >= 0 {
+		return true
+	}
+	certNatMax := new(big.Int).Lsh(big.NewInt(1), uint(len(leaderValue)*8))
+	certNat := new(big.Int).SetBytes(leaderValue)
+	recipQ := new(big.Float).SetPrec(leaderCheckPrecision).Quo(
+		new(big.Float).SetPrec(leaderCheckPrecision).SetInt(certNatMax),
+		new(big.Float).SetPrec(leaderCheckPrecision).SetInt(
+			new(big.Int).Sub(certNatMax, certNat),
+		),
+	)
+	// x = -sigma * ln(1 - f)
+	x := new(big.Float).SetPrec(leaderCheckPrecision).Mul(
+		new(big.Float).SetPrec(leaderCheckPrecision).SetRat(sigma),
+		negLog1m(activeSlotCoeff),
+	)
+	return recipQ.Cmp(expFloat(x)) < 0
+}
+
+// negLog1m returns -ln(1 - f) for 0 <= f < 1, using the series f + f^2/2 + f^3/3 + ...
+func negLog1m(f *big.Rat) *big.Float {
+	fFloat := new(big.Float).SetPrec(leaderCheckPrecision).SetRat(f)
+	epsilon := new(big.Float).SetMantExp(big.NewFloat(1), -leaderCheckPrecision)
+	ret := new(big.Float).SetPrec(leaderCheckPrecision)
+	power := new(big.Float).SetPrec(leaderCheckPrecision).Set(fFloat)
+	for n := int64(1); n < 100_000; n++ {
+		term := new(big.Float).SetPrec(leaderCheckPrecision).Quo(
+			power,
+			new(big.Float).SetInt64(n),
+		)
+		ret.Add(ret, term)
+		if term.Cmp(epsilon) < 0 {
+			break
+		}
+		power.Mul(power, fFloat)
+	}
+	return ret
+}
+
+// expFloat returns e^x for x >= 0, using the Taylor series 1 + x + x^2/2! + x^3/3! + ...
+func expFloat(x *big.Float) *big.Float {
+	epsilon := new(big.Float).SetMantExp(big.NewFloat(1), -leaderCheckPrecision)
+	ret := new(big.Float).SetPrec(leaderCheckPrecision).SetInt64(1)
+	term := new(big.Float).SetPrec(leaderCheckPrecision).SetInt64(1)
+	for n := int64(1); n < 100_000; n++ {
+		term.Mul(term, x)
+		term.Quo(term, new(big.Float).SetInt64(n))
+		ret.Add(ret, term)
+		if term.Cmp(epsilon) < 0 {
+			break
+		}
+	}
+	return ret
+}
+
+// ceilRat returns the value rounded up to the nearest integer, or 0 for negative values
+func ceilRat(val *big.Rat) uint64 {
+	if val.Sign() <= 0 {
+		return 0
+	}
+	ret := new(big.Int).Quo(val.Num(), val.Denom())
+	if new(big.Int).Mul(ret, val.Denom()).Cmp(val.Num()) != 0 {
+		ret.Add(ret, big.NewInt(1))
+	}
+	return ret.Uint64()
+}
@@ -0,0 +1,328 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/state/eras"
+	ouroboros "github.com/blinklabs-io/gouroboros"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+// leaderValueFromFraction returns a 32-byte leader value representing the specified fraction of the maximum value
+func leaderValueFromFraction(frac *big.Rat) []byte {
+	certNatMax := new(big.Int).Lsh(big.NewInt(1), 256)
+	certNat := new(big.Int).Quo(
+		new(big.Int).Mul(certNatMax, frac.Num()),
+		frac.Denom(),
+	)
+	ret := make([]byte, 32)
+	return certNat.FillBytes(ret)
+}
+
+func TestIsSlotLeader(t *testing.T) {
+	activeSlotCoeff := big.NewRat(1, 20)
+	testDefs := []struct {
+		leaderValue []byte
+		sigma       *big.Rat
+		expected    bool
+	}{
+		// A pool with all of the stake is elected in a fraction f of slots
+		{
+			leaderValue: leaderValueFromFraction(big.NewRat(49, 1000)),
+			sigma:       big.NewRat(1, 1),
+			expected:    true,
+		},
+		{
+			leaderValue: leaderValueFromFraction(big.NewRat(51, 1000)),
+			sigma:       big.NewRat(1, 1),
+			expected:    false,
+		},
+		// A pool with 10% of the stake is elected in a fraction 1 - (1 - f)^0.1 (~0.51%) of slots
+		{
+			leaderValue: leaderValueFromFraction(big.NewRat(50, 10000)),
+			sigma:       big.NewRat(1, 10),
+			expected:    true,
+		},
+		{
+			leaderValue: leaderValueFromFraction(big.NewRat(52, 10000)),
+			sigma:       big.NewRat(1, 10),
+			expected:    false,
+		},
+		// A pool with no stake is never elected
+		{
+			leaderValue: leaderValueFromFraction(big.NewRat(0, 1)),
+			sigma:       big.NewRat(0, 1),
+			expected:    false,
+		},
+	}
+	for _, testDef := range testDefs {
+		ret := isSlotLeader(testDef.leaderValue, testDef.sigma, activeSlotCoeff)
+		if ret != testDef.expected {
+			t.Fatalf(
+				"did not get expected result for leader value %x with sigma %s: got %v, expected %v",
+				testDef.leaderValue,
+				testDef.sigma.String(),
+				ret,
+				testDef.expected,
+			)
+		}
+	}
+}
+
+func TestCeilRat(t *testing.T) {
+	testDefs := []struct {
+		val      *big.Rat
+		expected uint64
+	}{
+		{val: big.NewRat(0, 1), expected: 0},
+		{val: big.NewRat(3, 1), expected: 3},
+		{val: big.NewRat(7, 2), expected: 4},
+		{val: big.NewRat(1, 100), expected: 1},
+	}
+	for _, testDef := range testDefs {
+		if ret := ceilRat(testDef.val); ret != testDef.expected {
+			t.Fatalf(
+				"did not get expected result for %s: got %d, expected %d",
+				testDef.val.String(),
+				ret,
+				testDef.expected,
+			)
+		}
+	}
+}
+
+func TestEpochLeaderParamsIsSlotLeader(t *testing.T) {
+	leaderValue := leaderValueFromFraction(big.NewRat(1, 1000))
+	testDefs := []struct {
+		params   EpochLeaderParams
+		expected bool
+	}{
+		// A pool is treated as holding all of the stake during the bootstrap epochs
+		{
+			params:   EpochLeaderParams{Bootstrap: true},
+			expected: true,
+		},
+		// Without a stake distribution, a pool is never elected outside of the bootstrap epochs
+		{
+			params:   EpochLeaderParams{},
+			expected: false,
+		},
+		{
+			params:   EpochLeaderParams{PoolStake: 1, TotalStake: 2},
+			expected: true,
+		},
+		{
+			params:   EpochLeaderParams{PoolStake: 0, TotalStake: 2},
+			expected: false,
+		},
+	}
+	for _, testDef := range testDefs {
+		testDef.params.ActiveSlotCoeff = big.NewRat(1, 20)
+		if ret := testDef.params.IsSlotLeader(leaderValue); ret != testDef.expected {
+			t.Fatalf(
+				"did not get expected result for params %+v: got %v, expected %v",
+				testDef.params,
+				ret,
+				testDef.expected,
+			)
+		}
+	}
+}
+
+func TestEpochLeaderParamsBootstrap(t *testing.T) {
+	ls := newTestLedgerState(t)
+	poolKeyHash := lcommon.PoolKeyHash(lcommon.NewBlake2b224(testKeyHash(1)))
+	testDefs := []struct {
+		epoch     uint64
+		bootstrap bool
+	}{
+		{epoch: 1, bootstrap: true},
+		{epoch: 2, bootstrap: false},
+	}
+	for _, testDef := range testDefs {
+		testLedgerTxn(t, ls, func(txn *database.Txn) error {
+			testAddEpoch(t, ls, txn, testDef.epoch)
+			// The epoch record ID is used to detect a chain without blocks
+			ls.currentEpoch.ID = uint(testDef.epoch)
+			return nil
+		})
+		txn := ls.db.Transaction(false)
+		params, err := ls.epochLeaderParams(txn, testDef.epoch*testEpochLength, poolKeyHash)
+		_ = txn.Rollback()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if params.Bootstrap != testDef.bootstrap {
+			t.Fatalf(
+				"did not get expected bootstrap flag for epoch %d: got %v, expected %v",
+				testDef.epoch,
+				params.Bootstrap,
+				testDef.bootstrap,
+			)
+		}
+	}
+}
+
+func TestPraosEpochStateSnapshotPoolParams(t *testing.T) {
+	ls := newTestLedgerState(t)
+	poolKeyHash := lcommon.PoolKeyHash(lcommon.NewBlake2b224(testKeyHash(1)))
+	origVrfKeyHash := bytes.Repeat([]byte{1}, 32)
+	newVrfKeyHash := bytes.Repeat([]byte{0xff}, 32)
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		testAddEpoch(t, ls, txn, 0)
+		testRegisterPool(t, txn, 1, 10, 5, testPoolDeposit)
+		testAddEpoch(t, ls, txn, 1)
+		// Re-register the pool with a new VRF key after the stake snapshot for epoch 2 was taken
+		err := txn.DB().Metadata().SetPoolRegistration(
+			&lcommon.PoolRegistrationCertificate{
+				CertType:      lcommon.CertificateTypePoolRegistration,
+				Operator:      poolKeyHash,
+				VrfKeyHash:    lcommon.VrfKeyHash(lcommon.NewBlake2b256(newVrfKeyHash)),
+				Pledge:        1000,
+				Cost:          340,
+				Margin:        cbor.Rat{Rat: big.NewRat(1, 100)},
+				RewardAccount: lcommon.AddrKeyHash(lcommon.NewBlake2b224(testKeyHash(10))),
+			},
+			testEpochLength+5,
+			0,
+			txn.Metadata(),
+		)
+		if err != nil {
+			return err
+		}
+		testAddEpoch(t, ls, txn, 2)
+		return nil
+	})
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	// The current pool params use the new VRF key
+	pools, err := ls.poolStates(txn, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if vrfKeyHash := pools[string(poolKeyHash[:])].params.VrfKeyHash; !bytes.Equal(vrfKeyHash, newVrfKeyHash) {
+		t.Fatalf("did not get expected current VRF key hash: got %x, expected %x", vrfKeyHash, newVrfKeyHash)
+	}
+	// Headers in epoch 2 are validated against the pool params from the stake snapshot
+	epochState, err := ls.praosEpochState(txn, 2*testEpochLength+10)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if epochState.epoch != 2 {
+		t.Fatalf("did not get expected epoch: got %d, expected %d", epochState.epoch, 2)
+	}
+	if vrfKeyHash := epochState.vrfKeyHashes[string(poolKeyHash[:])]; !bytes.Equal(vrfKeyHash, origVrfKeyHash) {
+		t.Fatalf("did not get expected snapshot VRF key hash: got %x, expected %x", vrfKeyHash, origVrfKeyHash)
+	}
+}
+
+func TestPraosEpochStateUnknown(t *testing.T) {
+	ls := newTestLedgerState(t)
+	ls.currentEra = eras.BabbageEraDesc
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		testAddEpoch(t, ls, txn, 2)
+		return nil
+	})
+	_, epochLength, err := ls.currentEra.EpochLengthFunc(ls.config.CardanoNodeConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testDefs := []uint64{
+		// Before the current epoch
+		2*testEpochLength - 1,
+		// Beyond the next epoch
+		3*testEpochLength + uint64(epochLength),
+	}
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	for _, slot := range testDefs {
+		_, err := ls.praosEpochState(txn, slot)
+		if !errors.Is(err, errPraosEpochStateUnknown) {
+			t.Fatalf("did not get expected error for slot %d: got %v", slot, err)
+		}
+	}
+}
+
+func TestValidateChainsyncHeader(t *testing.T) {
+	ls := newTestLedgerState(t)
+	ls.config.ValidationLevel = ValidationLevelFull
+	firstBlock := testImmutableDbBlockEvents(t, 1)[0]
+	blk := firstBlock.Block
+	var closedConnId *ouroboros.ConnectionId
+	ls.config.ConnectionCloseFunc = func(connId ouroboros.ConnectionId, err error) {
+		closedConnId = &connId
+	}
+	connId := ouroboros.ConnectionId{
+		LocalAddr:  &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 3001},
+		RemoteAddr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 3002},
+	}
+	setEpoch := func(era eras.EraDesc, epoch uint64) {
+		ls.currentEra = era
+		ls.currentEpoch = database.Epoch{
+			EpochId:       epoch,
+			StartSlot:     epoch * testPreviewEpochLength,
+			LengthInSlots: testPreviewEpochLength,
+			// This doesn't match the real epoch nonce, so the VRF proofs in the header don't verify
+			Nonce: make([]byte, 32),
+		}
+	}
+	// Headers aren't checked while we're still in the Byron era
+	setEpoch(eras.ByronEraDesc, testImmutableDbEpoch)
+	if err := ls.validateChainsyncHeader(blk.Header()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Headers from beyond the next epoch are left until their blocks are applied
+	setEpoch(eras.BabbageEraDesc, testImmutableDbEpoch-2)
+	if err := ls.validateChainsyncHeader(blk.Header()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Headers from the current epoch are checked against its nonce
+	setEpoch(eras.BabbageEraDesc, testImmutableDbEpoch)
+	if err := ls.validateChainsyncHeader(blk.Header()); err == nil {
+		t.Fatalf("did not get expected error")
+	}
+	// The peer that sent an invalid header is disconnected and the header isn't added to its candidate chain
+	err := ls.handleEventChainsyncBlockHeader(
+		ChainsyncEvent{
+			ConnectionId: connId,
+			Point:        firstBlock.Point,
+			BlockNumber:  blk.BlockNumber(),
+			BlockHeader:  blk.Header(),
+			Type:         firstBlock.Type,
+		},
+	)
+	var validationErr BlockValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("did not get expected error: got %v", err)
+	}
+	if closedConnId == nil || closedConnId.String() != connId.String() {
+		t.Fatalf("did not get expected connection closed: got %v, expected %s", closedConnId, connId.String())
+	}
+	if cand, ok := ls.chainsyncCandidates[connId]; ok && len(cand.headers) > 0 {
+		t.Fatalf("did not expect header to be added to candidate chain")
+	}
+}
@@ -15,17 +15,37 @@
 package state
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"slices"
 
 	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
 	"github.com/blinklabs-io/dingo/state/eras"
 	"github.com/blinklabs-io/gouroboros/cbor"
 	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
 	olocalstatequery "github.com/blinklabs-io/gouroboros/protocol/localstatequery"
 )
 
+const (
+	// Number of UTxOs to load at a time when answering a whole UTxO query
+	utxoWholeQueryBatchSize = 1000
+
+	// CBOR bytes for the start of an indefinite-length map and the break that ends it
+	cborIndefMapStart = 0xbf
+	cborBreak         = 0xff
+
+	// Header bytes for a reward account address with a key hash or script hash credential on a testnet
+	rewardAccountHeaderKeyHash    = 0xe0
+	rewardAccountHeaderScriptHash = 0xf0
+
+	shelleyGenesisNetworkMainnet = "Mainnet"
+)
+
 func (ls *LedgerState) Query(query any) (any, error) {
 	switch q := query.(type) {
 	case *olocalstatequery.BlockQuery:
@@ -173,25 +193,51 @@ func (ls *LedgerState) queryShelley(
 		return ls.queryShelleyUtxoByAddress(q.Addrs)
 	case *olocalstatequery.ShelleyUtxoByTxinQuery:
 		return ls.queryShelleyUtxoByTxIn(q.TxIns)
-	// TODO (#394)
+	case *olocalstatequery.ShelleyStakeDistributionQuery:
+		return ls.queryShelleyStakeDistribution(nil)
+	case *olocalstatequery.ShelleyPoolDistrQuery:
+		// NOTE: the protocol library rejects this query when it carries a set of pool IDs to
+		// filter on, so any query that gets here is for all pools
+		return ls.queryShelleyStakeDistribution(nil)
+	case *olocalstatequery.ShelleyStakeSnapshotsQuery:
+		// NOTE: the protocol library rejects this query when it carries a set of pool IDs to
+		// filter on, so any query that gets here is for all pools
+		return ls.queryShelleyStakeSnapshots(nil)
+	case *olocalstatequery.ShelleyFilteredDelegationAndRewardAccountsQuery:
+		// TODO: answer this with queryShelleyFilteredDelegationAndRewardAccounts once the protocol library
+		// decodes the set of stake credentials to filter on (#858). Until then, the reward account balances
+		// can't be queried, since we don't want to answer for every registered staking key instead
+		return nil, errors.New(
+			"filtered delegation and reward accounts query without stake credentials is not supported",
+		)
+	case *olocalstatequery.ShelleyUtxoWholeQuery:
+		return ls.queryShelleyUtxoWhole()
+	case *olocalstatequery.ShelleyProposedProtocolParamsUpdatesQuery:
+		return ls.queryShelleyProposedProtocolParamsUpdates()
+	case *olocalstatequery.ShelleyStakePoolsQuery:
+		return ls.queryShelleyStakePools()
+	case *olocalstatequery.ShelleyStakePoolParamsQuery:
+		// NOTE: the protocol library rejects this query when it carries a set of pool IDs to
+		// filter on (#859), so any query that gets here is for all pools
+		return ls.queryShelleyStakePoolParams(nil)
+	case *olocalstatequery.ShelleyPoolStateQuery:
+		// NOTE: the protocol library rejects this query when it carries a set of pool IDs to
+		// filter on, so any query that gets here is for all pools
+		return ls.queryShelleyPoolState(nil)
+	case *olocalstatequery.ShelleyDebugChainDepStateQuery:
+		return ls.queryShelleyDebugChainDepState()
+	// TODO: the Conway governance queries (constitution, gov state, DRep state and stake
+	// distribution, committee members state, SPO stake distribution) are blocked on the
+	// protocol library, which rejects their query types as of v0.114.1. They can be answered
+	// here once it decodes them (#394)
 	/*
 		case *olocalstatequery.ShelleyLedgerTipQuery:
 		case *olocalstatequery.ShelleyNonMyopicMemberRewardsQuery:
-		case *olocalstatequery.ShelleyProposedProtocolParamsUpdatesQuery:
-		case *olocalstatequery.ShelleyStakeDistributionQuery:
-		case *olocalstatequery.ShelleyUtxoWholeQuery:
 		case *olocalstatequery.ShelleyDebugEpochStateQuery:
 		case *olocalstatequery.ShelleyCborQuery:
-		case *olocalstatequery.ShelleyFilteredDelegationAndRewardAccountsQuery:
 		case *olocalstatequery.ShelleyDebugNewEpochStateQuery:
-		case *olocalstatequery.ShelleyDebugChainDepStateQuery:
 		case *olocalstatequery.ShelleyRewardProvenanceQuery:
-		case *olocalstatequery.ShelleyStakePoolsQuery:
-		case *olocalstatequery.ShelleyStakePoolParamsQuery:
 		case *olocalstatequery.ShelleyRewardInfoPoolsQuery:
-		case *olocalstatequery.ShelleyPoolStateQuery:
-		case *olocalstatequery.ShelleyStakeSnapshotsQuery:
-		case *olocalstatequery.ShelleyPoolDistrQuery:
 	*/
 	default:
 		return nil, fmt.Errorf("unsupported query type: %T", q)
@@ -203,6 +249,394 @@ func (ls *LedgerState) queryShelleyGenesisConfig() (any, error) {
 	return []any{shelleyGenesis}, nil
 }
 
+// poolIdFilter returns a lookup for the specified pool IDs, or nil to match all pools when no filter was specified
+func poolIdFilter(poolIds []ledger.PoolId) map[ledger.PoolId]bool {
+	if poolIds == nil {
+		return nil
+	}
+	ret := make(map[ledger.PoolId]bool, len(poolIds))
+	for _, poolId := range poolIds {
+		ret[poolId] = true
+	}
+	return ret
+}
+
+func (ls *LedgerState) queryShelleyStakeDistribution(
+	poolIds []ledger.PoolId,
+) (any, error) {
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	poolStakes, err := ls.poolDistribution(txn)
+	if err != nil {
+		return nil, err
+	}
+	var totalStake uint64
+	for _, poolStake := range poolStakes {
+		totalStake += poolStake.Stake
+	}
+	ret := olocalstatequery.StakeDistributionResult{}
+	ret.Results = make(
+		map[ledger.PoolId]struct {
+			cbor.StructAsArray
+			StakeFraction *cbor.Rat
+			VrfHash       ledger.Blake2b256
+		},
+	)
+	filter := poolIdFilter(poolIds)
+	for _, poolStake := range poolStakes {
+		poolKeyHash := lcommon.PoolKeyHash(
+			lcommon.NewBlake2b224(poolStake.PoolKeyHash),
+		)
+		if filter != nil && !filter[ledger.PoolId(poolKeyHash)] {
+			continue
+		}
+		poolRegs, err := txn.DB().GetPoolRegistrations(poolKeyHash, txn)
+		if err != nil {
+			return nil, err
+		}
+		if len(poolRegs) == 0 {
+			return nil, fmt.Errorf(
+				"no registration found for pool %x",
+				poolStake.PoolKeyHash,
+			)
+		}
+		tmpResult := ret.Results[ledger.PoolId(poolKeyHash)]
+		tmpResult.StakeFraction = &cbor.Rat{
+			Rat: big.NewRat(0, 1),
+		}
+		if totalStake > 0 {
+			tmpResult.StakeFraction.SetFrac(
+				new(big.Int).SetUint64(poolStake.Stake),
+				new(big.Int).SetUint64(totalStake),
+			)
+		}
+		// The most recent registration is returned first
+		tmpResult.VrfHash = ledger.Blake2b256(poolRegs[0].VrfKeyHash)
+		ret.Results[ledger.PoolId(poolKeyHash)] = tmpResult
+	}
+	return ret, nil
+}
+
+// stakeSnapshotsResult represents the result of a stake snapshots query
+type stakeSnapshotsResult struct {
+	cbor.StructAsArray
+	Pools     map[ledger.PoolId]stakeSnapshotsResultPool
+	MarkTotal uint64
+	SetTotal  uint64
+	GoTotal   uint64
+}
+
+type stakeSnapshotsResultPool struct {
+	cbor.StructAsArray
+	Mark uint64
+	Set  uint64
+	Go   uint64
+}
+
+// queryShelleyStakeSnapshots returns the mark, set and go snapshots for the specified pools. The totals always cover
+// the stake of all pools
+func (ls *LedgerState) queryShelleyStakeSnapshots(
+	poolIds []ledger.PoolId,
+) (any, error) {
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	ret := stakeSnapshotsResult{
+		Pools: make(map[ledger.PoolId]stakeSnapshotsResultPool),
+	}
+	filter := poolIdFilter(poolIds)
+	for _, snapshot := range []stakeSnapshot{stakeSnapshotMark, stakeSnapshotSet, stakeSnapshotGo} {
+		poolStakes, err := ls.poolStakeSnapshot(txn, snapshot)
+		if err != nil {
+			return nil, err
+		}
+		for _, poolStake := range poolStakes {
+			poolId := ledger.PoolId(poolStake.PoolKeyHash)
+			tmpPool := ret.Pools[poolId]
+			switch snapshot {
+			case stakeSnapshotMark:
+				tmpPool.Mark = poolStake.Stake
+				ret.MarkTotal += poolStake.Stake
+			case stakeSnapshotSet:
+				tmpPool.Set = poolStake.Stake
+				ret.SetTotal += poolStake.Stake
+			case stakeSnapshotGo:
+				tmpPool.Go = poolStake.Stake
+				ret.GoTotal += poolStake.Stake
+			}
+			if filter != nil && !filter[poolId] {
+				continue
+			}
+			ret.Pools[poolId] = tmpPool
+		}
+	}
+	return []any{ret}, nil
+}
+
+// filteredDelegationAndRewardAccountsResult represents the result of a filtered delegation and reward accounts query
+type filteredDelegationAndRewardAccountsResult struct {
+	cbor.StructAsArray
+	Delegations    map[stakeCredentialKey]ledger.PoolId
+	RewardAccounts map[stakeCredentialKey]uint64
+}
+
+// stakeCredentialKey is a comparable representation of a stake credential for use as a map key
+type stakeCredentialKey struct {
+	cbor.StructAsArray
+	CredType   uint
+	Credential ledger.Blake2b224
+}
+
+// queryShelleyFilteredDelegationAndRewardAccounts returns the delegation and reward account balance for each of the
+// specified stake credentials that is currently registered
+func (ls *LedgerState) queryShelleyFilteredDelegationAndRewardAccounts(
+	stakeCredentials []lcommon.StakeCredential,
+) (any, error) {
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	registeredStakingKeys, err := ls.registeredStakingKeys(txn)
+	if err != nil {
+		return nil, err
+	}
+	stakingKeys := make([][]byte, 0, len(stakeCredentials))
+	for _, stakeCred := range stakeCredentials {
+		stakingKeys = append(stakingKeys, stakeCred.Credential)
+	}
+	credTypes, err := ls.stakeCredentialTypes(txn, stakingKeys)
+	if err != nil {
+		return nil, err
+	}
+	delegations, err := ls.stakeDelegations(txn, ls.currentEpoch.EpochId)
+	if err != nil {
+		return nil, err
+	}
+	rewardBalances, err := ls.rewardBalances(txn, stakingKeys)
+	if err != nil {
+		return nil, err
+	}
+	ret := filteredDelegationAndRewardAccountsResult{
+		Delegations:    make(map[stakeCredentialKey]ledger.PoolId),
+		RewardAccounts: make(map[stakeCredentialKey]uint64),
+	}
+	for _, stakeCred := range stakeCredentials {
+		stakingKey := string(stakeCred.Credential)
+		// Only registered staking keys have a reward account, and a key hash credential doesn't
+		// match a script hash registration with the same hash or vice versa
+		if !registeredStakingKeys[stakingKey] ||
+			credTypes[stakingKey] != stakeCred.CredType {
+			continue
+		}
+		credKey := stakeCredentialKey{
+			CredType:   stakeCred.CredType,
+			Credential: ledger.NewBlake2b224(stakeCred.Credential),
+		}
+		ret.RewardAccounts[credKey] = rewardBalances[stakingKey]
+		if poolKeyHash, ok := delegations[stakingKey]; ok {
+			ret.Delegations[credKey] = ledger.PoolId(poolKeyHash)
+		}
+	}
+	return []any{ret}, nil
+}
+
+func (ls *LedgerState) queryShelleyProposedProtocolParamsUpdates() (any, error) {
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	// Proposals for the current epoch are voted on at the end of the epoch. The proposals made so far in
+	// the epoch are all returned, since they can't be made after the cutoff on a valid chain
+	proposals, err := ls.pparamUpdateProposals(
+		txn,
+		ls.currentEpoch.EpochId,
+		math.MaxUint64,
+	)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[ledger.Blake2b224]cbor.RawMessage, len(proposals))
+	for genesisHash, proposal := range proposals {
+		ret[ledger.NewBlake2b224([]byte(genesisHash))] = cbor.RawMessage(proposal.Cbor)
+	}
+	return []any{ret}, nil
+}
+
+// poolParamsResult represents the registered parameters of a stake pool
+type poolParamsResult struct {
+	cbor.StructAsArray
+	Operator      ledger.Blake2b224
+	VrfKeyHash    ledger.Blake2b256
+	Pledge        uint64
+	Cost          uint64
+	Margin        *cbor.Rat
+	RewardAccount []byte
+	Owners        []ledger.Blake2b224
+	Relays        []any
+	Metadata      *poolMetadataResult
+}
+
+type poolMetadataResult struct {
+	cbor.StructAsArray
+	Url  string
+	Hash []byte
+}
+
+// poolStateResult represents the result of a pool state query
+type poolStateResult struct {
+	cbor.StructAsArray
+	Params       map[ledger.PoolId]poolParamsResult
+	FutureParams map[ledger.PoolId]poolParamsResult
+	Retiring     map[ledger.PoolId]uint64
+	Deposits     map[ledger.PoolId]uint64
+}
+
+func (ls *LedgerState) queryShelleyStakePools() (any, error) {
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	pools, err := ls.poolStates(txn, ls.currentEpoch.EpochId)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]ledger.PoolId, 0, len(pools))
+	for poolKeyHash := range pools {
+		ret = append(ret, ledger.PoolId([]byte(poolKeyHash)))
+	}
+	slices.SortFunc(
+		ret,
+		func(a, b ledger.PoolId) int {
+			return bytes.Compare(a[:], b[:])
+		},
+	)
+	return []any{ret}, nil
+}
+
+func (ls *LedgerState) queryShelleyStakePoolParams(
+	poolIds []ledger.PoolId,
+) (any, error) {
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	pools, err := ls.poolStates(txn, ls.currentEpoch.EpochId)
+	if err != nil {
+		return nil, err
+	}
+	credTypes, err := ls.stakeCredentialTypes(txn, nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[ledger.PoolId]poolParamsResult, len(pools))
+	filter := poolIdFilter(poolIds)
+	for poolKeyHash, pool := range pools {
+		poolId := ledger.PoolId([]byte(poolKeyHash))
+		if filter != nil && !filter[poolId] {
+			continue
+		}
+		ret[poolId] = ls.poolParamsResult(pool.params, credTypes)
+	}
+	return []any{ret}, nil
+}
+
+func (ls *LedgerState) queryShelleyPoolState(
+	poolIds []ledger.PoolId,
+) (any, error) {
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	pools, err := ls.poolStates(txn, ls.currentEpoch.EpochId)
+	if err != nil {
+		return nil, err
+	}
+	credTypes, err := ls.stakeCredentialTypes(txn, nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := poolStateResult{
+		Params:       make(map[ledger.PoolId]poolParamsResult, len(pools)),
+		FutureParams: make(map[ledger.PoolId]poolParamsResult),
+		Retiring:     make(map[ledger.PoolId]uint64),
+		Deposits:     make(map[ledger.PoolId]uint64, len(pools)),
+	}
+	filter := poolIdFilter(poolIds)
+	for poolKeyHash, pool := range pools {
+		poolId := ledger.PoolId([]byte(poolKeyHash))
+		if filter != nil && !filter[poolId] {
+			continue
+		}
+		ret.Params[poolId] = ls.poolParamsResult(pool.params, credTypes)
+		if pool.futureParams != nil {
+			ret.FutureParams[poolId] = ls.poolParamsResult(*pool.futureParams, credTypes)
+		}
+		if pool.retiringEpoch > 0 {
+			ret.Retiring[poolId] = pool.retiringEpoch
+		}
+		ret.Deposits[poolId] = pool.deposit
+	}
+	return []any{ret}, nil
+}
+
+// poolParamsResult builds the query result representation of the stored pool registration. The credential types
+// of registered staking keys are used to build the reward account address
+func (ls *LedgerState) poolParamsResult(
+	poolReg models.PoolRegistration,
+	credTypes map[string]uint,
+) poolParamsResult {
+	ret := poolParamsResult{
+		Operator:   ledger.NewBlake2b224(poolReg.PoolKeyHash),
+		VrfKeyHash: ledger.NewBlake2b256(poolReg.VrfKeyHash),
+		Pledge:     uint64(poolReg.Pledge),
+		Cost:       uint64(poolReg.Cost),
+		Margin:     &cbor.Rat{Rat: big.NewRat(0, 1)},
+		RewardAccount: ls.rewardAccountAddress(
+			poolReg.RewardAccount,
+			credTypes[string(poolReg.RewardAccount)],
+		),
+		Owners: make([]ledger.Blake2b224, 0, len(poolReg.Owners)),
+		Relays: make([]any, 0, len(poolReg.Relays)),
+	}
+	if poolReg.Margin != nil && poolReg.Margin.Rat != nil {
+		ret.Margin.Set(poolReg.Margin.Rat)
+	}
+	for _, owner := range poolReg.Owners {
+		ret.Owners = append(ret.Owners, ledger.NewBlake2b224(owner.KeyHash))
+	}
+	for _, relay := range poolReg.Relays {
+		var port any
+		if relay.Port > 0 {
+			port = relay.Port
+		}
+		switch relay.Type {
+		case lcommon.PoolRelayTypeSingleHostAddress:
+			var ipv4, ipv6 any
+			if relay.Ipv4 != nil {
+				ipv4 = []byte(relay.Ipv4.To4())
+			}
+			if relay.Ipv6 != nil {
+				ipv6 = []byte(relay.Ipv6.To16())
+			}
+			ret.Relays = append(ret.Relays, []any{relay.Type, port, ipv4, ipv6})
+		case lcommon.PoolRelayTypeSingleHostName:
+			ret.Relays = append(ret.Relays, []any{relay.Type, port, relay.Hostname})
+		case lcommon.PoolRelayTypeMultiHostName:
+			ret.Relays = append(ret.Relays, []any{relay.Type, relay.Hostname})
+		}
+	}
+	if poolReg.MetadataUrl != "" {
+		ret.Metadata = &poolMetadataResult{
+			Url:  poolReg.MetadataUrl,
+			Hash: poolReg.MetadataHash,
+		}
+	}
+	return ret
+}
+
 func (ls *LedgerState) queryShelleyUtxoByAddress(
 	addrs []ledger.Address,
 ) (any, error) {
@@ -250,3 +684,167 @@ func (ls *LedgerState) queryShelleyUtxoByTxIn(
 	ret[utxoId] = txOut
 	return []any{ret}, nil
 }
+
+// queryShelleyUtxoWhole returns the full UTxO set. The result is encoded incrementally as an indefinite-length map
+// while paging through the UTxO set, using the stored UTxO CBOR rather than decoding each UTxO. The local state
+// query protocol sends the result as a single message, which the protocol layer splits into bounded segments, so
+// the encoded result is still held in memory until it's sent
+func (ls *LedgerState) queryShelleyUtxoWhole() (any, error) {
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	var ret bytes.Buffer
+	ret.WriteByte(cborIndefMapStart)
+	var lastId uint
+	for {
+		utxos, err := ls.db.UtxosBatch(lastId, utxoWholeQueryBatchSize, txn)
+		if err != nil {
+			return nil, err
+		}
+		if len(utxos) == 0 {
+			break
+		}
+		for _, utxo := range utxos {
+			if len(utxo.Cbor) == 0 {
+				return nil, fmt.Errorf(
+					"missing CBOR for UTxO %x#%d",
+					utxo.TxId,
+					utxo.OutputIdx,
+				)
+			}
+			utxoIdCbor, err := cbor.Encode(
+				olocalstatequery.UtxoId{
+					Hash: ledger.NewBlake2b256(utxo.TxId),
+					Idx:  int(utxo.OutputIdx),
+				},
+			)
+			if err != nil {
+				return nil, err
+			}
+			ret.Write(utxoIdCbor)
+			ret.Write(utxo.Cbor)
+			lastId = utxo.ID
+		}
+	}
+	ret.WriteByte(cborBreak)
+	return []any{cbor.RawMessage(ret.Bytes())}, nil
+}
+
+// rewardAccountAddress returns the reward account address for the specified staking key and credential type. An
+// unregistered reward account has no stored credential type, so it's treated as a key hash credential
+func (ls *LedgerState) rewardAccountAddress(
+	stakingKey []byte,
+	credType uint,
+) []byte {
+	header := byte(rewardAccountHeaderKeyHash)
+	if credType == lcommon.StakeCredentialTypeScriptHash {
+		header = rewardAccountHeaderScriptHash
+	}
+	if shelleyGenesis := ls.config.CardanoNodeConfig.ShelleyGenesis(); shelleyGenesis != nil &&
+		shelleyGenesis.NetworkId == shelleyGenesisNetworkMainnet {
+		header |= lcommon.AddressNetworkMainnet
+	}
+	return append([]byte{header}, stakingKey...)
+}
+
+// queryShelleyDebugChainDepState returns the consensus protocol state as of our current tip. This uses the Praos
+// state encoding as of Babbage and the TPraos state encoding for earlier eras
+func (ls *LedgerState) queryShelleyDebugChainDepState() (any, error) {
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	opCertCounters, err := txn.DB().Metadata().GetOpCertCounters(txn.Metadata())
+	if err != nil {
+		return nil, err
+	}
+	ocertCounters := make(map[lcommon.Blake2b224]uint64, len(opCertCounters))
+	for _, opCertCounter := range opCertCounters {
+		ocertCounters[lcommon.NewBlake2b224(opCertCounter.PoolKeyHash)] = opCertCounter.Counter
+	}
+	// The last slot uses the WithOrigin encoding
+	lastSlot := []any{0}
+	var labNonce []byte
+	if len(ls.currentTip.Point.Hash) > 0 {
+		lastSlot = []any{1, ls.currentTip.Point.Slot}
+		tipBlock, err := database.BlockByPointTxn(txn, ls.currentTip.Point)
+		if err != nil {
+			return nil, err
+		}
+		labNonce = tipBlock.PrevHash
+	}
+	// The candidate nonce stops tracking the evolving nonce once we're within the stability window of the
+	// next epoch
+	evolvingNonce := ls.currentTipBlockNonce
+	candidateNonce := evolvingNonce
+	stabilityWindow, err := ls.nonceStabilityWindow()
+	if err != nil {
+		return nil, err
+	}
+	nextEpochStartSlot := ls.currentEpoch.StartSlot + uint64(ls.currentEpoch.LengthInSlots)
+	if nextEpochStartSlot > stabilityWindow &&
+		ls.currentTip.Point.Slot >= nextEpochStartSlot-stabilityWindow {
+		candidateBlock, err := database.BlockBeforeSlotTxn(
+			txn,
+			nextEpochStartSlot-stabilityWindow,
+		)
+		if err != nil {
+			if !errors.Is(err, database.ErrBlockNotFound) {
+				return nil, err
+			}
+		} else {
+			candidateNonce = candidateBlock.Nonce
+		}
+	}
+	var lastEpochBlockNonce []byte
+	blockLastPrevEpoch, err := database.BlockBeforeSlotTxn(
+		txn,
+		ls.currentEpoch.StartSlot,
+	)
+	if err != nil {
+		if !errors.Is(err, database.ErrBlockNotFound) {
+			return nil, err
+		}
+	} else {
+		lastEpochBlockNonce = blockLastPrevEpoch.PrevHash
+	}
+	var state any
+	if ls.currentEra.Id < eras.BabbageEraDesc.Id {
+		state = []any{
+			lastSlot,
+			[]any{
+				[]any{
+					ocertCounters,
+					chainDepStateNonce(evolvingNonce),
+					chainDepStateNonce(candidateNonce),
+				},
+				[]any{
+					chainDepStateNonce(ls.currentEpoch.Nonce),
+					chainDepStateNonce(lastEpochBlockNonce),
+				},
+				chainDepStateNonce(labNonce),
+			},
+		}
+	} else {
+		state = []any{
+			lastSlot,
+			ocertCounters,
+			chainDepStateNonce(evolvingNonce),
+			chainDepStateNonce(candidateNonce),
+			chainDepStateNonce(ls.currentEpoch.Nonce),
+			chainDepStateNonce(labNonce),
+			chainDepStateNonce(lastEpochBlockNonce),
+		}
+	}
+	// The state is wrapped with its encoding version
+	return []any{[]any{0, state}}, nil
+}
+
+// chainDepStateNonce returns the encoding for a nonce, with an empty value representing the neutral nonce
+func chainDepStateNonce(nonce []byte) []any {
+	if len(nonce) == 0 {
+		return []any{0}
+	}
+	return []any{1, nonce}
+}
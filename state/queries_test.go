@@ -0,0 +1,108 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	olocalstatequery "github.com/blinklabs-io/gouroboros/protocol/localstatequery"
+)
+
+func TestQueryShelleyUtxoWhole(t *testing.T) {
+	ls := newTestLedgerState(t)
+	// Use enough UTxOs to need more than one batch
+	const utxoCount = utxoWholeQueryBatchSize + 5
+	expected := make(map[olocalstatequery.UtxoId][]byte, utxoCount)
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		for i := range utxoCount + 1 {
+			txId := bytes.Repeat([]byte{byte(i), byte(i >> 8)}, 16)
+			txOutCbor, err := cbor.Encode(
+				[]any{
+					append([]byte{0x61}, testKeyHash(byte(i))...),
+					uint64(1000000 + i),
+				},
+			)
+			if err != nil {
+				return err
+			}
+			utxo := models.Utxo{
+				TxId:      txId,
+				OutputIdx: uint32(i % 3),
+				AddedSlot: 10,
+				Amount:    uint64(1000000 + i),
+				Cbor:      txOutCbor,
+			}
+			// The last UTxO has been spent
+			if i == utxoCount {
+				utxo.DeletedSlot = 20
+			} else {
+				expected[olocalstatequery.UtxoId{
+					Hash: ledger.NewBlake2b256(txId),
+					Idx:  i % 3,
+				}] = txOutCbor
+			}
+			if err := ls.addUtxo(txn, utxo); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	tmpResult, err := ls.queryShelleyUtxoWhole()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resultCbor, err := cbor.Encode(tmpResult)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var result []map[olocalstatequery.UtxoId]cbor.RawMessage
+	if _, err := cbor.Decode(resultCbor, &result); err != nil {
+		t.Fatalf("unexpected error decoding result: %s", err)
+	}
+	if len(result) != 1 || len(result[0]) != len(expected) {
+		t.Fatalf("did not get expected number of UTxOs: got %d, expected %d", len(result[0]), len(expected))
+	}
+	for utxoId, txOutCbor := range expected {
+		if !bytes.Equal(result[0][utxoId], txOutCbor) {
+			t.Fatalf(
+				"did not get expected output for UTxO %x#%d: got %x, expected %x",
+				utxoId.Hash.Bytes(),
+				utxoId.Idx,
+				[]byte(result[0][utxoId]),
+				txOutCbor,
+			)
+		}
+	}
+	// Each UTxO ID is encoded as a 2-item list of transaction ID and output index
+	utxoIdCbor, err := cbor.Encode(
+		[]any{ledger.NewBlake2b256(bytes.Repeat([]byte{0, 0}, 16)), 0},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Contains(resultCbor, utxoIdCbor) {
+		t.Fatalf("did not find expected UTxO ID encoding %x in result", utxoIdCbor)
+	}
+	// The result is an indefinite-length map, so that it can be encoded without knowing the size of the UTxO set
+	resultMap := []byte(tmpResult.([]any)[0].(cbor.RawMessage))
+	if resultMap[0] != cborIndefMapStart || resultMap[len(resultMap)-1] != cborBreak {
+		t.Fatalf("did not get expected indefinite-length map encoding")
+	}
+}
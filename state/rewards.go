@@ -0,0 +1,544 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"github.com/blinklabs-io/dingo/state/eras"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+const (
+	// Number of epochs of pool block records to retain. Rewards for an epoch are calculated at the
+	// start of the epoch after next, and we keep an extra epoch so that we can roll back across an
+	// epoch boundary
+	poolBlockRetainEpochs = 3
+)
+
+// poolRewardInput contains the per-pool inputs for calculating pool rewards
+type poolRewardInput struct {
+	blocks     uint64
+	poolStake  uint64
+	ownerStake uint64
+	pledge     uint64
+	cost       uint64
+	margin     *big.Rat
+}
+
+// recordPoolBlock records the pool that produced a block and the fees collected in the block, which are
+// used when calculating rewards
+func (ls *LedgerState) recordPoolBlock(
+	txn *database.Txn,
+	point ocommon.Point,
+	block ledger.Block,
+) error {
+	var fees uint64
+	for _, tx := range block.Transactions() {
+		// Collateral is collected in place of the fee for TXs that fail phase-2 validation
+		if !tx.IsValid() {
+			fees += tx.TotalCollateral()
+			continue
+		}
+		fees += tx.Fee()
+	}
+	return txn.DB().Metadata().SetPoolBlock(
+		lcommon.PoolKeyHash(block.IssuerVkey().Hash()),
+		fees,
+		ls.currentEpoch.EpochId,
+		point.Slot,
+		txn.Metadata(),
+	)
+}
+
+// rewardBalances returns the reward account balance for each staking key, calculated from the rewards paid to the
+// account minus any withdrawals. The results can optionally be limited to the specified staking keys
+func (ls *LedgerState) rewardBalances(
+	txn *database.Txn,
+	stakingKeys [][]byte,
+) (map[string]uint64, error) {
+	ret := make(map[string]uint64)
+	var rewardAmounts []struct {
+		StakingKey []byte
+		Amount     uint64
+	}
+	query := txn.Metadata().
+		Model(&models.Reward{}).
+		Select("staking_key, SUM(amount) AS amount")
+	if stakingKeys != nil {
+		query = query.Where("staking_key IN ?", stakingKeys)
+	}
+	result := query.Group("staking_key").Scan(&rewardAmounts)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query rewards: %w", result.Error)
+	}
+	for _, rewardAmount := range rewardAmounts {
+		ret[string(rewardAmount.StakingKey)] = rewardAmount.Amount
+	}
+	var withdrawalAmounts []struct {
+		StakingKey []byte
+		Amount     uint64
+	}
+	query = txn.Metadata().
+		Model(&models.Withdrawal{}).
+		Select("staking_key, SUM(amount) AS amount")
+	if stakingKeys != nil {
+		query = query.Where("staking_key IN ?", stakingKeys)
+	}
+	result = query.Group("staking_key").Scan(&withdrawalAmounts)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query withdrawals: %w", result.Error)
+	}
+	for _, withdrawalAmount := range withdrawalAmounts {
+		balance := ret[string(withdrawalAmount.StakingKey)]
+		if withdrawalAmount.Amount >= balance {
+			delete(ret, string(withdrawalAmount.StakingKey))
+			continue
+		}
+		ret[string(withdrawalAmount.StakingKey)] = balance - withdrawalAmount.Amount
+	}
+	return ret, nil
+}
+
+// circulatingSupply returns the total amount of lovelace in unspent UTxOs and reward accounts
+func (ls *LedgerState) circulatingSupply(txn *database.Txn) (uint64, error) {
+	var utxoTotal uint64
+	result := txn.Metadata().
+		Model(&models.Utxo{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("deleted_slot = 0").
+		Scan(&utxoTotal)
+	if result.Error != nil {
+		return 0, fmt.Errorf("query UTxO total: %w", result.Error)
+	}
+	rewardBalances, err := ls.rewardBalances(txn, nil)
+	if err != nil {
+		return 0, err
+	}
+	ret := utxoTotal
+	for _, balance := range rewardBalances {
+		ret += balance
+	}
+	return ret, nil
+}
+
+// processRewards calculates the rewards for blocks produced two epochs before the specified epoch and pays them to
+// the reward accounts, along with updating the reserves and treasury for the specified epoch. This must be called
+// before any protocol parameter updates for the new epoch are applied
+func (ls *LedgerState) processRewards(
+	txn *database.Txn,
+	epoch uint64,
+	slot uint64,
+) error {
+	if ls.currentEra.RewardParamsFunc == nil || epoch == 0 {
+		return nil
+	}
+	shelleyGenesis := ls.config.CardanoNodeConfig.ShelleyGenesis()
+	if shelleyGenesis == nil {
+		return errors.New("could not get genesis config")
+	}
+	prevPots, err := txn.DB().Metadata().GetAdaPots(epoch-1, txn.Metadata())
+	if err != nil {
+		return err
+	}
+	// Initialize the reserves from the circulating supply at the first epoch boundary with stake pools
+	if prevPots.ID == 0 {
+		circulation, err := ls.circulatingSupply(txn)
+		if err != nil {
+			return err
+		}
+		var reserves uint64
+		if shelleyGenesis.MaxLovelaceSupply > circulation {
+			reserves = shelleyGenesis.MaxLovelaceSupply - circulation
+		}
+		return txn.DB().Metadata().SetAdaPots(
+			epoch,
+			reserves,
+			0,
+			slot,
+			txn.Metadata(),
+		)
+	}
+	reserves := uint64(prevPots.Reserves)
+	treasury := uint64(prevPots.Treasury)
+	rewardParams, err := ls.currentEra.RewardParamsFunc(ls.currentPParams)
+	if err != nil {
+		return err
+	}
+	// Blocks are attributed to the pool stake distribution that was used for leader election in the epoch
+	// they were produced in
+	var poolBlocks []models.PoolBlock
+	var poolStakes []models.PoolStake
+	var delegatedStakes []models.DelegatedStake
+	if epoch >= 3 {
+		poolBlocks, err = txn.DB().Metadata().GetPoolBlocks(epoch-2, txn.Metadata())
+		if err != nil {
+			return err
+		}
+		poolStakes, err = txn.DB().Metadata().GetPoolStakes(epoch-3, txn.Metadata())
+		if err != nil {
+			return err
+		}
+		delegatedStakes, err = txn.DB().Metadata().GetDelegatedStakes(epoch-3, txn.Metadata())
+		if err != nil {
+			return err
+		}
+	}
+	var fees uint64
+	poolBlockCounts := make(map[string]uint64)
+	for _, poolBlock := range poolBlocks {
+		fees += poolBlock.Fees
+		poolBlockCounts[string(poolBlock.PoolKeyHash)]++
+	}
+	var activeStake uint64
+	var totalBlocks uint64
+	for _, poolStake := range poolStakes {
+		activeStake += poolStake.Stake
+		// Only count blocks produced by pools in the stake distribution. This excludes blocks produced
+		// by the genesis delegates while the chain was not fully decentralized
+		totalBlocks += poolBlockCounts[string(poolStake.PoolKeyHash)]
+	}
+	// Calculate monetary expansion and treasury cut
+	var circulation uint64
+	if shelleyGenesis.MaxLovelaceSupply > reserves {
+		circulation = shelleyGenesis.MaxLovelaceSupply - reserves
+	}
+	eta := calculateEta(
+		totalBlocks,
+		uint64(shelleyGenesis.EpochLength), // #nosec G115
+		shelleyGenesis.ActiveSlotsCoeff.Rat,
+		rewardParams.Decentralization,
+	)
+	deltaR1 := floorRat(
+		new(big.Rat).Mul(
+			new(big.Rat).Mul(eta, rewardParams.Rho),
+			ratFromUint64(reserves),
+		),
+	)
+	rewardPot := fees + deltaR1
+	deltaT1 := floorRat(
+		new(big.Rat).Mul(rewardParams.Tau, ratFromUint64(rewardPot)),
+	)
+	poolRewardPot := rewardPot - deltaT1
+	// Calculate pool rewards
+	registeredStakingKeys, err := ls.registeredStakingKeys(txn)
+	if err != nil {
+		return err
+	}
+	poolDelegators := make(map[string][]models.DelegatedStake)
+	for _, delegatedStake := range delegatedStakes {
+		poolDelegators[string(delegatedStake.PoolKeyHash)] = append(
+			poolDelegators[string(delegatedStake.PoolKeyHash)],
+			delegatedStake,
+		)
+	}
+	rewards := []models.Reward{}
+	var distributed, unregistered uint64
+	addReward := func(stakingKey []byte, poolKeyHash []byte, rewardType uint8, amount uint64) {
+		if amount == 0 {
+			return
+		}
+		// Rewards for unregistered reward accounts go to the treasury
+		if !registeredStakingKeys[string(stakingKey)] {
+			unregistered += amount
+			return
+		}
+		distributed += amount
+		rewards = append(
+			rewards,
+			models.Reward{
+				StakingKey:  stakingKey,
+				PoolKeyHash: poolKeyHash,
+				Epoch:       epoch - 2,
+				Type:        rewardType,
+				Amount:      amount,
+				AddedSlot:   slot,
+			},
+		)
+	}
+	for _, poolStake := range poolStakes {
+		blocks := poolBlockCounts[string(poolStake.PoolKeyHash)]
+		if blocks == 0 || poolStake.Stake == 0 {
+			continue
+		}
+		// Use the pool parameters in effect when the stake snapshot was taken
+		var poolReg models.PoolRegistration
+		result := txn.Metadata().
+			Preload("Owners").
+			Where("pool_key_hash = ? AND added_slot <= ?", poolStake.PoolKeyHash, poolStake.AddedSlot).
+			Order("id DESC").
+			First(&poolReg)
+		if result.Error != nil {
+			return fmt.Errorf(
+				"query registration for pool %x: %w",
+				poolStake.PoolKeyHash,
+				result.Error,
+			)
+		}
+		poolOwners := make(map[string]bool)
+		for _, owner := range poolReg.Owners {
+			poolOwners[string(owner.KeyHash)] = true
+		}
+		var ownerStake uint64
+		for _, delegator := range poolDelegators[string(poolStake.PoolKeyHash)] {
+			if poolOwners[string(delegator.StakingKey)] {
+				ownerStake += delegator.Stake
+			}
+		}
+		pool := poolRewardInput{
+			blocks:     blocks,
+			poolStake:  poolStake.Stake,
+			ownerStake: ownerStake,
+			pledge:     uint64(poolReg.Pledge),
+			cost:       uint64(poolReg.Cost),
+			margin:     poolReg.Margin.Rat,
+		}
+		poolReward := calculatePoolReward(
+			poolRewardPot,
+			circulation,
+			activeStake,
+			totalBlocks,
+			rewardParams,
+			pool,
+		)
+		if poolReward == 0 {
+			continue
+		}
+		addReward(
+			poolReg.RewardAccount,
+			poolStake.PoolKeyHash,
+			models.RewardTypeLeader,
+			calculateLeaderReward(poolReward, pool),
+		)
+		for _, delegator := range poolDelegators[string(poolStake.PoolKeyHash)] {
+			// Pool owners are rewarded through the leader reward
+			if poolOwners[string(delegator.StakingKey)] {
+				continue
+			}
+			addReward(
+				delegator.StakingKey,
+				poolStake.PoolKeyHash,
+				models.RewardTypeMember,
+				calculateMemberReward(poolReward, delegator.Stake, pool),
+			)
+		}
+	}
+	if err := txn.DB().Metadata().SetRewards(rewards, txn.Metadata()); err != nil {
+		return err
+	}
+	// Undistributed rewards are returned to the reserves
+	deltaR2 := poolRewardPot - distributed - unregistered
+	reserves = reserves - deltaR1 + deltaR2
+	treasury += deltaT1 + unregistered
+	err = txn.DB().Metadata().SetAdaPots(
+		epoch,
+		reserves,
+		treasury,
+		slot,
+		txn.Metadata(),
+	)
+	if err != nil {
+		return err
+	}
+	// Remove pool block records that are no longer needed
+	if epoch > poolBlockRetainEpochs {
+		err := txn.DB().Metadata().DeletePoolBlocksBefore(
+			epoch-poolBlockRetainEpochs,
+			txn.Metadata(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	ls.config.Logger.Debug(
+		fmt.Sprintf(
+			"calculated rewards for epoch %d: %d lovelace to %d reward accounts, %d lovelace to treasury, reserves now %d lovelace",
+			epoch-2,
+			distributed,
+			len(rewards),
+			deltaT1+unregistered,
+			reserves,
+		),
+		"component", "ledger",
+	)
+	return nil
+}
+
+// calculateEta returns the ratio of blocks produced by pools to the expected number of blocks, which is used to
+// scale the monetary expansion when fewer blocks than expected are produced
+func calculateEta(
+	blocks uint64,
+	epochLength uint64,
+	activeSlotsCoeff *big.Rat,
+	decentralization *big.Rat,
+) *big.Rat {
+	// The monetary expansion isn't scaled while the chain is mostly centralized
+	if decentralization.Cmp(big.NewRat(8, 10)) >= 0 {
+		return big.NewRat(1, 1)
+	}
+	expectedBlocks := new(big.Rat).Mul(
+		new(big.Rat).Sub(big.NewRat(1, 1), decentralization),
+		new(big.Rat).Mul(activeSlotsCoeff, ratFromUint64(epochLength)),
+	)
+	if expectedBlocks.Sign() <= 0 {
+		return big.NewRat(1, 1)
+	}
+	return minRat(
+		big.NewRat(1, 1),
+		new(big.Rat).Quo(ratFromUint64(blocks), expectedBlocks),
+	)
+}
+
+// calculatePoolReward returns the total reward for a pool, based on its stake, pledge and apparent performance
+func calculatePoolReward(
+	rewardPot uint64,
+	totalStake uint64,
+	activeStake uint64,
+	totalBlocks uint64,
+	rewardParams eras.RewardParams,
+	pool poolRewardInput,
+) uint64 {
+	if totalStake == 0 || activeStake == 0 || totalBlocks == 0 ||
+		rewardParams.NOpt == 0 {
+		return 0
+	}
+	// Pools that don't meet their pledge receive no rewards
+	if pool.ownerStake < pool.pledge {
+		return 0
+	}
+	sigma := new(big.Rat).SetFrac(
+		new(big.Int).SetUint64(pool.poolStake),
+		new(big.Int).SetUint64(totalStake),
+	)
+	pledgeRatio := new(big.Rat).SetFrac(
+		new(big.Int).SetUint64(pool.pledge),
+		new(big.Int).SetUint64(totalStake),
+	)
+	// Calculate the maximum pool reward
+	//   R / (1 + a0) * (sigma' + s' * a0 * (sigma' - s' * (z0 - sigma') / z0) / z0)
+	z0 := big.NewRat(1, int64(rewardParams.NOpt)) // #nosec G115
+	sigmaP := minRat(sigma, z0)
+	pledgeP := minRat(pledgeRatio, z0)
+	factor3 := new(big.Rat).Quo(new(big.Rat).Sub(z0, sigmaP), z0)
+	factor2 := new(big.Rat).Quo(
+		new(big.Rat).Sub(sigmaP, new(big.Rat).Mul(pledgeP, factor3)),
+		z0,
+	)
+	factor1 := new(big.Rat).Add(
+		sigmaP,
+		new(big.Rat).Mul(
+			new(big.Rat).Mul(pledgeP, rewardParams.A0),
+			factor2,
+		),
+	)
+	maxPool := new(big.Rat).Mul(
+		new(big.Rat).Quo(
+			ratFromUint64(rewardPot),
+			new(big.Rat).Add(big.NewRat(1, 1), rewardParams.A0),
+		),
+		factor1,
+	)
+	// Calculate apparent performance. Performance isn't tracked while the chain is mostly centralized
+	performance := big.NewRat(1, 1)
+	if rewardParams.Decentralization.Cmp(big.NewRat(8, 10)) < 0 {
+		blockRatio := new(big.Rat).SetFrac(
+			new(big.Int).SetUint64(pool.blocks),
+			new(big.Int).SetUint64(totalBlocks),
+		)
+		activeSigma := new(big.Rat).SetFrac(
+			new(big.Int).SetUint64(pool.poolStake),
+			new(big.Int).SetUint64(activeStake),
+		)
+		performance.Quo(blockRatio, activeSigma)
+	}
+	return floorRat(new(big.Rat).Mul(performance, maxPool))
+}
+
+// calculateLeaderReward returns the portion of the pool reward paid to the pool operator
+//
+//	cost + (reward - cost) * (margin + (1 - margin) * ownerStake / poolStake)
+func calculateLeaderReward(
+	poolReward uint64,
+	pool poolRewardInput,
+) uint64 {
+	if poolReward <= pool.cost {
+		return poolReward
+	}
+	ownerRatio := new(big.Rat).SetFrac(
+		new(big.Int).SetUint64(pool.ownerStake),
+		new(big.Int).SetUint64(pool.poolStake),
+	)
+	share := new(big.Rat).Add(
+		pool.margin,
+		new(big.Rat).Mul(
+			new(big.Rat).Sub(big.NewRat(1, 1), pool.margin),
+			ownerRatio,
+		),
+	)
+	return pool.cost + floorRat(
+		new(big.Rat).Mul(ratFromUint64(poolReward-pool.cost), share),
+	)
+}
+
+// calculateMemberReward returns the portion of the pool reward paid to a delegator
+//
+//	(reward - cost) * (1 - margin) * memberStake / poolStake
+func calculateMemberReward(
+	poolReward uint64,
+	memberStake uint64,
+	pool poolRewardInput,
+) uint64 {
+	if poolReward <= pool.cost {
+		return 0
+	}
+	memberRatio := new(big.Rat).SetFrac(
+		new(big.Int).SetUint64(memberStake),
+		new(big.Int).SetUint64(pool.poolStake),
+	)
+	return floorRat(
+		new(big.Rat).Mul(
+			new(big.Rat).Mul(
+				ratFromUint64(poolReward-pool.cost),
+				new(big.Rat).Sub(big.NewRat(1, 1), pool.margin),
+			),
+			memberRatio,
+		),
+	)
+}
+
+func ratFromUint64(val uint64) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).SetUint64(val))
+}
+
+// floorRat returns the value rounded down to the nearest integer, or 0 for negative values
+func floorRat(val *big.Rat) uint64 {
+	if val.Sign() <= 0 {
+		return 0
+	}
+	return new(big.Int).Quo(val.Num(), val.Denom()).Uint64()
+}
+
+func minRat(a, b *big.Rat) *big.Rat {
+	if a.Cmp(b) < 0 {
+		return a
+	}
+	return b
+}
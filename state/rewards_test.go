@@ -0,0 +1,244 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"github.com/blinklabs-io/dingo/state/eras"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+func TestCalculateEta(t *testing.T) {
+	testDefs := []struct {
+		blocks           uint64
+		decentralization *big.Rat
+		expected         *big.Rat
+	}{
+		{
+			blocks:           10800,
+			decentralization: big.NewRat(0, 1),
+			expected:         big.NewRat(1, 2),
+		},
+		// Capped at 1
+		{
+			blocks:           30000,
+			decentralization: big.NewRat(0, 1),
+			expected:         big.NewRat(1, 1),
+		},
+		// Always 1 when mostly centralized
+		{
+			blocks:           0,
+			decentralization: big.NewRat(9, 10),
+			expected:         big.NewRat(1, 1),
+		},
+	}
+	for _, testDef := range testDefs {
+		eta := calculateEta(
+			testDef.blocks,
+			432000,
+			big.NewRat(1, 20),
+			testDef.decentralization,
+		)
+		if eta.Cmp(testDef.expected) != 0 {
+			t.Fatalf(
+				"did not get expected eta: got %s, expected %s",
+				eta.String(),
+				testDef.expected.String(),
+			)
+		}
+	}
+}
+
+func TestCalculatePoolRewards(t *testing.T) {
+	rewardParams := eras.RewardParams{
+		NOpt:             1,
+		A0:               big.NewRat(0, 1),
+		Rho:              big.NewRat(3, 1000),
+		Tau:              big.NewRat(2, 10),
+		Decentralization: big.NewRat(0, 1),
+	}
+	pool := poolRewardInput{
+		blocks:     2,
+		poolStake:  1000,
+		ownerStake: 100,
+		pledge:     100,
+		cost:       340,
+		margin:     big.NewRat(1, 10),
+	}
+	poolReward := calculatePoolReward(
+		1000000,
+		10000,
+		5000,
+		10,
+		rewardParams,
+		pool,
+	)
+	if poolReward != 100000 {
+		t.Fatalf(
+			"did not get expected pool reward: got %d, expected %d",
+			poolReward,
+			100000,
+		)
+	}
+	// (100000 - 340) * (0.1 + 0.9 * 0.1) = 18935.4
+	leaderReward := calculateLeaderReward(poolReward, pool)
+	if leaderReward != 340+18935 {
+		t.Fatalf(
+			"did not get expected leader reward: got %d, expected %d",
+			leaderReward,
+			340+18935,
+		)
+	}
+	// (100000 - 340) * 0.9 * 0.45 = 40362.3
+	memberReward := calculateMemberReward(poolReward, 450, pool)
+	if memberReward != 40362 {
+		t.Fatalf(
+			"did not get expected member reward: got %d, expected %d",
+			memberReward,
+			40362,
+		)
+	}
+	// Pools that don't meet their pledge get no rewards
+	pool.ownerStake = 50
+	poolReward = calculatePoolReward(
+		1000000,
+		10000,
+		5000,
+		10,
+		rewardParams,
+		pool,
+	)
+	if poolReward != 0 {
+		t.Fatalf(
+			"did not get expected pool reward: got %d, expected %d",
+			poolReward,
+			0,
+		)
+	}
+}
+
+func TestQueryShelleyFilteredDelegationAndRewardAccounts(t *testing.T) {
+	ls := testStakeLedgerState(t)
+	scriptCred := lcommon.StakeCredential{
+		CredType:   lcommon.StakeCredentialTypeScriptHash,
+		Credential: testKeyHash(12),
+	}
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		err := txn.DB().Metadata().SetStakeRegistration(
+			&lcommon.StakeRegistrationCertificate{
+				CertType:          lcommon.CertificateTypeStakeRegistration,
+				StakeRegistration: scriptCred,
+			},
+			210,
+			2000000,
+			txn.Metadata(),
+		)
+		if err != nil {
+			return err
+		}
+		return txn.DB().Metadata().SetRewards(
+			[]models.Reward{
+				{
+					StakingKey:  testKeyHash(10),
+					PoolKeyHash: testKeyHash(1),
+					Epoch:       2,
+					Type:        models.RewardTypeMember,
+					Amount:      1234,
+					AddedSlot:   2 * testEpochLength,
+				},
+			},
+			txn.Metadata(),
+		)
+	})
+	keyCred := func(id byte) lcommon.StakeCredential {
+		return lcommon.StakeCredential{
+			CredType:   lcommon.StakeCredentialTypeAddrKeyHash,
+			Credential: testKeyHash(id),
+		}
+	}
+	tmpResult, err := ls.queryShelleyFilteredDelegationAndRewardAccounts(
+		[]lcommon.StakeCredential{
+			keyCred(10),
+			scriptCred,
+			// Registered as a key hash credential
+			{
+				CredType:   lcommon.StakeCredentialTypeScriptHash,
+				Credential: testKeyHash(11),
+			},
+			// Not registered
+			keyCred(13),
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	result := tmpResult.([]any)[0].(filteredDelegationAndRewardAccountsResult)
+	credKey := func(cred lcommon.StakeCredential) stakeCredentialKey {
+		return stakeCredentialKey{
+			CredType:   cred.CredType,
+			Credential: ledger.NewBlake2b224(cred.Credential),
+		}
+	}
+	expected := filteredDelegationAndRewardAccountsResult{
+		Delegations: map[stakeCredentialKey]ledger.PoolId{
+			credKey(keyCred(10)): ledger.PoolId(ledger.NewBlake2b224(testKeyHash(1))),
+		},
+		RewardAccounts: map[stakeCredentialKey]uint64{
+			credKey(keyCred(10)): 1234,
+			credKey(scriptCred):  0,
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf(
+			"did not get expected result: got %#v, expected %#v",
+			result,
+			expected,
+		)
+	}
+}
+
+func TestRewardAccountAddress(t *testing.T) {
+	ls := newTestLedgerState(t)
+	testDefs := []struct {
+		credType uint
+		expected byte
+	}{
+		{
+			credType: lcommon.StakeCredentialTypeAddrKeyHash,
+			expected: 0xe0,
+		},
+		{
+			credType: lcommon.StakeCredentialTypeScriptHash,
+			expected: 0xf0,
+		},
+	}
+	for _, testDef := range testDefs {
+		addr := ls.rewardAccountAddress(testKeyHash(1), testDef.credType)
+		if addr[0] != testDef.expected || !bytes.Equal(addr[1:], testKeyHash(1)) {
+			t.Fatalf(
+				"did not get expected reward account address: got %x, expected header %x",
+				addr,
+				testDef.expected,
+			)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+// stakeSnapshot identifies one of the rotating stake snapshots by its age in epochs
+type stakeSnapshot uint64
+
+const (
+	stakeSnapshotMark stakeSnapshot = 0
+	stakeSnapshotSet  stakeSnapshot = 1
+	stakeSnapshotGo   stakeSnapshot = 2
+)
+
+const (
+	// Number of epochs of per-staking key stake snapshots to retain
+	stakeSnapshotRetainEpochs = 4
+)
+
+// stakeDelegations returns the pool that each staking key is currently delegated to, ignoring delegations to pools
+// that aren't active in the specified epoch
+func (ls *LedgerState) stakeDelegations(
+	txn *database.Txn,
+	epoch uint64,
+) (map[string][]byte, error) {
+	// Determine active pools
+	pools, err := ls.poolStates(txn, epoch)
+	if err != nil {
+		return nil, err
+	}
+	// Determine current delegation for each staking key
+	var stakeDeregs []models.StakeDeregistration
+	result := txn.Metadata().Order("id ASC").Find(&stakeDeregs)
+	if result.Error != nil {
+		return nil, fmt.Errorf(
+			"query stake deregistrations: %w",
+			result.Error,
+		)
+	}
+	stakeDeregSlots := make(map[string]uint64)
+	for _, stakeDereg := range stakeDeregs {
+		stakeDeregSlots[string(stakeDereg.StakingKey)] = stakeDereg.AddedSlot
+	}
+	var stakeDelegs []models.StakeDelegation
+	result = txn.Metadata().Order("id ASC").Find(&stakeDelegs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query stake delegations: %w", result.Error)
+	}
+	stakeKeyPools := make(map[string][]byte)
+	for _, stakeDeleg := range stakeDelegs {
+		// Deregistering a staking key removes any existing delegation
+		if deregSlot, ok := stakeDeregSlots[string(stakeDeleg.StakingKey)]; ok &&
+			deregSlot >= stakeDeleg.AddedSlot {
+			delete(stakeKeyPools, string(stakeDeleg.StakingKey))
+			continue
+		}
+		// Ignore delegations to pools that aren't active. A delegation to a pool that has since retired
+		// does not carry over if the pool registers again
+		pool, ok := pools[string(stakeDeleg.PoolKeyHash)]
+		if !ok || stakeDeleg.AddedSlot < pool.registeredSlot {
+			delete(stakeKeyPools, string(stakeDeleg.StakingKey))
+			continue
+		}
+		stakeKeyPools[string(stakeDeleg.StakingKey)] = stakeDeleg.PoolKeyHash
+	}
+	return stakeKeyPools, nil
+}
+
+// registeredStakingKeys returns the set of staking keys that are currently registered
+func (ls *LedgerState) registeredStakingKeys(
+	txn *database.Txn,
+) (map[string]bool, error) {
+	var stakeRegs []models.StakeRegistration
+	result := txn.Metadata().
+		Select("staking_key, added_slot").
+		Order("id ASC").
+		Find(&stakeRegs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query stake registrations: %w", result.Error)
+	}
+	stakeRegSlots := make(map[string]uint64)
+	for _, stakeReg := range stakeRegs {
+		stakeRegSlots[string(stakeReg.StakingKey)] = stakeReg.AddedSlot
+	}
+	var stakeDeregs []models.StakeDeregistration
+	result = txn.Metadata().
+		Select("staking_key, added_slot").
+		Order("id ASC").
+		Find(&stakeDeregs)
+	if result.Error != nil {
+		return nil, fmt.Errorf(
+			"query stake deregistrations: %w",
+			result.Error,
+		)
+	}
+	for _, stakeDereg := range stakeDeregs {
+		// A registration after the deregistration certificate re-registers the staking key
+		regSlot, ok := stakeRegSlots[string(stakeDereg.StakingKey)]
+		if ok && stakeDereg.AddedSlot >= regSlot {
+			delete(stakeRegSlots, string(stakeDereg.StakingKey))
+		}
+	}
+	ret := make(map[string]bool, len(stakeRegSlots))
+	for stakingKey := range stakeRegSlots {
+		ret[stakingKey] = true
+	}
+	return ret, nil
+}
+
+// stakeCredentialTypes returns the credential type from the most recent registration of each of the specified
+// staking keys, or of all staking keys if none are specified
+func (ls *LedgerState) stakeCredentialTypes(
+	txn *database.Txn,
+	stakingKeys [][]byte,
+) (map[string]uint, error) {
+	var stakeRegs []models.StakeRegistration
+	query := txn.Metadata().
+		Select("staking_key, credential_type").
+		Order("id ASC")
+	if stakingKeys != nil {
+		query = query.Where("staking_key IN ?", stakingKeys)
+	}
+	if result := query.Find(&stakeRegs); result.Error != nil {
+		return nil, fmt.Errorf("query stake registrations: %w", result.Error)
+	}
+	ret := make(map[string]uint, len(stakeRegs))
+	for _, stakeReg := range stakeRegs {
+		ret[string(stakeReg.StakingKey)] = stakeReg.CredentialType
+	}
+	return ret, nil
+}
+
+// stakeKeyAmounts returns the total amount of lovelace controlled by each staking key, which includes both unspent
+// UTxOs and the reward account balance
+func (ls *LedgerState) stakeKeyAmounts(
+	txn *database.Txn,
+) (map[string]uint64, error) {
+	// Sum unspent UTxO amounts for each staking key
+	var stakeKeyUtxoAmounts []struct {
+		StakingKey []byte
+		Amount     uint64
+	}
+	result := txn.Metadata().
+		Model(&models.Utxo{}).
+		Select("staking_key, SUM(amount) AS amount").
+		Where("deleted_slot = 0").
+		Group("staking_key").
+		Scan(&stakeKeyUtxoAmounts)
+	if result.Error != nil {
+		return nil, fmt.Errorf("query UTxO stake: %w", result.Error)
+	}
+	stakeKeyAmounts := make(map[string]uint64)
+	for _, stakeKeyUtxoAmount := range stakeKeyUtxoAmounts {
+		stakeKeyAmounts[string(stakeKeyUtxoAmount.StakingKey)] += stakeKeyUtxoAmount.Amount
+	}
+	// Add reward account balances
+	rewardBalances, err := ls.rewardBalances(txn, nil)
+	if err != nil {
+		return nil, err
+	}
+	for stakingKey, balance := range rewardBalances {
+		stakeKeyAmounts[stakingKey] += balance
+	}
+	return stakeKeyAmounts, nil
+}
+
+// calculateDelegatedStake calculates the active stake for each staking key that is delegated to a registered pool
+// from the current ledger state. The active stake includes both unspent UTxOs and the reward account balance
+func (ls *LedgerState) calculateDelegatedStake(
+	txn *database.Txn,
+	epoch uint64,
+) ([]models.DelegatedStake, error) {
+	ret := []models.DelegatedStake{}
+	stakeKeyPools, err := ls.stakeDelegations(txn, epoch)
+	if err != nil {
+		return nil, err
+	}
+	if len(stakeKeyPools) == 0 {
+		return ret, nil
+	}
+	stakeKeyAmounts, err := ls.stakeKeyAmounts(txn)
+	if err != nil {
+		return nil, err
+	}
+	for stakingKey, poolKeyHash := range stakeKeyPools {
+		amount, ok := stakeKeyAmounts[stakingKey]
+		if !ok {
+			continue
+		}
+		ret = append(
+			ret,
+			models.DelegatedStake{
+				StakingKey:  []byte(stakingKey),
+				PoolKeyHash: poolKeyHash,
+				Epoch:       epoch,
+				Stake:       amount,
+			},
+		)
+	}
+	return ret, nil
+}
+
+// snapshotStake calculates and saves the stake snapshot taken at the start of the specified epoch. This becomes the
+// "mark" snapshot, with the snapshots from the previous two epochs becoming the "set" and "go" snapshots
+func (ls *LedgerState) snapshotStake(
+	txn *database.Txn,
+	epoch uint64,
+	slot uint64,
+) error {
+	delegatedStakes, err := ls.calculateDelegatedStake(txn, epoch)
+	if err != nil {
+		return err
+	}
+	poolStake := make(map[lcommon.PoolKeyHash]uint64)
+	var totalStake uint64
+	for idx, delegatedStake := range delegatedStakes {
+		delegatedStakes[idx].AddedSlot = slot
+		poolKeyHash := lcommon.PoolKeyHash(
+			lcommon.NewBlake2b224(delegatedStake.PoolKeyHash),
+		)
+		poolStake[poolKeyHash] += delegatedStake.Stake
+		totalStake += delegatedStake.Stake
+	}
+	err = txn.DB().Metadata().SetDelegatedStakes(
+		delegatedStakes,
+		txn.Metadata(),
+	)
+	if err != nil {
+		return err
+	}
+	for poolKeyHash, stake := range poolStake {
+		err := txn.DB().Metadata().SetPoolStake(
+			poolKeyHash,
+			stake,
+			epoch,
+			slot,
+			txn.Metadata(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	// Remove per-staking key snapshots that are no longer needed. We keep an extra epoch
+	// beyond the "go" snapshot so that we can roll back across an epoch boundary
+	if epoch > stakeSnapshotRetainEpochs {
+		err := txn.DB().Metadata().DeleteDelegatedStakesBefore(
+			epoch-stakeSnapshotRetainEpochs,
+			txn.Metadata(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	ls.config.Logger.Debug(
+		fmt.Sprintf(
+			"took stake snapshot for epoch %d: %d pools, %d lovelace active stake",
+			epoch,
+			len(poolStake),
+			totalStake,
+		),
+		"component", "ledger",
+	)
+	return nil
+}
+
+// stakeSnapshotEpoch returns the epoch in which the specified snapshot was taken, relative to the current epoch
+func (ls *LedgerState) stakeSnapshotEpoch(snapshot stakeSnapshot) (uint64, bool) {
+	if ls.currentEpoch.EpochId < uint64(snapshot) {
+		return 0, false
+	}
+	return ls.currentEpoch.EpochId - uint64(snapshot), true
+}
+
+// poolStakeSnapshot returns the per-pool stake from the specified snapshot
+func (ls *LedgerState) poolStakeSnapshot(
+	txn *database.Txn,
+	snapshot stakeSnapshot,
+) ([]models.PoolStake, error) {
+	epoch, ok := ls.stakeSnapshotEpoch(snapshot)
+	if !ok {
+		return []models.PoolStake{}, nil
+	}
+	return txn.DB().Metadata().GetPoolStakes(epoch, txn.Metadata())
+}
+
+// poolDistribution returns the pool stake distribution used for leader election in the current epoch. This
+// is the "set" snapshot
+func (ls *LedgerState) poolDistribution(
+	txn *database.Txn,
+) ([]models.PoolStake, error) {
+	return ls.poolStakeSnapshot(txn, stakeSnapshotSet)
+}
@@ -0,0 +1,214 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	olocalstatequery "github.com/blinklabs-io/gouroboros/protocol/localstatequery"
+)
+
+// testStakeLedgerState returns a ledger in epoch 2 with two pools. Pool 1 has 300 lovelace delegated from
+// staking key 10 and pool 2 has 100 lovelace delegated from staking key 11, both as of the snapshot taken
+// at the start of epoch 1
+func testStakeLedgerState(t *testing.T) *LedgerState {
+	ls := newTestLedgerState(t)
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		testAddEpoch(t, ls, txn, 0)
+		testRegisterPool(t, txn, 1, 10, 5, 500000000)
+		testRegisterPool(t, txn, 2, 11, 6, 500000000)
+		testDelegateStake(t, txn, 10, 1, 7, 300)
+		testDelegateStake(t, txn, 11, 2, 8, 100)
+		for _, epoch := range []uint64{1, 2} {
+			testAddEpoch(t, ls, txn, epoch)
+			if err := ls.snapshotStake(txn, epoch, epoch*testEpochLength); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return ls
+}
+
+func TestQueryShelleyStakeDistribution(t *testing.T) {
+	ls := testStakeLedgerState(t)
+	pool1 := ledger.PoolId(ledger.NewBlake2b224(testKeyHash(1)))
+	pool2 := ledger.PoolId(ledger.NewBlake2b224(testKeyHash(2)))
+	testDefs := []struct {
+		poolIds  []ledger.PoolId
+		expected map[ledger.PoolId]*big.Rat
+	}{
+		{
+			expected: map[ledger.PoolId]*big.Rat{
+				pool1: big.NewRat(3, 4),
+				pool2: big.NewRat(1, 4),
+			},
+		},
+		// The stake fraction is relative to the total stake of all pools
+		{
+			poolIds: []ledger.PoolId{pool1},
+			expected: map[ledger.PoolId]*big.Rat{
+				pool1: big.NewRat(3, 4),
+			},
+		},
+		// Unknown pool
+		{
+			poolIds:  []ledger.PoolId{ledger.PoolId(ledger.NewBlake2b224(testKeyHash(3)))},
+			expected: map[ledger.PoolId]*big.Rat{},
+		},
+	}
+	for _, testDef := range testDefs {
+		tmpResult, err := ls.queryShelleyStakeDistribution(testDef.poolIds)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		result, ok := tmpResult.(olocalstatequery.StakeDistributionResult)
+		if !ok {
+			t.Fatalf("did not get expected result type: got %T", tmpResult)
+		}
+		if len(result.Results) != len(testDef.expected) {
+			t.Fatalf(
+				"did not get expected number of pools: got %d, expected %d",
+				len(result.Results),
+				len(testDef.expected),
+			)
+		}
+		for poolId, expectedFraction := range testDef.expected {
+			poolResult, ok := result.Results[poolId]
+			if !ok {
+				t.Fatalf("did not get expected pool %s", poolId.String())
+			}
+			if poolResult.StakeFraction.Cmp(expectedFraction) != 0 {
+				t.Fatalf(
+					"did not get expected stake fraction for pool %s: got %s, expected %s",
+					poolId.String(),
+					poolResult.StakeFraction.String(),
+					expectedFraction.String(),
+				)
+			}
+		}
+	}
+}
+
+func TestQueryShelleyStakeSnapshots(t *testing.T) {
+	ls := testStakeLedgerState(t)
+	// Add more stake for pool 1 in epoch 2 and rotate the snapshots
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		testAddUtxo(t, txn, 10, 250, 200)
+		testAddEpoch(t, ls, txn, 3)
+		return ls.snapshotStake(txn, 3, 3*testEpochLength)
+	})
+	pool1 := ledger.PoolId(ledger.NewBlake2b224(testKeyHash(1)))
+	pool2 := ledger.PoolId(ledger.NewBlake2b224(testKeyHash(2)))
+	testDefs := []struct {
+		poolIds  []ledger.PoolId
+		expected stakeSnapshotsResult
+	}{
+		{
+			expected: stakeSnapshotsResult{
+				Pools: map[ledger.PoolId]stakeSnapshotsResultPool{
+					pool1: {Mark: 500, Set: 300, Go: 300},
+					pool2: {Mark: 100, Set: 100, Go: 100},
+				},
+				MarkTotal: 600,
+				SetTotal:  400,
+				GoTotal:   400,
+			},
+		},
+		// The totals always cover all pools
+		{
+			poolIds: []ledger.PoolId{pool2},
+			expected: stakeSnapshotsResult{
+				Pools: map[ledger.PoolId]stakeSnapshotsResultPool{
+					pool2: {Mark: 100, Set: 100, Go: 100},
+				},
+				MarkTotal: 600,
+				SetTotal:  400,
+				GoTotal:   400,
+			},
+		},
+	}
+	for _, testDef := range testDefs {
+		tmpResult, err := ls.queryShelleyStakeSnapshots(testDef.poolIds)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		result := tmpResult.([]any)[0].(stakeSnapshotsResult)
+		if !reflect.DeepEqual(result, testDef.expected) {
+			t.Fatalf(
+				"did not get expected stake snapshots: got %#v, expected %#v",
+				result,
+				testDef.expected,
+			)
+		}
+	}
+}
+
+func TestStakeSnapshotRollback(t *testing.T) {
+	ls := testStakeLedgerState(t)
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		testAddUtxo(t, txn, 10, 250, 200)
+		testAddEpoch(t, ls, txn, 3)
+		return ls.snapshotStake(txn, 3, 3*testEpochLength)
+	})
+	// Roll back to before the start of epoch 3
+	testLedgerTxn(t, ls, func(txn *database.Txn) error {
+		if err := ls.rollbackLedgerRecords(txn, 3*testEpochLength-1); err != nil {
+			return err
+		}
+		tmpEpoch, err := txn.DB().GetEpochLatest(txn)
+		if err != nil {
+			return err
+		}
+		ls.currentEpoch = tmpEpoch
+		return nil
+	})
+	if ls.currentEpoch.EpochId != 2 {
+		t.Fatalf(
+			"did not get expected epoch after rollback: got %d, expected 2",
+			ls.currentEpoch.EpochId,
+		)
+	}
+	txn := ls.db.Transaction(false)
+	defer func() {
+		_ = txn.Rollback()
+	}()
+	poolStakes, err := txn.DB().Metadata().GetPoolStakes(3, txn.Metadata())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(poolStakes) != 0 {
+		t.Fatalf("did not get expected rolled back snapshot: got %d pool stakes", len(poolStakes))
+	}
+	// The snapshot taken at the start of epoch 2 is the mark snapshot again
+	markStakes, err := ls.poolStakeSnapshot(txn, stakeSnapshotMark)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var markTotal uint64
+	for _, poolStake := range markStakes {
+		if poolStake.Epoch != 2 {
+			t.Fatalf("did not get expected snapshot epoch: got %d, expected 2", poolStake.Epoch)
+		}
+		markTotal += poolStake.Stake
+	}
+	if markTotal != 400 {
+		t.Fatalf("did not get expected mark snapshot total: got %d, expected 400", markTotal)
+	}
+}
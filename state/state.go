@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/blinklabs-io/dingo/config/cardano"
+	"github.com/blinklabs-io/dingo/connmanager"
 	"github.com/blinklabs-io/dingo/database"
 	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
 	"github.com/blinklabs-io/dingo/event"
@@ -40,30 +41,72 @@ import (
 )
 
 const (
-	cleanupConsumedUtxosInterval   = 5 * time.Minute
-	cleanupConsumedUtxosSlotWindow = 50000 // TODO: calculate this from params (#395)
+	cleanupConsumedUtxosInterval = 5 * time.Minute
+	archiveBlocksInterval        = 1 * time.Minute
+	archiveBlocksBatchSize       = 1000
 )
 
+// ledgerRollbackModels contains the list of metadata models with an AddedSlot field that should be
+// removed on rollback
+var ledgerRollbackModels = []any{
+	&models.AdaPots{},
+	&models.AuthCommitteeHot{},
+	&models.Committee{},
+	&models.CommitteeMember{},
+	&models.Constitution{},
+	&models.DelegatedStake{},
+	&models.DrepDeregistration{},
+	&models.DrepRegistration{},
+	&models.DrepStake{},
+	&models.DrepUpdate{},
+	&models.GenesisKeyDelegation{},
+	&models.GovernanceProposal{},
+	&models.GovernanceVote{},
+	&models.OpCertCounter{},
+	&models.PoolBlock{},
+	&models.PoolRegistration{},
+	&models.PoolRetirement{},
+	&models.PoolStake{},
+	&models.PParams{},
+	&models.PParamUpdate{},
+	&models.ResignCommitteeCold{},
+	&models.Reward{},
+	&models.StakeDelegation{},
+	&models.StakeDeregistration{},
+	&models.StakeRegistration{},
+	&models.VoteDelegation{},
+	&models.Withdrawal{},
+}
+
 type LedgerStateConfig struct {
 	Logger            *slog.Logger
 	DataDir           string
+	BlobPlugin        string
+	MetadataPlugin    string
 	EventBus          *event.EventBus
 	CardanoNodeConfig *cardano.CardanoNodeConfig
 	PromRegistry      prometheus.Registerer
+	ValidationLevel   ValidationLevel
 	// Callback(s)
 	BlockfetchRequestRangeFunc BlockfetchRequestRangeFunc
+	ConnectionCloseFunc        ConnectionCloseFunc
 }
 
 // BlockfetchRequestRangeFunc describes a callback function used to start a blockfetch request for
 // a range of blocks
 type BlockfetchRequestRangeFunc func(ouroboros.ConnectionId, ocommon.Point, ocommon.Point) error
 
+// ConnectionCloseFunc describes a callback function used to close the connection to a peer that
+// provided an invalid block
+type ConnectionCloseFunc func(ouroboros.ConnectionId, error)
+
 type LedgerState struct {
 	sync.RWMutex
 	chainsyncMutex              sync.Mutex
 	config                      LedgerStateConfig
 	db                          *database.Database
 	timerCleanupConsumedUtxos   *time.Timer
+	timerArchiveBlocks          *time.Timer
 	currentPParams              lcommon.ProtocolParameters
 	currentEpoch                database.Epoch
 	currentEra                  eras.EraDesc
@@ -75,15 +118,24 @@ type LedgerState struct {
 	chainsyncBlockEvents        []BlockfetchEvent
 	chainsyncBlockfetchBusy     bool
 	chainsyncBlockfetchBusyTime time.Time
+	chainsyncBlockfetchConnId   ouroboros.ConnectionId
 	chainsyncBlockfetchMutex    sync.Mutex
 	chainsyncBlockfetchWaiting  bool
-	chainsyncHeaderMutex        sync.Mutex
+	chainsyncBlockfetchDoneChan chan struct{}
+	chainsyncCandidates         map[ouroboros.ConnectionId]*chainsyncCandidate
+	chainsyncCandidatesMutex    sync.Mutex
+	chainsyncCandidatesCond     *sync.Cond
+	chainsyncSelectedConnId     *ouroboros.ConnectionId
+	chainsyncForkPoint          *ocommon.Point
+	praosEpochStates            map[uint64]*praosEpochState
 }
 
 func NewLedgerState(cfg LedgerStateConfig) (*LedgerState, error) {
 	ls := &LedgerState{
-		config: cfg,
+		config:              cfg,
+		chainsyncCandidates: make(map[ouroboros.ConnectionId]*chainsyncCandidate),
 	}
+	ls.chainsyncCandidatesCond = sync.NewCond(&ls.chainsyncCandidatesMutex)
 	if cfg.Logger == nil {
 		// Create logger to throw away logs
 		// We do this so we don't have to add guards around every log operation
@@ -92,7 +144,14 @@ func NewLedgerState(cfg LedgerStateConfig) (*LedgerState, error) {
 	// Init metrics
 	ls.metrics.init(ls.config.PromRegistry)
 	// Load database
-	db, err := database.New(cfg.Logger, cfg.DataDir)
+	db, err := database.New(
+		&database.Config{
+			BlobPlugin:     cfg.BlobPlugin,
+			DataDir:        cfg.DataDir,
+			Logger:         cfg.Logger,
+			MetadataPlugin: cfg.MetadataPlugin,
+		},
+	)
 	if db == nil {
 		ls.config.Logger.Error(
 			"failed to create database",
@@ -130,8 +189,14 @@ func NewLedgerState(cfg LedgerStateConfig) (*LedgerState, error) {
 		BlockfetchEventType,
 		ls.handleEventBlockfetch,
 	)
+	ls.config.EventBus.SubscribeFunc(
+		connmanager.ConnectionClosedEventType,
+		ls.handleEventConnectionClosed,
+	)
 	// Schedule periodic process to purge consumed UTxOs outside of the rollback window
 	ls.scheduleCleanupConsumedUtxos()
+	// Schedule periodic move of immutable blocks to chunk storage
+	ls.scheduleArchiveBlocks()
 	// Load current epoch from DB
 	if err := ls.loadEpoch(); err != nil {
 		return nil, err
@@ -182,6 +247,15 @@ func (ls *LedgerState) recoverCommitTimestampConflict() error {
 }
 
 func (ls *LedgerState) Close() error {
+	// Stop periodic processes before closing the database
+	ls.Lock()
+	if ls.timerCleanupConsumedUtxos != nil {
+		ls.timerCleanupConsumedUtxos.Stop()
+	}
+	if ls.timerArchiveBlocks != nil {
+		ls.timerArchiveBlocks.Stop()
+	}
+	ls.Unlock()
 	return ls.db.Close()
 }
 
@@ -198,12 +272,25 @@ func (ls *LedgerState) scheduleCleanupConsumedUtxos() {
 				// Schedule the next run
 				ls.scheduleCleanupConsumedUtxos()
 			}()
-			// Get the current tip, since we're querying by slot
-			tip := ls.Tip()
-			// Get UTxOs that are marked as deleted and older than our slot window
+			// UTxOs consumed at or before the immutable tip can no longer be restored by a rollback
+			ls.RLock()
+			immutableTip, err := ls.immutableTip()
+			ls.RUnlock()
+			if err != nil {
+				ls.config.Logger.Error(
+					"failed to determine immutable tip",
+					"error",
+					err,
+				)
+				return
+			}
+			if len(immutableTip.Point.Hash) == 0 {
+				return
+			}
+			// Get UTxOs that are marked as deleted at or before the immutable tip
 			var tmpUtxos []models.Utxo
 			result := ls.db.Metadata().DB().
-				Where("deleted_slot > 0 AND deleted_slot <= ?", tip.Point.Slot-cleanupConsumedUtxosSlotWindow).
+				Where("deleted_slot > 0 AND deleted_slot <= ?", immutableTip.Point.Slot).
 				Order("id DESC").
 				Find(&tmpUtxos)
 			if result.Error != nil {
@@ -215,7 +302,7 @@ func (ls *LedgerState) scheduleCleanupConsumedUtxos() {
 				return
 			}
 			utxos := []database.Utxo{}
-			for _, utxo := range utxos {
+			for _, utxo := range tmpUtxos {
 				tmpUtxo := database.Utxo{
 					ID:          utxo.ID,
 					TxId:        utxo.TxId,
@@ -224,46 +311,51 @@ func (ls *LedgerState) scheduleCleanupConsumedUtxos() {
 					DeletedSlot: utxo.DeletedSlot,
 					PaymentKey:  utxo.PaymentKey,
 					StakingKey:  utxo.StakingKey,
+					Amount:      utxo.Amount,
 					Cbor:        utxo.Cbor,
 				}
 				utxos = append(utxos, tmpUtxo)
 			}
-			for {
+			for len(utxos) > 0 {
+				batchSize := min(1000, len(utxos))
 				ls.Lock()
 				// Perform updates in a transaction
 				txn := ls.db.Transaction(true)
-				batchSize := min(1000, len(utxos))
-				if batchSize == 0 {
-					ls.Unlock()
-					break
-				}
-				// Delete the UTxOs
-				if err := ls.db.UtxosDelete(utxos[0:batchSize], txn); err != nil {
+				err := txn.Do(func(txn *database.Txn) error {
+					// Delete the UTxOs
+					return ls.db.UtxosDelete(utxos[0:batchSize], txn)
+				})
+				ls.Unlock()
+				if err != nil {
 					ls.config.Logger.Error(
 						"failed to remove consumed UTxO",
 						"component", "ledger",
 						"error", err,
 					)
-					ls.Unlock()
 					break
 				}
 				// Remove batch
 				utxos = slices.Delete(utxos, 0, batchSize)
-				ls.Unlock()
 			}
 		},
 	)
 }
 
 func (ls *LedgerState) rollback(point ocommon.Point) error {
+	var rolledBackBlocks []database.Block
 	// Start a transaction
 	txn := ls.db.Transaction(true)
 	err := txn.Do(func(txn *database.Txn) error {
+		// Blocks more than k blocks behind the tip are immutable
+		if err := ls.checkRollbackDepth(txn, point); err != nil {
+			return err
+		}
 		// Remove rolled-back blocks in reverse order
 		tmpBlocks, err := database.BlocksAfterSlotTxn(txn, point.Slot)
 		if err != nil {
 			return fmt.Errorf("query blocks: %w", err)
 		}
+		rolledBackBlocks = tmpBlocks
 		for _, tmpBlock := range tmpBlocks {
 			if err := ls.removeBlock(txn, tmpBlock); err != nil {
 				return fmt.Errorf("remove block: %w", err)
@@ -289,6 +381,7 @@ func (ls *LedgerState) rollback(point ocommon.Point) error {
 					DeletedSlot: utxo.DeletedSlot,
 					PaymentKey:  utxo.PaymentKey,
 					StakingKey:  utxo.StakingKey,
+					Amount:      utxo.Amount,
 					Cbor:        utxo.Cbor,
 				}
 				utxos = append(utxos, tmpUtxo)
@@ -308,6 +401,10 @@ func (ls *LedgerState) rollback(point ocommon.Point) error {
 				result.Error,
 			)
 		}
+		// Remove rolled-back ledger records
+		if err := ls.rollbackLedgerRecords(txn, point.Slot); err != nil {
+			return err
+		}
 		// Update tip
 		recentBlocks, err := database.BlocksRecentTxn(txn, 1)
 		if err != nil {
@@ -330,18 +427,31 @@ func (ls *LedgerState) rollback(point ocommon.Point) error {
 	if err != nil {
 		return err
 	}
+	// Reload epoch and protocol params, since we may have rolled back across an epoch boundary
+	if err := ls.loadEpoch(); err != nil {
+		return err
+	}
+	if err := ls.loadPParams(); err != nil {
+		return err
+	}
 	// Reload tip
 	if err := ls.loadTip(); err != nil {
 		return err
 	}
-	// Generate event
+	// Generate events
+	rollbackEvent := ChainRollbackEvent{
+		Point:            point,
+		RolledBackBlocks: rolledBackBlocks,
+	}
 	ls.config.EventBus.Publish(
 		ChainRollbackEventType,
+		event.NewEvent(ChainRollbackEventType, rollbackEvent),
+	)
+	ls.config.EventBus.Publish(
+		ChainUpdateEventType,
 		event.NewEvent(
-			ChainRollbackEventType,
-			ChainRollbackEvent{
-				Point: point,
-			},
+			ChainUpdateEventType,
+			ChainUpdateEvent{Rollback: &rollbackEvent},
 		),
 	)
 	var hash string
@@ -362,6 +472,61 @@ func (ls *LedgerState) rollback(point ocommon.Point) error {
 	return nil
 }
 
+// rollbackLedgerRecords removes ledger records that were added after the specified slot
+func (ls *LedgerState) rollbackLedgerRecords(
+	txn *database.Txn,
+	slot uint64,
+) error {
+	// Pool registration owners and relays don't have their own slot, so we remove them
+	// based on their parent registration
+	poolRegIds := txn.Metadata().
+		Model(&models.PoolRegistration{}).
+		Select("id").
+		Where("added_slot > ?", slot)
+	for _, model := range []any{&models.PoolRegistrationOwner{}, &models.PoolRegistrationRelay{}} {
+		result := txn.Metadata().
+			Where("pool_registration_id IN (?)", poolRegIds).
+			Delete(model)
+		if result.Error != nil {
+			return fmt.Errorf("remove rolled-back records: %w", result.Error)
+		}
+	}
+	// Epochs don't have an added slot, so we use the start slot
+	result := txn.Metadata().
+		Where("start_slot > ?", slot).
+		Delete(&models.Epoch{})
+	if result.Error != nil {
+		return fmt.Errorf("remove rolled-back epochs: %w", result.Error)
+	}
+	for _, model := range ledgerRollbackModels {
+		result := txn.Metadata().
+			Where("added_slot > ?", slot).
+			Delete(model)
+		if result.Error != nil {
+			return fmt.Errorf("remove rolled-back records: %w", result.Error)
+		}
+	}
+	// Restore the state of governance proposals and committee members that were updated after the
+	// specified slot
+	for _, prefix := range []string{"ratified", "enacted", "expired"} {
+		result := txn.Metadata().
+			Model(&models.GovernanceProposal{}).
+			Where(prefix+"_slot > ?", slot).
+			Updates(map[string]any{prefix + "_epoch": 0, prefix + "_slot": 0})
+		if result.Error != nil {
+			return fmt.Errorf("restore governance proposals: %w", result.Error)
+		}
+	}
+	result = txn.Metadata().
+		Model(&models.CommitteeMember{}).
+		Where("deleted_slot > ?", slot).
+		Update("deleted_slot", 0)
+	if result.Error != nil {
+		return fmt.Errorf("restore committee members: %w", result.Error)
+	}
+	return nil
+}
+
 func (ls *LedgerState) transitionToEra(
 	txn *database.Txn,
 	nextEraId uint,
@@ -401,64 +566,154 @@ func (ls *LedgerState) transitionToEra(
 			return err
 		}
 	}
+	// Initialize governance state when entering the first era with on-chain governance
+	if nextEra.GovParamsFunc != nil && ls.currentEra.GovParamsFunc == nil {
+		if err := ls.seedGovernance(txn, addedSlot); err != nil {
+			return fmt.Errorf("initialize governance: %w", err)
+		}
+	}
 	ls.currentEra = nextEra
 	return nil
 }
 
+// applyPParamUpdates applies the protocol parameter update proposed for the current epoch, if one has been
+// proposed by enough genesis delegates to meet the update quorum from the Shelley genesis. The specified slot
+// is the first slot of the next epoch
 func (ls *LedgerState) applyPParamUpdates(
 	txn *database.Txn,
 	currentEpoch uint64,
 	addedSlot uint64,
 ) error {
+	shelleyGenesis := ls.config.CardanoNodeConfig.ShelleyGenesis()
+	if shelleyGenesis == nil {
+		return errors.New("could not get genesis config")
+	}
+	// A quorum of 0 would apply any proposal, so we treat it as a config error
+	quorum := shelleyGenesis.UpdateQuorum
+	if quorum <= 0 {
+		return fmt.Errorf("invalid update quorum in Shelley genesis: %d", quorum)
+	}
+	// Proposals for the current epoch must be made at least two stability windows before the end of the
+	// epoch, so that the update is stable before it's applied
+	stabilityWindow, err := ls.nonceStabilityWindow()
+	if err != nil {
+		return err
+	}
+	var cutoffSlot uint64
+	if addedSlot > 2*stabilityWindow {
+		cutoffSlot = addedSlot - 2*stabilityWindow
+	}
 	// Check for pparam updates that apply at the end of the epoch
+	proposals, err := ls.pparamUpdateProposals(txn, currentEpoch, cutoffSlot)
+	if err != nil {
+		return err
+	}
+	if len(proposals) == 0 {
+		return nil
+	}
+	// Count the genesis delegates that proposed each distinct update
+	votes := make(map[string]int)
+	for _, proposal := range proposals {
+		votes[string(proposal.Cbor)]++
+	}
+	// The update is only applied if exactly one proposal meets the quorum
+	var votedUpdates [][]byte
+	for updateCbor, count := range votes {
+		if count >= quorum {
+			votedUpdates = append(votedUpdates, []byte(updateCbor))
+		}
+	}
+	if len(votedUpdates) != 1 {
+		ls.config.Logger.Debug(
+			fmt.Sprintf(
+				"not applying protocol parameter updates for epoch %d: %d proposals from %d genesis delegates, %d meeting quorum of %d",
+				currentEpoch,
+				len(votes),
+				len(proposals),
+				len(votedUpdates),
+				quorum,
+			),
+			"component", "ledger",
+		)
+		return nil
+	}
+	return ls.applyPParamUpdate(txn, votedUpdates[0], currentEpoch+1, addedSlot)
+}
+
+// pparamUpdateProposals returns the protocol parameter update proposals for the specified epoch that were made
+// before the cutoff slot, keyed on the genesis key hash. A later proposal from the same genesis delegate replaces
+// any earlier one
+func (ls *LedgerState) pparamUpdateProposals(
+	txn *database.Txn,
+	epoch uint64,
+	cutoffSlot uint64,
+) (map[string]models.PParamUpdate, error) {
 	pparamUpdates, err := txn.DB().
 		Metadata().
-		GetPParamUpdates(currentEpoch, txn.Metadata())
+		GetPParamUpdates(epoch, txn.Metadata())
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if len(pparamUpdates) > 0 {
-		// We only want the latest for the epoch
-		pparamUpdate := pparamUpdates[0]
-		if ls.currentEra.DecodePParamsUpdateFunc != nil {
-			tmpPParamUpdate, err := ls.currentEra.DecodePParamsUpdateFunc(
-				pparamUpdate.Cbor,
-			)
-			if err != nil {
-				return err
-			}
-			if ls.currentEra.PParamsUpdateFunc != nil {
-				// Update current pparams
-				newPParams, err := ls.currentEra.PParamsUpdateFunc(
-					ls.currentPParams,
-					tmpPParamUpdate,
-				)
-				if err != nil {
-					return err
-				}
-				ls.currentPParams = newPParams
-				ls.config.Logger.Debug(
-					"updated protocol params",
-					"pparams",
-					fmt.Sprintf("%#v", ls.currentPParams),
-				)
-				pparamsCbor, err := cbor.Encode(&ls.currentPParams)
-				if err != nil {
-					return err
-				}
-				// Write pparams update to DB
-				err = txn.DB().Metadata().SetPParams(
-					pparamsCbor,
-					addedSlot,
-					uint64(currentEpoch+1),
-					ls.currentEra.Id,
-					txn.Metadata(),
-				)
-				if err != nil {
-					return err
-				}
-			}
+	ret := make(map[string]models.PParamUpdate)
+	// Updates are returned with the latest first
+	for _, pparamUpdate := range pparamUpdates {
+		if pparamUpdate.AddedSlot >= cutoffSlot {
+			continue
 		}
+		if _, ok := ret[string(pparamUpdate.GenesisHash)]; ok {
+			continue
+		}
+		ret[string(pparamUpdate.GenesisHash)] = pparamUpdate
+	}
+	return ret, nil
+}
+
+// applyPParamUpdate applies a CBOR-encoded protocol parameter update to the current pparams, with the result
+// taking effect in the specified epoch
+func (ls *LedgerState) applyPParamUpdate(
+	txn *database.Txn,
+	updateCbor []byte,
+	epoch uint64,
+	addedSlot uint64,
+) error {
+	if ls.currentEra.DecodePParamsUpdateFunc == nil ||
+		ls.currentEra.PParamsUpdateFunc == nil {
+		return nil
+	}
+	tmpPParamUpdate, err := ls.currentEra.DecodePParamsUpdateFunc(
+		updateCbor,
+	)
+	if err != nil {
+		return err
+	}
+	// Update current pparams
+	newPParams, err := ls.currentEra.PParamsUpdateFunc(
+		ls.currentPParams,
+		tmpPParamUpdate,
+	)
+	if err != nil {
+		return err
+	}
+	ls.currentPParams = newPParams
+	ls.config.Logger.Debug(
+		"updated protocol params",
+		"pparams",
+		fmt.Sprintf("%#v", ls.currentPParams),
+	)
+	pparamsCbor, err := cbor.Encode(&ls.currentPParams)
+	if err != nil {
+		return err
+	}
+	// Write pparams update to DB
+	err = txn.DB().Metadata().SetPParams(
+		pparamsCbor,
+		addedSlot,
+		epoch,
+		ls.currentEra.Id,
+		txn.Metadata(),
+	)
+	if err != nil {
+		return err
 	}
 	return nil
 }
@@ -538,6 +793,19 @@ func (ls *LedgerState) removeBlock(
 }
 
 func (ls *LedgerState) loadPParams() error {
+	// Byron protocol parameters come from the genesis rather than the chain
+	if ls.currentEra.Id == eras.ByronEraDesc.Id {
+		if ls.config.CardanoNodeConfig == nil ||
+			ls.config.CardanoNodeConfig.ByronGenesis() == nil {
+			return nil
+		}
+		byronPParams, err := eras.PParamsByron(ls.config.CardanoNodeConfig)
+		if err != nil {
+			return err
+		}
+		ls.currentPParams = byronPParams
+		return nil
+	}
 	pparams, err := ls.db.Metadata().GetPParams(ls.currentEpoch.EpochId, nil)
 	if err != nil {
 		return err
@@ -564,6 +832,8 @@ func (ls *LedgerState) loadEpoch() error {
 	}
 	ls.currentEpoch = tmpEpoch
 	ls.currentEra = eras.Eras[tmpEpoch.EraId]
+	// Clear cached per-epoch header validation state
+	ls.praosEpochStates = nil
 	// Update metrics
 	ls.metrics.epochNum.Set(float64(ls.currentEpoch.EpochId))
 	return nil
@@ -677,6 +947,11 @@ func (ls *LedgerState) Tip() ochainsync.Tip {
 	return ls.currentTip
 }
 
+// CurrentEpoch returns the current epoch number
+func (ls *LedgerState) CurrentEpoch() uint64 {
+	return ls.currentEpoch.EpochId
+}
+
 // GetCurrentPParams returns the currentPParams value
 func (ls *LedgerState) GetCurrentPParams() lcommon.ProtocolParameters {
 	return ls.currentPParams
@@ -708,6 +983,7 @@ func (ls *LedgerState) UtxosByAddress(
 			DeletedSlot: utxo.DeletedSlot,
 			PaymentKey:  utxo.PaymentKey,
 			StakingKey:  utxo.StakingKey,
+			Amount:      utxo.Amount,
 			Cbor:        utxo.Cbor,
 		}
 		ret = append(ret, tmpUtxo)
@@ -718,13 +994,24 @@ func (ls *LedgerState) UtxosByAddress(
 // ValidateTx runs ledger validation on the provided transaction
 func (ls *LedgerState) ValidateTx(
 	tx lcommon.Transaction,
+) error {
+	return ls.ValidateTxWithOverlay(tx, nil)
+}
+
+// ValidateTxWithOverlay runs ledger validation on the provided transaction, using the UTxO changes from the
+// overlay (if any) on top of the ledger. This allows validating transactions that spend the outputs of other
+// transactions that aren't on chain yet
+func (ls *LedgerState) ValidateTxWithOverlay(
+	tx lcommon.Transaction,
+	overlay UtxoOverlay,
 ) error {
 	if ls.currentEra.ValidateTxFunc != nil {
 		txn := ls.db.Transaction(false)
 		err := txn.Do(func(txn *database.Txn) error {
 			lv := &LedgerView{
-				txn: txn,
-				ls:  ls,
+				txn:     txn,
+				ls:      ls,
+				overlay: overlay,
 			}
 			err := ls.currentEra.ValidateTxFunc(
 				tx,
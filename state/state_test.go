@@ -0,0 +1,283 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/config/cardano"
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"github.com/blinklabs-io/dingo/state/eras"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+const (
+	testEpochLength = 100
+)
+
+// newTestLedgerState returns a LedgerState backed by an in-memory database, for testing the handling of ledger
+// records without needing genesis config or blocks. The in-memory database is shared within the process until
+// it's closed, so tests using this must not run in parallel
+func newTestLedgerState(t *testing.T) *LedgerState {
+	db, err := database.New(&database.Config{}) // in-memory
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	nodeConfig, err := cardano.NewCardanoNodeConfigFromFile(
+		"../config/cardano/testdata/config.json",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return &LedgerState{
+		db: db,
+		config: LedgerStateConfig{
+			Logger:            slog.New(slog.NewJSONHandler(io.Discard, nil)),
+			CardanoNodeConfig: nodeConfig,
+		},
+	}
+}
+
+// testLedgerTxn runs the provided function in a read/write transaction, failing the test on error
+func testLedgerTxn(t *testing.T, ls *LedgerState, fn func(*database.Txn) error) {
+	t.Helper()
+	txn := ls.db.Transaction(true)
+	if err := txn.Do(fn); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+// testKeyHash returns a recognizable 28-byte key hash for use in tests
+func testKeyHash(id byte) []byte {
+	return bytes.Repeat([]byte{id}, 28)
+}
+
+// testAddEpoch adds an epoch record with a fixed length and moves the ledger to it
+func testAddEpoch(t *testing.T, ls *LedgerState, txn *database.Txn, epoch uint64) {
+	t.Helper()
+	err := txn.DB().Metadata().SetEpoch(
+		epoch*testEpochLength,
+		epoch,
+		nil,
+		1,
+		1,
+		testEpochLength,
+		txn.Metadata(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ls.currentEpoch = database.Epoch{
+		EpochId:       epoch,
+		StartSlot:     epoch * testEpochLength,
+		LengthInSlots: testEpochLength,
+	}
+}
+
+// testRegisterPool adds a pool registration with the reward account set to the specified staking key
+func testRegisterPool(
+	t *testing.T,
+	txn *database.Txn,
+	poolId byte,
+	rewardAccount byte,
+	slot uint64,
+	deposit uint64,
+) {
+	t.Helper()
+	cert := &lcommon.PoolRegistrationCertificate{
+		CertType: lcommon.CertificateTypePoolRegistration,
+		Operator: lcommon.PoolKeyHash(lcommon.NewBlake2b224(testKeyHash(poolId))),
+		VrfKeyHash: lcommon.VrfKeyHash(
+			lcommon.NewBlake2b256(bytes.Repeat([]byte{poolId}, 32)),
+		),
+		Pledge:        1000,
+		Cost:          340,
+		Margin:        cbor.Rat{Rat: big.NewRat(1, 100)},
+		RewardAccount: lcommon.AddrKeyHash(lcommon.NewBlake2b224(testKeyHash(rewardAccount))),
+	}
+	err := txn.DB().Metadata().SetPoolRegistration(cert, slot, deposit, txn.Metadata())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+// testDelegateStake registers the specified staking key, delegates it to a pool and adds a UTxO with the
+// specified amount for it
+func testDelegateStake(
+	t *testing.T,
+	txn *database.Txn,
+	stakingKey byte,
+	poolId byte,
+	slot uint64,
+	amount uint64,
+) {
+	t.Helper()
+	stakeCred := lcommon.StakeCredential{
+		CredType:   lcommon.StakeCredentialTypeAddrKeyHash,
+		Credential: testKeyHash(stakingKey),
+	}
+	err := txn.DB().Metadata().SetStakeRegistration(
+		&lcommon.StakeRegistrationCertificate{
+			CertType:          lcommon.CertificateTypeStakeRegistration,
+			StakeRegistration: stakeCred,
+		},
+		slot,
+		2000000,
+		txn.Metadata(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = txn.DB().Metadata().SetStakeDelegation(
+		&lcommon.StakeDelegationCertificate{
+			CertType:        lcommon.CertificateTypeStakeDelegation,
+			StakeCredential: &stakeCred,
+			PoolKeyHash:     lcommon.PoolKeyHash(lcommon.NewBlake2b224(testKeyHash(poolId))),
+		},
+		slot,
+		txn.Metadata(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testAddUtxo(t, txn, stakingKey, slot, amount)
+}
+
+// testAddUtxo adds a UTxO with the specified amount for a staking key
+func testAddUtxo(
+	t *testing.T,
+	txn *database.Txn,
+	stakingKey byte,
+	slot uint64,
+	amount uint64,
+) {
+	t.Helper()
+	result := txn.Metadata().Create(
+		&models.Utxo{
+			TxId:       bytes.Repeat([]byte{stakingKey, byte(slot)}, 16),
+			AddedSlot:  slot,
+			StakingKey: testKeyHash(stakingKey),
+			Amount:     amount,
+		},
+	)
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+}
+
+func TestApplyPParamUpdates(t *testing.T) {
+	// The test genesis has an update quorum of 5 and a stability window of 25920 slots, so proposals must be
+	// made at least 51840 slots before the end of the epoch
+	const epochEndSlot = 172800
+	testDefs := []struct {
+		name          string
+		proposalSlots []uint64
+		quorum        int
+		expectedSize  uint
+		expectedErr   string
+	}{
+		{
+			name:          "quorum before cutoff",
+			proposalSlots: []uint64{100000, 100001, 100002, 100003, 120959},
+			quorum:        5,
+			expectedSize:  16384,
+		},
+		{
+			name:          "late proposal",
+			proposalSlots: []uint64{100000, 100001, 100002, 100003, 120960},
+			quorum:        5,
+			expectedSize:  1000,
+		},
+		{
+			name:          "below quorum",
+			proposalSlots: []uint64{100000, 100001, 100002, 100003},
+			quorum:        5,
+			expectedSize:  1000,
+		},
+		{
+			name:          "zero quorum",
+			proposalSlots: []uint64{100000},
+			quorum:        0,
+			expectedErr:   "invalid update quorum in Shelley genesis: 0",
+		},
+	}
+	for _, testDef := range testDefs {
+		// Each test case needs its own in-memory database
+		t.Run(testDef.name, func(t *testing.T) {
+			ls := newTestLedgerState(t)
+			testHardFork(t, ls, eras.BabbageEraDesc.Id)
+			pparams, ok := ls.currentPParams.(*babbage.BabbageProtocolParameters)
+			if !ok {
+				t.Fatalf("unexpected pparams type: %T", ls.currentPParams)
+			}
+			pparams.MaxTxSize = 1000
+			ls.config.CardanoNodeConfig.ShelleyGenesis().UpdateQuorum = testDef.quorum
+			updateCbor, err := cbor.Encode(map[uint]uint{3: 16384})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			txn := ls.db.Transaction(true)
+			err = txn.Do(func(txn *database.Txn) error {
+				for idx, slot := range testDef.proposalSlots {
+					err := txn.DB().Metadata().SetPParamUpdate(
+						testKeyHash(byte(idx)),
+						updateCbor,
+						slot,
+						1,
+						txn.Metadata(),
+					)
+					if err != nil {
+						return err
+					}
+				}
+				return ls.applyPParamUpdates(txn, 1, epochEndSlot)
+			})
+			if testDef.expectedErr != "" {
+				if err == nil || err.Error() != testDef.expectedErr {
+					t.Fatalf(
+						"did not get expected error: got %v, expected %s",
+						err,
+						testDef.expectedErr,
+					)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			pparams, ok = ls.currentPParams.(*babbage.BabbageProtocolParameters)
+			if !ok {
+				t.Fatalf("unexpected pparams type: %T", ls.currentPParams)
+			}
+			if pparams.MaxTxSize != testDef.expectedSize {
+				t.Fatalf(
+					"did not get expected max TX size: got %d, expected %d",
+					pparams.MaxTxSize,
+					testDef.expectedSize,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/state/eras"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	"github.com/blinklabs-io/gouroboros/ledger/allegra"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+// ValidationLevel controls how much validation is performed on blocks received from upstream peers
+type ValidationLevel int
+
+const (
+	// ValidationLevelTrustUpstream trusts blocks from upstream peers. Transactions are still validated,
+	// but failures are only logged
+	ValidationLevelTrustUpstream ValidationLevel = iota
+	// ValidationLevelTx rejects blocks containing transactions that fail validation
+	ValidationLevelTx
+	// ValidationLevelBody additionally checks the block body against the body hash and size from the block header
+	ValidationLevelBody
+	// ValidationLevelFull additionally validates the block header
+	ValidationLevelFull
+)
+
+var validationLevelNames = map[ValidationLevel]string{
+	ValidationLevelTrustUpstream: "trust",
+	ValidationLevelTx:            "tx",
+	ValidationLevelBody:          "body",
+	ValidationLevelFull:          "full",
+}
+
+func (l ValidationLevel) String() string {
+	if name, ok := validationLevelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", int(l))
+}
+
+// ParseValidationLevel returns the ValidationLevel for the specified name. An empty name returns the default level
+func ParseValidationLevel(name string) (ValidationLevel, error) {
+	if name == "" {
+		return ValidationLevelTrustUpstream, nil
+	}
+	for level, levelName := range validationLevelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown validation level: %s", name)
+}
+
+// BlockValidationError is returned when a block received from an upstream peer fails validation
+type BlockValidationError struct {
+	Point ocommon.Point
+	Err   error
+}
+
+func (e BlockValidationError) Error() string {
+	return fmt.Sprintf(
+		"block %x at slot %d failed validation: %s",
+		e.Point.Hash,
+		e.Point.Slot,
+		e.Err,
+	)
+}
+
+func (e BlockValidationError) Unwrap() error {
+	return e.Err
+}
+
+// validateBlockBody checks the block body against the body hash and size from the block header
+func (ls *LedgerState) validateBlockBody(e BlockfetchEvent) error {
+	var expectedHash lcommon.Blake2b256
+	var expectedSize uint64
+	switch h := e.Block.Header().(type) {
+	case *ledger.ShelleyBlockHeader:
+		expectedHash = h.Body.BlockBodyHash
+		expectedSize = h.Body.BlockBodySize
+	case *allegra.AllegraBlockHeader:
+		expectedHash = h.Body.BlockBodyHash
+		expectedSize = h.Body.BlockBodySize
+	case *ledger.MaryBlockHeader:
+		expectedHash = h.Body.BlockBodyHash
+		expectedSize = h.Body.BlockBodySize
+	case *ledger.AlonzoBlockHeader:
+		expectedHash = h.Body.BlockBodyHash
+		expectedSize = h.Body.BlockBodySize
+	case *ledger.BabbageBlockHeader:
+		expectedHash = h.Body.BlockBodyHash
+		expectedSize = h.Body.BlockBodySize
+	case *ledger.ConwayBlockHeader:
+		expectedHash = h.Body.BlockBodyHash
+		expectedSize = h.Body.BlockBodySize
+	default:
+		// Byron blocks don't include a body hash in this form
+		return nil
+	}
+	bodyHash, bodySize, err := blockBodyHash(e.Block.Cbor())
+	if err != nil {
+		return err
+	}
+	if bodySize != expectedSize {
+		return fmt.Errorf(
+			"block body size %d does not match header (%d)",
+			bodySize,
+			expectedSize,
+		)
+	}
+	if bodyHash != expectedHash {
+		return fmt.Errorf(
+			"block body hash %s does not match header (%s)",
+			bodyHash.String(),
+			expectedHash.String(),
+		)
+	}
+	return nil
+}
+
+// blockBodyHash calculates the body hash and size for a Shelley-era or later block. The body hash is the
+// hash of the concatenated hashes of each block body component (TX bodies, witness sets, metadata, and
+// invalid TXs as of Alonzo), and the body size is the combined size of those components
+func blockBodyHash(blockCbor []byte) (lcommon.Blake2b256, uint64, error) {
+	var blockParts []cbor.RawMessage
+	if _, err := cbor.Decode(blockCbor, &blockParts); err != nil {
+		return lcommon.Blake2b256{}, 0, fmt.Errorf("decode block: %w", err)
+	}
+	if len(blockParts) < 2 {
+		return lcommon.Blake2b256{}, 0, errors.New("block has no body")
+	}
+	var bodySize uint64
+	partHashes := make([]byte, 0, (len(blockParts)-1)*32)
+	for _, part := range blockParts[1:] {
+		bodySize += uint64(len(part))
+		partHash := lcommon.Blake2b256Hash(part)
+		partHashes = append(partHashes, partHash.Bytes()...)
+	}
+	return lcommon.Blake2b256Hash(partHashes), bodySize, nil
+}
+
+// validateBlockHeader checks the block header against the current chain tip and protocol parameters, and validates
+// the VRF proofs, KES signature, and operational certificate
+func (ls *LedgerState) validateBlockHeader(
+	txn *database.Txn,
+	e BlockfetchEvent,
+) error {
+	// There's nothing to check for Byron blocks beyond what's already covered by the chain difficulty
+	if ls.currentEra.Id == eras.ByronEraDesc.Id {
+		return nil
+	}
+	if len(ls.currentTip.Point.Hash) > 0 &&
+		e.Point.Slot <= ls.currentTip.Point.Slot {
+		return fmt.Errorf(
+			"block slot %d is not after current tip slot %d",
+			e.Point.Slot,
+			ls.currentTip.Point.Slot,
+		)
+	}
+	if ls.currentPParams != nil {
+		if err := ls.validateBlockSizes(e); err != nil {
+			return err
+		}
+	}
+	return ls.validateBlockHeaderPraos(txn, e.Block.Header(), true)
+}
+
+// validateChainsyncHeader validates the VRF proofs, KES signature, and operational certificate from a block header
+// received via chainsync, before we fetch the block. We can only do this for headers from an epoch whose state we
+// know, which covers a header from the current epoch or the next one once its nonce is fixed. The rest are
+// validated when their blocks are applied
+func (ls *LedgerState) validateChainsyncHeader(header ledger.BlockHeader) error {
+	switch header.(type) {
+	case *ledger.ByronMainBlockHeader, *ledger.ByronEpochBounaryBlockHeader:
+		return nil
+	}
+	ls.Lock()
+	defer ls.Unlock()
+	// We have no stake distribution before the first Shelley epoch
+	if ls.currentEra.Id == eras.ByronEraDesc.Id {
+		return nil
+	}
+	// The header may be from a fork that branches off before our tip, so we leave checking the operational
+	// certificate issue number against our chain until its block is applied
+	txn := ls.db.Transaction(false)
+	err := txn.Do(func(txn *database.Txn) error {
+		return ls.validateBlockHeaderPraos(txn, header, false)
+	})
+	if errors.Is(err, errPraosEpochStateUnknown) {
+		return nil
+	}
+	return err
+}
+
+// validateBlockSizes checks the block header and body sizes against the current protocol parameters
+func (ls *LedgerState) validateBlockSizes(e BlockfetchEvent) error {
+	pparams := ls.currentPParams.Utxorpc()
+	if pparams == nil {
+		return nil
+	}
+	headerSize := uint64(len(e.Block.Header().Cbor()))
+	if pparams.GetMaxBlockHeaderSize() > 0 &&
+		headerSize > pparams.GetMaxBlockHeaderSize() {
+		return fmt.Errorf(
+			"block header size %d exceeds maximum (%d)",
+			headerSize,
+			pparams.GetMaxBlockHeaderSize(),
+		)
+	}
+	bodySize := e.Block.BlockBodySize()
+	if pparams.GetMaxBlockBodySize() > 0 &&
+		bodySize > pparams.GetMaxBlockBodySize() {
+		return fmt.Errorf(
+			"block body size %d exceeds maximum (%d)",
+			bodySize,
+			pparams.GetMaxBlockBodySize(),
+		)
+	}
+	return nil
+}
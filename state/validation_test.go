@@ -0,0 +1,53 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"testing"
+)
+
+func TestParseValidationLevel(t *testing.T) {
+	testDefs := []struct {
+		name     string
+		expected ValidationLevel
+		err      bool
+	}{
+		{name: "", expected: ValidationLevelTrustUpstream},
+		{name: "trust", expected: ValidationLevelTrustUpstream},
+		{name: "tx", expected: ValidationLevelTx},
+		{name: "Body", expected: ValidationLevelBody},
+		{name: "full", expected: ValidationLevelFull},
+		{name: "bogus", err: true},
+	}
+	for _, testDef := range testDefs {
+		level, err := ParseValidationLevel(testDef.name)
+		if err != nil {
+			if !testDef.err {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			continue
+		}
+		if testDef.err {
+			t.Fatalf("did not get expected error for name %q", testDef.name)
+		}
+		if level != testDef.expected {
+			t.Fatalf(
+				"did not get expected level: got %s, expected %s",
+				level,
+				testDef.expected,
+			)
+		}
+	}
+}
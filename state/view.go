@@ -15,13 +15,25 @@
 package state
 
 import (
+	"fmt"
+
 	"github.com/blinklabs-io/dingo/database"
 	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
 )
 
+// UtxoOverlay provides the UTxOs consumed and produced by transactions that aren't on chain yet, such as those in
+// the mempool. It's consulted before the ledger when validating a transaction
+type UtxoOverlay interface {
+	// ConsumedBy returns the hash of the pending transaction that consumes the specified UTxO, if any
+	ConsumedBy(lcommon.TransactionInput) (string, bool)
+	// ProducedUtxo returns the specified UTxO if it's produced by a pending transaction
+	ProducedUtxo(lcommon.TransactionInput) (lcommon.Utxo, bool)
+}
+
 type LedgerView struct {
-	ls  *LedgerState
-	txn *database.Txn
+	ls      *LedgerState
+	txn     *database.Txn
+	overlay UtxoOverlay
 }
 
 func (lv *LedgerView) NetworkId() uint {
@@ -40,6 +52,18 @@ func (lv *LedgerView) NetworkId() uint {
 func (lv *LedgerView) UtxoById(
 	utxoId lcommon.TransactionInput,
 ) (lcommon.Utxo, error) {
+	if lv.overlay != nil {
+		if txHash, ok := lv.overlay.ConsumedBy(utxoId); ok {
+			return lcommon.Utxo{}, fmt.Errorf(
+				"UTxO %s is consumed by pending TX %s",
+				utxoId.String(),
+				txHash,
+			)
+		}
+		if utxo, ok := lv.overlay.ProducedUtxo(utxoId); ok {
+			return utxo, nil
+		}
+	}
 	utxo, err := lv.ls.db.UtxoByRef(
 		utxoId.Id().Bytes(),
 		utxoId.Index(),
@@ -133,6 +133,21 @@ func (n *Node) txsubmissionServerInit(ctx otxsubmission.CallbackContext) error {
 						txBody.TxBody,
 					)
 					if err != nil {
+						// Keep receiving transactions from the peer while our mempool is full
+						if errors.Is(err, mempool.ErrMempoolFull) {
+							n.config.logger.Debug(
+								fmt.Sprintf(
+									"dropped tx %s: %s",
+									tx.Hash(),
+									err,
+								),
+								"component", "network",
+								"protocol", "tx-submission",
+								"role", "server",
+								"connection_id", ctx.ConnectionId.String(),
+							)
+							continue
+						}
 						n.config.logger.Error(
 							fmt.Sprintf(
 								"failed to add tx %x to mempool: %s",
@@ -166,9 +181,9 @@ func (n *Node) txsubmissionClientRequestTxIds(
 	if ack > 0 {
 		consumer.ClearCache()
 	}
-	// Get available TXs
+	// Get available TXs, up to the number requested
 	var tmpTxs []*mempool.MempoolTransaction
-	for {
+	for len(tmpTxs) < int(req) {
 		if blocking && len(tmpTxs) == 0 {
 			// Wait until we see a TX
 			tmpTx := consumer.NextTx(true)
@@ -0,0 +1,111 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utxorpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/blinklabs-io/dingo/forge"
+)
+
+const (
+	// LeaderlogPath is the HTTP path for querying the leader schedule of a pool
+	LeaderlogPath = "/leaderlog"
+
+	// Maximum size of a leader schedule request body, which contains a VRF signing key text envelope
+	leaderlogMaxRequestSize = 64 * 1024
+)
+
+// handleLeaderlog returns the leader schedule for a pool in the current or next epoch as JSON. A GET request
+// returns the values needed to calculate the slots. A POST request with the pool's VRF signing key in the
+// cardano-cli text envelope format as the body also returns the assigned slots. The node never uses its own
+// forging key here, since this endpoint is served without authentication
+func (u *Utxorpc) handleLeaderlog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var next bool
+	switch epoch := r.URL.Query().Get("epoch"); epoch {
+	case "", "current":
+	case "next":
+		next = true
+	default:
+		http.Error(
+			w,
+			fmt.Sprintf("invalid epoch: %s", epoch),
+			http.StatusBadRequest,
+		)
+		return
+	}
+	poolIdParam := r.URL.Query().Get("pool")
+	if poolIdParam == "" {
+		http.Error(w, "pool is required", http.StatusBadRequest)
+		return
+	}
+	poolKeyHash, err := forge.ParsePoolId(poolIdParam)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var vrfSkey []byte
+	if r.Method == http.MethodPost {
+		reqBody, err := io.ReadAll(
+			http.MaxBytesReader(w, r.Body, leaderlogMaxRequestSize),
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		vrfSkey, err = forge.ParseVrfSigningKey(reqBody)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	schedule, err := forge.NewLeaderSchedule(
+		u.config.LedgerState,
+		poolKeyHash,
+		next,
+	)
+	if err != nil {
+		u.config.Logger.Error(
+			"failed to get leader schedule",
+			"error", err,
+		)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if vrfSkey != nil {
+		if err := schedule.CalculateSlots(vrfSkey); err != nil {
+			u.config.Logger.Error(
+				"failed to calculate leader schedule",
+				"error", err,
+			)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(schedule); err != nil {
+		u.config.Logger.Error(
+			"failed to write leader schedule",
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utxorpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+)
+
+// ImmutableTipPath is the HTTP path for querying the immutable tip, which is the most recent block that can no
+// longer be rolled back
+const ImmutableTipPath = "/immutable-tip"
+
+type immutableTipResponse struct {
+	Slot        uint64 `json:"slot"`
+	Hash        string `json:"hash"`
+	BlockNumber uint64 `json:"blockNumber"`
+}
+
+// handleImmutableTip returns the immutable tip as JSON. The response contains an empty hash when the chain
+// doesn't contain more than k blocks yet
+func (u *Utxorpc) handleImmutableTip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	tip, err := u.config.LedgerState.ImmutableTip()
+	if err != nil {
+		u.config.Logger.Error(
+			"failed to get immutable tip",
+			"error", err,
+		)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	resp := immutableTipResponse{
+		Slot:        tip.Point.Slot,
+		Hash:        hex.EncodeToString(tip.Point.Hash),
+		BlockNumber: tip.BlockNumber,
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		u.config.Logger.Error(
+			"failed to write immutable tip",
+			"error", err,
+		)
+	}
+}
@@ -89,6 +89,8 @@ func (u *Utxorpc) Start() error {
 	mux.Handle(submitPath, submitHandler)
 	mux.Handle(syncPath, syncHandler)
 	mux.Handle(watchPath, watchHandler)
+	mux.HandleFunc(LeaderlogPath, u.handleLeaderlog)
+	mux.HandleFunc(ImmutableTipPath, u.handleImmutableTip)
 	mux.Handle(
 		grpchealth.NewHandler(
 			grpchealth.NewStaticChecker(